@@ -0,0 +1,64 @@
+// Archivo: tools/drone-observe/cmd/acl.go
+// Rol: comando acl para verificar la matriz de permisos MQTT contra un broker real.
+// No hace: editar acl_file ni crear usuarios; el broker de laboratorio es el servicio mqtt-acl de compose.
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"drone-observe/internal/acl"
+	"drone-observe/internal/config"
+	"drone-observe/internal/repo"
+)
+
+func runACL(cfg config.Config, flags []string) int {
+	file := cfg.ACLMatrix
+	if v := flagValue(flags, "--matrix"); v != "" {
+		file = v
+	}
+	path, err := repo.Resolve(file)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	m, err := acl.LoadMatrix(path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	host, port := cfg.MQTTHost, cfg.MQTTPort
+	broker := m.Broker
+	if v := flagValue(flags, "--broker"); v != "" {
+		broker = v
+	}
+	if broker != "" {
+		if host, port, err = acl.SplitBroker(broker); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+	}
+
+	report := acl.Run(m, host, port)
+	fmt.Printf("Matriz ACL: %s (broker %s, observador %s)\n", file, report.Broker, m.Observer)
+	passed := 0
+	for _, r := range report.Results {
+		status := "FALLO"
+		if r.Pass() {
+			status = "OK"
+			passed++
+		}
+		observed := r.Observed
+		if observed == "" {
+			observed = "?"
+		}
+		fmt.Printf("  %-6s %-16s %-9s %-24s esperado %-5s observado %-5s %s\n",
+			status, r.Identity, r.Op, r.Topic, r.Expected, observed, r.Detail)
+	}
+	fmt.Printf("%d/%d OK\n", passed, len(report.Results))
+	if report.Failed() {
+		return 1
+	}
+	return 0
+}
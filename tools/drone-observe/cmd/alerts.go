@@ -0,0 +1,32 @@
+// Archivo: tools/drone-observe/cmd/alerts.go
+// Rol: comando alerts para ver alertas de Prometheus/Alertmanager y operar silencios.
+// No hace: crear reglas de alerta; eso es `generate rules`.
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"drone-observe/internal/config"
+	"drone-observe/internal/ui"
+)
+
+const defaultSilenceDuration = 2 * time.Hour
+
+func runAlerts(cfg config.Config, flags []string) int {
+	silenceFor := defaultSilenceDuration
+	if v := flagValue(flags, "--silence-duration"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			fmt.Fprintf(os.Stderr, "duracion de silencio invalida: %s\n", v)
+			return 2
+		}
+		silenceFor = d
+	}
+	if err := ui.RunAlerts(cfg, silenceFor); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
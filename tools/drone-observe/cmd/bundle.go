@@ -0,0 +1,115 @@
+// Archivo: tools/drone-observe/cmd/bundle.go
+// Rol: comando bundle para juntar la evidencia de un incidente en un tar.gz con manifest y checksums.
+// No hace: subir el bundle a ningun lado; se adjunta a mano al ticket.
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"drone-observe/internal/bundle"
+	"drone-observe/internal/config"
+	"drone-observe/internal/ui"
+)
+
+const (
+	defaultBundleWindow  = time.Hour
+	defaultBundleCapture = 10 * time.Second
+)
+
+func runBundle(cfg config.Config, flags []string) int {
+	now := time.Now()
+	opts := bundle.Options{Window: defaultBundleWindow, Capture: defaultBundleCapture}
+	for _, f := range []struct {
+		name string
+		dst  *time.Duration
+		zero bool
+	}{{"--window", &opts.Window, false}, {"--step", &opts.Step, false}, {"--capture", &opts.Capture, true}} {
+		v := flagValue(flags, f.name)
+		if v == "" {
+			continue
+		}
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 || (d == 0 && !f.zero) {
+			fmt.Fprintf(os.Stderr, "%s invalido: %s\n", f.name, v)
+			return 2
+		}
+		*f.dst = d
+	}
+	if opts.Step == 0 {
+		opts.Step = (opts.Window / rangeStepCount).Round(time.Second)
+		if opts.Step < minRangeStep {
+			opts.Step = minRangeStep
+		}
+	}
+	out := flagValue(flags, "--out")
+	if out == "" {
+		out = fmt.Sprintf("drone-observe-bundle-%s.tar.gz", now.UTC().Format("20060102T150405Z"))
+	}
+
+	w, err := bundle.Create(out, bundle.Manifest{
+		CreatedAt: now,
+		Window:    opts.Window.String(),
+		Step:      opts.Step.String(),
+		Capture:   opts.Capture.String(),
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	fmt.Printf("Recolectando bundle (rango %s, captura MQTT %s)...\n", opts.Window, opts.Capture)
+	checkList := append(bundle.Checks(), bundle.Check{
+		Name: "validate",
+		Run:  func(cfg config.Config) (any, error) { return ui.ValidateReport(cfg), nil },
+	})
+	if err := bundle.Collect(w, cfg, opts, checkList); err != nil {
+		w.Abort()
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	manifest, err := w.Close()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	failed := manifest.Failed()
+	fmt.Printf("Bundle: %s\n", out)
+	for _, e := range failed {
+		fmt.Printf("  [error] %-36s %s\n", e.Path, e.Error)
+	}
+	fmt.Printf("%d archivos, %d con error, %s\n", len(manifest.Entries)-countMissing(manifest), len(failed),
+		manifest.FinishedAt.Sub(manifest.CreatedAt).Round(time.Second))
+	if sum, err := fileSHA256(out); err == nil {
+		fmt.Printf("sha256 %s\n", sum)
+	}
+	return 0
+}
+
+// countMissing cuenta entradas sin archivo (fallaron sin cuerpo).
+func countMissing(m bundle.Manifest) int {
+	n := 0
+	for _, e := range m.Entries {
+		if e.SHA256 == "" {
+			n++
+		}
+	}
+	return n
+}
+
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
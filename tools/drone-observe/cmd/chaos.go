@@ -0,0 +1,141 @@
+// Archivo: tools/drone-observe/cmd/chaos.go
+// Rol: comando chaos para correr un escenario de fallas sobre el pipeline y medir la latencia de deteccion.
+// No hace: detener el edge ni tocar compose; el escenario ocupa el lugar del edge y exige que este callado.
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"drone-observe/internal/acl"
+	"drone-observe/internal/config"
+	"drone-observe/internal/mqttclient"
+	"drone-observe/internal/repo"
+	"drone-observe/internal/scenario"
+	"drone-observe/internal/sim"
+	"drone-observe/internal/ui"
+)
+
+// chaosPreflight es cuanto se escucha <topic>/telemetry antes de empezar para detectar otro productor.
+const chaosPreflight = 3 * time.Second
+
+func runChaos(cfg config.Config, flags []string) int {
+	file := cfg.ChaosScenario
+	if v := flagValue(flags, "--scenario"); v != "" {
+		file = v
+	}
+	path, err := repo.Resolve(file)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	s, err := scenario.Load(path, ui.LiveChecks)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	if s.Topic == "" {
+		s.Topic = cfg.MQTTBaseTopic
+	}
+
+	host, port := cfg.MQTTHost, cfg.MQTTPort
+	broker := s.Broker
+	if v := flagValue(flags, "--broker"); v != "" {
+		broker = v
+	}
+	if broker != "" {
+		if host, port, err = acl.SplitBroker(broker); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+	}
+
+	fmt.Printf("Escenario: %s (%s, %d pasos, %s)\n", s.Name, file, len(s.Steps), s.Duration())
+	fmt.Printf("  dron en %s:%d: %s/telemetry a %g msg/s, checks cada %s\n", host, port, s.Topic, s.Rate, s.PollInterval())
+	if hasFlag(flags, "--dry-run") {
+		for i, st := range s.Steps {
+			fmt.Printf("  [%d] %-20s %-9s %s%s\n", i+1, st.Name, st.Action, st.Duration(), factorLabel(st))
+			for _, e := range st.Expect {
+				fmt.Printf("        espera %s/%s = %s en %s\n", e.Check, e.Item, e.State, e.Deadline())
+			}
+		}
+		return 0
+	}
+
+	if err := chaosQuiet(host, port, s.Topic); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	env := scenario.Env{
+		Cfg: cfg,
+		Dial: func(clientID string) (sim.Publisher, error) {
+			client, err := mqttclient.Dial(host, port, mqttclient.Options{ClientID: clientID})
+			if err != nil {
+				return nil, err
+			}
+			return client, nil
+		},
+		States:   ui.LiveStates,
+		Progress: func(line string) { fmt.Println(line) },
+		Seed:     time.Now().UnixNano(),
+	}
+	report := scenario.Run(ctx, s, env)
+
+	fmt.Println()
+	fmt.Println("Reporte:")
+	met, total := 0, 0
+	for _, st := range report.Steps {
+		fmt.Printf("  %s (%s%s)\n", st.Step.Name, st.Step.Action, factorLabel(st.Step))
+		for _, r := range st.Expects {
+			total++
+			status, detail := "FALLO", fmt.Sprintf("no detectado en %s (ultimo: %s)", r.Expect.Deadline(), r.Observed)
+			if r.Met {
+				met++
+				status, detail = "OK", fmt.Sprintf("detectado en %s", r.Latency.Round(time.Second))
+			}
+			fmt.Printf("    %-6s %-10s %-24s esperado %-10s %s\n", status, r.Expect.Check, r.Expect.Item, r.Expect.State, detail)
+		}
+	}
+	fmt.Printf("%d/%d OK\n", met, total)
+	if report.Err != nil {
+		fmt.Fprintf(os.Stderr, "escenario interrumpido: %v\n", report.Err)
+	}
+	if report.Failed() {
+		return 1
+	}
+	return 0
+}
+
+// chaosQuiet falla si otro productor (normalmente el edge) publica en el topic: mezclaria su trafico con las fallas.
+func chaosQuiet(host string, port int, topic string) error {
+	client, err := mqttclient.Dial(host, port, mqttclient.Options{ClientID: "drone-observe-chaos-preflight"})
+	if err != nil {
+		return fmt.Errorf("mqtt %s:%d: %w", host, port, err)
+	}
+	defer client.Close()
+	filter := strings.TrimSuffix(topic, "/") + "/telemetry"
+	if _, err := client.Subscribe(filter, 0); err != nil {
+		return fmt.Errorf("mqtt subscribe %s: %w", filter, err)
+	}
+	select {
+	case <-client.Messages():
+		return fmt.Errorf("%s ya recibe telemetria de otro productor; detener el edge antes del escenario (docker compose stop edge)", filter)
+	case <-time.After(chaosPreflight):
+		return nil
+	}
+}
+
+func factorLabel(st scenario.Step) string {
+	if st.Action != scenario.ActionFlood {
+		return ""
+	}
+	return fmt.Sprintf(" x%g", st.Factor)
+}
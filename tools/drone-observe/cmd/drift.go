@@ -4,12 +4,14 @@
 package cmd
 
 import (
+	"time"
+
 	"drone-observe/internal/config"
 	"drone-observe/internal/ui"
 )
 
-func runDrift(cfg config.Config) int {
-	if err := ui.RunDrift(cfg); err != nil {
+func runDrift(cfg config.Config, watch time.Duration) int {
+	if err := ui.RunDrift(cfg, watch); err != nil {
 		return 1
 	}
 	return 0
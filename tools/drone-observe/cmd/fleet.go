@@ -0,0 +1,16 @@
+// Archivo: tools/drone-observe/cmd/fleet.go
+// Rol: comando fleet para ver estado por dron (drone_id) con detalle opcional.
+// No hace: acciones sobre drones ni cambios de configuracion.
+package cmd
+
+import (
+	"drone-observe/internal/config"
+	"drone-observe/internal/ui"
+)
+
+func runFleet(cfg config.Config, drone string) int {
+	if err := ui.RunFleet(cfg, drone); err != nil {
+		return 1
+	}
+	return 0
+}
@@ -4,12 +4,14 @@
 package cmd
 
 import (
+	"time"
+
 	"drone-observe/internal/config"
 	"drone-observe/internal/ui"
 )
 
-func runFreshness(cfg config.Config) int {
-	if err := ui.RunFreshness(cfg); err != nil {
+func runFreshness(cfg config.Config, watch time.Duration) int {
+	if err := ui.RunFreshness(cfg, watch); err != nil {
 		return 1
 	}
 	return 0
@@ -0,0 +1,92 @@
+// Archivo: tools/drone-observe/cmd/generate.go
+// Rol: comando generate para producir artefactos (dashboards, reglas) desde el contrato METRICS.md.
+// No hace: aplicar los artefactos en Grafana/Prometheus; se versionan y provisionan como siempre.
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"drone-observe/internal/config"
+	"drone-observe/internal/contract"
+	"drone-observe/internal/generate"
+)
+
+func runGenerate(cfg config.Config, flags []string) int {
+	target := ""
+	if len(flags) > 0 && !strings.HasPrefix(flags[0], "-") {
+		target = flags[0]
+	}
+
+	catalog, err := contract.Load(cfg.MetricsDocPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	var out []byte
+	switch target {
+	case "dashboard":
+		out, err = generate.Dashboard(catalog, generate.DashboardOptions{
+			UID:           flagValue(flags, "--uid"),
+			Title:         flagValue(flags, "--title"),
+			IncludeFuture: hasFlag(flags, "--future"),
+		})
+	case "rules":
+		out, err = generate.RulesYAML(catalog, hasFlag(flags, "--future"))
+	default:
+		fmt.Fprintf(os.Stderr, "artefacto desconocido: %q (validos: dashboard, rules)\n", target)
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return writeGenerated(out, flagValue(flags, "--out"), hasFlag(flags, "--check"))
+}
+
+// PARTE CRITICA **********************
+// --check compara sin escribir y sale 1 si el archivo versionado no coincide con lo generado.
+// Si se escribe en modo --check, CI "arregla" el drift en vez de reportarlo.
+// FIN DE PARTE CRITICA ****************
+func writeGenerated(out []byte, path string, check bool) int {
+	if path == "" {
+		if check {
+			fmt.Fprintln(os.Stderr, "--check requiere --out <archivo>")
+			return 2
+		}
+		os.Stdout.Write(out)
+		return 0
+	}
+	current, err := os.ReadFile(path)
+	same := err == nil && bytes.Equal(current, out)
+	if check {
+		if same {
+			fmt.Fprintf(os.Stderr, "%s al dia\n", path)
+			return 0
+		}
+		fmt.Fprintf(os.Stderr, "%s difiere de lo generado (ejecutar sin --check para regenerar)\n", path)
+		return 1
+	}
+	if same {
+		fmt.Fprintf(os.Stderr, "%s sin cambios\n", path)
+		return 0
+	}
+	if err := os.WriteFile(path, out, 0o644); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	fmt.Fprintf(os.Stderr, "%s generado\n", path)
+	return 0
+}
+
+func hasFlag(flags []string, name string) bool {
+	for _, f := range flags {
+		if f == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,157 @@
+// Archivo: tools/drone-observe/cmd/history.go
+// Rol: flags globales --at y --range: evaluar checks de Prometheus en un instante pasado o a lo largo de un rango.
+// No hace: evaluar lo que solo existe en vivo (broker, HTTP, /metrics); cada check lo omite en modo historico.
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"drone-observe/internal/config"
+	"drone-observe/internal/timeline"
+	"drone-observe/internal/ui"
+)
+
+// atCommands son los comandos que aceptan --at.
+var atCommands = map[string]bool{"freshness": true, "limits": true, "topology": true, "validate": true, "fleet": true}
+
+const (
+	minRangeStep   = 15 * time.Second
+	rangeStepCount = 120
+)
+
+// parseAt lee --at <instante>; cero significa "ahora".
+func parseAt(flags []string, now time.Time) (time.Time, error) {
+	v := flagValue(flags, "--at")
+	if v == "" {
+		return time.Time{}, nil
+	}
+	t, err := parseInstant(v, now)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("--at: %w", err)
+	}
+	return t, nil
+}
+
+// PARTE CRITICA **********************
+// Las horas sin fecha ("03:12") son la ocurrencia mas reciente en el pasado, en hora local: "a las 03:12
+// de anoche" no debe convertirse en una evaluacion en el futuro (Prometheus la responderia con datos
+// actuales). Un instante futuro se rechaza siempre.
+// FIN DE PARTE CRITICA ****************
+func parseInstant(v string, now time.Time) (time.Time, error) {
+	if strings.HasPrefix(v, "-") {
+		d, err := time.ParseDuration(v[1:])
+		if err != nil || d <= 0 {
+			return time.Time{}, fmt.Errorf("duracion relativa invalida: %s", v)
+		}
+		return now.Add(-d), nil
+	}
+	if secs, err := strconv.ParseFloat(v, 64); err == nil && !strings.Contains(v, ":") {
+		return checkPast(time.UnixMilli(int64(secs*1000)), now)
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return checkPast(t, now)
+	}
+	for _, layout := range []string{"2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02T15:04", "2006-01-02 15:04"} {
+		if t, err := time.ParseInLocation(layout, v, now.Location()); err == nil {
+			return checkPast(t, now)
+		}
+	}
+	for _, layout := range []string{"15:04:05", "15:04"} {
+		if clock, err := time.ParseInLocation(layout, v, now.Location()); err == nil {
+			t := time.Date(now.Year(), now.Month(), now.Day(), clock.Hour(), clock.Minute(), clock.Second(), 0, now.Location())
+			if t.After(now) {
+				t = t.AddDate(0, 0, -1)
+			}
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("instante invalido %q (RFC3339, 2006-01-02 15:04[:05], 15:04[:05], unix o -<duracion>)", v)
+}
+
+func checkPast(t, now time.Time) (time.Time, error) {
+	if t.After(now) {
+		return time.Time{}, fmt.Errorf("instante en el futuro: %s", t.Format(time.RFC3339))
+	}
+	return t, nil
+}
+
+// parseRange lee --range <desde>,<hasta> o --range <duracion> (terminando en --at o ahora) y --step.
+func parseRange(flags []string, end, now time.Time) (time.Time, time.Time, time.Duration, error) {
+	v := flagValue(flags, "--range")
+	var from, to time.Time
+	if start, stop, ok := strings.Cut(v, ","); ok {
+		var err error
+		if from, err = parseInstant(start, now); err != nil {
+			return from, to, 0, fmt.Errorf("--range: %w", err)
+		}
+		if to, err = parseInstant(stop, now); err != nil {
+			return from, to, 0, fmt.Errorf("--range: %w", err)
+		}
+	} else {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return from, to, 0, fmt.Errorf("--range invalido: %s (<desde>,<hasta> o duracion)", v)
+		}
+		to = end
+		from = to.Add(-d)
+	}
+
+	step := (to.Sub(from) / rangeStepCount).Round(time.Second)
+	if step < minRangeStep {
+		step = minRangeStep
+	}
+	if s := flagValue(flags, "--step"); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil || d <= 0 {
+			return from, to, 0, fmt.Errorf("--step invalido: %s", s)
+		}
+		step = d
+	}
+	return from, to, step, nil
+}
+
+func runRange(cfg config.Config, check string, flags []string) int {
+	eval, ok := ui.CheckStates(check)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "--range no soportado por %q (validos: %s)\n", check, strings.Join(ui.RangeChecks, ", "))
+		return 2
+	}
+	now := time.Now()
+	from, to, step, err := parseRange(flags, cfg.EvalTime(), now)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	tl, err := timeline.Run(from, to, step, func(at time.Time) map[string]string {
+		c := cfg
+		c.At = at
+		return eval(c)
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	withDate := from.YearDay() != to.YearDay() || from.Year() != to.Year()
+	fmt.Printf("Linea de tiempo %s: %s -> %s (paso %s, %d evaluaciones)\n",
+		ui.RangeLabel(check), ui.FormatInstant(from, true), ui.FormatInstant(to, true), step, tl.Evaluations)
+	changed, transitions := 0, 0
+	for _, it := range tl.Items {
+		if it.Changes() == 0 {
+			fmt.Printf("  %-40s estable %s\n", it.Key, it.Transitions[0].State)
+			continue
+		}
+		changed++
+		transitions += it.Changes()
+		fmt.Printf("  %s\n", it.Key)
+		for _, tr := range it.Transitions {
+			fmt.Printf("      %s  %s\n", ui.FormatInstant(tr.At, withDate), tr.State)
+		}
+	}
+	fmt.Printf("%d transiciones en %d de %d items\n", transitions, changed, len(tl.Items))
+	return 0
+}
@@ -4,12 +4,14 @@
 package cmd
 
 import (
+	"time"
+
 	"drone-observe/internal/config"
 	"drone-observe/internal/ui"
 )
 
-func runLimits(cfg config.Config) int {
-	if err := ui.RunLimits(cfg); err != nil {
+func runLimits(cfg config.Config, watch time.Duration) int {
+	if err := ui.RunLimits(cfg, watch); err != nil {
 		return 1
 	}
 	return 0
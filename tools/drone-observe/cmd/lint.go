@@ -0,0 +1,45 @@
+// Archivo: tools/drone-observe/cmd/lint.go
+// Rol: comando lint-queries para analizar estaticamente el PromQL del CLI, dashboards y METRICS.md.
+// No hace: ejecutar queries contra Prometheus.
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"drone-observe/internal/audit"
+	"drone-observe/internal/config"
+)
+
+var severityOrder = map[audit.Severity]int{audit.SeverityHigh: 0, audit.SeverityMed: 1, audit.SeverityLow: 2}
+
+func runLintQueries(cfg config.Config, flags []string) int {
+	failOn := audit.SeverityMed
+	if v := flagValue(flags, "--fail-on"); v != "" {
+		failOn = audit.Severity(v)
+		if _, ok := severityOrder[failOn]; !ok {
+			fmt.Fprintf(os.Stderr, "--fail-on invalido %q (validos: alta, media, baja)\n", v)
+			return 2
+		}
+	}
+	checked, findings := audit.LintQueries(cfg)
+	sort.SliceStable(findings, func(i, j int) bool {
+		return severityOrder[findings[i].Severity] < severityOrder[findings[j].Severity]
+	})
+
+	fmt.Printf("Lint de queries: %d queries (registro CLI, dashboards, METRICS.md)\n", checked)
+	counts := map[audit.Severity]int{}
+	for _, f := range findings {
+		counts[f.Severity]++
+		fmt.Printf("  [%-5s] %s\n          %s\n", f.Severity, f.Item, f.Detail)
+	}
+	fmt.Printf("%d alta, %d media, %d baja\n", counts[audit.SeverityHigh], counts[audit.SeverityMed], counts[audit.SeverityLow])
+	// Sale 1 con findings de severidad --fail-on o mayor, para que el lint pueda cortar CI.
+	for _, f := range findings {
+		if severityOrder[f.Severity] <= severityOrder[failOn] {
+			return 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,39 @@
+// Archivo: tools/drone-observe/cmd/offline.go
+// Rol: flag global --from: ejecutar checks contra un bundle extraido en lugar de los endpoints en vivo.
+// No hace: extraer el tar.gz ni recolectar; el bundle lo arma `drone-observe bundle`.
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"drone-observe/internal/config"
+	"drone-observe/internal/source"
+)
+
+// fromCommands son los comandos que aceptan --from.
+var fromCommands = map[string]bool{"validate": true, "drift": true, "limits": true, "freshness": true}
+
+// applyFrom abre el bundle de --from y devuelve la config que lo lee; el bundle reemplaza --at.
+func applyFrom(cfg config.Config, cmd string, flags []string, watch time.Duration) (config.Config, error) {
+	dir := flagValue(flags, "--from")
+	switch {
+	case !fromCommands[cmd]:
+		return cfg, fmt.Errorf("--from no soportado por %q (validos: drift, freshness, limits, validate)", cmd)
+	case cfg.Historical() || flagValue(flags, "--range") != "" || watch > 0:
+		return cfg, fmt.Errorf("--from no se combina con --at/--range/--watch (el bundle tiene un solo instante)")
+	}
+	d, err := source.Open(dir)
+	if err != nil {
+		return cfg, fmt.Errorf("--from: %w", err)
+	}
+	// Un checksum que no coincide no impide el analisis, pero la evidencia ya no es la capturada.
+	if bad, err := d.Verify(); err != nil {
+		fmt.Fprintf(os.Stderr, "aviso: sin verificar checksums: %v\n", err)
+	} else if len(bad) > 0 {
+		fmt.Fprintf(os.Stderr, "aviso: %s no coincide con %s: %s\n", d.Path, source.SumsFile, strings.Join(bad, ", "))
+	}
+	return d.Apply(cfg)
+}
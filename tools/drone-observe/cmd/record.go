@@ -0,0 +1,154 @@
+// Archivo: tools/drone-observe/cmd/record.go
+// Rol: comando record para grabar trafico MQTT (topic, payload, QoS, retain, tiempo de llegada) a un .mqrec.
+// No hace: filtrar ni decodificar payloads; se graba byte a byte lo que entrego el broker.
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"drone-observe/internal/acl"
+	"drone-observe/internal/config"
+	"drone-observe/internal/mqttclient"
+	"drone-observe/internal/recording"
+)
+
+// recordFlushEvery acota lo que se pierde si el proceso muere sin Ctrl-C.
+const recordFlushEvery = time.Second
+
+func runRecord(cfg config.Config, flags []string) int {
+	host, port, err := brokerFlag(cfg, flags)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	var duration time.Duration
+	if v := flagValue(flags, "--duration"); v != "" {
+		if duration, err = time.ParseDuration(v); err != nil || duration <= 0 {
+			fmt.Fprintf(os.Stderr, "--duration invalido: %s\n", v)
+			return 2
+		}
+	}
+	limit := 0
+	if v := flagValue(flags, "--max"); v != "" {
+		if limit, err = strconv.Atoi(v); err != nil || limit <= 0 {
+			fmt.Fprintf(os.Stderr, "--max invalido: %s\n", v)
+			return 2
+		}
+	}
+	filters := recordFilters(cfg, flagValue(flags, "--topic"))
+	if len(filters) == 0 {
+		fmt.Fprintln(os.Stderr, "--topic vacio")
+		return 2
+	}
+	start := time.Now()
+	out := flagValue(flags, "--out")
+	if out == "" {
+		out = fmt.Sprintf("drone-observe-record-%s%s", start.UTC().Format("20060102T150405Z"), recording.Extension)
+	}
+
+	client, err := mqttclient.Dial(host, port, mqttclient.Options{
+		ClientID: fmt.Sprintf("drone-observe-record-%d", start.UnixNano()%100000),
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "mqtt %s:%d: %v\n", host, port, err)
+		return 1
+	}
+	defer client.Close()
+	for _, f := range filters {
+		if code, err := client.Subscribe(f, 1); err != nil {
+			fmt.Fprintf(os.Stderr, "subscribe %s: %v\n", f, err)
+			return 1
+		} else if code == mqttclient.SubackFailure {
+			fmt.Fprintf(os.Stderr, "suscripcion rechazada por el broker: %s\n", f)
+			return 1
+		}
+	}
+
+	w, err := recording.Create(out, recording.Header{Start: start, Filters: strings.Join(filters, ",")})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, duration)
+		defer cancel()
+	}
+	fmt.Printf("Grabando %s de %s:%d en %s (Ctrl-C para terminar)...\n", strings.Join(filters, ", "), host, port, out)
+
+	recErr := recordLoop(ctx, client, w, limit)
+	if err := w.Close(); err != nil && recErr == nil {
+		recErr = err
+	}
+	fmt.Printf("%s: %d mensajes, %d topics, %s\n", out, w.Count(), w.Topics(), time.Since(start).Round(time.Second))
+	if n := client.Dropped(); n > 0 {
+		fmt.Fprintf(os.Stderr, "%d mensajes descartados: la escritura no siguio el ritmo del broker\n", n)
+	}
+	if recErr != nil {
+		fmt.Fprintf(os.Stderr, "grabacion interrumpida: %v\n", recErr)
+		return 1
+	}
+	return 0
+}
+
+// recordLoop escribe mensajes hasta ctx, limit o la caida de la conexion.
+func recordLoop(ctx context.Context, client *mqttclient.Client, w *recording.Writer, limit int) error {
+	ticker := time.NewTicker(recordFlushEvery)
+	defer ticker.Stop()
+	for limit == 0 || w.Count() < limit {
+		select {
+		case msg, ok := <-client.Messages():
+			if !ok {
+				if err := client.Err(); err != nil {
+					return err
+				}
+				return fmt.Errorf("el broker cerro la conexion")
+			}
+			if err := w.Write(msg); err != nil {
+				return err
+			}
+		case <-ticker.C:
+			if err := w.Flush(); err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			return nil
+		}
+	}
+	return nil
+}
+
+// recordFilters parte --topic por comas; sin flag graba lo mismo que la captura del bundle.
+func recordFilters(cfg config.Config, v string) []string {
+	if v != "" {
+		var out []string
+		for _, f := range strings.Split(v, ",") {
+			if f = strings.TrimSpace(f); f != "" {
+				out = append(out, f)
+			}
+		}
+		return out
+	}
+	filters := []string{"drone/#"}
+	if base := strings.Trim(cfg.MQTTBaseTopic, "/"); !mqttclient.MatchTopic("drone/#", base) {
+		filters = append(filters, base+"/#")
+	}
+	return filters
+}
+
+// brokerFlag lee --broker host:port; sin flag usa MQTT_HOST:MQTT_PORT.
+func brokerFlag(cfg config.Config, flags []string) (string, int, error) {
+	if v := flagValue(flags, "--broker"); v != "" {
+		return acl.SplitBroker(v)
+	}
+	return cfg.MQTTHost, cfg.MQTTPort, nil
+}
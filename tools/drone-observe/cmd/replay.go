@@ -0,0 +1,109 @@
+// Archivo: tools/drone-observe/cmd/replay.go
+// Rol: comando replay para republicar una grabacion .mqrec en un broker (velocidad original, escalada o maxima).
+// No hace: modificar payloads ni timestamps dentro del payload; solo el topic puede reescribirse (--rewrite).
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"drone-observe/internal/config"
+	"drone-observe/internal/mqttclient"
+	"drone-observe/internal/recording"
+)
+
+func runReplay(cfg config.Config, flags []string) int {
+	in := flagValue(flags, "--in")
+	if in == "" {
+		fmt.Fprintln(os.Stderr, "uso: drone-observe replay --in <archivo.mqrec> [--speed <x>|max] [--rewrite <desde>=<hacia>]")
+		return 2
+	}
+	host, port, err := brokerFlag(cfg, flags)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	opts := recording.Options{Speed: 1}
+	if v := flagValue(flags, "--speed"); v == "max" {
+		opts.Speed = 0
+	} else if v != "" {
+		if opts.Speed, err = strconv.ParseFloat(v, 64); err != nil || opts.Speed <= 0 {
+			fmt.Fprintf(os.Stderr, "--speed invalido: %s (numero > 0 o max)\n", v)
+			return 2
+		}
+	}
+	if v := flagValue(flags, "--rewrite"); v != "" {
+		if opts.Rewrite, err = recording.ParseRewrite(v); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+	}
+
+	summary, err := recording.Scan(in)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	fmt.Printf("Grabacion: %s (%s, inicio %s)\n", in, summary.Header.Filters, summary.Header.Start.Local().Format(time.RFC3339))
+	fmt.Printf("  %d mensajes, %d topics, %d bytes de payload, %s\n",
+		summary.Messages, len(summary.Topics), summary.Bytes, summary.Duration.Round(time.Millisecond))
+	if summary.Truncated {
+		fmt.Fprintln(os.Stderr, "aviso: el ultimo registro esta cortado (grabacion interrumpida); se republica hasta el anterior")
+	}
+	if hits := recording.Collides(summary.Topics, opts.Rewrite, cfg.MQTTBaseTopic); len(hits) > 0 {
+		fmt.Fprintf(os.Stderr, "aviso: %d topics caen bajo MQTT_BASE_TOPIC (%s), p. ej. %s; los consumidores del broker los tratan como trafico real (usar --rewrite)\n",
+			len(hits), cfg.MQTTBaseTopic, hits[0])
+	}
+
+	r, err := recording.Open(in)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer r.Close()
+	client, err := mqttclient.Dial(host, port, mqttclient.Options{
+		ClientID: fmt.Sprintf("drone-observe-replay-%d", time.Now().UnixNano()%100000),
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "mqtt %s:%d: %v\n", host, port, err)
+		return 1
+	}
+	defer client.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	fmt.Printf("Republicando en %s:%d a %s%s (Ctrl-C para cortar)...\n", host, port, speedLabel(opts.Speed), rewriteLabel(opts.Rewrite))
+	res, err := recording.Replay(ctx, r, client, opts)
+	fmt.Printf("%d/%d mensajes publicados en %s", res.Published, summary.Messages, res.Elapsed.Round(time.Millisecond))
+	if opts.Speed > 0 {
+		fmt.Printf(" (atraso maximo %s)", res.MaxLag.Round(time.Millisecond))
+	}
+	fmt.Println()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "replay interrumpido: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+func speedLabel(speed float64) string {
+	switch speed {
+	case 0:
+		return "velocidad maxima"
+	case 1:
+		return "velocidad original"
+	}
+	return "x" + strconv.FormatFloat(speed, 'g', -1, 64)
+}
+
+func rewriteLabel(rw recording.Rewrite) string {
+	if rw.From == "" {
+		return ""
+	}
+	return fmt.Sprintf(", %s/# -> %s/#", rw.From, rw.To)
+}
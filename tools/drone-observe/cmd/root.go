@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"drone-observe/internal/config"
 )
@@ -29,6 +30,36 @@ func Execute(args []string) int {
 
 	cfg := config.FromEnv()
 
+	watch, err := parseWatch(flags)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	if cfg.At, err = parseAt(flags, time.Now()); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	if flagValue(flags, "--from") != "" {
+		if cfg, err = applyFrom(cfg, cmd, flags, watch); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+	}
+	historical := cfg.Historical() || flagValue(flags, "--range") != ""
+	switch {
+	case watch > 0 && !watchCommands[cmd]:
+		fmt.Fprintf(os.Stderr, "--watch no soportado por %q (validos: drift, freshness, limits, targets, topology, validate)\n", cmd)
+		return 2
+	case historical && watch > 0:
+		fmt.Fprintln(os.Stderr, "--watch no se combina con --at/--range (el pasado no cambia)")
+		return 2
+	case flagValue(flags, "--range") != "":
+		return runRange(cfg, cmd, flags)
+	case cfg.Historical() && !atCommands[cmd]:
+		fmt.Fprintf(os.Stderr, "--at no soportado por %q (validos: fleet, freshness, limits, topology, validate)\n", cmd)
+		return 2
+	}
+
 	switch cmd {
 	case "health":
 		return runHealth(cfg)
@@ -37,15 +68,43 @@ func Execute(args []string) int {
 	case "llm":
 		return runLLM(cfg)
 	case "validate":
-		return runValidate(cfg)
+		return runValidate(cfg, watch)
 	case "topology":
-		return runTopology(cfg)
+		return runTopology(cfg, watch)
 	case "freshness":
-		return runFreshness(cfg)
+		return runFreshness(cfg, watch)
 	case "drift":
-		return runDrift(cfg)
+		return runDrift(cfg, watch)
 	case "limits":
-		return runLimits(cfg)
+		return runLimits(cfg, watch)
+	case "fleet":
+		return runFleet(cfg, flagValue(flags, "--drone"))
+	case "serve":
+		return runServe(cfg, flags)
+	case "generate":
+		return runGenerate(cfg, flags)
+	case "test":
+		return runTest(cfg, flags)
+	case "lint-queries":
+		return runLintQueries(cfg, flags)
+	case "alerts":
+		return runAlerts(cfg, flags)
+	case "targets":
+		return runTargets(cfg, watch)
+	case "security":
+		return runSecurity(cfg)
+	case "acl":
+		return runACL(cfg, flags)
+	case "bundle":
+		return runBundle(cfg, flags)
+	case "record":
+		return runRecord(cfg, flags)
+	case "replay":
+		return runReplay(cfg, flags)
+	case "simulate":
+		return runSimulate(cfg, flags)
+	case "chaos":
+		return runChaos(cfg, flags)
 	default:
 		printHelp("", language)
 		return 2
@@ -79,6 +138,35 @@ func parseLang(flags []string) lang {
 	return langES
 }
 
+// flagValue devuelve el valor de un flag con forma "--name valor" o "--name=valor".
+func flagValue(flags []string, name string) string {
+	for i, f := range flags {
+		if f == name && i+1 < len(flags) {
+			return flags[i+1]
+		}
+		if strings.HasPrefix(f, name+"=") {
+			return strings.TrimPrefix(f, name+"=")
+		}
+	}
+	return ""
+}
+
+// parseWatch lee --watch <intervalo>; 0 significa ejecucion unica.
+// watchCommands son los comandos que aceptan --watch; el resto lo ignoraria en silencio.
+var watchCommands = map[string]bool{"drift": true, "freshness": true, "limits": true, "targets": true, "topology": true, "validate": true}
+
+func parseWatch(flags []string) (time.Duration, error) {
+	v := flagValue(flags, "--watch")
+	if v == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("intervalo de --watch invalido: %s", v)
+	}
+	return d, nil
+}
+
 func hasHelpFlag(flags []string) bool {
 	for _, f := range flags {
 		if f == "--help" || f == "-h" {
@@ -149,6 +237,10 @@ Verifica:
   - No hay metricas inesperadas en el backend
 
 Flags:
+  --watch <dur> re-ejecuta el check en intervalo y resalta cambios
+  --at <t>      evalua en un instante pasado (ver ayuda general)
+  --range <r>   linea de tiempo de transiciones (--step <dur>)
+  --from <dir>  lee un bundle extraido en lugar de los endpoints (ver ayuda general)
   --help, -h   ayuda
   --es         espanol (default)
   --en         english
@@ -158,10 +250,14 @@ Flags:
 Muestra topologia efectiva del sistema (sin discovery).
 
 Observa:
-  - Edge -> MQTT -> Backend -> Prometheus -> Grafana
+  - Edge -> MQTT -> Backend -> ML Analytics -> Prometheus -> Grafana
   - Componentes OK y componentes mudos
+  - Un target down en Prometheus (job backend / ml-analytics) pone su componente en FAIL
 
 Flags:
+  --watch <dur> re-ejecuta el check en intervalo y resalta cambios
+  --at <t>      evalua en un instante pasado (ver ayuda general)
+  --range <r>   linea de tiempo de transiciones (--step <dur>)
   --help, -h   ayuda
   --es         espanol (default)
   --en         english
@@ -175,6 +271,10 @@ Observa:
   - Semaforo temporal por umbral
 
 Flags:
+  --watch <dur> re-ejecuta el check en intervalo y resalta cambios
+  --at <t>      evalua en un instante pasado (ver ayuda general)
+  --range <r>   linea de tiempo de transiciones (--step <dur>)
+  --from <dir>  lee un bundle extraido en lugar de los endpoints (ver ayuda general)
   --help, -h   ayuda
   --es         espanol (default)
   --en         english
@@ -186,8 +286,16 @@ Detecta desviaciones respecto a docs y dashboards.
 Observa:
   - Metricas documentadas vs reales
   - Dashboards versionados vs docs
+  - PromQL de paneles vs catalogo METRICS.md (fuera de contrato, FUTURO, sin panel)
+  - Dashboards minimos de METRICS.md vs drones-data-plane.json (AST PromQL)
+  - Dashboards en Grafana (API) vs JSON versionados
+  - Provisioning Grafana vs compose/prometheus.yml, uid de datasources y health
+  - Alertas de METRICS.md vs archivo de reglas versionado y reglas cargadas (/api/v1/rules)
+  - prometheus.yml vs docker-compose.yml vs defaults del CLI (targets, puertos publicados, jobs sin componente)
 
 Flags:
+  --watch <dur> re-ejecuta el check en intervalo y resalta cambios
+  --from <dir>  lee un bundle extraido en lugar de los endpoints (ver ayuda general)
   --help, -h   ayuda
   --es         espanol (default)
   --en         english
@@ -202,9 +310,335 @@ Observa:
   - Conteo de metricas y cardinalidad
 
 Flags:
+  --watch <dur> re-ejecuta el check en intervalo y resalta cambios
+  --at <t>      evalua en un instante pasado (ver ayuda general)
+  --range <r>   linea de tiempo de transiciones (--step <dur>)
+  --from <dir>  lee un bundle extraido en lugar de los endpoints (ver ayuda general)
+  --help, -h   ayuda
+  --es         espanol (default)
+  --en         english
+`
+	case "fleet":
+		return `drone-observe fleet
+Vista por dron (label drone_id y topics drone/<id>/...).
+
+Muestra:
+  - Bateria, mensajes por segundo y freshness por dron
+  - Estado ML y ultimo evento observado
+  - Tabla ordenable con detalle por dron
+
+Teclas:
+  ↑/↓      seleccionar dron
+  enter    detalle del dron
+  esc      volver a la tabla
+  s / r    cambiar columna de orden / invertir
+
+Flags:
+  --drone <id> abrir directamente el detalle de un dron
+  --at <t>     evalua en un instante pasado (solo Prometheus, sin MQTT)
+  --help, -h   ayuda
+  --es         espanol (default)
+  --en         english
+`
+	case "serve":
+		return `drone-observe serve
+Ejecuta checks de gobernanza periodicamente y los expone a Prometheus.
+
+Checks:
+  - topology, freshness, drift, limits
+
+Endpoints:
+  /metrics   drone_observe_check_status{check,status} y tiempos de ejecucion
+  /probe     probe puntual: /probe?target=<host:port>&module=<modulo>
+  /healthz   200 si la ultima ronda de checks es reciente (healthcheck de contenedor)
+
+Modulos de /probe:
+  mqtt_connect     handshake MQTT CONNECT/CONNACK
+  mqtt_roundtrip   publish + recepcion en topic drone-observe/probe/...
+  backend_metrics  GET /metrics del backend
+  grafana_health   GET /api/health de Grafana
+
+Flags:
+  --listen <addr>      direccion HTTP (default: :9109)
+  --interval <dur>     intervalo entre rondas (default: 30s)
+  --help, -h           ayuda
+  --es                 espanol (default)
+  --en                 english
+`
+	case "lint-queries":
+		return `drone-observe lint-queries
+Analiza estaticamente todas las queries PromQL sin ejecutarlas.
+
+Fuentes:
+  - Registro de queries del CLI (internal/queries)
+  - Targets de los dashboards versionados (DASHBOARDS_DIR)
+  - Paneles y alertas de METRICS.md
+
+Reglas (tipos de metrica segun METRICS.md):
+  alta   PromQL invalido / metrica fuera del contrato
+  alta   rate/irate/increase/resets sobre un gauge
+  media  rango de rate/increase/delta menor a 2x scrape_interval (PROMETHEUS_CONFIG)
+  media  count sobre selector sin nombre de metrica (cardinalidad sin cota)
+  baja   cardinalidad sin cota aceptada en el registro (queries de limits)
+
+Sale 1 si hay findings de severidad --fail-on o mayor.
+
+Flags:
+  --fail-on <sev>  alta, media o baja (default: media)
+  --help, -h       ayuda
+  --es             espanol (default)
+  --en             english
+`
+	case "targets":
+		return `drone-observe targets
+Salud de scrape por job segun Prometheus (/api/v1/targets y /api/v1/targets/metadata).
+
+Muestra por job y target:
+  - Estado (up/down), ultimo error y antiguedad del ultimo scrape
+  - Duracion del ultimo scrape y cantidad de samples (scrape_samples_scraped)
+  - scrape_interval efectivo vs prometheus.yml versionado (PROMETHEUS_CONFIG)
+  - Metricas con metadata expuestas por el job
+  - Jobs declarados sin targets activos o scrapeados sin estar versionados
+
+Flags:
+  --watch <dur> re-ejecuta el check en intervalo y resalta cambios
   --help, -h   ayuda
   --es         espanol (default)
   --en         english
+`
+	case "alerts":
+		return `drone-observe alerts
+Alertas activas agrupadas por severidad y silencios de Alertmanager.
+
+Fuentes:
+  - Prometheus /api/v1/alerts (pending/firing segun las reglas cargadas)
+  - Alertmanager /api/v2/alerts y /api/v2/silences (ALERTMANAGER_URL)
+
+Un resumen de alertas tambien aparece en health, telemetry y llm.
+
+Teclas:
+  ↑/↓      seleccionar
+  tab      alternar alertas / silencios
+  s        silenciar la alerta seleccionada (pide confirmacion y/n)
+  x        expirar el silencio seleccionado (pide confirmacion y/n)
+  r        refrescar
+
+Flags:
+  --silence-duration <dur>  duracion de los silencios creados (default: 2h)
+  --help, -h                ayuda
+  --es                      espanol (default)
+  --en                      english
+`
+	case "security":
+		return `drone-observe security
+Audita mosquitto.conf versionado (MOSQUITTO_CONF) y confirma contra el broker (MQTT_HOST/MQTT_PORT).
+
+Estatico (docs/06-seguridad.md: anonimo y 1883 en claro son solo laboratorio):
+  alta   allow_anonymous true, listener sin TLS, sin acl_file
+  alta   password_file/acl_file no montado o password_file en texto plano
+  media  TLS obsoleto (tlsv1, tlsv1.1), sin listener explicito, sin metodo de autenticacion
+  media  persistence_location sin volumen en compose
+  baja   persistence false, include_dir no auditado
+
+Activo (handshake MQTT real, sin credenciales):
+  - CONNECT anonimo: CONNACK 0 confirma allow_anonymous
+  - publish QoS 1 a drone/drone-observe-security/cmd/...: la entrega confirma la falta de ACL
+  - diferencias entre el archivo y el broker se reportan como media
+
+Sale 1 si hay findings de severidad alta.
+
+Flags:
+  --help, -h   ayuda
+  --es         espanol (default)
+  --en         english
+`
+	case "acl":
+		return `drone-observe acl
+Verifica permisos MQTT por identidad contra un broker real (matriz ACL_MATRIX).
+
+La matriz (mqtt/acl/matrix.yml) declara identidades, topics y permisos esperados:
+  identities   name, username, password_env (variable de entorno; nunca la password), connect
+  checks       identity, topic, publish: allow|deny, subscribe: allow|deny, probe_topic
+  observer     identidad con lectura/escritura que confirma las entregas
+
+Cada permiso se verifica por entrega real (el PUBACK no prueba nada en MQTT 3.1.1):
+  publish      la identidad publica QoS 1; el observador debe recibir el mensaje
+  subscribe    SUBACK 0x80 = deny; si no, el observador publica y la identidad debe recibirlo
+
+Broker de laboratorio: docker compose --profile acl up -d mqtt-acl (localhost:1884).
+Sale 1 si alguna fila no coincide o no se pudo verificar.
+
+Flags:
+  --matrix <archivo>    matriz (default: ACL_MATRIX)
+  --broker <host:port>  broker (default: broker de la matriz, o MQTT_HOST:MQTT_PORT)
+  --help, -h            ayuda
+  --es                  espanol (default)
+  --en                  english
+`
+	case "bundle":
+		return `drone-observe bundle
+Junta en un tar.gz la evidencia de un incidente para adjuntar al ticket.
+
+Contenido:
+  config.json              config efectiva (GRAFANA_ADMIN_PASSWORD y credenciales en URLs redactadas)
+  checks/*.json            salida de cada check (topology, freshness, limits, drift, validate, targets,
+                           alerts, fleet, security, lint-queries y el resumen de serve); security es
+                           solo la parte estatica: el bundle no conecta ni publica en el broker
+  backend/metrics.txt      /metrics crudo del backend
+  prometheus/*.json        targets, rules, flags, buildinfo, tsdb y config (API cruda)
+  prometheus/queries.json  queries del CLI y metricas del contrato en el instante del bundle
+  prometheus/range/*.json  query_range de cada metrica del contrato (--window)
+  grafana/                 dashboards tal como los sirve Grafana
+  mqtt/capture.jsonl       captura de drone/# (--capture; hasta 10000 mensajes)
+  manifest.json            timestamps, duracion, tamano, sha256 y error de cada archivo
+  SHA256SUMS               verificable con sha256sum -c
+
+Un origen que falla no aborta el bundle: queda en el manifest con su error.
+Analisis offline: tar xzf <bundle> && drone-observe drift --from <dir>
+
+Flags:
+  --out <archivo>    destino (default: drone-observe-bundle-<UTC>.tar.gz)
+  --window <dur>     rango de datos de Prometheus (default: 1h)
+  --step <dur>       paso del rango (default: window/120, minimo 15s)
+  --capture <dur>    captura MQTT (default: 10s; 0 la desactiva)
+  --help, -h         ayuda
+  --es               espanol (default)
+  --en               english
+`
+	case "record":
+		return `drone-observe record
+Graba trafico MQTT para reproducir bugs del backend y del ML con la secuencia exacta del edge.
+
+Cada mensaje guarda topic, payload (bytes crudos), QoS, retain y tiempo de llegada en un archivo
+.mqrec compacto (tiempos en delta, topics en tabla). Termina con Ctrl-C, --duration o --max;
+un corte sin Ctrl-C pierde a lo sumo el ultimo segundo.
+
+Flags:
+  --topic <f1,f2>       filtros (default: drone/# y MQTT_BASE_TOPIC/# si esta fuera de drone/)
+  --out <archivo>       destino (default: drone-observe-record-<UTC>.mqrec)
+  --duration <dur>      graba durante dur
+  --max <n>             corta a los n mensajes
+  --broker <host:port>  broker (default: MQTT_HOST:MQTT_PORT)
+  --help, -h            ayuda
+  --es                  espanol (default)
+  --en                  english
+`
+	case "replay":
+		return `drone-observe replay --in <archivo.mqrec>
+Republica una grabacion de record respetando los intervalos originales.
+
+Cada mensaje se programa contra el inicio del replay: un PUBACK lento atrasa ese mensaje
+pero no corre el resto. QoS (0/1) y retain se republican como se grabaron.
+Avisa si los topics caen bajo MQTT_BASE_TOPIC: los consumidores de ese broker (backend, ML)
+los procesan como trafico real. Usar --rewrite para republicar en otro prefijo.
+
+Flags:
+  --in <archivo>              grabacion a republicar
+  --speed <x>|max             1 = original (default), 2 = el doble de rapido, max = sin esperas
+  --rewrite <desde>=<hacia>   reemplaza el prefijo del topic (p. ej. drone/alpha=replay/alpha)
+  --broker <host:port>        broker (default: MQTT_HOST:MQTT_PORT)
+  --help, -h                  ayuda
+  --es                        espanol (default)
+  --en                        english
+`
+	case "simulate":
+		return `drone-observe simulate
+Genera carga MQTT con N drones virtuales (una conexion por dron) para validar limits y el backend.
+
+Cada dron publica como el edge (EVENTS.md): <topic>/telemetry {seq, ts, battery_pct, altitude_m}
+con QoS 0 y <topic>/event BATTERY_LOW con QoS 1 cuando battery_pct llega a 25 (ciclo de 120 mensajes).
+El backend solo consume MQTT_BASE_TOPIC: para cargarlo usar --topic drone/alpha (todos comparten topic).
+
+Fallas (--fault, separadas por coma; probabilidades por mensaje):
+  dropout=<p>          el dron se calla dropout-for (enlace caido; seq sigue avanzando)
+  dropout-for=<dur>    duracion del silencio (default: 10s)
+  malformed=<p>        publica el JSON cortado
+  skew=<dur>           reloj desviado: cada dron toma un desvio fijo en [-dur, +dur] para ts
+  seq-reset=<p>        reinicia seq (reboot del edge)
+
+Flags:
+  --drones <n>          cantidad de drones (default: 5)
+  --rate <r>            telemetria por segundo por dron (default: 1)
+  --jitter <j>          variacion del periodo, fraccion (default: 0.1 = +-10%)
+  --topic <plantilla>   topic base con {id} (default: drone/{id})
+  --id-prefix <p>       ids <p>01..<p>N (default: sim)
+  --fault <lista>       fallas a inyectar (ver arriba)
+  --seed <n>            semilla (misma semilla = mismos payloads y fallas)
+  --duration <dur>      corre durante dur (default: hasta Ctrl-C)
+  --broker <host:port>  broker (default: MQTT_HOST:MQTT_PORT)
+  --help, -h            ayuda
+  --es                  espanol (default)
+  --en                  english
+`
+	case "chaos":
+		return `drone-observe chaos
+Corre un escenario de fallas (YAML con pasos temporizados) y mide cuanto tarda cada check en detectarlas.
+
+El escenario publica como un dron en MQTT_BASE_TOPIC (el topic que consumen backend y ML) y exige que
+nadie mas publique ahi: detener el edge antes (docker compose stop edge; despues docker compose start edge).
+
+Acciones por paso (action, durante for):
+  publish     telemetria sana como el edge (1 msg/s por default, BATTERY_LOW en 25)
+  stop        no publica nada (edge caido)
+  malformed   telemetria con JSON cortado
+  flood       telemetria sana a rate x factor (default: 100)
+  anomaly     rafagas normales cerradas por un pico de bateria y altitud (ml_state CRIT)
+
+Cada expect (check, item, state, within) debe verse antes de within desde el inicio del paso:
+  checks      freshness, health, limits, ml (item ml_state), topology, validate
+  state       un estado o una lista ([WARN, FAIL]); within <= for (default: for)
+El reporte da por expect OK y la latencia de deteccion, o FALLO y el ultimo estado visto. Sale 1 si falla alguno.
+
+Flags:
+  --scenario <archivo>  escenario (default: CHAOS_SCENARIO)
+  --broker <host:port>  broker (default: broker del escenario, o MQTT_HOST:MQTT_PORT)
+  --dry-run             valida el escenario y muestra los pasos sin publicar
+  --help, -h            ayuda
+  --es                  espanol (default)
+  --en                  english
+`
+	case "test":
+		return `drone-observe test rules [archivo...]
+Ejecuta tests de reglas en formato promtool sin Prometheus vivo.
+
+Que hace:
+  - Carga rule_files (relativos al archivo de test) y las input_series sinteticas
+  - Evalua las reglas cada evaluation_interval sobre el tiempo simulado (PromQL en memoria)
+  - Verifica alert_rule_test (alertas firing con labels/annotations) y promql_expr_test
+
+Sin archivo usa RULES_FILE con sufijo .test.yml (observability/rules/drones.rules.test.yml).
+Sale 1 si algun grupo falla.
+
+El formato es el de promtool, el evaluador no: el resultado coincide con promtool solo donde
+internal/promql replica a Prometheus (ver docs/11). Ante una diferencia, manda promtool test rules.
+
+Flags:
+  --help, -h   ayuda
+  --es         espanol (default)
+  --en         english
+`
+	case "generate":
+		return `drone-observe generate dashboard|rules
+Genera artefactos versionables desde METRICS.md (salida determinista).
+
+Artefactos:
+  dashboard   dashboard Grafana (JSON de provisioning)
+              - paneles de "Dashboards minimos" con su PromQL exacto
+              - metricas del catalogo sin panel: gauge -> stat, counter -> timeseries de rate
+              - unidad Grafana segun la unidad del contrato (pct -> percent, ms, dbm...)
+  rules       reglas de alerta Prometheus desde "Alertas recomendadas" (grupo drones-contract)
+              - expr validada con el parser PromQL; severity y summary ES/EN como labels/annotations
+
+Flags:
+  --out <archivo>   escribe en archivo (default: stdout)
+  --check           no escribe; sale 1 si --out difiere de lo generado (CI)
+  --uid <uid>       uid del dashboard (default: drones-contract)
+  --title <titulo>  titulo del dashboard
+  (rules: --out observability/rules/drones.rules.yml, montado en Prometheus)
+  --future          incluye items y metricas FUTURO
+  --help, -h        ayuda
+  --es              espanol (default)
+  --en              english
 `
 	default:
 		return `drone-observe
@@ -219,19 +653,57 @@ Comandos:
   freshness  recencia de datos
   drift      deriva vs docs/dashboards
   limits     limites tecnicos observados
+  fleet      estado por dron (drone_id)
+  serve      exporter Prometheus de checks
+  generate   genera dashboard y reglas de alerta desde METRICS.md
+  test       tests unitarios de reglas de alerta (formato promtool)
+  lint-queries  analisis estatico del PromQL del CLI y dashboards
+  alerts     alertas activas por severidad y silencios (Alertmanager)
+  targets    salud de scrape por job (targets de Prometheus)
+  security   auditoria de mosquitto.conf con verificacion activa
+  acl        matriz de permisos MQTT por identidad (broker real)
+  bundle     bundle de soporte (tar.gz) para post-mortems
+  record     graba trafico MQTT a un archivo .mqrec
+  replay     republica una grabacion (original, escalada o max; --rewrite de topics)
+  simulate   carga MQTT con N drones virtuales y fallas inyectables
+  chaos      escenario de fallas con deteccion esperada y latencia por check
 
 Flags:
   --help, -h   ayuda
   --es         espanol (default)
   --en         english
 
+Evaluacion historica (freshness, topology, limits, validate; --at tambien fleet):
+  --at <t>          evalua las queries Prometheus en un instante pasado (edades relativas a t)
+                    t: RFC3339, "2006-01-02 15:04[:05]", "15:04" (ultima ocurrencia), unix o -<dur>
+  --range <a>,<b>   linea de tiempo de transiciones por item entre dos instantes
+  --range <dur>     idem, terminando en --at (o ahora)
+  --step <dur>      paso del rango (default: rango/120, minimo 15s)
+
+Analisis offline (validate, drift, limits, freshness):
+  --from <dir>      lee un bundle extraido (drone-observe bundle) en lugar de los endpoints en vivo
+                    evalua en el instante de captura; verifica SHA256SUMS y avisa si algo no coincide
+
 Variables de entorno:
   MQTT_HOST (default: mqtt)
   MQTT_PORT (default: 1883)
+  MQTT_BASE_TOPIC (default: drone/alpha)
   BACKEND_HTTP_PORT (default: 8080)
   PROMETHEUS_URL (default: http://localhost:9090)
+  ALERTMANAGER_URL (default: http://localhost:9093)
   GRAFANA_URL (default: http://localhost:3000)
+  GRAFANA_ADMIN_USER (default: admin)
+  GRAFANA_ADMIN_PASSWORD (default: admin)
   METRICS_DOC (default: METRICS.md)
+  DASHBOARDS_DIR (default: observability/grafana/dashboards)
+  DOCS_DIR (default: docs)
+  GRAFANA_PROVISIONING_DIR (default: observability/grafana/provisioning)
+  PROMETHEUS_CONFIG (default: observability/prometheus.yml)
+  RULES_FILE (default: observability/rules/drones.rules.yml)
+  COMPOSE_FILE (default: docker-compose.yml)
+  MOSQUITTO_CONF (default: mqtt/mosquitto.conf)
+  ACL_MATRIX (default: mqtt/acl/matrix.yml)
+  CHAOS_SCENARIO (default: observability/scenarios/pipeline-faults.yml)
   FRESHNESS_WARN_SEC (default: 30)
   FRESHNESS_FAIL_SEC (default: 120)
 
@@ -293,6 +765,10 @@ Checks:
   - No unexpected backend metrics
 
 Flags:
+  --watch <dur> re-run the check periodically and highlight changes
+  --at <t>      evaluate at a past instant (see general help)
+  --range <r>   timeline of transitions (--step <dur>)
+  --from <dir>  read an extracted bundle instead of live endpoints (see general help)
   --help, -h   help
   --es         spanish (default)
   --en         english
@@ -302,10 +778,14 @@ Flags:
 Shows the effective system topology (no discovery).
 
 Observes:
-  - Edge -> MQTT -> Backend -> Prometheus -> Grafana
+  - Edge -> MQTT -> Backend -> ML Analytics -> Prometheus -> Grafana
   - OK vs silent components
+  - A down Prometheus target (job backend / ml-analytics) flips its component to FAIL
 
 Flags:
+  --watch <dur> re-run the check periodically and highlight changes
+  --at <t>      evaluate at a past instant (see general help)
+  --range <r>   timeline of transitions (--step <dur>)
   --help, -h   help
   --es         spanish (default)
   --en         english
@@ -319,6 +799,10 @@ Observes:
   - Time-based status
 
 Flags:
+  --watch <dur> re-run the check periodically and highlight changes
+  --at <t>      evaluate at a past instant (see general help)
+  --range <r>   timeline of transitions (--step <dur>)
+  --from <dir>  read an extracted bundle instead of live endpoints (see general help)
   --help, -h   help
   --es         spanish (default)
   --en         english
@@ -330,8 +814,16 @@ Detects deviations from docs and dashboards.
 Observes:
   - Documented vs real metrics
   - Versioned dashboards vs docs
+  - Panel PromQL vs METRICS.md catalog (out of contract, FUTURO, no panel)
+  - METRICS.md minimal dashboards vs drones-data-plane.json (PromQL AST)
+  - Dashboards in Grafana (API) vs versioned JSON
+  - Grafana provisioning vs compose/prometheus.yml, datasource uids and health
+  - METRICS.md alerts vs versioned rules file and loaded rules (/api/v1/rules)
+  - prometheus.yml vs docker-compose.yml vs CLI defaults (targets, published ports, jobs without component)
 
 Flags:
+  --watch <dur> re-run the check periodically and highlight changes
+  --from <dir>  read an extracted bundle instead of live endpoints (see general help)
   --help, -h   help
   --es         spanish (default)
   --en         english
@@ -346,9 +838,335 @@ Observes:
   - Metric count and cardinality
 
 Flags:
+  --watch <dur> re-run the check periodically and highlight changes
+  --at <t>      evaluate at a past instant (see general help)
+  --range <r>   timeline of transitions (--step <dur>)
+  --from <dir>  read an extracted bundle instead of live endpoints (see general help)
+  --help, -h   help
+  --es         spanish (default)
+  --en         english
+`
+	case "fleet":
+		return `drone-observe fleet
+Per-drone view (drone_id label and drone/<id>/... topics).
+
+Shows:
+  - Battery, messages per second and freshness per drone
+  - ML state and last observed event
+  - Sortable table with per-drone drill-down
+
+Keys:
+  ↑/↓      select drone
+  enter    drone detail
+  esc      back to table
+  s / r    change sort column / reverse
+
+Flags:
+  --drone <id> open one drone's detail directly
+  --at <t>     evaluate at a past instant (Prometheus only, no MQTT)
+  --help, -h   help
+  --es         spanish (default)
+  --en         english
+`
+	case "serve":
+		return `drone-observe serve
+Runs governance checks on a schedule and exposes them to Prometheus.
+
+Checks:
+  - topology, freshness, drift, limits
+
+Endpoints:
+  /metrics   drone_observe_check_status{check,status} and run timings
+  /probe     one-shot probe: /probe?target=<host:port>&module=<module>
+  /healthz   200 if the last check round is recent (container healthcheck)
+
+/probe modules:
+  mqtt_connect     MQTT CONNECT/CONNACK handshake
+  mqtt_roundtrip   publish + receive on drone-observe/probe/... topic
+  backend_metrics  GET backend /metrics
+  grafana_health   GET Grafana /api/health
+
+Flags:
+  --listen <addr>      HTTP address (default: :9109)
+  --interval <dur>     interval between rounds (default: 30s)
+  --help, -h           help
+  --es                 spanish (default)
+  --en                 english
+`
+	case "lint-queries":
+		return `drone-observe lint-queries
+Statically analyzes every PromQL query without running it.
+
+Sources:
+  - CLI query registry (internal/queries)
+  - Versioned dashboard targets (DASHBOARDS_DIR)
+  - METRICS.md panels and alerts
+
+Rules (metric types from METRICS.md):
+  alta   invalid PromQL / metric outside the contract
+  alta   rate/irate/increase/resets over a gauge
+  media  rate/increase/delta range shorter than 2x scrape_interval (PROMETHEUS_CONFIG)
+  media  count over a selector without metric name (unbounded cardinality)
+  baja   unbounded cardinality accepted in the registry (limits queries)
+
+Exits 1 if there are findings of --fail-on severity or higher.
+
+Flags:
+  --fail-on <sev>  alta, media or baja (default: media)
+  --help, -h       help
+  --es             spanish (default)
+  --en             english
+`
+	case "targets":
+		return `drone-observe targets
+Scrape health per job as seen by Prometheus (/api/v1/targets and /api/v1/targets/metadata).
+
+Shows per job and target:
+  - Health (up/down), last error and age of the last scrape
+  - Last scrape duration and sample count (scrape_samples_scraped)
+  - Effective scrape_interval vs versioned prometheus.yml (PROMETHEUS_CONFIG)
+  - Metrics with metadata exposed by the job
+  - Jobs declared without active targets or scraped without being versioned
+
+Flags:
+  --watch <dur> re-run the check periodically and highlight changes
   --help, -h   help
   --es         spanish (default)
   --en         english
+`
+	case "alerts":
+		return `drone-observe alerts
+Active alerts grouped by severity and Alertmanager silences.
+
+Sources:
+  - Prometheus /api/v1/alerts (pending/firing from loaded rules)
+  - Alertmanager /api/v2/alerts and /api/v2/silences (ALERTMANAGER_URL)
+
+An alerts summary is also shown in health, telemetry and llm.
+
+Keys:
+  ↑/↓      select
+  tab      switch alerts / silences
+  s        silence the selected alert (asks y/n confirmation)
+  x        expire the selected silence (asks y/n confirmation)
+  r        refresh
+
+Flags:
+  --silence-duration <dur>  duration of created silences (default: 2h)
+  --help, -h                help
+  --es                      spanish (default)
+  --en                      english
+`
+	case "security":
+		return `drone-observe security
+Audits the versioned mosquitto.conf (MOSQUITTO_CONF) and confirms against the broker (MQTT_HOST/MQTT_PORT).
+
+Static (docs/06-seguridad.md: anonymous and plain 1883 are lab-only):
+  alta   allow_anonymous true, listener without TLS, no acl_file
+  alta   password_file/acl_file not mounted or plaintext password_file
+  media  obsolete TLS (tlsv1, tlsv1.1), no explicit listener, no authentication method
+  media  persistence_location without a compose volume
+  baja   persistence false, include_dir not audited
+
+Active (real MQTT handshake, no credentials):
+  - anonymous CONNECT: CONNACK 0 confirms allow_anonymous
+  - QoS 1 publish to drone/drone-observe-security/cmd/...: delivery confirms missing ACL
+  - differences between the file and the broker are reported as media
+
+Exits 1 if there are high severity findings.
+
+Flags:
+  --help, -h   help
+  --es         spanish (default)
+  --en         english
+`
+	case "acl":
+		return `drone-observe acl
+Verifies MQTT permissions per identity against a real broker (ACL_MATRIX matrix).
+
+The matrix (mqtt/acl/matrix.yml) declares identities, topics and expected permissions:
+  identities   name, username, password_env (environment variable; never the password), connect
+  checks       identity, topic, publish: allow|deny, subscribe: allow|deny, probe_topic
+  observer     identity with read/write access that confirms deliveries
+
+Each permission is verified by actual delivery (PUBACK proves nothing in MQTT 3.1.1):
+  publish      the identity publishes QoS 1; the observer must receive the message
+  subscribe    SUBACK 0x80 = deny; otherwise the observer publishes and the identity must receive it
+
+Lab broker: docker compose --profile acl up -d mqtt-acl (localhost:1884).
+Exits 1 if any row does not match or could not be verified.
+
+Flags:
+  --matrix <file>       matrix (default: ACL_MATRIX)
+  --broker <host:port>  broker (default: matrix broker, or MQTT_HOST:MQTT_PORT)
+  --help, -h            help
+  --es                  spanish (default)
+  --en                  english
+`
+	case "bundle":
+		return `drone-observe bundle
+Collects incident evidence into a tar.gz to attach to the ticket.
+
+Contents:
+  config.json              effective config (GRAFANA_ADMIN_PASSWORD and URL credentials redacted)
+  checks/*.json            output of each check (topology, freshness, limits, drift, validate, targets,
+                           alerts, fleet, security, lint-queries and the serve summary); security is
+                           static only: the bundle does not connect or publish to the broker
+  backend/metrics.txt      raw backend /metrics
+  prometheus/*.json        targets, rules, flags, buildinfo, tsdb and config (raw API)
+  prometheus/queries.json  CLI queries and contract metrics at bundle time
+  prometheus/range/*.json  query_range of each contract metric (--window)
+  grafana/                 dashboards as served by Grafana
+  mqtt/capture.jsonl       capture of drone/# (--capture; up to 10000 messages)
+  manifest.json            timestamps, duration, size, sha256 and error of each file
+  SHA256SUMS               verifiable with sha256sum -c
+
+A failing source does not abort the bundle: it is recorded in the manifest with its error.
+Offline analysis: tar xzf <bundle> && drone-observe drift --from <dir>
+
+Flags:
+  --out <file>       destination (default: drone-observe-bundle-<UTC>.tar.gz)
+  --window <dur>     Prometheus data range (default: 1h)
+  --step <dur>       range step (default: window/120, minimum 15s)
+  --capture <dur>    MQTT capture (default: 10s; 0 disables it)
+  --help, -h         help
+  --es               spanish (default)
+  --en               english
+`
+	case "record":
+		return `drone-observe record
+Records MQTT traffic to reproduce backend and ML bugs with the exact edge sequence.
+
+Each message stores topic, payload (raw bytes), QoS, retain and receive time in a compact
+.mqrec file (delta times, topic table). Stops on Ctrl-C, --duration or --max;
+a stop without Ctrl-C loses at most the last second.
+
+Flags:
+  --topic <f1,f2>       filters (default: drone/# and MQTT_BASE_TOPIC/# if outside drone/)
+  --out <file>          destination (default: drone-observe-record-<UTC>.mqrec)
+  --duration <dur>      record for dur
+  --max <n>             stop after n messages
+  --broker <host:port>  broker (default: MQTT_HOST:MQTT_PORT)
+  --help, -h            help
+  --es                  spanish (default)
+  --en                  english
+`
+	case "replay":
+		return `drone-observe replay --in <file.mqrec>
+Republishes a recording keeping the original intervals.
+
+Each message is scheduled against the replay start: a slow PUBACK delays that message
+but does not shift the rest. QoS (0/1) and retain are republished as recorded.
+Warns if topics fall under MQTT_BASE_TOPIC: consumers on that broker (backend, ML)
+process them as real traffic. Use --rewrite to republish under another prefix.
+
+Flags:
+  --in <file>                 recording to republish
+  --speed <x>|max             1 = original (default), 2 = twice as fast, max = no waits
+  --rewrite <from>=<to>       replaces the topic prefix (e.g. drone/alpha=replay/alpha)
+  --broker <host:port>        broker (default: MQTT_HOST:MQTT_PORT)
+  --help, -h                  help
+  --es                        spanish (default)
+  --en                        english
+`
+	case "simulate":
+		return `drone-observe simulate
+Generates MQTT load with N virtual drones (one connection per drone) to validate limits and the backend.
+
+Each drone publishes like the edge (EVENTS.md): <topic>/telemetry {seq, ts, battery_pct, altitude_m}
+with QoS 0 and <topic>/event BATTERY_LOW with QoS 1 when battery_pct reaches 25 (120-message cycle).
+The backend only consumes MQTT_BASE_TOPIC: to load it use --topic drone/alpha (all drones share the topic).
+
+Faults (--fault, comma separated; per-message probabilities):
+  dropout=<p>          the drone goes silent for dropout-for (link down; seq keeps advancing)
+  dropout-for=<dur>    silence length (default: 10s)
+  malformed=<p>        publishes truncated JSON
+  skew=<dur>           clock skew: each drone takes a fixed offset in [-dur, +dur] for ts
+  seq-reset=<p>        resets seq (edge reboot)
+
+Flags:
+  --drones <n>          number of drones (default: 5)
+  --rate <r>            telemetry per second per drone (default: 1)
+  --jitter <j>          period variation, fraction (default: 0.1 = +-10%)
+  --topic <template>    base topic with {id} (default: drone/{id})
+  --id-prefix <p>       ids <p>01..<p>N (default: sim)
+  --fault <list>        faults to inject (see above)
+  --seed <n>            seed (same seed = same payloads and faults)
+  --duration <dur>      run for dur (default: until Ctrl-C)
+  --broker <host:port>  broker (default: MQTT_HOST:MQTT_PORT)
+  --help, -h            help
+  --es                  spanish (default)
+  --en                  english
+`
+	case "chaos":
+		return `drone-observe chaos
+Runs a fault scenario (YAML with timed steps) and measures how long each check takes to detect the faults.
+
+The scenario publishes as one drone on MQTT_BASE_TOPIC (the topic backend and ML consume) and requires
+that nobody else publishes there: stop the edge first (docker compose stop edge; then docker compose start edge).
+
+Step actions (action, for the step's for):
+  publish     healthy telemetry like the edge (1 msg/s by default, BATTERY_LOW at 25)
+  stop        publishes nothing (edge down)
+  malformed   telemetry with truncated JSON
+  flood       healthy telemetry at rate x factor (default: 100)
+  anomaly     normal bursts closed by a battery and altitude spike (ml_state CRIT)
+
+Each expect (check, item, state, within) must be seen before within, counted from the step start:
+  checks      freshness, health, limits, ml (item ml_state), topology, validate
+  state       one state or a list ([WARN, FAIL]); within <= for (default: for)
+The report shows OK and the detection latency per expect, or FALLO and the last state seen. Exits 1 if any fails.
+
+Flags:
+  --scenario <file>     scenario (default: CHAOS_SCENARIO)
+  --broker <host:port>  broker (default: scenario broker, or MQTT_HOST:MQTT_PORT)
+  --dry-run             validates the scenario and lists the steps without publishing
+  --help, -h            help
+  --es                  spanish (default)
+  --en                  english
+`
+	case "test":
+		return `drone-observe test rules [file...]
+Runs promtool-format rule tests without a live Prometheus.
+
+What it does:
+  - Loads rule_files (relative to the test file) and the synthetic input_series
+  - Evaluates rules every evaluation_interval over simulated time (in-memory PromQL)
+  - Checks alert_rule_test (firing alerts with labels/annotations) and promql_expr_test
+
+Without a file it uses RULES_FILE with a .test.yml suffix (observability/rules/drones.rules.test.yml).
+Exits 1 if any group fails.
+
+The format is promtool's, the evaluator is not: results match promtool only where internal/promql
+replicates Prometheus (see docs/11). On any difference, promtool test rules wins.
+
+Flags:
+  --help, -h   help
+  --es         spanish (default)
+  --en         english
+`
+	case "generate":
+		return `drone-observe generate dashboard|rules
+Generates versionable artifacts from METRICS.md (deterministic output).
+
+Artifacts:
+  dashboard   Grafana dashboard (provisioning JSON)
+              - "Dashboards minimos" panels with their exact PromQL
+              - catalog metrics without a panel: gauge -> stat, counter -> rate timeseries
+              - Grafana unit from the contract unit (pct -> percent, ms, dbm...)
+  rules       Prometheus alerting rules from "Alertas recomendadas" (group drones-contract)
+              - expr validated with the PromQL parser; severity and ES/EN summary as labels/annotations
+
+Flags:
+  --out <file>      write to file (default: stdout)
+  --check           do not write; exit 1 if --out differs from generated output (CI)
+  --uid <uid>       dashboard uid (default: drones-contract)
+  --title <title>   dashboard title
+  (rules: --out observability/rules/drones.rules.yml, mounted in Prometheus)
+  --future          include FUTURO items and metrics
+  --help, -h        help
+  --es              spanish (default)
+  --en              english
 `
 	default:
 		return `drone-observe
@@ -363,19 +1181,57 @@ Commands:
   freshness  data recency
   drift      drift vs docs/dashboards
   limits     observed technical limits
+  fleet      per-drone status (drone_id)
+  serve      Prometheus exporter for checks
+  generate   generate dashboard and alert rules from METRICS.md
+  test       unit tests for alerting rules (promtool format)
+  lint-queries  static analysis of CLI and dashboard PromQL
+  alerts     active alerts by severity and silences (Alertmanager)
+  targets    scrape health per job (Prometheus targets)
+  security   mosquitto.conf audit with active verification
+  acl        MQTT permission matrix per identity (real broker)
+  bundle     support bundle (tar.gz) for post-mortems
+  record     records MQTT traffic to a .mqrec file
+  replay     republishes a recording (original, scaled or max; topic --rewrite)
+  simulate   MQTT load with N virtual drones and injectable faults
+  chaos      fault scenario with expected detection and latency per check
 
 Flags:
   --help, -h   help
   --es         spanish (default)
   --en         english
 
+Historical evaluation (freshness, topology, limits, validate; --at also fleet):
+  --at <t>          evaluate Prometheus queries at a past instant (ages relative to t)
+                    t: RFC3339, "2006-01-02 15:04[:05]", "15:04" (latest occurrence), unix or -<dur>
+  --range <a>,<b>   timeline of per-item transitions between two instants
+  --range <dur>     same, ending at --at (or now)
+  --step <dur>      range step (default: range/120, minimum 15s)
+
+Offline analysis (validate, drift, limits, freshness):
+  --from <dir>      read an extracted bundle (drone-observe bundle) instead of live endpoints
+                    evaluates at capture time; verifies SHA256SUMS and warns on mismatches
+
 Environment:
   MQTT_HOST (default: mqtt)
   MQTT_PORT (default: 1883)
+  MQTT_BASE_TOPIC (default: drone/alpha)
   BACKEND_HTTP_PORT (default: 8080)
   PROMETHEUS_URL (default: http://localhost:9090)
+  ALERTMANAGER_URL (default: http://localhost:9093)
   GRAFANA_URL (default: http://localhost:3000)
+  GRAFANA_ADMIN_USER (default: admin)
+  GRAFANA_ADMIN_PASSWORD (default: admin)
   METRICS_DOC (default: METRICS.md)
+  DASHBOARDS_DIR (default: observability/grafana/dashboards)
+  DOCS_DIR (default: docs)
+  GRAFANA_PROVISIONING_DIR (default: observability/grafana/provisioning)
+  PROMETHEUS_CONFIG (default: observability/prometheus.yml)
+  RULES_FILE (default: observability/rules/drones.rules.yml)
+  COMPOSE_FILE (default: docker-compose.yml)
+  MOSQUITTO_CONF (default: mqtt/mosquitto.conf)
+  ACL_MATRIX (default: mqtt/acl/matrix.yml)
+  CHAOS_SCENARIO (default: observability/scenarios/pipeline-faults.yml)
   FRESHNESS_WARN_SEC (default: 30)
   FRESHNESS_FAIL_SEC (default: 120)
 
@@ -0,0 +1,31 @@
+// Archivo: tools/drone-observe/cmd/security.go
+// Rol: comando security para auditar mosquitto.conf y confirmar los hallazgos contra el broker.
+// No hace: modificar mosquitto.conf ni probar credenciales.
+package cmd
+
+import (
+	"fmt"
+	"sort"
+
+	"drone-observe/internal/audit"
+	"drone-observe/internal/config"
+)
+
+func runSecurity(cfg config.Config) int {
+	findings := audit.Security(cfg)
+	sort.SliceStable(findings, func(i, j int) bool {
+		return severityOrder[findings[i].Severity] < severityOrder[findings[j].Severity]
+	})
+
+	fmt.Printf("Seguridad MQTT: %s (verificacion activa en %s:%d)\n", cfg.MosquittoConf, cfg.MQTTHost, cfg.MQTTPort)
+	counts := map[audit.Severity]int{}
+	for _, f := range findings {
+		counts[f.Severity]++
+		fmt.Printf("  [%-5s] %s\n          %s\n", f.Severity, f.Item, f.Detail)
+	}
+	fmt.Printf("%d alta, %d media, %d baja\n", counts[audit.SeverityHigh], counts[audit.SeverityMed], counts[audit.SeverityLow])
+	if counts[audit.SeverityHigh] > 0 {
+		return 1
+	}
+	return 0
+}
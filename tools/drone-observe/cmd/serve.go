@@ -0,0 +1,36 @@
+// Archivo: tools/drone-observe/cmd/serve.go
+// Rol: comando serve para exponer checks de gobernanza como metricas Prometheus.
+// No hace: TUI ni alertas; Prometheus/Grafana consumen /metrics.
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"drone-observe/internal/config"
+	"drone-observe/internal/exporter"
+)
+
+func runServe(cfg config.Config, flags []string) int {
+	listen := flagValue(flags, "--listen")
+	if listen == "" {
+		listen = exporter.DefaultListen
+	}
+	interval := exporter.DefaultInterval
+	if v := flagValue(flags, "--interval"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			fmt.Fprintf(os.Stderr, "intervalo invalido: %s\n", v)
+			return 2
+		}
+		interval = d
+	}
+
+	fmt.Fprintf(os.Stderr, "drone-observe serve escuchando en %s (intervalo %s)\n", listen, interval)
+	if err := exporter.Serve(cfg, listen, interval); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,167 @@
+// Archivo: tools/drone-observe/cmd/simulate.go
+// Rol: comando simulate para generar carga MQTT con N drones virtuales (contrato de EVENTS.md y fallas).
+// No hace: reemplazar al edge de compose; se corre a mano contra un broker de laboratorio.
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"drone-observe/internal/config"
+	"drone-observe/internal/mqttclient"
+	"drone-observe/internal/recording"
+	"drone-observe/internal/sim"
+)
+
+const (
+	defaultSimDrones = 5
+	defaultSimJitter = 0.1
+	defaultSimTopic  = "drone/{id}"
+	simReportEvery   = 5 * time.Second
+	maxSimRate       = 1000
+	simMaxErrors     = 10
+)
+
+func runSimulate(cfg config.Config, flags []string) int {
+	host, port, err := brokerFlag(cfg, flags)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	simCfg := sim.Config{Topic: defaultSimTopic, Rate: 1, Jitter: defaultSimJitter, Seed: time.Now().UnixNano()}
+	drones := defaultSimDrones
+	if v := flagValue(flags, "--drones"); v != "" {
+		if drones, err = strconv.Atoi(v); err != nil || drones <= 0 {
+			fmt.Fprintf(os.Stderr, "--drones invalido: %s\n", v)
+			return 2
+		}
+	}
+	if v := flagValue(flags, "--rate"); v != "" {
+		if simCfg.Rate, err = strconv.ParseFloat(v, 64); err != nil || simCfg.Rate <= 0 || simCfg.Rate > maxSimRate {
+			fmt.Fprintf(os.Stderr, "--rate invalido: %s (mensajes/s por dron, 0 < r <= %d)\n", v, maxSimRate)
+			return 2
+		}
+	}
+	if v := flagValue(flags, "--jitter"); v != "" {
+		if simCfg.Jitter, err = strconv.ParseFloat(v, 64); err != nil || simCfg.Jitter < 0 || simCfg.Jitter >= 1 {
+			fmt.Fprintf(os.Stderr, "--jitter invalido: %s (fraccion del periodo, 0 <= j < 1)\n", v)
+			return 2
+		}
+	}
+	if v := flagValue(flags, "--seed"); v != "" {
+		if simCfg.Seed, err = strconv.ParseInt(v, 10, 64); err != nil {
+			fmt.Fprintf(os.Stderr, "--seed invalido: %s\n", v)
+			return 2
+		}
+	}
+	if v := flagValue(flags, "--topic"); v != "" {
+		simCfg.Topic = v
+	}
+	if strings.ContainsAny(simCfg.Topic, "+#") {
+		fmt.Fprintf(os.Stderr, "--topic invalido: %s (sin wildcards)\n", simCfg.Topic)
+		return 2
+	}
+	prefix := "sim"
+	if v := flagValue(flags, "--id-prefix"); v != "" {
+		prefix = v
+	}
+	simCfg.IDs = sim.IDs(prefix, drones)
+	if simCfg.Faults, err = sim.ParseFaults(flagValue(flags, "--fault")); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	var duration time.Duration
+	if v := flagValue(flags, "--duration"); v != "" {
+		if duration, err = time.ParseDuration(v); err != nil || duration <= 0 {
+			fmt.Fprintf(os.Stderr, "--duration invalido: %s\n", v)
+			return 2
+		}
+	}
+
+	first, last := sim.TopicFor(simCfg.Topic, simCfg.IDs[0]), sim.TopicFor(simCfg.Topic, simCfg.IDs[len(simCfg.IDs)-1])
+	fmt.Printf("Simulando %d drones en %s:%d: %s/{telemetry,event}", drones, host, port, first)
+	if last != first {
+		fmt.Printf(" .. %s", last)
+	}
+	fmt.Printf("\n  %g msg/s por dron (jitter %g), ~%g msg/s total, semilla %d\n",
+		simCfg.Rate, simCfg.Jitter, simCfg.Rate*float64(drones), simCfg.Seed)
+	if f := simCfg.Faults.String(); f != "" {
+		fmt.Printf("  fallas: %s\n", f)
+	}
+	if hits := recording.Collides([]string{first + "/telemetry", last + "/telemetry"}, recording.Rewrite{}, cfg.MQTTBaseTopic); len(hits) > 0 {
+		fmt.Fprintf(os.Stderr, "aviso: se publica bajo MQTT_BASE_TOPIC (%s): el backend y el ML consumen esta carga como trafico real\n", cfg.MQTTBaseTopic)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, duration)
+		defer cancel()
+	}
+	dial := func(clientID string) (sim.Publisher, error) {
+		client, err := mqttclient.Dial(host, port, mqttclient.Options{ClientID: clientID})
+		if err != nil {
+			return nil, err
+		}
+		return client, nil
+	}
+
+	var stats sim.Stats
+	start := time.Now()
+	done := make(chan []sim.DroneError, 1)
+	go func() { done <- sim.Run(ctx, simCfg, dial, &stats) }()
+	ticker := time.NewTicker(simReportEvery)
+	defer ticker.Stop()
+	var failures []sim.DroneError
+	prev := int64(0)
+loop:
+	for {
+		select {
+		case <-ticker.C:
+			sent := stats.Telemetry.Load()
+			fmt.Printf("  %-6s %7.1f msg/s  %s\n", time.Since(start).Round(time.Second),
+				float64(sent-prev)/simReportEvery.Seconds(), simSummary(&stats))
+			prev = sent
+		case failures = <-done:
+			break loop
+		}
+	}
+
+	elapsed := time.Since(start)
+	fmt.Printf("Fin tras %s: %s\n", elapsed.Round(time.Second), simSummary(&stats))
+	for i, f := range failures {
+		if i == simMaxErrors {
+			fmt.Fprintf(os.Stderr, "  ... y %d drones caidos mas\n", len(failures)-i)
+			break
+		}
+		fmt.Fprintf(os.Stderr, "  [error] %s\n", f)
+	}
+	if len(failures) > 0 {
+		return 1
+	}
+	return 0
+}
+
+func simSummary(s *sim.Stats) string {
+	out := fmt.Sprintf("telemetria %d, eventos %d", s.Telemetry.Load(), s.Events.Load())
+	if n := s.Dropped.Load(); n > 0 {
+		out += fmt.Sprintf(", callados %d (%d dropouts)", n, s.Dropouts.Load())
+	}
+	if n := s.Malformed.Load(); n > 0 {
+		out += fmt.Sprintf(", malformados %d", n)
+	}
+	if n := s.SeqResets.Load(); n > 0 {
+		out += fmt.Sprintf(", seq resets %d", n)
+	}
+	if n := s.Errors.Load(); n > 0 {
+		out += fmt.Sprintf(", drones caidos %d", n)
+	}
+	return out
+}
@@ -0,0 +1,18 @@
+// Archivo: tools/drone-observe/cmd/targets.go
+// Rol: comando targets para inspeccionar salud de scrape por job.
+// No hace: editar prometheus.yml ni recargar Prometheus.
+package cmd
+
+import (
+	"time"
+
+	"drone-observe/internal/config"
+	"drone-observe/internal/ui"
+)
+
+func runTargets(cfg config.Config, watch time.Duration) int {
+	if err := ui.RunTargets(cfg, watch); err != nil {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,65 @@
+// Archivo: tools/drone-observe/cmd/test.go
+// Rol: comando test para ejecutar tests unitarios de reglas Prometheus sin Prometheus vivo.
+// No hace: cargar reglas en Prometheus; `drift` verifica lo cargado.
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"drone-observe/internal/config"
+	"drone-observe/internal/repo"
+	"drone-observe/internal/ruletest"
+)
+
+func runTest(cfg config.Config, flags []string) int {
+	var args []string
+	for _, f := range flags {
+		if !strings.HasPrefix(f, "-") {
+			args = append(args, f)
+		}
+	}
+	if len(args) == 0 || args[0] != "rules" {
+		fmt.Fprintln(os.Stderr, "uso: drone-observe test rules [archivo...]")
+		return 2
+	}
+	files := args[1:]
+	if len(files) == 0 {
+		files = []string{strings.TrimSuffix(cfg.RulesFile, ".yml") + ".test.yml"}
+	}
+
+	failed := false
+	for _, file := range files {
+		path, err := repo.Resolve(file)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		res, err := ruletest.Run(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", file, err)
+			failed = true
+			continue
+		}
+		fmt.Printf("Tests de reglas: %s\n", file)
+		ok := 0
+		for _, g := range res.Groups {
+			if len(g.Failures) == 0 {
+				ok++
+				fmt.Printf("  OK     %s\n", g.Name)
+				continue
+			}
+			fmt.Printf("  FALLO  %s\n", g.Name)
+			for _, f := range g.Failures {
+				fmt.Printf("         %s\n", strings.ReplaceAll(f, "\n", "\n         "))
+			}
+		}
+		fmt.Printf("%d/%d grupos OK\n", ok, len(res.Groups))
+		failed = failed || res.Failed()
+	}
+	if failed {
+		return 1
+	}
+	return 0
+}
@@ -4,12 +4,14 @@
 package cmd
 
 import (
+	"time"
+
 	"drone-observe/internal/config"
 	"drone-observe/internal/ui"
 )
 
-func runTopology(cfg config.Config) int {
-	if err := ui.RunTopology(cfg); err != nil {
+func runTopology(cfg config.Config, watch time.Duration) int {
+	if err := ui.RunTopology(cfg, watch); err != nil {
 		return 1
 	}
 	return 0
@@ -4,12 +4,14 @@
 package cmd
 
 import (
+	"time"
+
 	"drone-observe/internal/config"
 	"drone-observe/internal/ui"
 )
 
-func runValidate(cfg config.Config) int {
-	if err := ui.RunValidate(cfg); err != nil {
+func runValidate(cfg config.Config, watch time.Duration) int {
+	if err := ui.RunValidate(cfg, watch); err != nil {
 		return 1
 	}
 	return 0
@@ -0,0 +1,270 @@
+// Archivo: tools/drone-observe/internal/acl/check.go
+// Rol: ejecutar la matriz de ACL contra un broker real (CONNECT, SUBSCRIBE y PUBLISH por identidad).
+// No hace: leer acl_file ni inferir permisos; solo reporta lo que el broker permitio.
+package acl
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"drone-observe/internal/mqttclient"
+)
+
+// Operaciones reportadas.
+const (
+	OpConnect   = "connect"
+	OpPublish   = "publish"
+	OpSubscribe = "subscribe"
+)
+
+const (
+	dialTimeout = 3 * time.Second
+	// deliveryTimeout acota la espera de una entrega; un deny solo se observa como ausencia de mensaje.
+	deliveryTimeout = 2 * time.Second
+	// probeSegment reemplaza wildcards para derivar un topic concreto desde un filtro.
+	probeSegment = "drone-observe-acl"
+)
+
+// Result es una fila verificada: lo esperado contra lo que el broker permitio.
+type Result struct {
+	Identity string
+	Op       string
+	Topic    string
+	Expected string
+	// Observed es allow, deny o vacio si no se pudo verificar (cuenta como fallo).
+	Observed string
+	Detail   string
+}
+
+// Pass indica si el broker se comporto como se esperaba.
+func (r Result) Pass() bool { return r.Observed != "" && r.Observed == r.Expected }
+
+type Report struct {
+	Broker  string
+	Results []Result
+}
+
+// Failed indica si alguna fila no paso.
+func (r Report) Failed() bool {
+	for _, res := range r.Results {
+		if !res.Pass() {
+			return true
+		}
+	}
+	return false
+}
+
+// runner guarda el broker y el contador de client ids de una corrida.
+type runner struct {
+	host   string
+	port   int
+	matrix Matrix
+	seq    int
+}
+
+// PARTE CRITICA **********************
+// MQTT 3.1.1 no informa un publish denegado: el broker responde PUBACK y descarta el mensaje. Por eso
+// cada permiso se verifica por entrega: el observador escucha lo que publica la identidad, y la
+// identidad escucha lo que publica el observador. Antes de cada verificacion el observador se prueba
+// a si mismo; si no recibe su propio mensaje la fila queda sin verificar en lugar de contarse como deny.
+// Cada operacion abre su propia conexion (un deny puede cerrar la sesion en algunos brokers).
+// FIN DE PARTE CRITICA ****************
+func Run(m Matrix, host string, port int) Report {
+	r := &runner{host: host, port: port, matrix: m}
+	report := Report{Broker: fmt.Sprintf("%s:%d", host, port)}
+
+	connected := map[string]Result{}
+	for _, id := range m.Identities {
+		if id.Connect == "" && id.Name != m.Observer && !r.usedInChecks(id.Name) {
+			continue
+		}
+		res := r.checkConnect(id)
+		connected[id.Name] = res
+		report.Results = append(report.Results, res)
+	}
+
+	for _, c := range m.Checks {
+		id, _ := m.Identity(c.Identity)
+		conn := connected[c.Identity]
+		for _, op := range []struct{ name, expected string }{{OpPublish, c.Publish}, {OpSubscribe, c.Subscribe}} {
+			if op.expected == "" {
+				continue
+			}
+			res := Result{Identity: id.Name, Op: op.name, Topic: c.Topic, Expected: op.expected}
+			switch {
+			case conn.Observed == Deny:
+				res.Observed, res.Detail = Deny, "sin conexion (CONNECT rechazado)"
+			case conn.Observed == "":
+				res.Detail = "sin verificar: " + conn.Detail
+			case op.name == OpPublish:
+				res.Observed, res.Detail = r.checkPublish(id, c.Topic)
+			default:
+				res.Observed, res.Detail = r.checkSubscribe(id, c.Topic, probeTopic(c))
+			}
+			report.Results = append(report.Results, res)
+		}
+	}
+	return report
+}
+
+func (r *runner) usedInChecks(name string) bool {
+	for _, c := range r.matrix.Checks {
+		if c.Identity == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *runner) dial(id Identity) (*mqttclient.Client, error) {
+	password, err := id.Password()
+	if err != nil {
+		return nil, err
+	}
+	r.seq++
+	return mqttclient.Dial(r.host, r.port, mqttclient.Options{
+		ClientID: fmt.Sprintf("drone-observe-acl-%s-%d-%d", id.Name, time.Now().UnixNano()%100000, r.seq),
+		Username: id.Username,
+		Password: password,
+		Timeout:  dialTimeout,
+	})
+}
+
+func (r *runner) checkConnect(id Identity) Result {
+	res := Result{Identity: id.Name, Op: OpConnect, Topic: "-", Expected: id.Connect}
+	if res.Expected == "" {
+		res.Expected = Allow
+	}
+	client, err := r.dial(id)
+	var refused *mqttclient.ConnectError
+	switch {
+	case errors.As(err, &refused):
+		res.Observed, res.Detail = Deny, err.Error()
+	case err != nil:
+		res.Detail = err.Error()
+	default:
+		_ = client.Close()
+		res.Observed = Allow
+		if id.Username == "" {
+			res.Detail = "anonimo"
+		}
+	}
+	return res
+}
+
+// checkPublish publica como la identidad y confirma con el observador suscripto al mismo topic.
+func (r *runner) checkPublish(id Identity, topic string) (string, string) {
+	observer, err := r.observerOn(topic)
+	if err != nil {
+		return "", err.Error()
+	}
+	defer observer.Close()
+
+	client, err := r.dial(id)
+	if err != nil {
+		return "", err.Error()
+	}
+	defer client.Close()
+	payload := r.payload(id.Name)
+	if err := client.Publish(topic, payload, 1, false); err != nil {
+		// Algunos brokers cortan la sesion ante un publish denegado.
+		return Deny, "publish: " + err.Error()
+	}
+	if awaitMessage(observer, topic, payload) {
+		return Allow, "entregado al observador"
+	}
+	return Deny, fmt.Sprintf("PUBACK sin entrega en %s", deliveryTimeout)
+}
+
+// checkSubscribe suscribe como la identidad y publica desde el observador en un topic concreto del filtro.
+func (r *runner) checkSubscribe(id Identity, filter, probe string) (string, string) {
+	client, err := r.dial(id)
+	if err != nil {
+		return "", err.Error()
+	}
+	defer client.Close()
+	code, err := client.Subscribe(filter, 1)
+	if err != nil {
+		return "", "subscribe: " + err.Error()
+	}
+	if code == mqttclient.SubackFailure {
+		return Deny, "SUBACK 0x80"
+	}
+
+	observer, err := r.observerOn(probe)
+	if err != nil {
+		return "", err.Error()
+	}
+	defer observer.Close()
+	payload := r.payload(id.Name)
+	if err := observer.Publish(probe, payload, 1, false); err != nil {
+		return "", "observador: " + err.Error()
+	}
+	if awaitMessage(client, probe, payload) {
+		return Allow, "recibido desde " + probe
+	}
+	return Deny, fmt.Sprintf("SUBACK %d sin entrega de %s", code, probe)
+}
+
+// observerOn conecta al observador suscripto a topic y verifica que recibe su propio mensaje.
+func (r *runner) observerOn(topic string) (*mqttclient.Client, error) {
+	id, _ := r.matrix.Identity(r.matrix.Observer)
+	observer, err := r.dial(id)
+	if err != nil {
+		return nil, fmt.Errorf("sin verificar: observador %s: %v", id.Name, err)
+	}
+	code, err := observer.Subscribe(topic, 1)
+	if err == nil && code == mqttclient.SubackFailure {
+		err = errors.New("SUBACK 0x80")
+	}
+	if err == nil {
+		control := r.payload(id.Name)
+		if err = observer.Publish(topic, control, 1, false); err == nil && !awaitMessage(observer, topic, control) {
+			err = errors.New("no recibe su propio mensaje")
+		}
+	}
+	if err != nil {
+		_ = observer.Close()
+		return nil, fmt.Errorf("sin verificar: observador %s sin lectura/escritura en %s (%v)", id.Name, topic, err)
+	}
+	return observer, nil
+}
+
+func (r *runner) payload(name string) []byte {
+	r.seq++
+	return []byte(fmt.Sprintf("drone-observe acl %s %d-%d", name, time.Now().UnixNano(), r.seq))
+}
+
+func awaitMessage(client *mqttclient.Client, topic string, payload []byte) bool {
+	timer := time.NewTimer(deliveryTimeout)
+	defer timer.Stop()
+	for {
+		select {
+		case msg, ok := <-client.Messages():
+			if !ok {
+				return false
+			}
+			if msg.Topic == topic && string(msg.Payload) == string(payload) {
+				return true
+			}
+		case <-timer.C:
+			return false
+		}
+	}
+}
+
+// probeTopic devuelve el topic concreto para verificar una suscripcion (probe_topic o el filtro sin wildcards).
+func probeTopic(c Check) string {
+	if c.ProbeTopic != "" {
+		return c.ProbeTopic
+	}
+	levels := strings.Split(c.Topic, "/")
+	for i, l := range levels {
+		if l == "+" || l == "#" {
+			levels[i] = probeSegment
+		}
+	}
+	return strings.Join(levels, "/")
+}
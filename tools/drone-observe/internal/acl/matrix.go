@@ -0,0 +1,168 @@
+// Archivo: tools/drone-observe/internal/acl/matrix.go
+// Rol: leer la matriz versionada de identidades MQTT, topics y permisos esperados.
+// No hace: guardar passwords; cada identidad referencia una variable de entorno.
+package acl
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"drone-observe/internal/mqttclient"
+)
+
+// Valores aceptados para una expectativa.
+const (
+	Allow = "allow"
+	Deny  = "deny"
+)
+
+// Identity es un cliente MQTT; sin Username conecta anonimo.
+type Identity struct {
+	Name        string `yaml:"name"`
+	Username    string `yaml:"username"`
+	PasswordEnv string `yaml:"password_env"`
+	// Connect es la expectativa del CONNECT (default allow).
+	Connect string `yaml:"connect"`
+}
+
+// Check es una fila de la matriz: una identidad sobre un topic o filtro.
+type Check struct {
+	Identity  string `yaml:"identity"`
+	Topic     string `yaml:"topic"`
+	Publish   string `yaml:"publish"`
+	Subscribe string `yaml:"subscribe"`
+	// ProbeTopic es el topic concreto que publica el observador para verificar un filtro con wildcards.
+	ProbeTopic string `yaml:"probe_topic"`
+}
+
+type Matrix struct {
+	Path string `yaml:"-"`
+	// Broker es host:port; --broker lo reemplaza y sin ninguno se usa MQTT_HOST:MQTT_PORT.
+	Broker string `yaml:"broker"`
+	// Observer es la identidad con lectura/escritura usada para confirmar entregas.
+	Observer   string     `yaml:"observer"`
+	Identities []Identity `yaml:"identities"`
+	Checks     []Check    `yaml:"checks"`
+}
+
+// Identity busca una identidad por nombre.
+func (m Matrix) Identity(name string) (Identity, bool) {
+	for _, id := range m.Identities {
+		if id.Name == name {
+			return id, true
+		}
+	}
+	return Identity{}, false
+}
+
+// Password resuelve la password desde el entorno; vacia si la identidad no declara variable.
+func (id Identity) Password() (string, error) {
+	if id.PasswordEnv == "" {
+		return "", nil
+	}
+	v, ok := os.LookupEnv(id.PasswordEnv)
+	if !ok {
+		return "", fmt.Errorf("identidad %s: variable %s no definida", id.Name, id.PasswordEnv)
+	}
+	return v, nil
+}
+
+// LoadMatrix lee y valida la matriz.
+func LoadMatrix(path string) (Matrix, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Matrix{}, err
+	}
+	var m Matrix
+	if err := yaml.Unmarshal(data, &m); err != nil {
+		return Matrix{}, fmt.Errorf("%s: %w", path, err)
+	}
+	m.Path = path
+	if err := m.validate(); err != nil {
+		return Matrix{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return m, nil
+}
+
+func (m Matrix) validate() error {
+	seen := map[string]bool{}
+	for _, id := range m.Identities {
+		if id.Name == "" {
+			return fmt.Errorf("identidad sin name")
+		}
+		if seen[id.Name] {
+			return fmt.Errorf("identidad duplicada: %s", id.Name)
+		}
+		seen[id.Name] = true
+		if err := validExpectation(id.Connect); err != nil {
+			return fmt.Errorf("identidad %s: connect: %w", id.Name, err)
+		}
+		if id.PasswordEnv != "" && id.Username == "" {
+			return fmt.Errorf("identidad %s: password_env sin username", id.Name)
+		}
+	}
+	if m.Observer == "" {
+		return fmt.Errorf("falta observer (identidad que confirma las entregas)")
+	}
+	if !seen[m.Observer] {
+		return fmt.Errorf("observer %s no es una identidad declarada", m.Observer)
+	}
+	if m.Broker != "" {
+		if _, _, err := SplitBroker(m.Broker); err != nil {
+			return err
+		}
+	}
+	for i, c := range m.Checks {
+		where := fmt.Sprintf("check %d (%s %s)", i+1, c.Identity, c.Topic)
+		if !seen[c.Identity] {
+			return fmt.Errorf("%s: identidad no declarada", where)
+		}
+		if c.Topic == "" {
+			return fmt.Errorf("%s: falta topic", where)
+		}
+		if c.Publish == "" && c.Subscribe == "" {
+			return fmt.Errorf("%s: sin publish ni subscribe", where)
+		}
+		for _, v := range []string{c.Publish, c.Subscribe} {
+			if err := validExpectation(v); err != nil {
+				return fmt.Errorf("%s: %w", where, err)
+			}
+		}
+		if c.Publish != "" && hasWildcard(c.Topic) {
+			return fmt.Errorf("%s: publish requiere un topic sin wildcards", where)
+		}
+		if c.ProbeTopic != "" && (hasWildcard(c.ProbeTopic) || !mqttclient.MatchTopic(c.Topic, c.ProbeTopic)) {
+			return fmt.Errorf("%s: probe_topic %s no es un topic concreto que matchee el filtro", where, c.ProbeTopic)
+		}
+	}
+	return nil
+}
+
+func validExpectation(v string) error {
+	if v == "" || v == Allow || v == Deny {
+		return nil
+	}
+	return fmt.Errorf("valor %q invalido (allow|deny)", v)
+}
+
+// SplitBroker separa host:port.
+func SplitBroker(addr string) (string, int, error) {
+	host, portStr, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", 0, fmt.Errorf("broker %q: %w", addr, err)
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		return "", 0, fmt.Errorf("broker %q: puerto invalido", addr)
+	}
+	return host, port, nil
+}
+
+func hasWildcard(topic string) bool {
+	return strings.ContainsAny(topic, "+#")
+}
@@ -0,0 +1,160 @@
+// Archivo: tools/drone-observe/internal/alertmanager/alertmanager.go
+// Rol: cliente minimo de la API v2 de Alertmanager (alertas, silencios, crear y expirar silencio).
+// No hace: rutas, receivers ni inhibiciones; solo lo necesario para operar silencios desde el CLI.
+package alertmanager
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Client consulta la API v2 de Alertmanager (sin autenticacion, como el contenedor local).
+type Client struct {
+	BaseURL string
+	HTTP    *http.Client
+}
+
+func NewClient(baseURL string) *Client {
+	return &Client{
+		BaseURL: strings.TrimRight(baseURL, "/"),
+		HTTP:    &http.Client{Timeout: 3 * time.Second},
+	}
+}
+
+// Alert es una alerta recibida por Alertmanager.
+type Alert struct {
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations"`
+	StartsAt    time.Time         `json:"startsAt"`
+	EndsAt      time.Time         `json:"endsAt"`
+	Fingerprint string            `json:"fingerprint"`
+	Status      struct {
+		State       string   `json:"state"`
+		SilencedBy  []string `json:"silencedBy"`
+		InhibitedBy []string `json:"inhibitedBy"`
+	} `json:"status"`
+}
+
+type Matcher struct {
+	Name    string `json:"name"`
+	Value   string `json:"value"`
+	IsRegex bool   `json:"isRegex"`
+	IsEqual bool   `json:"isEqual"`
+}
+
+func (m Matcher) String() string {
+	op := "="
+	switch {
+	case m.IsRegex && m.IsEqual:
+		op = "=~"
+	case m.IsRegex:
+		op = "!~"
+	case !m.IsEqual:
+		op = "!="
+	}
+	return fmt.Sprintf("%s%s%q", m.Name, op, m.Value)
+}
+
+// Silence es un silencio; ID y Status los completa Alertmanager.
+type Silence struct {
+	ID        string    `json:"id,omitempty"`
+	Matchers  []Matcher `json:"matchers"`
+	StartsAt  time.Time `json:"startsAt"`
+	EndsAt    time.Time `json:"endsAt"`
+	CreatedBy string    `json:"createdBy"`
+	Comment   string    `json:"comment"`
+	Status    *struct {
+		State string `json:"state"`
+	} `json:"status,omitempty"`
+}
+
+// State devuelve active, pending o expired ("" si Alertmanager no lo informo).
+func (s Silence) State() string {
+	if s.Status == nil {
+		return ""
+	}
+	return s.Status.State
+}
+
+// Alerts lista las alertas (activas, silenciadas e inhibidas).
+func (c *Client) Alerts(ctx context.Context) ([]Alert, error) {
+	var alerts []Alert
+	if err := c.do(ctx, http.MethodGet, "/api/v2/alerts", nil, &alerts); err != nil {
+		return nil, err
+	}
+	return alerts, nil
+}
+
+// Silences lista todos los silencios, incluidos los expirados.
+func (c *Client) Silences(ctx context.Context) ([]Silence, error) {
+	var silences []Silence
+	if err := c.do(ctx, http.MethodGet, "/api/v2/silences", nil, &silences); err != nil {
+		return nil, err
+	}
+	return silences, nil
+}
+
+// CreateSilence crea un silencio y devuelve su id.
+func (c *Client) CreateSilence(ctx context.Context, s Silence) (string, error) {
+	s.ID, s.Status = "", nil
+	var resp struct {
+		SilenceID string `json:"silenceID"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/api/v2/silences", s, &resp); err != nil {
+		return "", err
+	}
+	if resp.SilenceID == "" {
+		return "", fmt.Errorf("alertmanager: respuesta sin silenceID")
+	}
+	return resp.SilenceID, nil
+}
+
+// ExpireSilence expira un silencio activo o pendiente.
+func (c *Client) ExpireSilence(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodDelete, "/api/v2/silence/"+url.PathEscape(id), nil, nil)
+}
+
+func (c *Client) do(ctx context.Context, method, path string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.BaseURL+path, body)
+	if err != nil {
+		return err
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg := strings.TrimSpace(string(data))
+		if len(msg) > 120 {
+			msg = msg[:120]
+		}
+		return fmt.Errorf("alertmanager %s %s: http status %d %s", method, path, resp.StatusCode, msg)
+	}
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, out)
+}
@@ -0,0 +1,222 @@
+// Archivo: tools/drone-observe/internal/alerts/alerts.go
+// Rol: unir alertas de Prometheus (/api/v1/alerts) y Alertmanager (/api/v2/alerts) agrupadas por severidad.
+// No hace: evaluar reglas ni decidir severidades; se usa el label severity de cada alerta.
+package alerts
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"drone-observe/internal/alertmanager"
+	"drone-observe/internal/config"
+	"drone-observe/internal/prometheus"
+)
+
+// SilenceCreator identifica los silencios creados desde el CLI.
+const SilenceCreator = "drone-observe"
+
+// severityOrder define el orden de los grupos; severidades desconocidas van despues, "" al final.
+var severityOrder = map[string]int{"critical": 0, "warning": 1, "info": 2}
+
+// Item es una alerta vista por Prometheus, por Alertmanager o por ambos.
+type Item struct {
+	Name     string
+	Severity string
+	Labels   map[string]string
+	Summary  string
+	Since    time.Time
+	// PromState es pending/firing ("" si Prometheus no la reporta).
+	PromState string
+	// AMState es active/suppressed/unprocessed ("" si no llego a Alertmanager).
+	AMState    string
+	SilencedBy []string
+}
+
+// LabelString imprime los labels sin alertname, ordenados: {job="backend",severity="warning"}.
+func (i Item) LabelString() string {
+	keys := make([]string, 0, len(i.Labels))
+	for k := range i.Labels {
+		if k != "alertname" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, fmt.Sprintf("%s=%q", k, i.Labels[k]))
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+// Silenced indica si Alertmanager la suprime por un silencio.
+func (i Item) Silenced() bool { return len(i.SilencedBy) > 0 }
+
+type Group struct {
+	Severity string
+	Items    []Item
+}
+
+type Snapshot struct {
+	Groups []Group
+	// Silences son los silencios activos y pendientes (los expirados no se muestran).
+	Silences        []alertmanager.Silence
+	PrometheusErr   string
+	AlertmanagerErr string
+	UpdatedAt       time.Time
+}
+
+// Counts devuelve alertas firing por severidad y cuantas estan silenciadas.
+func (s Snapshot) Counts() (firing map[string]int, silenced int) {
+	firing = map[string]int{}
+	for _, g := range s.Groups {
+		for _, it := range g.Items {
+			if it.Silenced() {
+				silenced++
+				continue
+			}
+			if it.PromState == "firing" || it.AMState == "active" {
+				firing[g.Severity]++
+			}
+		}
+	}
+	return firing, silenced
+}
+
+// PARTE CRITICA **********************
+// Prometheus y Alertmanager se consultan por separado y se unen por el conjunto de labels:
+// pending solo existe en Prometheus y los silencios solo en Alertmanager. Si uno falla se muestra
+// el otro con el error explicito; no ocultar alertas por no poder cruzarlas.
+// FIN DE PARTE CRITICA ****************
+func Collect(cfg config.Config) Snapshot {
+	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second)
+	defer cancel()
+
+	snap := Snapshot{UpdatedAt: time.Now()}
+	items := map[string]*Item{}
+	get := func(labels, annotations map[string]string) *Item {
+		key := labelKey(labels)
+		it, ok := items[key]
+		if !ok {
+			it = &Item{Name: labels["alertname"], Severity: labels["severity"], Labels: labels}
+			items[key] = it
+		}
+		if it.Summary == "" {
+			it.Summary = annotations["summary"]
+			if it.Summary == "" {
+				it.Summary = annotations["description"]
+			}
+		}
+		return it
+	}
+
+	promAlerts, err := prometheus.Alerts(ctx, cfg.PrometheusURL)
+	if err != nil {
+		snap.PrometheusErr = err.Error()
+	}
+	for _, a := range promAlerts {
+		it := get(a.Labels, a.Annotations)
+		it.PromState = a.State
+		it.Since = a.ActiveAt
+	}
+
+	if cfg.AlertmanagerURL != "" {
+		am := alertmanager.NewClient(cfg.AlertmanagerURL)
+		amAlerts, err := am.Alerts(ctx)
+		if err != nil {
+			snap.AlertmanagerErr = err.Error()
+		}
+		for _, a := range amAlerts {
+			it := get(a.Labels, a.Annotations)
+			it.AMState = a.Status.State
+			it.SilencedBy = a.Status.SilencedBy
+			if it.Since.IsZero() {
+				it.Since = a.StartsAt
+			}
+		}
+		silences, err := am.Silences(ctx)
+		if err != nil && snap.AlertmanagerErr == "" {
+			snap.AlertmanagerErr = err.Error()
+		}
+		for _, s := range silences {
+			if s.State() != "expired" {
+				snap.Silences = append(snap.Silences, s)
+			}
+		}
+		sort.Slice(snap.Silences, func(i, j int) bool { return snap.Silences[i].EndsAt.Before(snap.Silences[j].EndsAt) })
+	} else {
+		snap.AlertmanagerErr = "ALERTMANAGER_URL vacio"
+	}
+
+	bySeverity := map[string][]Item{}
+	for _, it := range items {
+		bySeverity[it.Severity] = append(bySeverity[it.Severity], *it)
+	}
+	for sev, list := range bySeverity {
+		sort.Slice(list, func(i, j int) bool {
+			if list[i].Name != list[j].Name {
+				return list[i].Name < list[j].Name
+			}
+			return list[i].LabelString() < list[j].LabelString()
+		})
+		snap.Groups = append(snap.Groups, Group{Severity: sev, Items: list})
+	}
+	sort.Slice(snap.Groups, func(i, j int) bool {
+		return severityLess(snap.Groups[i].Severity, snap.Groups[j].Severity)
+	})
+	return snap
+}
+
+func severityLess(a, b string) bool {
+	ra, oka := severityOrder[a]
+	rb, okb := severityOrder[b]
+	switch {
+	case oka && okb:
+		return ra < rb
+	case oka != okb:
+		return oka
+	case (a == "") != (b == ""):
+		return b == ""
+	}
+	return a < b
+}
+
+func labelKey(labels map[string]string) string {
+	return Item{Labels: labels}.LabelString() + labels["alertname"]
+}
+
+// SilenceFor arma un silencio que matchea exactamente los labels de la alerta.
+func SilenceFor(it Item, d time.Duration, now time.Time) alertmanager.Silence {
+	keys := make([]string, 0, len(it.Labels))
+	for k := range it.Labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	matchers := make([]alertmanager.Matcher, 0, len(keys))
+	for _, k := range keys {
+		matchers = append(matchers, alertmanager.Matcher{Name: k, Value: it.Labels[k], IsEqual: true})
+	}
+	return alertmanager.Silence{
+		Matchers:  matchers,
+		StartsAt:  now,
+		EndsAt:    now.Add(d),
+		CreatedBy: SilenceCreator,
+		Comment:   fmt.Sprintf("silenciado desde drone-observe alerts (%s)", it.Name),
+	}
+}
+
+// CreateSilence crea el silencio de una alerta en Alertmanager.
+func CreateSilence(cfg config.Config, it Item, d time.Duration) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second)
+	defer cancel()
+	return alertmanager.NewClient(cfg.AlertmanagerURL).CreateSilence(ctx, SilenceFor(it, d, time.Now()))
+}
+
+// ExpireSilence expira un silencio por id.
+func ExpireSilence(cfg config.Config, id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second)
+	defer cancel()
+	return alertmanager.NewClient(cfg.AlertmanagerURL).ExpireSilence(ctx, id)
+}
@@ -0,0 +1,254 @@
+// Archivo: tools/drone-observe/internal/audit/consistency.go
+// Rol: consistencia estatica entre prometheus.yml, docker-compose.yml, defaults de config y topologia.
+// No hace: resolver DNS ni abrir conexiones; solo cruza artefactos versionados.
+package audit
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"sort"
+	"strconv"
+
+	"drone-observe/internal/compose"
+	"drone-observe/internal/config"
+	"drone-observe/internal/prometheus"
+	"drone-observe/internal/repo"
+	"drone-observe/internal/topology"
+)
+
+// hostGateway es el alias con el que un contenedor alcanza el host (requiere extra_hosts en Linux).
+const hostGateway = "host.docker.internal"
+
+// configEndpoint es un endpoint que el CLI alcanza segun config.FromEnv y el servicio de compose que lo sirve.
+type configEndpoint struct {
+	name    string
+	service string
+	addr    string
+}
+
+// PARTE CRITICA **********************
+// Cada direccion se evalua desde donde se usa: los targets de prometheus.yml desde el contenedor
+// Prometheus (nombre de servicio + puerto del contenedor, o host.docker.internal + extra_hosts) y los
+// endpoints del CLI desde el host (puerto publicado). Mezclar ambos lados oculta justamente el error
+// tipico: un puerto que existe en la red de compose pero no esta publicado, o al reves.
+// FIN DE PARTE CRITICA ****************
+func checkConsistency(cfg config.Config) []Finding {
+	cf, err := compose.Load(cfg.ComposeFile)
+	if err != nil {
+		return []Finding{{Severity: SeverityMed, Item: "docker-compose.yml", Detail: err.Error()}}
+	}
+	path, err := repo.Resolve(cfg.PrometheusConfig)
+	if err != nil {
+		return []Finding{{Severity: SeverityHigh, Item: "prometheus.yml", Detail: err.Error()}}
+	}
+	pc, err := prometheus.LoadConfig(path)
+	if err != nil {
+		return []Finding{{Severity: SeverityHigh, Item: "prometheus.yml", Detail: err.Error()}}
+	}
+
+	findings := []Finding{}
+	promSvc, _, mounted := cf.MountOf(cfg.PrometheusConfig)
+	if !mounted {
+		// El montaje faltante ya lo reporta checkProvisioning; se asume el servicio por nombre.
+		promSvc = cf.Services["prometheus"]
+	}
+	for _, sc := range pc.ScrapeConfigs {
+		for _, addr := range sc.Addresses() {
+			findings = append(findings, checkTarget(cf, promSvc, "job "+sc.JobName, addr)...)
+		}
+	}
+	for _, am := range pc.Alerting.Alertmanagers {
+		for _, st := range am.StaticConfigs {
+			for _, addr := range st.Targets {
+				findings = append(findings, checkTarget(cf, promSvc, "alerting", addr)...)
+			}
+		}
+	}
+	findings = append(findings, checkJobComponents(pc)...)
+	findings = append(findings, checkEndpoints(cfg, cf)...)
+	findings = append(findings, checkDefaults(cf)...)
+	return findings
+}
+
+// checkTarget valida un host:port tal como lo ve el contenedor de Prometheus.
+func checkTarget(cf compose.File, promSvc compose.Service, where, addr string) []Finding {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return []Finding{{Severity: SeverityHigh, Item: "Target invalido en prometheus.yml", Detail: fmt.Sprintf("%s: %s (%v)", where, addr, err)}}
+	}
+	switch {
+	case host == hostGateway:
+		if promSvc.Name != "" && !promSvc.HasExtraHost(hostGateway) {
+			return []Finding{{
+				Severity: SeverityHigh,
+				Item:     "Target en el host sin extra_hosts",
+				Detail:   fmt.Sprintf("%s: %s (%s no declara %s:host-gateway)", where, addr, promSvc.Name, hostGateway),
+			}}
+		}
+		if svc, ok := cf.PublishedBy(port); ok {
+			return []Finding{{
+				Severity: SeverityLow,
+				Item:     "Target via host a un servicio de compose",
+				Detail:   fmt.Sprintf("%s: %s es el puerto publicado de %s (usar %s directo)", where, addr, svc.Name, svc.Name),
+			}}
+		}
+		return []Finding{{
+			Severity: SeverityLow,
+			Item:     "Target fuera de compose",
+			Detail:   fmt.Sprintf("%s: %s corre en el host; compose no lo levanta", where, addr),
+		}}
+	case host == "localhost" || host == "127.0.0.1":
+		if promSvc.Name != "" && containsPort(promSvc.ContainerPorts(), port) {
+			return nil
+		}
+		return []Finding{{
+			Severity: SeverityHigh,
+			Item:     "Target localhost dentro del contenedor Prometheus",
+			Detail:   fmt.Sprintf("%s: %s apunta al propio contenedor (usar nombre de servicio o %s)", where, addr, hostGateway),
+		}}
+	case net.ParseIP(host) != nil:
+		return nil
+	}
+
+	svc, ok := cf.Lookup(host)
+	if !ok {
+		return []Finding{{
+			Severity: SeverityHigh,
+			Item:     "Target apunta a servicio no definido en compose",
+			Detail:   fmt.Sprintf("%s: %s (servicios: %v)", where, addr, cf.Names()),
+		}}
+	}
+	ports := svc.ContainerPorts()
+	if len(ports) > 0 && !containsPort(ports, port) {
+		return []Finding{{
+			Severity: SeverityHigh,
+			Item:     "Target con puerto que el servicio no declara",
+			Detail:   fmt.Sprintf("%s: %s (%s declara %v)", where, addr, svc.Name, ports),
+		}}
+	}
+	return nil
+}
+
+// checkJobComponents cruza los jobs de prometheus.yml con los componentes de topology.
+func checkJobComponents(pc prometheus.Config) []Finding {
+	findings := []Finding{}
+	jobs := map[string]struct{}{}
+	for _, sc := range pc.ScrapeConfigs {
+		jobs[sc.JobName] = struct{}{}
+		if topology.ComponentForJob(sc.JobName) == "" && !topology.IsObserverJob(sc.JobName) {
+			findings = append(findings, Finding{
+				Severity: SeverityMed,
+				Item:     "Job sin componente en topologia",
+				Detail:   fmt.Sprintf("%s (un target down no se refleja en topology)", sc.JobName),
+			})
+		}
+	}
+	for _, job := range topology.Jobs() {
+		if _, ok := jobs[job]; !ok {
+			findings = append(findings, Finding{
+				Severity: SeverityHigh,
+				Item:     "Componente sin job en prometheus.yml",
+				Detail:   fmt.Sprintf("%s espera el job %s", topology.ComponentForJob(job), job),
+			})
+		}
+	}
+	return findings
+}
+
+// checkEndpoints valida que cada endpoint local del CLI sea un puerto publicado por el servicio esperado.
+func checkEndpoints(cfg config.Config, cf compose.File) []Finding {
+	endpoints := []configEndpoint{
+		{name: "MQTT_HOST/MQTT_PORT", service: "mqtt", addr: net.JoinHostPort(cfg.MQTTHost, strconv.Itoa(cfg.MQTTPort))},
+	}
+	for _, e := range []struct{ name, service, raw string }{
+		{"BACKEND_HTTP_PORT", "backend", cfg.BackendMetricsURL},
+		{"PROMETHEUS_URL", "prometheus", cfg.PrometheusURL},
+		{"GRAFANA_URL", "grafana", cfg.GrafanaURL},
+		{"ALERTMANAGER_URL", "alertmanager", cfg.AlertmanagerURL},
+	} {
+		u, err := url.Parse(e.raw)
+		if err != nil || u.Host == "" {
+			continue
+		}
+		host, port := u.Hostname(), u.Port()
+		if port == "" {
+			port = map[string]string{"http": "80", "https": "443"}[u.Scheme]
+		}
+		endpoints = append(endpoints, configEndpoint{name: e.name, service: e.service, addr: net.JoinHostPort(host, port)})
+	}
+
+	findings := []Finding{}
+	for _, e := range endpoints {
+		host, port, _ := net.SplitHostPort(e.addr)
+		if host != "localhost" && host != "127.0.0.1" {
+			// Nombre de servicio: el CLI corre dentro de la red de compose; se valida como un target.
+			if svc, ok := cf.Lookup(host); ok {
+				if ports := svc.ContainerPorts(); len(ports) > 0 && !containsPort(ports, port) {
+					findings = append(findings, Finding{
+						Severity: SeverityHigh,
+						Item:     "Puerto de config que el servicio no declara",
+						Detail:   fmt.Sprintf("%s=%s (%s declara %v)", e.name, e.addr, svc.Name, ports),
+					})
+				}
+			}
+			continue
+		}
+		if _, defined := cf.Services[e.service]; !defined {
+			findings = append(findings, Finding{
+				Severity: SeverityMed,
+				Item:     "Servicio de config no definido en compose",
+				Detail:   fmt.Sprintf("%s=%s espera el servicio %s", e.name, e.addr, e.service),
+			})
+			continue
+		}
+		svc, published := cf.PublishedBy(port)
+		switch {
+		case !published:
+			findings = append(findings, Finding{
+				Severity: SeverityHigh,
+				Item:     "Puerto no publicado en compose",
+				Detail:   fmt.Sprintf("%s=%s: %s no publica el puerto %s en el host", e.name, e.addr, e.service, port),
+			})
+		case svc.Name != e.service:
+			findings = append(findings, Finding{
+				Severity: SeverityHigh,
+				Item:     "Puerto de config publicado por otro servicio",
+				Detail:   fmt.Sprintf("%s=%s: el puerto %s lo publica %s, no %s", e.name, e.addr, port, svc.Name, e.service),
+			})
+		}
+	}
+	return findings
+}
+
+// checkDefaults compara ${VAR:-default} de compose con el default de la misma variable en config.
+func checkDefaults(cf compose.File) []Finding {
+	defaults := config.Defaults()
+	names := make([]string, 0, len(cf.Defaults))
+	for name := range cf.Defaults {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	findings := []Finding{}
+	for _, name := range names {
+		want, ok := defaults[name]
+		if !ok || cf.Defaults[name] == want {
+			continue
+		}
+		findings = append(findings, Finding{
+			Severity: SeverityMed,
+			Item:     "Default distinto entre compose y config",
+			Detail:   fmt.Sprintf("%s: compose %q, drone-observe %q", name, cf.Defaults[name], want),
+		})
+	}
+	return findings
+}
+
+func containsPort(ports []string, port string) bool {
+	for _, p := range ports {
+		if p == port {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,227 @@
+// Archivo: tools/drone-observe/internal/audit/dashboards.go
+// Rol: cruzar las queries PromQL de los dashboards versionados contra el contrato METRICS.md.
+// No hace: consultas a Grafana ni a Prometheus; solo lee artefactos del repo.
+package audit
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"drone-observe/internal/config"
+	"drone-observe/internal/contract"
+	"drone-observe/internal/grafana"
+	"drone-observe/internal/promql"
+	"drone-observe/internal/repo"
+)
+
+// builtinMetrics son series que genera Prometheus y no forman parte del contrato.
+var builtinMetrics = map[string]struct{}{"up": {}, "scrape_samples_scraped": {}}
+
+// minimalDashboard es el dashboard que implementa la seccion "Dashboards minimos" de METRICS.md.
+const minimalDashboard = "drones-data-plane.json"
+
+// checkDashboards carga los dashboards una vez y corre los cruces contra el contrato.
+func checkDashboards(cfg config.Config, catalog contract.Catalog) []Finding {
+	dir, err := repo.Resolve(cfg.DashboardsDir)
+	if err != nil {
+		return []Finding{{Severity: SeverityMed, Item: "Dashboards Grafana", Detail: err.Error()}}
+	}
+	dashboards, err := grafana.LoadDir(dir)
+	if err != nil {
+		return []Finding{{Severity: SeverityHigh, Item: "Dashboards Grafana", Detail: err.Error()}}
+	}
+	findings := checkDashboardQueries(dashboards, catalog)
+	findings = append(findings, checkDashboardDocs(cfg, dashboards, catalog.CurrentNames())...)
+	findings = append(findings, checkMinimalDashboard(dashboards, catalog)...)
+	findings = append(findings, checkGrafanaLive(cfg, dashboards)...)
+	return append(findings, checkProvisioning(cfg, dashboards)...)
+}
+
+// PARTE CRITICA **********************
+// Cada expr de panel se parsea como PromQL; los nombres de metrica salen del AST, no de regex.
+// Fuera del contrato = alta (el panel queda vacio), FUTURO = media (vacio hasta implementarse),
+// metrica del contrato sin panel = baja (deuda de visualizacion).
+// No degradar PromQL invalido: Grafana lo muestra como error en el panel.
+// FIN DE PARTE CRITICA ****************
+func checkDashboardQueries(dashboards []grafana.Dashboard, catalog contract.Catalog) []Finding {
+	findings := []Finding{}
+	visualized := map[string]struct{}{}
+	for _, d := range dashboards {
+		for _, q := range d.Queries() {
+			expr, err := promql.Parse(grafana.ExpandIntervals(q.Expr))
+			if err != nil {
+				findings = append(findings, Finding{
+					Severity: SeverityHigh,
+					Item:     "PromQL invalido en panel",
+					Detail:   fmt.Sprintf("%s: %v", q.Location(), err),
+				})
+				continue
+			}
+			for _, name := range promql.MetricNames(expr) {
+				visualized[name] = struct{}{}
+				if _, ok := builtinMetrics[name]; ok {
+					continue
+				}
+				m, ok := catalog.Lookup(name)
+				switch {
+				case !ok:
+					findings = append(findings, Finding{
+						Severity: SeverityHigh,
+						Item:     "Panel consulta metrica fuera del contrato",
+						Detail:   fmt.Sprintf("%s -> %s", q.Location(), name),
+					})
+				case m.Future:
+					findings = append(findings, Finding{
+						Severity: SeverityMed,
+						Item:     "Panel consulta metrica FUTURO",
+						Detail:   fmt.Sprintf("%s -> %s", q.Location(), name),
+					})
+				}
+			}
+		}
+	}
+
+	for _, m := range catalog.Current {
+		if _, ok := visualized[m.Name]; !ok {
+			findings = append(findings, Finding{
+				Severity: SeverityLow,
+				Item:     "Metrica del contrato sin panel",
+				Detail:   m.Name,
+			})
+		}
+	}
+	return findings
+}
+
+type parsedQuery struct {
+	query   grafana.Query
+	expr    promql.Expr
+	matched bool
+}
+
+// PARTE CRITICA **********************
+// La comparacion es por AST canonico: espacios, orden de matchers o 60s vs 1m no generan drift,
+// pero otra funcion, otro rango u otro selector si. Un panel con la misma query que un item
+// documentado (p. ej. stat + timeseries) cuenta como implementacion, no como extra.
+// Items FUTURO ausentes no se reportan: aun no hay metrica que graficar.
+// FIN DE PARTE CRITICA ****************
+func checkMinimalDashboard(dashboards []grafana.Dashboard, catalog contract.Catalog) []Finding {
+	var dash *grafana.Dashboard
+	for i := range dashboards {
+		if dashboards[i].File == minimalDashboard {
+			dash = &dashboards[i]
+		}
+	}
+	if dash == nil {
+		return []Finding{{Severity: SeverityHigh, Item: "Dashboard minimo ausente", Detail: minimalDashboard}}
+	}
+
+	findings := []Finding{}
+	var panels []*parsedQuery
+	for _, q := range dash.Queries() {
+		expr, err := promql.Parse(grafana.ExpandIntervals(q.Expr))
+		if err != nil {
+			// Ya reportado por checkDashboardQueries.
+			continue
+		}
+		panels = append(panels, &parsedQuery{query: q, expr: expr})
+	}
+
+	type spec struct {
+		contract.PanelSpec
+		expr promql.Expr
+	}
+	var pending []spec
+	for _, ps := range catalog.Panels {
+		expr, err := promql.Parse(ps.Expr)
+		if err != nil {
+			findings = append(findings, Finding{
+				Severity: SeverityHigh,
+				Item:     "PromQL invalido en METRICS.md",
+				Detail:   fmt.Sprintf("%s: %v", ps.Title, err),
+			})
+			continue
+		}
+		found := false
+		for _, p := range panels {
+			if promql.Equal(expr, p.expr) {
+				p.matched = true
+				found = true
+			}
+		}
+		if !found && !ps.Future {
+			pending = append(pending, spec{ps, expr})
+		}
+	}
+
+	// Candidatos: panel libre que consulta las mismas metricas. Se asignan primero los pares
+	// mas parecidos (mismo rango/funcion pesa menos que otra funcion) para no cruzar items.
+	type pair struct {
+		spec  int
+		panel *parsedQuery
+		diff  []string
+		score int
+	}
+	var pairs []pair
+	for i, sp := range pending {
+		want := strings.Join(promql.MetricNames(sp.expr), ",")
+		for _, p := range panels {
+			if p.matched || strings.Join(promql.MetricNames(p.expr), ",") != want {
+				continue
+			}
+			d := promql.Diff(sp.expr, p.expr)
+			pairs = append(pairs, pair{spec: i, panel: p, diff: d, score: diffScore(d)})
+		}
+	}
+	sort.SliceStable(pairs, func(i, j int) bool { return pairs[i].score < pairs[j].score })
+	assigned := map[int]bool{}
+	for _, pr := range pairs {
+		if assigned[pr.spec] || pr.panel.matched {
+			continue
+		}
+		assigned[pr.spec] = true
+		pr.panel.matched = true
+		sp := pending[pr.spec]
+		findings = append(findings, Finding{
+			Severity: SeverityHigh,
+			Item:     "Panel difiere de METRICS.md",
+			Detail: fmt.Sprintf("%s: doc %s, panel %s (%s)",
+				pr.panel.query.Location(), sp.expr, pr.panel.expr, strings.Join(pr.diff, "; ")),
+		})
+	}
+	for i, sp := range pending {
+		if !assigned[i] {
+			findings = append(findings, Finding{
+				Severity: SeverityHigh,
+				Item:     "Panel minimo faltante",
+				Detail:   fmt.Sprintf("%s -> %s", sp.Title, sp.expr),
+			})
+		}
+	}
+
+	for _, p := range panels {
+		if !p.matched {
+			findings = append(findings, Finding{
+				Severity: SeverityMed,
+				Item:     "Panel fuera de Dashboards minimos",
+				Detail:   fmt.Sprintf("%s -> %s", p.query.Location(), p.expr),
+			})
+		}
+	}
+	return findings
+}
+
+// diffScore pondera diferencias: cambiar funcion, agregacion o forma pesa el doble que rango o matchers.
+func diffScore(diff []string) int {
+	score := 0
+	for _, d := range diff {
+		switch {
+		case strings.HasPrefix(d, "rango "), strings.HasPrefix(d, "matchers "), strings.HasPrefix(d, "offset "):
+			score++
+		default:
+			score += 2
+		}
+	}
+	return score
+}
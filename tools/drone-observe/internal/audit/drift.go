@@ -15,7 +15,10 @@ import (
 	"time"
 
 	"drone-observe/internal/config"
-	"drone-observe/internal/prometheus"
+	"drone-observe/internal/contract"
+	"drone-observe/internal/grafana"
+	"drone-observe/internal/repo"
+	"drone-observe/internal/source"
 )
 
 type Severity string
@@ -41,25 +44,40 @@ func Drift(cfg config.Config) ([]Finding, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 6*time.Second)
 	defer cancel()
 
-	contract, err := readMetricsContract(cfg.MetricsDocPath)
+	catalog, err := contract.Load(cfg.MetricsDocPath)
 	if err != nil {
 		return []Finding{{Severity: SeverityHigh, Item: "METRICS.md", Detail: err.Error()}}, nil
 	}
+	contract := catalog.CurrentNames()
 
 	findings := []Finding{}
+	src := source.For(cfg)
 
 	for _, name := range contract {
-		_, ok, err := prometheus.QueryInstant(ctx, cfg.PrometheusURL, name)
-		if err != nil || !ok {
+		vec, err := src.Prometheus.Query(ctx, name, cfg.EvalTime())
+		if err != nil || len(vec) == 0 {
 			findings = append(findings, Finding{
 				Severity: SeverityHigh,
 				Item:     "Metrica documentada ausente",
 				Detail:   name,
 			})
+			continue
+		}
+		// El contrato actual no define labels; varias series indican un productor no documentado.
+		if len(vec) > 1 {
+			labels := make([]string, 0, len(vec))
+			for _, s := range vec {
+				labels = append(labels, s.LabelString())
+			}
+			findings = append(findings, Finding{
+				Severity: SeverityLow,
+				Item:     "Metrica con multiples series",
+				Detail:   fmt.Sprintf("%s -> %s", name, strings.Join(labels, " ")),
+			})
 		}
 	}
 
-	actual, err := readBackendMetrics(cfg.BackendMetricsURL)
+	actual, err := readBackendMetrics(src.Backend)
 	if err != nil {
 		findings = append(findings, Finding{
 			Severity: SeverityHigh,
@@ -77,13 +95,9 @@ func Drift(cfg config.Config) ([]Finding, error) {
 		}
 	}
 
-	dashFindings := checkDashboardDocs()
-	findings = append(findings, dashFindings...)
-
-	docFindings, err := checkDocsMetrics(contract)
-	if err == nil {
-		findings = append(findings, docFindings...)
-	}
+	findings = append(findings, checkDashboards(cfg, catalog)...)
+	findings = append(findings, checkRules(cfg, catalog)...)
+	findings = append(findings, checkConsistency(cfg)...)
 
 	order := map[Severity]int{SeverityHigh: 0, SeverityMed: 1, SeverityLow: 2}
 	sort.Slice(findings, func(i, j int) bool {
@@ -92,54 +106,84 @@ func Drift(cfg config.Config) ([]Finding, error) {
 	return findings, nil
 }
 
-func checkDashboardDocs() []Finding {
-	findings := []Finding{}
-	dashDir := filepath.Join("observability", "grafana", "dashboards")
-	files, _ := filepath.Glob(filepath.Join(dashDir, "*.json"))
-
-	for _, f := range files {
-		base := filepath.Base(f)
-		doc := ""
-		switch base {
-		case "drones-control-plane.json":
-			doc = filepath.Join("docs", "09-dashboard-control-plane.md")
-		case "drones-data-plane.json":
-			doc = filepath.Join("docs", "10-dashboard-data-plane.md")
-		default:
-			doc = ""
-		}
+// PARTE CRITICA **********************
+// El mapeo dashboard -> doc sale de los docs: cada doc de dashboard declara su
+// "JSON fuente: `<ruta>.json`". Un dashboard nuevo sin doc se reporta sin tocar este codigo.
+// No volver a una tabla fija de nombres: deja de ver dashboards agregados despues.
+// FIN DE PARTE CRITICA ****************
+func checkDashboardDocs(cfg config.Config, dashboards []grafana.Dashboard, contract []string) []Finding {
+	dir, err := repo.Resolve(cfg.DocsDir)
+	if err != nil {
+		return []Finding{{Severity: SeverityMed, Item: "Docs de dashboards", Detail: err.Error()}}
+	}
+	docs, err := dashboardDocs(dir)
+	if err != nil {
+		return []Finding{{Severity: SeverityMed, Item: "Docs de dashboards", Detail: err.Error()}}
+	}
 
-		if doc == "" {
+	findings := []Finding{}
+	loaded := map[string]struct{}{}
+	for _, d := range dashboards {
+		loaded[d.File] = struct{}{}
+		if _, ok := docs[d.File]; !ok {
 			findings = append(findings, Finding{
 				Severity: SeverityMed,
 				Item:     "Dashboard sin doc",
-				Detail:   base,
+				Detail:   d.File,
 			})
-			continue
 		}
-		if _, err := os.Stat(doc); err != nil {
+	}
+
+	paths := map[string]struct{}{}
+	for file, doc := range docs {
+		paths[doc] = struct{}{}
+		if _, ok := loaded[file]; !ok {
 			findings = append(findings, Finding{
 				Severity: SeverityHigh,
-				Item:     "Doc faltante para dashboard",
-				Detail:   base,
+				Item:     "Doc apunta a dashboard inexistente",
+				Detail:   fmt.Sprintf("%s -> %s", filepath.Base(doc), file),
 			})
 		}
 	}
+	sorted := make([]string, 0, len(paths))
+	for doc := range paths {
+		sorted = append(sorted, doc)
+	}
+	sort.Strings(sorted)
+	return append(findings, checkDocsMetrics(sorted, contract)...)
+}
 
-	return findings
+// jsonSourceRe captura el archivo declarado en "JSON fuente: `.../<archivo>.json`".
+var jsonSourceRe = regexp.MustCompile("JSON fuente:\\s*`([^`]+\\.json)`")
+
+// dashboardDocs devuelve archivo de dashboard -> ruta del doc que lo declara como fuente.
+func dashboardDocs(dir string) (map[string]string, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.md"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	docs := map[string]string{}
+	for _, f := range files {
+		content, err := os.ReadFile(f)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range jsonSourceRe.FindAllStringSubmatch(string(content), -1) {
+			if _, ok := docs[filepath.Base(m[1])]; !ok {
+				docs[filepath.Base(m[1])] = f
+			}
+		}
+	}
+	return docs, nil
 }
 
-func checkDocsMetrics(contract []string) ([]Finding, error) {
+func checkDocsMetrics(docFiles, contract []string) []Finding {
 	allowed := map[string]struct{}{"up": {}}
 	for _, c := range contract {
 		allowed[c] = struct{}{}
 	}
 
-	docFiles := []string{
-		filepath.Join("docs", "09-dashboard-control-plane.md"),
-		filepath.Join("docs", "10-dashboard-data-plane.md"),
-	}
-
 	findings := []Finding{}
 	for _, doc := range docFiles {
 		content, err := os.ReadFile(doc)
@@ -157,7 +201,7 @@ func checkDocsMetrics(contract []string) ([]Finding, error) {
 			}
 		}
 	}
-	return findings, nil
+	return findings
 }
 
 func extractMetricTokens(s string) []string {
@@ -179,54 +223,10 @@ func extractMetricTokens(s string) []string {
 	return out
 }
 
-func readMetricsContract(path string) ([]string, error) {
-	f, _, err := openMetricsFile(path)
-	if err != nil {
-		return nil, err
-	}
-	defer f.Close()
-
-	var metrics []string
-	scanner := bufio.NewScanner(f)
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if !strings.HasPrefix(line, "|") {
-			continue
-		}
-		cols := strings.Split(line, "|")
-		if len(cols) < 2 {
-			continue
-		}
-		name := strings.TrimSpace(cols[1])
-		if name == "" || name == "nombre" || strings.HasPrefix(name, "---") {
-			continue
-		}
-		metrics = append(metrics, name)
-	}
-	if err := scanner.Err(); err != nil {
-		return nil, err
-	}
-	return metrics, nil
-}
-
-func openMetricsFile(path string) (*os.File, string, error) {
-	candidates := []string{
-		path,
-		filepath.Join("..", path),
-		filepath.Join("..", "..", path),
-	}
-	for _, p := range candidates {
-		if f, err := os.Open(p); err == nil {
-			return f, p, nil
-		}
-	}
-	return nil, "", fmt.Errorf("no se encontro %s en rutas conocidas", path)
-}
-
-func readBackendMetrics(url string) ([]string, error) {
+func readBackendMetrics(backend source.Backend) ([]string, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
-	body, err := fetchText(ctx, url)
+	body, err := backend.Metrics(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -270,11 +270,3 @@ func diffUnexpected(contract, actual []string) []string {
 	sort.Strings(extra)
 	return extra
 }
-
-func fetchText(ctx context.Context, url string) (string, error) {
-	req, err := httpRequest(ctx, url)
-	if err != nil {
-		return "", err
-	}
-	return req, nil
-}
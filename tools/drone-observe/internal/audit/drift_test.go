@@ -0,0 +1,83 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"drone-observe/internal/config"
+	"drone-observe/internal/grafana"
+)
+
+func TestCheckDashboardDocs(t *testing.T) {
+	tests := []struct {
+		name       string
+		docs       map[string]string
+		dashboards []string
+		want       []string
+	}{
+		{
+			name: "cada dashboard con su doc",
+			docs: map[string]string{
+				"09-dashboard-control-plane.md": "- JSON fuente: `observability/grafana/dashboards/drones-control-plane.json`.\n",
+				"10-dashboard-data-plane.md":    "- JSON fuente: `observability/grafana/dashboards/drones-data-plane.json`.\n",
+			},
+			dashboards: []string{"drones-control-plane.json", "drones-data-plane.json"},
+		},
+		{
+			name: "dashboard nuevo sin doc",
+			docs: map[string]string{
+				"09-dashboard-control-plane.md": "- JSON fuente: `observability/grafana/dashboards/drones-control-plane.json`.\n",
+				// Mencionar el archivo no alcanza: el doc debe declararlo como fuente.
+				"11-cli.md": "Se compara con `drones-ml.json`.\n",
+			},
+			dashboards: []string{"drones-control-plane.json", "drones-ml.json"},
+			want:       []string{"Dashboard sin doc: drones-ml.json"},
+		},
+		{
+			name: "doc de un dashboard borrado",
+			docs: map[string]string{
+				"09-dashboard-control-plane.md": "- JSON fuente: `observability/grafana/dashboards/drones-control-plane.json`.\n",
+				"10-dashboard-data-plane.md":    "- JSON fuente: `observability/grafana/dashboards/drones-data-plane.json`.\n",
+			},
+			dashboards: []string{"drones-control-plane.json"},
+			want:       []string{"Doc apunta a dashboard inexistente: 10-dashboard-data-plane.md -> drones-data-plane.json"},
+		},
+		{
+			name: "metrica fuera del contrato en el doc",
+			docs: map[string]string{
+				"10-dashboard-data-plane.md": "- JSON fuente: `drones-data-plane.json`.\nPanel: `rate(mqtt_messages_total[1m])` y `drone_speed_mps`.\n",
+			},
+			dashboards: []string{"drones-data-plane.json"},
+			want:       []string{"Doc refiere metrica no documentada: 10-dashboard-data-plane.md -> drone_speed_mps"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.docs {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			var dashboards []grafana.Dashboard
+			for _, f := range tt.dashboards {
+				dashboards = append(dashboards, grafana.Dashboard{File: f})
+			}
+			var got []string
+			for _, f := range checkDashboardDocs(config.Config{DocsDir: dir}, dashboards, []string{"mqtt_messages_total"}) {
+				got = append(got, f.Item+": "+f.Detail)
+			}
+			sort.Strings(got)
+			if len(got) != len(tt.want) {
+				t.Fatalf("findings = %q; esperado %q", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("finding = %q; esperado %q", got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
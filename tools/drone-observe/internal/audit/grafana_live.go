@@ -0,0 +1,109 @@
+// Archivo: tools/drone-observe/internal/audit/grafana_live.go
+// Rol: comparar dashboards servidos por Grafana (API) con los JSON versionados.
+// No hace: sincronizacion ni restauracion de dashboards.
+package audit
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"drone-observe/internal/config"
+	"drone-observe/internal/grafana"
+	"drone-observe/internal/source"
+)
+
+// maxChangesPerDashboard acota los findings por dashboard; el resto se resume en uno.
+const maxChangesPerDashboard = 8
+
+// PARTE CRITICA **********************
+// El repo es la fuente de verdad (GitOps): todo dashboard de Grafana debe existir en el repo y viceversa.
+// Edicion en UI = alta, versionado no cargado = alta, solo en Grafana = media (copia manual sin versionar).
+// No comparar por titulo: el uid es la identidad estable del dashboard.
+// FIN DE PARTE CRITICA ****************
+func checkGrafanaLive(cfg config.Config, dashboards []grafana.Dashboard) []Finding {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	client := source.For(cfg).Grafana
+	hits, err := client.Search(ctx)
+	if err != nil {
+		return []Finding{{Severity: SeverityHigh, Item: "Grafana API", Detail: err.Error()}}
+	}
+
+	versioned := map[string]grafana.Dashboard{}
+	for _, d := range dashboards {
+		versioned[d.UID] = d
+	}
+	live := map[string]grafana.SearchHit{}
+	for _, h := range hits {
+		live[h.UID] = h
+	}
+
+	findings := []Finding{}
+	uids := make([]string, 0, len(versioned))
+	for uid := range versioned {
+		uids = append(uids, uid)
+	}
+	sort.Strings(uids)
+	for _, uid := range uids {
+		d := versioned[uid]
+		if _, ok := live[uid]; !ok {
+			findings = append(findings, Finding{
+				Severity: SeverityHigh,
+				Item:     "Dashboard versionado no cargado en Grafana",
+				Detail:   fmt.Sprintf("%s (uid=%s)", d.File, uid),
+			})
+			continue
+		}
+		model, err := client.Dashboard(ctx, uid)
+		if err != nil {
+			findings = append(findings, Finding{
+				Severity: SeverityHigh,
+				Item:     "Grafana API",
+				Detail:   fmt.Sprintf("uid=%s: %v", uid, err),
+			})
+			continue
+		}
+		changes := grafana.Diff(d.Raw, model)
+		for i, c := range changes {
+			if i == maxChangesPerDashboard {
+				findings = append(findings, Finding{
+					Severity: SeverityHigh,
+					Item:     "Dashboard difiere de Grafana",
+					Detail:   fmt.Sprintf("%s: y %d diferencias mas", d.File, len(changes)-i),
+				})
+				break
+			}
+			findings = append(findings, Finding{
+				Severity: SeverityHigh,
+				Item:     "Dashboard difiere de Grafana",
+				Detail:   fmt.Sprintf("%s: %s", d.File, c),
+			})
+		}
+	}
+
+	var extra []grafana.SearchHit
+	for uid, h := range live {
+		if _, ok := versioned[uid]; !ok {
+			extra = append(extra, h)
+		}
+	}
+	sort.Slice(extra, func(i, j int) bool { return extra[i].UID < extra[j].UID })
+	for _, h := range extra {
+		findings = append(findings, Finding{
+			Severity: SeverityMed,
+			Item:     "Dashboard solo en Grafana",
+			Detail:   fmt.Sprintf("%s (uid=%s, carpeta=%s)", h.Title, h.UID, folderName(h.FolderTitle)),
+		})
+	}
+	return findings
+}
+
+func folderName(title string) string {
+	if title == "" {
+		return "General"
+	}
+	return title
+}
@@ -0,0 +1,310 @@
+// Archivo: tools/drone-observe/internal/audit/provisioning.go
+// Rol: auditar provisioning de Grafana contra compose, prometheus.yml, dashboards y la API.
+// No hace: corregir YAML ni reiniciar Grafana.
+package audit
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"drone-observe/internal/compose"
+	"drone-observe/internal/config"
+	"drone-observe/internal/grafana"
+	"drone-observe/internal/repo"
+)
+
+const (
+	defaultPrometheusConfigPath = "/etc/prometheus/prometheus.yml"
+	defaultPrometheusPort       = "9090"
+	defaultGrafanaProvisioning  = "/etc/grafana/provisioning"
+)
+
+// PARTE CRITICA **********************
+// El provisioning se valida contra artefactos versionados (compose, prometheus.yml, dashboards)
+// y luego contra la API: primero "esta bien declarado", despues "funciona".
+// Si se omite la parte estatica, un datasource que funciona por casualidad (p. ej. uid resuelto
+// por nombre) no se detecta hasta que cambia el nombre.
+// FIN DE PARTE CRITICA ****************
+func checkProvisioning(cfg config.Config, dashboards []grafana.Dashboard) []Finding {
+	dir, err := repo.Resolve(cfg.ProvisioningDir)
+	if err != nil {
+		return []Finding{{Severity: SeverityHigh, Item: "Provisioning Grafana", Detail: err.Error()}}
+	}
+	prov, err := grafana.LoadProvisioning(dir)
+	if err != nil {
+		return []Finding{{Severity: SeverityHigh, Item: "Provisioning Grafana", Detail: err.Error()}}
+	}
+
+	findings := []Finding{}
+	cf, err := compose.Load(cfg.ComposeFile)
+	if err != nil {
+		findings = append(findings, Finding{Severity: SeverityMed, Item: "docker-compose.yml", Detail: err.Error()})
+	} else {
+		findings = append(findings, checkDatasourceURLs(cfg, prov, cf)...)
+		findings = append(findings, checkProviderMounts(cfg, prov, cf)...)
+	}
+	findings = append(findings, checkPanelDatasources(prov, dashboards)...)
+	if !cfg.Offline() {
+		// El health de datasources solo existe en vivo; un bundle no lo guarda.
+		findings = append(findings, checkDatasourceHealth(cfg, prov)...)
+	}
+	return findings
+}
+
+func checkDatasourceURLs(cfg config.Config, prov grafana.Provisioning, cf compose.File) []Finding {
+	findings := []Finding{}
+	promSvc, promVol, mounted := cf.MountOf(cfg.PrometheusConfig)
+	if !mounted {
+		findings = append(findings, Finding{
+			Severity: SeverityHigh,
+			Item:     "prometheus.yml no montado en compose",
+			Detail:   cfg.PrometheusConfig,
+		})
+	} else {
+		want := defaultPrometheusConfigPath
+		if v, ok := promSvc.Flag("--config.file"); ok {
+			want = v
+		}
+		if promVol.Target != want {
+			findings = append(findings, Finding{
+				Severity: SeverityHigh,
+				Item:     "prometheus.yml montado en ruta que Prometheus no lee",
+				Detail:   fmt.Sprintf("%s: %s (lee %s)", promSvc.Name, promVol.Target, want),
+			})
+		}
+	}
+
+	found := false
+	for _, ds := range prov.Datasources {
+		if ds.Type != "prometheus" {
+			continue
+		}
+		found = true
+		u, err := url.Parse(ds.URL)
+		if err != nil || u.Hostname() == "" {
+			findings = append(findings, Finding{
+				Severity: SeverityHigh,
+				Item:     "URL de datasource invalida",
+				Detail:   fmt.Sprintf("%s (%s): %q", ds.Name, ds.File, ds.URL),
+			})
+			continue
+		}
+		host, port := u.Hostname(), u.Port()
+		if port == "" {
+			port = "80"
+			if u.Scheme == "https" {
+				port = "443"
+			}
+		}
+		if _, ok := cf.Services[host]; !ok {
+			findings = append(findings, Finding{
+				Severity: SeverityHigh,
+				Item:     "Datasource apunta a servicio inexistente",
+				Detail:   fmt.Sprintf("%s -> %s (servicios: %s)", ds.Name, ds.URL, strings.Join(cf.Names(), ", ")),
+			})
+			continue
+		}
+		if mounted && host != promSvc.Name {
+			findings = append(findings, Finding{
+				Severity: SeverityHigh,
+				Item:     "Datasource no apunta al Prometheus del repo",
+				Detail:   fmt.Sprintf("%s -> %s; %s lo monta el servicio %s", ds.Name, host, cfg.PrometheusConfig, promSvc.Name),
+			})
+			continue
+		}
+		listen := defaultPrometheusPort
+		if v, ok := cf.Services[host].Flag("--web.listen-address"); ok {
+			listen = v[strings.LastIndex(v, ":")+1:]
+		}
+		if port != listen {
+			findings = append(findings, Finding{
+				Severity: SeverityHigh,
+				Item:     "Puerto de datasource distinto al de Prometheus",
+				Detail:   fmt.Sprintf("%s -> %s (Prometheus escucha en %s)", ds.Name, ds.URL, listen),
+			})
+		}
+	}
+	if !found {
+		findings = append(findings, Finding{
+			Severity: SeverityHigh,
+			Item:     "Sin datasource Prometheus provisionado",
+			Detail:   prov.Dir,
+		})
+	}
+	return findings
+}
+
+func checkProviderMounts(cfg config.Config, prov grafana.Provisioning, cf compose.File) []Finding {
+	grafanaSvc, provVol, ok := cf.MountOf(cfg.ProvisioningDir)
+	if !ok {
+		return []Finding{{Severity: SeverityHigh, Item: "Provisioning no montado en compose", Detail: cfg.ProvisioningDir}}
+	}
+	findings := []Finding{}
+	want := defaultGrafanaProvisioning
+	if v := grafanaSvc.Environment["GF_PATHS_PROVISIONING"]; v != "" {
+		want = v
+	}
+	if provVol.Target != want {
+		findings = append(findings, Finding{
+			Severity: SeverityHigh,
+			Item:     "Provisioning montado en ruta que Grafana no lee",
+			Detail:   fmt.Sprintf("%s: %s (lee %s)", grafanaSvc.Name, provVol.Target, want),
+		})
+	}
+
+	dashSvc, dashVol, dashMounted := cf.MountOf(cfg.DashboardsDir)
+	for _, p := range prov.Providers {
+		if p.Type != "" && p.Type != "file" {
+			continue
+		}
+		switch {
+		case !dashMounted || dashSvc.Name != grafanaSvc.Name:
+			findings = append(findings, Finding{
+				Severity: SeverityHigh,
+				Item:     "Dashboards versionados no montados en Grafana",
+				Detail:   fmt.Sprintf("provider %s espera %s", p.Name, p.Options.Path),
+			})
+		case strings.TrimRight(p.Options.Path, "/") != strings.TrimRight(dashVol.Target, "/"):
+			findings = append(findings, Finding{
+				Severity: SeverityHigh,
+				Item:     "Provider de dashboards apunta a ruta no montada",
+				Detail:   fmt.Sprintf("provider %s: %s (montado en %s)", p.Name, p.Options.Path, dashVol.Target),
+			})
+		}
+		if p.AllowUIUpdates {
+			findings = append(findings, Finding{
+				Severity: SeverityMed,
+				Item:     "Provider permite editar dashboards en UI",
+				Detail:   fmt.Sprintf("provider %s (%s): allowUiUpdates=true", p.Name, p.File),
+			})
+		}
+	}
+	return findings
+}
+
+// PARTE CRITICA **********************
+// Un uid de panel debe coincidir con el uid provisionado. Grafana intenta resolver por nombre
+// como compatibilidad, pero alerting y APIs no; por eso resolver solo por nombre es media, no OK.
+// No usar el datasource default como fallback de un uid explicito.
+// FIN DE PARTE CRITICA ****************
+func checkPanelDatasources(prov grafana.Provisioning, dashboards []grafana.Dashboard) []Finding {
+	byUID := map[string]grafana.DatasourceDef{}
+	byName := map[string]grafana.DatasourceDef{}
+	for _, ds := range prov.Datasources {
+		if ds.UID != "" {
+			byUID[ds.UID] = ds
+		}
+		byName[ds.Name] = ds
+	}
+	_, hasDefault := prov.Default()
+
+	type key struct{ file, uid string }
+	counts := map[key]int{}
+	for _, d := range dashboards {
+		for _, p := range d.AllPanels() {
+			if len(p.Targets) == 0 {
+				continue
+			}
+			refs := []*grafana.Datasource{p.Datasource}
+			for _, t := range p.Targets {
+				if t.Datasource != nil {
+					refs = append(refs, t.Datasource)
+				}
+			}
+			for _, ref := range refs {
+				uid := ""
+				if ref != nil {
+					uid = ref.UID
+				}
+				counts[key{d.File, uid}]++
+			}
+		}
+	}
+
+	keys := make([]key, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].file != keys[j].file {
+			return keys[i].file < keys[j].file
+		}
+		return keys[i].uid < keys[j].uid
+	})
+
+	findings := []Finding{}
+	for _, k := range keys {
+		n := counts[k]
+		switch {
+		case k.uid == "":
+			if !hasDefault {
+				findings = append(findings, Finding{
+					Severity: SeverityHigh,
+					Item:     "Panel sin datasource y sin default",
+					Detail:   fmt.Sprintf("%s (%d refs)", k.file, n),
+				})
+			}
+		case strings.HasPrefix(k.uid, "$") || strings.HasPrefix(k.uid, "-- "):
+			// Variable de template o datasource especial (-- Mixed --, -- Grafana --).
+		case byUID[k.uid].Name != "":
+			// Resuelve por uid.
+		case byName[k.uid].Name != "":
+			findings = append(findings, Finding{
+				Severity: SeverityMed,
+				Item:     "Datasource de panel resuelve solo por nombre",
+				Detail: fmt.Sprintf("%s: uid=%s (%d refs); fijar uid: %s en %s",
+					k.file, k.uid, n, k.uid, byName[k.uid].File),
+			})
+		default:
+			findings = append(findings, Finding{
+				Severity: SeverityHigh,
+				Item:     "Datasource de panel no provisionado",
+				Detail:   fmt.Sprintf("%s: uid=%s (%d refs)", k.file, k.uid, n),
+			})
+		}
+	}
+	return findings
+}
+
+func checkDatasourceHealth(cfg config.Config, prov grafana.Provisioning) []Finding {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	client := grafana.NewClient(cfg.GrafanaURL, cfg.GrafanaUser, cfg.GrafanaPassword)
+
+	findings := []Finding{}
+	for _, ds := range prov.Datasources {
+		uid := ds.UID
+		if uid == "" {
+			info, err := client.DatasourceByName(ctx, ds.Name)
+			if err != nil {
+				findings = append(findings, Finding{
+					Severity: SeverityHigh,
+					Item:     "Datasource provisionado no visible en Grafana",
+					Detail:   fmt.Sprintf("%s: %v", ds.Name, err),
+				})
+				continue
+			}
+			uid = info.UID
+		}
+		status, msg, err := client.DatasourceHealth(ctx, uid)
+		switch {
+		case err != nil:
+			findings = append(findings, Finding{
+				Severity: SeverityHigh,
+				Item:     "Health de datasource no disponible",
+				Detail:   fmt.Sprintf("%s (uid=%s): %v", ds.Name, uid, err),
+			})
+		case status != "OK":
+			findings = append(findings, Finding{
+				Severity: SeverityHigh,
+				Item:     "Datasource Grafana no saludable",
+				Detail:   fmt.Sprintf("%s (uid=%s): %s %s", ds.Name, uid, status, msg),
+			})
+		}
+	}
+	return findings
+}
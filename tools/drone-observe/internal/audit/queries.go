@@ -0,0 +1,180 @@
+// Archivo: tools/drone-observe/internal/audit/queries.go
+// Rol: analisis estatico de todas las queries PromQL (registro del CLI, dashboards y METRICS.md).
+// No hace: ejecutar queries; un error aca se detecta antes de verse como "sin datos".
+package audit
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"drone-observe/internal/config"
+	"drone-observe/internal/contract"
+	"drone-observe/internal/grafana"
+	"drone-observe/internal/prometheus"
+	"drone-observe/internal/promql"
+	"drone-observe/internal/queries"
+	"drone-observe/internal/repo"
+)
+
+// counterFunctions solo tienen sentido sobre counters (corrigen resets).
+var counterFunctions = map[string]struct{}{"rate": {}, "irate": {}, "increase": {}, "resets": {}}
+
+// windowFunctions necesitan al menos dos muestras en el rango.
+var windowFunctions = map[string]struct{}{"rate": {}, "irate": {}, "increase": {}, "delta": {}, "idelta": {}}
+
+type lintQuery struct {
+	location string
+	expr     string
+	// variables indica que la query usa variables de Grafana ($__rate_interval ya respeta el scrape).
+	variables bool
+	// unbounded indica cardinalidad sin cota aceptada en el registro (queries.Query.Unbounded).
+	unbounded bool
+}
+
+// LintQueries parsea cada query conocida y devuelve la cantidad analizada y los findings.
+func LintQueries(cfg config.Config) (int, []Finding) {
+	findings := []Finding{}
+	catalog, err := contract.Load(cfg.MetricsDocPath)
+	if err != nil {
+		return 0, []Finding{{Severity: SeverityHigh, Item: "METRICS.md", Detail: err.Error()}}
+	}
+
+	scrape := 5 * time.Second
+	if path, err := repo.Resolve(cfg.PrometheusConfig); err != nil {
+		findings = append(findings, Finding{Severity: SeverityLow, Item: "prometheus.yml", Detail: err.Error() + " (se asume scrape_interval 5s)"})
+	} else if pc, err := prometheus.LoadConfig(path); err != nil {
+		findings = append(findings, Finding{Severity: SeverityLow, Item: "prometheus.yml", Detail: err.Error() + " (se asume scrape_interval 5s)"})
+	} else if scrape, err = pc.ScrapeInterval(""); err != nil {
+		findings = append(findings, Finding{Severity: SeverityLow, Item: "prometheus.yml", Detail: "scrape_interval: " + err.Error()})
+		scrape = 5 * time.Second
+	}
+
+	var all []lintQuery
+	for _, q := range queries.All() {
+		all = append(all, lintQuery{location: fmt.Sprintf("CLI %s (%s)", q.Name, strings.Join(q.Users, ", ")), expr: q.Expr, unbounded: q.Unbounded})
+	}
+	for _, p := range catalog.Panels {
+		all = append(all, lintQuery{location: "METRICS.md panel " + p.Title, expr: p.Expr})
+	}
+	for _, a := range catalog.Alerts {
+		all = append(all, lintQuery{location: "METRICS.md alerta " + a.Name, expr: a.Expr})
+	}
+	if dir, err := repo.Resolve(cfg.DashboardsDir); err != nil {
+		findings = append(findings, Finding{Severity: SeverityMed, Item: "Dashboards Grafana", Detail: err.Error()})
+	} else if dashboards, err := grafana.LoadDir(dir); err != nil {
+		findings = append(findings, Finding{Severity: SeverityHigh, Item: "Dashboards Grafana", Detail: err.Error()})
+	} else {
+		for _, d := range dashboards {
+			for _, q := range d.Queries() {
+				all = append(all, lintQuery{location: q.Location(), expr: q.Expr, variables: strings.Contains(q.Expr, "$__")})
+			}
+		}
+	}
+
+	for _, q := range all {
+		findings = append(findings, lintExpr(q, catalog, scrape)...)
+	}
+	return len(all), findings
+}
+
+// PARTE CRITICA **********************
+// Reglas de lint, todas sobre el AST (no regex). Sintaxis invalida o metrica fuera del contrato = alta
+// (la query devuelve error o vacio); rate/irate/increase/resets sobre gauge = alta (resultado sin sentido);
+// rango < 2x scrape_interval = media (a veces una sola muestra: vacio intermitente);
+// count sobre selector sin nombre de metrica = media (recorre todas las series del job), baja si el
+// registro la acepta explicitamente (Unbounded).
+// Los tipos salen de METRICS.md; no inferir tipo por sufijo (_total) para no duplicar el contrato.
+// FIN DE PARTE CRITICA ****************
+func lintExpr(q lintQuery, catalog contract.Catalog, scrape time.Duration) []Finding {
+	expr, err := promql.Parse(grafana.ExpandIntervals(q.expr))
+	if err != nil {
+		return []Finding{{Severity: SeverityHigh, Item: "PromQL invalido", Detail: fmt.Sprintf("%s: %v", q.location, err)}}
+	}
+
+	findings := []Finding{}
+	for _, name := range promql.MetricNames(expr) {
+		if _, ok := builtinMetrics[name]; ok {
+			continue
+		}
+		if _, ok := catalog.Lookup(name); !ok {
+			findings = append(findings, Finding{
+				Severity: SeverityHigh,
+				Item:     "Query con metrica fuera del contrato",
+				Detail:   fmt.Sprintf("%s -> %s", q.location, name),
+			})
+		}
+	}
+
+	unbounded := false
+	promql.Inspect(expr, func(n promql.Expr) bool {
+		switch x := n.(type) {
+		case *promql.Call:
+			ms, ok := rangeArg(x)
+			if !ok {
+				return true
+			}
+			name := ms.Vector.MetricName()
+			if _, counterOnly := counterFunctions[x.Func]; counterOnly {
+				if m, ok := catalog.Lookup(name); ok && m.Type == "gauge" {
+					findings = append(findings, Finding{
+						Severity: SeverityHigh,
+						Item:     fmt.Sprintf("%s() sobre gauge", x.Func),
+						Detail:   fmt.Sprintf("%s: %s es gauge en METRICS.md (usar delta/deriv/*_over_time)", q.location, name),
+					})
+				}
+			}
+			if _, window := windowFunctions[x.Func]; window && !q.variables && ms.Range < 2*scrape {
+				findings = append(findings, Finding{
+					Severity: SeverityMed,
+					Item:     "Rango menor a 2x scrape_interval",
+					Detail: fmt.Sprintf("%s: %s(%s[%s]) con scrape_interval %s (minimo %s)",
+						q.location, x.Func, name, promql.FormatDuration(ms.Range), promql.FormatDuration(scrape), promql.FormatDuration(2*scrape)),
+				})
+			}
+		case *promql.AggregateExpr:
+			if x.Op == "count" && !unbounded && hasNamelessSelector(x.Expr) {
+				unbounded = true
+				sev, item := SeverityMed, "Cardinalidad sin cota"
+				if q.unbounded {
+					sev, item = SeverityLow, "Cardinalidad sin cota (aceptada)"
+				}
+				findings = append(findings, Finding{
+					Severity: sev,
+					Item:     item,
+					Detail:   fmt.Sprintf("%s: %s recorre todas las series del selector", q.location, x),
+				})
+			}
+		}
+		return true
+	})
+	return findings
+}
+
+// rangeArg devuelve el selector de rango de una funcion de ventana (rate(x[5m])).
+func rangeArg(c *promql.Call) (*promql.MatrixSelector, bool) {
+	for _, a := range c.Args {
+		for {
+			p, ok := a.(*promql.ParenExpr)
+			if !ok {
+				break
+			}
+			a = p.Expr
+		}
+		if ms, ok := a.(*promql.MatrixSelector); ok {
+			return ms, true
+		}
+	}
+	return nil, false
+}
+
+func hasNamelessSelector(e promql.Expr) bool {
+	found := false
+	promql.Inspect(e, func(n promql.Expr) bool {
+		if vs, ok := n.(*promql.VectorSelector); ok && vs.MetricName() == "" {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
@@ -0,0 +1,150 @@
+// Archivo: tools/drone-observe/internal/audit/rules.go
+// Rol: verificar que las reglas de alerta del contrato esten versionadas y cargadas en Prometheus.
+// No hace: evaluar alertas ni consultar Alertmanager.
+package audit
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"drone-observe/internal/config"
+	"drone-observe/internal/contract"
+	"drone-observe/internal/generate"
+	"drone-observe/internal/prometheus"
+	"drone-observe/internal/promql"
+	"drone-observe/internal/repo"
+	"drone-observe/internal/source"
+)
+
+// PARTE CRITICA **********************
+// Cadena completa: METRICS.md -> archivo de reglas versionado -> reglas cargadas en Prometheus.
+// Cada eslabon se valida por separado para que el finding diga donde se corto.
+// La comparacion de expr es por AST canonico: Prometheus reformatea la query al cargarla.
+// FIN DE PARTE CRITICA ****************
+func checkRules(cfg config.Config, catalog contract.Catalog) []Finding {
+	expected, err := generate.Rules(catalog, false)
+	if err != nil {
+		return []Finding{{Severity: SeverityHigh, Item: "Alertas de METRICS.md invalidas", Detail: err.Error()}}
+	}
+
+	findings := []Finding{}
+	want, err := generate.RulesYAML(catalog, false)
+	if err == nil {
+		path, rerr := repo.Resolve(cfg.RulesFile)
+		var current []byte
+		if rerr == nil {
+			current, rerr = os.ReadFile(path)
+		}
+		switch {
+		case rerr != nil:
+			findings = append(findings, Finding{
+				Severity: SeverityHigh,
+				Item:     "Archivo de reglas no versionado",
+				Detail:   fmt.Sprintf("%s (generar con: drone-observe generate rules --out %s)", cfg.RulesFile, cfg.RulesFile),
+			})
+		case !bytes.Equal(current, want):
+			findings = append(findings, Finding{
+				Severity: SeverityMed,
+				Item:     "Archivo de reglas desactualizado vs METRICS.md",
+				Detail:   fmt.Sprintf("%s (regenerar con: drone-observe generate rules --out %s)", cfg.RulesFile, cfg.RulesFile),
+			})
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second)
+	defer cancel()
+	groups, err := source.For(cfg).Prometheus.Rules(ctx)
+	if err != nil {
+		return append(findings, Finding{Severity: SeverityHigh, Item: "Prometheus /api/v1/rules", Detail: err.Error()})
+	}
+
+	loaded := map[string]prometheus.LoadedRule{}
+	for _, g := range groups {
+		if g.Name != generate.RuleGroup {
+			continue
+		}
+		for _, r := range g.Rules {
+			if r.Type == "alerting" {
+				loaded[r.Name] = r
+			}
+		}
+	}
+
+	expectedNames := map[string]struct{}{}
+	for _, r := range expected {
+		expectedNames[r.Alert] = struct{}{}
+		got, ok := loaded[r.Alert]
+		if !ok {
+			findings = append(findings, Finding{
+				Severity: SeverityHigh,
+				Item:     "Alerta del contrato no cargada en Prometheus",
+				Detail:   fmt.Sprintf("%s (grupo %s)", r.Alert, generate.RuleGroup),
+			})
+			continue
+		}
+		if diff := exprDiff(r.Expr, got.Query); diff != "" {
+			findings = append(findings, Finding{
+				Severity: SeverityHigh,
+				Item:     "Alerta cargada difiere de METRICS.md",
+				Detail:   fmt.Sprintf("%s: %s", r.Alert, diff),
+			})
+		}
+		if wantFor, _ := promql.ParseDuration(r.For); r.For != "" && time.Duration(got.Duration*float64(time.Second)) != wantFor {
+			findings = append(findings, Finding{
+				Severity: SeverityMed,
+				Item:     "Alerta cargada con otro for",
+				Detail:   fmt.Sprintf("%s: contrato %s, Prometheus %s", r.Alert, r.For, promql.FormatDuration(time.Duration(got.Duration*float64(time.Second)))),
+			})
+		}
+		if got.Labels["severity"] != r.Labels.Severity {
+			findings = append(findings, Finding{
+				Severity: SeverityMed,
+				Item:     "Alerta cargada con otra severidad",
+				Detail:   fmt.Sprintf("%s: contrato %s, Prometheus %s", r.Alert, r.Labels.Severity, got.Labels["severity"]),
+			})
+		}
+		if got.Health != "" && got.Health != "ok" && got.Health != "unknown" {
+			findings = append(findings, Finding{
+				Severity: SeverityHigh,
+				Item:     "Alerta con error de evaluacion",
+				Detail:   fmt.Sprintf("%s: %s %s", r.Alert, got.Health, got.LastError),
+			})
+		}
+	}
+	extra := []string{}
+	for name := range loaded {
+		if _, ok := expectedNames[name]; !ok {
+			extra = append(extra, name)
+		}
+	}
+	sort.Strings(extra)
+	for _, name := range extra {
+		findings = append(findings, Finding{
+			Severity: SeverityMed,
+			Item:     "Alerta cargada fuera del contrato",
+			Detail:   fmt.Sprintf("%s (grupo %s)", name, generate.RuleGroup),
+		})
+	}
+	return findings
+}
+
+// exprDiff compara dos expresiones por AST; devuelve "" si son equivalentes.
+func exprDiff(want, got string) string {
+	w, err := promql.Parse(want)
+	if err != nil {
+		return err.Error()
+	}
+	g, err := promql.Parse(got)
+	if err != nil {
+		return fmt.Sprintf("query cargada no parseable: %v", err)
+	}
+	if promql.Equal(w, g) {
+		return ""
+	}
+	return strings.Join(promql.Diff(w, g), "; ")
+}
@@ -0,0 +1,309 @@
+// Archivo: tools/drone-observe/internal/audit/security.go
+// Rol: auditoria de seguridad de mosquitto.conf versionado, confirmada con un handshake MQTT real.
+// No hace: fuerza bruta de credenciales ni cambios en el broker; la prueba activa es anonima y de un solo mensaje.
+package audit
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"drone-observe/internal/compose"
+	"drone-observe/internal/config"
+	"drone-observe/internal/mosquitto"
+	"drone-observe/internal/mqttclient"
+	"drone-observe/internal/repo"
+)
+
+// forbiddenTopicPrefix esta fuera de drone/<id>/telemetry|event: ningun cliente anonimo deberia poder escribirlo.
+const forbiddenTopicPrefix = "drone/drone-observe-security/cmd/"
+
+const securityProbeTimeout = 3 * time.Second
+
+// Security audita mosquitto.conf (estatico) y luego intenta confirmar los hallazgos contra el broker.
+func Security(cfg config.Config) []Finding {
+	return security(cfg, true)
+}
+
+// SecurityStatic es la parte estatica de Security: no conecta ni publica en el broker.
+func SecurityStatic(cfg config.Config) []Finding {
+	return security(cfg, false)
+}
+
+func security(cfg config.Config, active bool) []Finding {
+	path, err := repo.Resolve(cfg.MosquittoConf)
+	if err != nil {
+		return []Finding{{Severity: SeverityHigh, Item: "mosquitto.conf", Detail: err.Error()}}
+	}
+	mc, err := mosquitto.Load(path)
+	if err != nil {
+		return []Finding{{Severity: SeverityHigh, Item: "mosquitto.conf", Detail: err.Error()}}
+	}
+
+	findings := []Finding{}
+	cf, err := compose.Load(cfg.ComposeFile)
+	if err != nil {
+		findings = append(findings, Finding{Severity: SeverityLow, Item: "docker-compose.yml", Detail: err.Error() + " (sin resolver rutas del contenedor)"})
+	}
+	svc, _, _ := cf.MountOf(cfg.MosquittoConf)
+
+	findings = append(findings, checkListeners(mc, svc)...)
+	findings = append(findings, checkAuth(mc, svc)...)
+	findings = append(findings, checkPersistence(mc, svc)...)
+	if active {
+		findings = append(findings, confirmAnonymous(cfg, mc)...)
+	}
+	return findings
+}
+
+// PARTE CRITICA **********************
+// Severidades segun docs/06-seguridad.md: lo aceptable "solo para laboratorio" (anonimo, sin TLS,
+// sin ACL) es alta porque es exactamente lo que no debe llegar a un despliegue real; la deuda de
+// operacion (persistencia, include_dir) es baja. No bajar severidades por estar en un lab.
+// FIN DE PARTE CRITICA ****************
+func checkListeners(mc mosquitto.Config, svc compose.Service) []Finding {
+	if len(mc.Listeners) == 0 {
+		return []Finding{{
+			Severity: SeverityMed,
+			Item:     "Sin listener explicito",
+			Detail:   fmt.Sprintf("Mosquitto 2.x arranca en local only mode (solo localhost:%d); en Docker no acepta conexiones", mosquitto.DefaultPort),
+		}}
+	}
+	findings := []Finding{}
+	for _, l := range mc.Listeners {
+		local := l.Bind == "127.0.0.1" || l.Bind == "localhost" || l.Bind == "::1"
+		if !l.TLS() && !local {
+			detail := fmt.Sprintf("listener %s (linea %d): credenciales y telemetria viajan en claro", l.Name(), l.Line)
+			if hostPort := publishedPort(svc, l.Port); hostPort != "" {
+				detail += fmt.Sprintf("; publicado en el host como %s", hostPort)
+			}
+			findings = append(findings, Finding{Severity: SeverityHigh, Item: "Listener sin TLS", Detail: detail})
+		}
+		if l.TLS() && (l.TLSVersion == "tlsv1" || l.TLSVersion == "tlsv1.1") {
+			findings = append(findings, Finding{
+				Severity: SeverityMed,
+				Item:     "TLS obsoleto",
+				Detail:   fmt.Sprintf("listener %s: tls_version %s", l.Name(), l.TLSVersion),
+			})
+		}
+	}
+	return findings
+}
+
+func checkAuth(mc mosquitto.Config, svc compose.Service) []Finding {
+	type scope struct {
+		name string
+		sec  mosquitto.Security
+	}
+	scopes := []scope{{name: "global", sec: mc.Security}}
+	if mc.PerListenerSettings {
+		scopes = scopes[:0]
+		for _, l := range mc.Listeners {
+			scopes = append(scopes, scope{name: "listener " + l.Name(), sec: l.Security})
+		}
+	}
+
+	findings := []Finding{}
+	for _, sc := range scopes {
+		s := sc.sec
+		if s.Anonymous() {
+			findings = append(findings, Finding{
+				Severity: SeverityHigh,
+				Item:     "allow_anonymous true",
+				Detail:   sc.name + ": cualquier cliente conecta sin credenciales",
+			})
+		}
+		if !s.Anonymous() && s.PasswordFile == "" && len(s.Plugins) == 0 {
+			findings = append(findings, Finding{
+				Severity: SeverityMed,
+				Item:     "Sin metodo de autenticacion",
+				Detail:   sc.name + ": allow_anonymous false sin password_file ni plugin (el broker rechaza todo cliente)",
+			})
+		}
+		if s.ACLFile == "" && len(s.Plugins) == 0 {
+			findings = append(findings, Finding{
+				Severity: SeverityHigh,
+				Item:     "Sin acl_file",
+				Detail:   sc.name + ": todo cliente conectado publica y suscribe cualquier topic (docs/06-seguridad.md: ACL por topic)",
+			})
+		}
+		if s.PasswordFile != "" {
+			findings = append(findings, checkMountedFile(svc, "password_file", s.PasswordFile, true)...)
+		}
+		if s.ACLFile != "" {
+			findings = append(findings, checkMountedFile(svc, "acl_file", s.ACLFile, false)...)
+		}
+	}
+	return findings
+}
+
+// checkMountedFile resuelve un archivo del contenedor al repo y, si es password_file, busca texto plano.
+func checkMountedFile(svc compose.Service, option, containerPath string, passwords bool) []Finding {
+	source, named, ok := svc.HostPath(containerPath)
+	switch {
+	case !ok:
+		return []Finding{{
+			Severity: SeverityHigh,
+			Item:     option + " no montado",
+			Detail:   fmt.Sprintf("%s: ningun volumen de %s cubre la ruta (Mosquitto no arranca)", containerPath, svcName(svc)),
+		}}
+	case named:
+		// Vive en un volumen: no es un artefacto versionado y no se puede auditar.
+		return nil
+	}
+	path, err := repo.Resolve(source)
+	if err != nil {
+		return []Finding{{Severity: SeverityHigh, Item: option + " no versionado", Detail: fmt.Sprintf("%s -> %s: %v", containerPath, source, err)}}
+	}
+	if !passwords {
+		return nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return []Finding{{Severity: SeverityHigh, Item: option, Detail: err.Error()}}
+	}
+	defer f.Close()
+	var plain []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		user, hash, found := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if !found || strings.HasPrefix(user, "#") {
+			continue
+		}
+		// mosquitto_passwd escribe $6$ (sha512) o $7$ (pbkdf2); otra cosa es texto plano.
+		if !strings.HasPrefix(hash, "$6$") && !strings.HasPrefix(hash, "$7$") {
+			plain = append(plain, user)
+		}
+	}
+	findings := []Finding{{Severity: SeverityLow, Item: "password_file versionado", Detail: source + " (hashes en el repo; preferir secreto montado)"}}
+	if len(plain) > 0 {
+		findings = append(findings, Finding{
+			Severity: SeverityHigh,
+			Item:     "password_file en texto plano",
+			Detail:   fmt.Sprintf("%s: usuarios %s (usar mosquitto_passwd -U)", source, strings.Join(plain, ", ")),
+		})
+	}
+	return findings
+}
+
+func checkPersistence(mc mosquitto.Config, svc compose.Service) []Finding {
+	findings := []Finding{}
+	switch {
+	case !mc.Persistence:
+		findings = append(findings, Finding{Severity: SeverityLow, Item: "persistence false", Detail: "sesiones y mensajes QoS 1 en vuelo se pierden al reiniciar el broker"})
+	case mc.PersistenceLocation == "":
+		findings = append(findings, Finding{Severity: SeverityLow, Item: "persistence sin persistence_location", Detail: "la base se escribe en el directorio de trabajo del contenedor"})
+	case svc.Name != "":
+		if _, _, ok := svc.HostPath(mc.PersistenceLocation); !ok {
+			findings = append(findings, Finding{
+				Severity: SeverityMed,
+				Item:     "persistence_location sin volumen",
+				Detail:   fmt.Sprintf("%s: se pierde al recrear el contenedor %s", mc.PersistenceLocation, svc.Name),
+			})
+		}
+	}
+	for _, dir := range mc.IncludeDirs {
+		findings = append(findings, Finding{Severity: SeverityLow, Item: "include_dir no auditado", Detail: dir})
+	}
+	return findings
+}
+
+// confirmAnonymous intenta un CONNECT anonimo real y un publish a un topic prohibido que el mismo cliente
+// escucha: en MQTT 3.1.1 el broker hace PUBACK aunque la ACL descarte el mensaje, solo la entrega lo prueba.
+func confirmAnonymous(cfg config.Config, mc mosquitto.Config) []Finding {
+	addr := fmt.Sprintf("%s:%d", cfg.MQTTHost, cfg.MQTTPort)
+	anonymous, acl := mc.Security.Anonymous(), mc.Security.ACLFile != "" || len(mc.Security.Plugins) > 0
+	if mc.PerListenerSettings {
+		anonymous, acl = false, true
+		for _, l := range mc.Listeners {
+			if l.Port == cfg.MQTTPort {
+				anonymous = l.Security.Anonymous()
+				acl = l.Security.ACLFile != "" || len(l.Security.Plugins) > 0
+			}
+		}
+	}
+
+	client, err := mqttclient.Dial(cfg.MQTTHost, cfg.MQTTPort, mqttclient.Options{
+		ClientID: fmt.Sprintf("drone-observe-security-%d", time.Now().UnixNano()%100000),
+		Timeout:  securityProbeTimeout,
+	})
+	var refused *mqttclient.ConnectError
+	switch {
+	case errors.As(err, &refused):
+		if anonymous {
+			return []Finding{{
+				Severity: SeverityMed,
+				Item:     "mosquitto.conf no coincide con el broker",
+				Detail:   fmt.Sprintf("allow_anonymous true en el archivo, pero %s rechaza anonimos (%v)", addr, err),
+			}}
+		}
+		return nil
+	case err != nil:
+		return []Finding{{Severity: SeverityLow, Item: "Verificacion activa omitida", Detail: fmt.Sprintf("%s: %v", addr, err)}}
+	}
+	defer client.Close()
+
+	findings := []Finding{{
+		Severity: SeverityHigh,
+		Item:     "Conexion anonima aceptada (verificado)",
+		Detail:   fmt.Sprintf("CONNACK 0 en %s sin usuario ni password", addr),
+	}}
+	if !anonymous {
+		findings[0].Detail += " (mosquitto.conf versionado no habilita anonimos: el broker corre otra config)"
+	}
+
+	topic := fmt.Sprintf("%s%d", forbiddenTopicPrefix, time.Now().UnixNano())
+	code, err := client.Subscribe(topic, 1)
+	if err != nil || code == mqttclient.SubackFailure {
+		return append(findings, Finding{Severity: SeverityLow, Item: "Publish anonimo sin confirmar", Detail: topic + ": suscripcion rechazada, no se puede observar la entrega"})
+	}
+	payload := []byte("drone-observe security probe")
+	if err := client.Publish(topic, payload, 1, false); err != nil {
+		return append(findings, Finding{Severity: SeverityLow, Item: "Publish anonimo sin confirmar", Detail: fmt.Sprintf("%s: %v", topic, err)})
+	}
+	timer := time.NewTimer(securityProbeTimeout)
+	defer timer.Stop()
+	for {
+		select {
+		case msg, ok := <-client.Messages():
+			if !ok {
+				return findings
+			}
+			if msg.Topic == topic {
+				return append(findings, Finding{
+					Severity: SeverityHigh,
+					Item:     "Publish anonimo a topic prohibido entregado (verificado)",
+					Detail:   fmt.Sprintf("%s: fuera de drone/<id>/telemetry|event y sin credenciales", topic),
+				})
+			}
+		case <-timer.C:
+			if !acl {
+				findings = append(findings, Finding{
+					Severity: SeverityMed,
+					Item:     "mosquitto.conf no coincide con el broker",
+					Detail:   fmt.Sprintf("sin acl_file en el archivo, pero %s descarto el publish a %s", addr, topic),
+				})
+			}
+			return findings
+		}
+	}
+}
+
+func publishedPort(svc compose.Service, port int) string {
+	for _, p := range svc.Ports {
+		if p.Container == fmt.Sprint(port) {
+			return p.Host
+		}
+	}
+	return ""
+}
+
+func svcName(svc compose.Service) string {
+	if svc.Name == "" {
+		return "el servicio MQTT"
+	}
+	return svc.Name
+}
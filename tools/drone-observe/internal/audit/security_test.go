@@ -0,0 +1,41 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"drone-observe/internal/config"
+	"drone-observe/internal/testenv"
+)
+
+// SecurityStatic es lo que archiva el bundle: mismos hallazgos del archivo, sin tocar el broker.
+func TestSecurityStaticNoBroker(t *testing.T) {
+	b := testenv.NewBroker(t)
+	conf := filepath.Join(t.TempDir(), "mosquitto.conf")
+	if err := os.WriteFile(conf, []byte("listener 1883\nallow_anonymous true\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := config.Config{MosquittoConf: conf, MQTTHost: b.Host, MQTTPort: b.Port}
+
+	static := SecurityStatic(cfg)
+	if !hasItem(static, "allow_anonymous true") {
+		t.Errorf("estatico sin allow_anonymous: %+v", static)
+	}
+	if hasItem(static, "verificado") || len(b.Published()) != 0 {
+		t.Fatalf("SecurityStatic toco el broker: %+v", static)
+	}
+	if active := Security(cfg); !hasItem(active, "Conexion anonima aceptada (verificado)") {
+		t.Errorf("Security sin verificacion activa: %+v", active)
+	}
+}
+
+func hasItem(findings []Finding, item string) bool {
+	for _, f := range findings {
+		if strings.Contains(f.Item, item) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,171 @@
+// Archivo: tools/drone-observe/internal/bundle/bundle.go
+// Rol: escribir un bundle de soporte (tar.gz) con manifest, timestamps y sha256 por archivo.
+// No hace: decidir que se recolecta; eso vive en collect.go.
+package bundle
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"drone-observe/internal/source"
+)
+
+// Entry es un archivo recolectado (o un intento fallido, sin archivo).
+type Entry struct {
+	Path        string    `json:"path"`
+	Size        int       `json:"size"`
+	SHA256      string    `json:"sha256,omitempty"`
+	CollectedAt time.Time `json:"collected_at"`
+	DurationMS  int64     `json:"duration_ms"`
+	Error       string    `json:"error,omitempty"`
+}
+
+type Manifest struct {
+	Tool       string    `json:"tool"`
+	Format     int       `json:"format"`
+	Host       string    `json:"host"`
+	CreatedAt  time.Time `json:"created_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	// Window y Step describen los datos de rango; Capture la captura MQTT (0s: sin captura).
+	Window  string  `json:"window"`
+	Step    string  `json:"step"`
+	Capture string  `json:"capture"`
+	Entries []Entry `json:"entries"`
+}
+
+// Failed devuelve las entradas con error.
+func (m Manifest) Failed() []Entry {
+	var out []Entry
+	for _, e := range m.Entries {
+		if e.Error != "" {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// Writer arma el tar.gz en un archivo temporal y lo renombra al cerrar.
+type Writer struct {
+	out      string
+	tmp      *os.File
+	gz       *gzip.Writer
+	tw       *tar.Writer
+	root     string
+	manifest Manifest
+}
+
+// Create abre el bundle en un temporal junto a out; el tar usa RootDir(out) como directorio raiz.
+func Create(out string, m Manifest) (*Writer, error) {
+	tmp, err := os.CreateTemp(filepath.Dir(out), ".drone-observe-bundle-*")
+	if err != nil {
+		return nil, err
+	}
+	gz := gzip.NewWriter(tmp)
+	m.Tool = "drone-observe"
+	m.Format = source.FormatVersion
+	if m.Host == "" {
+		m.Host, _ = os.Hostname()
+	}
+	return &Writer{
+		out:      out,
+		tmp:      tmp,
+		gz:       gz,
+		tw:       tar.NewWriter(gz),
+		root:     RootDir(out),
+		manifest: m,
+	}, nil
+}
+
+// RootDir es el directorio raiz del tar: el nombre del archivo sin .tar.gz.
+func RootDir(out string) string {
+	base := filepath.Base(out)
+	for _, ext := range []string{".tar.gz", ".tgz"} {
+		base = strings.TrimSuffix(base, ext)
+	}
+	return base
+}
+
+// PARTE CRITICA **********************
+// Un fallo de recoleccion no aborta el bundle: queda en el manifest con su error y, si hubo cuerpo
+// (p. ej. un 500 de la API), el cuerpo se archiva igual. En un incidente lo que no responde es parte
+// del diagnostico. El sha256 se calcula sobre lo escrito en el tar, no sobre lo que se intento leer.
+// FIN DE PARTE CRITICA ****************
+func (w *Writer) Collect(name string, collect func() ([]byte, error)) error {
+	start := time.Now()
+	data, err := collect()
+	entry := Entry{Path: name, CollectedAt: start, DurationMS: time.Since(start).Milliseconds()}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	if data != nil {
+		sum := sha256.Sum256(data)
+		entry.Size = len(data)
+		entry.SHA256 = hex.EncodeToString(sum[:])
+		if werr := w.write(name, data, start); werr != nil {
+			return werr
+		}
+	}
+	w.manifest.Entries = append(w.manifest.Entries, entry)
+	return nil
+}
+
+func (w *Writer) write(name string, data []byte, modTime time.Time) error {
+	hdr := &tar.Header{
+		Name:    path.Join(w.root, name),
+		Mode:    0o644,
+		Size:    int64(len(data)),
+		ModTime: modTime,
+	}
+	if err := w.tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	_, err := w.tw.Write(data)
+	return err
+}
+
+// Close escribe SHA256SUMS (formato de sha256sum -c) y manifest.json, y deja el bundle en su ruta final.
+func (w *Writer) Close() (Manifest, error) {
+	w.manifest.FinishedAt = time.Now()
+	var sums strings.Builder
+	for _, e := range w.manifest.Entries {
+		if e.SHA256 != "" {
+			fmt.Fprintf(&sums, "%s  %s\n", e.SHA256, e.Path)
+		}
+	}
+	err := w.write(source.SumsFile, []byte(sums.String()), w.manifest.FinishedAt)
+	if err == nil {
+		var data []byte
+		if data, err = json.MarshalIndent(w.manifest, "", "  "); err == nil {
+			err = w.write(source.ManifestFile, append(data, '\n'), w.manifest.FinishedAt)
+		}
+	}
+	for _, closer := range []func() error{w.tw.Close, w.gz.Close, w.tmp.Close} {
+		if cerr := closer(); err == nil {
+			err = cerr
+		}
+	}
+	if err == nil {
+		err = os.Rename(w.tmp.Name(), w.out)
+	}
+	if err != nil {
+		_ = os.Remove(w.tmp.Name())
+	}
+	return w.manifest, err
+}
+
+// Abort descarta el bundle a medio escribir.
+func (w *Writer) Abort() {
+	_ = w.tw.Close()
+	_ = w.gz.Close()
+	_ = w.tmp.Close()
+	_ = os.Remove(w.tmp.Name())
+}
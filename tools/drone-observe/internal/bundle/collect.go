@@ -0,0 +1,363 @@
+// Archivo: tools/drone-observe/internal/bundle/collect.go
+// Rol: recolectar config, checks, /metrics del backend, API de Prometheus, dashboards de Grafana y trafico MQTT.
+// No hace: analizar lo recolectado; el bundle guarda evidencia, los checks ya dejaron su veredicto.
+package bundle
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"drone-observe/internal/alerts"
+	"drone-observe/internal/audit"
+	"drone-observe/internal/checks"
+	"drone-observe/internal/config"
+	"drone-observe/internal/contract"
+	"drone-observe/internal/fleet"
+	"drone-observe/internal/freshness"
+	"drone-observe/internal/grafana"
+	"drone-observe/internal/limits"
+	"drone-observe/internal/mqttclient"
+	"drone-observe/internal/prometheus"
+	"drone-observe/internal/queries"
+	"drone-observe/internal/source"
+	"drone-observe/internal/targets"
+	"drone-observe/internal/topology"
+)
+
+const (
+	httpTimeout = 5 * time.Second
+	// fleetWindow es la ventana MQTT de fleet; la captura completa va aparte en mqtt/capture.jsonl.
+	fleetWindow = 3 * time.Second
+	// captureMaxMessages acota la captura para que un flood no convierta el bundle en un volcado.
+	captureMaxMessages = 10000
+	redacted           = "REDACTADO"
+)
+
+// Options controla los datos de rango y la captura MQTT.
+type Options struct {
+	Window  time.Duration
+	Step    time.Duration
+	Capture time.Duration
+}
+
+// Check es un check cuya salida se archiva como checks/<Name>.json.
+type Check struct {
+	Name string
+	Run  func(cfg config.Config) (any, error)
+}
+
+// Checks son los checks de internal/* que se archivan; los de ui (validate) los agrega cmd.
+func Checks() []Check {
+	return []Check{
+		{"summary", func(cfg config.Config) (any, error) { return checks.RunAll(cfg), nil }},
+		{"topology", func(cfg config.Config) (any, error) { return topology.Check(cfg), nil }},
+		{"freshness", func(cfg config.Config) (any, error) { return freshness.Check(cfg), nil }},
+		{"limits", func(cfg config.Config) (any, error) { return limits.Observe(cfg) }},
+		{"drift", func(cfg config.Config) (any, error) { return audit.Drift(cfg) }},
+		{"targets", func(cfg config.Config) (any, error) { return targets.Check(cfg) }},
+		{"alerts", func(cfg config.Config) (any, error) { return alerts.Collect(cfg), nil }},
+		{"fleet", func(cfg config.Config) (any, error) { return fleet.Discover(cfg, fleetWindow), nil }},
+		// Solo estatico: el bundle se arma durante un incidente y no debe publicar en el broker.
+		{"security", func(cfg config.Config) (any, error) { return audit.SecurityStatic(cfg), nil }},
+		{"lint-queries", func(cfg config.Config) (any, error) {
+			checked, findings := audit.LintQueries(cfg)
+			return struct {
+				Checked  int
+				Findings []audit.Finding
+			}{checked, findings}, nil
+		}},
+	}
+}
+
+// PARTE CRITICA **********************
+// El orden es deliberado: primero lo barato y estatico (config), despues los checks y la evidencia
+// cruda de Prometheus, y al final la captura MQTT, que es la que mas tarda. Si Prometheus o Grafana no
+// responden, cada archivo registra su error y el resto se recolecta igual.
+// La config se archiva redactada: el bundle se adjunta a tickets.
+// FIN DE PARTE CRITICA ****************
+func Collect(w *Writer, cfg config.Config, opts Options, checkList []Check) error {
+	steps := []func() error{
+		func() error {
+			return w.Collect(source.ConfigFile, func() ([]byte, error) { return marshal(Redact(cfg)) })
+		},
+		func() error { return collectChecks(w, cfg, checkList) },
+		func() error {
+			return w.Collect(source.BackendMetricsFile, func() ([]byte, error) { return get(cfg.BackendMetricsURL) })
+		},
+		func() error { return collectPrometheus(w, cfg, opts) },
+		func() error { return collectGrafana(w, cfg) },
+	}
+	if opts.Capture > 0 {
+		steps = append(steps, func() error {
+			return w.Collect("mqtt/capture.jsonl", func() ([]byte, error) { return capture(cfg, opts.Capture) })
+		})
+	}
+	for _, step := range steps {
+		if err := step(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Redact devuelve la config sin secretos: password de Grafana y credenciales embebidas en URLs.
+func Redact(cfg config.Config) config.Config {
+	if cfg.GrafanaPassword != "" {
+		cfg.GrafanaPassword = redacted
+	}
+	for _, u := range []*string{&cfg.PrometheusURL, &cfg.GrafanaURL, &cfg.AlertmanagerURL, &cfg.BackendMetricsURL} {
+		*u = redactURL(*u)
+	}
+	return cfg
+}
+
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.User == nil {
+		return raw
+	}
+	if _, ok := u.User.Password(); ok {
+		u.User = url.UserPassword(u.User.Username(), redacted)
+	}
+	return u.String()
+}
+
+func collectChecks(w *Writer, cfg config.Config, checkList []Check) error {
+	for _, c := range checkList {
+		c := c
+		err := w.Collect("checks/"+c.Name+".json", func() ([]byte, error) {
+			out, err := c.Run(cfg)
+			if err != nil {
+				return nil, err
+			}
+			return marshal(out)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// collectPrometheus archiva la API cruda: estado, las queries del CLI al instante del bundle y el rango
+// reciente de cada metrica del contrato.
+func collectPrometheus(w *Writer, cfg config.Config, opts Options) error {
+	for _, api := range []struct{ file, path string }{
+		{"targets.json", prometheus.PathTargets},
+		{"rules.json", prometheus.PathRules},
+		{"flags.json", prometheus.PathFlags},
+		{"buildinfo.json", prometheus.PathBuildInfo},
+		{"tsdb.json", prometheus.PathTSDB},
+		{"config.json", prometheus.PathConfig},
+	} {
+		api := api
+		err := w.Collect("prometheus/"+api.file, func() ([]byte, error) {
+			ctx, cancel := context.WithTimeout(context.Background(), httpTimeout)
+			defer cancel()
+			return prometheus.Raw(ctx, cfg.PrometheusURL, api.path)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	catalog, catalogErr := contract.Load(cfg.MetricsDocPath)
+	names := catalog.CurrentNames()
+	at := time.Now()
+	if err := w.Collect(source.QueriesFile, func() ([]byte, error) { return instantQueries(cfg, names, at) }); err != nil {
+		return err
+	}
+	if catalogErr != nil {
+		return w.Collect("prometheus/range", func() ([]byte, error) { return nil, catalogErr })
+	}
+	for _, name := range names {
+		name := name
+		err := w.Collect("prometheus/range/"+name+".json", func() ([]byte, error) {
+			ctx, cancel := context.WithTimeout(context.Background(), httpTimeout)
+			defer cancel()
+			return prometheus.RawQueryRange(ctx, cfg.PrometheusURL, name, at.Add(-opts.Window), at, opts.Step)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// instantQueries evalua el registro de queries del CLI y cada metrica del contrato en un mismo instante.
+func instantQueries(cfg config.Config, names []string, at time.Time) ([]byte, error) {
+	exprs := make([]string, 0, len(names)+len(queries.All()))
+	seen := map[string]bool{}
+	for _, q := range queries.All() {
+		if !seen[q.Expr] {
+			seen[q.Expr] = true
+			exprs = append(exprs, q.Expr)
+		}
+	}
+	for _, name := range names {
+		if !seen[name] {
+			seen[name] = true
+			exprs = append(exprs, name)
+		}
+	}
+
+	file := source.QueryFile{Time: at}
+	failed := 0
+	for _, expr := range exprs {
+		ctx, cancel := context.WithTimeout(context.Background(), httpTimeout)
+		body, err := prometheus.RawQuery(ctx, cfg.PrometheusURL, expr, at)
+		cancel()
+		res := source.QueryResult{Expr: expr}
+		if json.Valid(body) {
+			res.Response = body
+		}
+		if err != nil {
+			res.Error = err.Error()
+			failed++
+		}
+		file.Results = append(file.Results, res)
+	}
+	data, err := marshal(file)
+	if err == nil && failed > 0 {
+		err = fmt.Errorf("%d de %d queries fallaron", failed, len(exprs))
+	}
+	return data, err
+}
+
+// collectGrafana archiva el JSON de cada dashboard tal como lo sirve Grafana (no el versionado).
+func collectGrafana(w *Writer, cfg config.Config) error {
+	client := grafana.NewClient(cfg.GrafanaURL, cfg.GrafanaUser, cfg.GrafanaPassword)
+	var hits []grafana.SearchHit
+	err := w.Collect(source.GrafanaSearchFile, func() ([]byte, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), httpTimeout)
+		defer cancel()
+		var err error
+		if hits, err = client.Search(ctx); err != nil {
+			return nil, err
+		}
+		return marshal(hits)
+	})
+	if err != nil {
+		return err
+	}
+	for _, hit := range hits {
+		uid := hit.UID
+		// Un uid con separadores escribiria fuera de grafana/dashboards; offline Dir.Dashboard lo rechaza igual.
+		name, err := source.DashboardFile(uid)
+		if err != nil {
+			continue
+		}
+		err = w.Collect(filepath.ToSlash(name), func() ([]byte, error) {
+			ctx, cancel := context.WithTimeout(context.Background(), httpTimeout)
+			defer cancel()
+			dash, err := client.Dashboard(ctx, uid)
+			if err != nil {
+				return nil, err
+			}
+			return marshal(dash)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CapturedMessage es una linea de mqtt/capture.jsonl; el payload va como texto si es UTF-8 valido.
+type CapturedMessage struct {
+	Received   time.Time `json:"received"`
+	Topic      string    `json:"topic"`
+	QoS        byte      `json:"qos"`
+	Retain     bool      `json:"retain"`
+	Payload    string    `json:"payload,omitempty"`
+	PayloadB64 string    `json:"payload_b64,omitempty"`
+}
+
+// capture escucha drone/# (y MQTT_BASE_TOPIC/# si esta fuera de drone/) durante d.
+func capture(cfg config.Config, d time.Duration) ([]byte, error) {
+	client, err := mqttclient.Dial(cfg.MQTTHost, cfg.MQTTPort, mqttclient.Options{
+		ClientID: fmt.Sprintf("drone-observe-bundle-%d", time.Now().UnixNano()%100000),
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+
+	filters := []string{"drone/#"}
+	if base := strings.Trim(cfg.MQTTBaseTopic, "/"); !mqttclient.MatchTopic("drone/#", base) {
+		filters = append(filters, base+"/#")
+	}
+	for _, f := range filters {
+		if code, err := client.Subscribe(f, 1); err != nil {
+			return nil, err
+		} else if code == mqttclient.SubackFailure {
+			return nil, fmt.Errorf("suscripcion rechazada: %s", f)
+		}
+	}
+
+	var out strings.Builder
+	enc := json.NewEncoder(&out)
+	count := 0
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	for count < captureMaxMessages {
+		select {
+		case msg, ok := <-client.Messages():
+			if !ok {
+				return []byte(out.String()), client.Err()
+			}
+			line := CapturedMessage{Received: msg.Received, Topic: msg.Topic, QoS: msg.QoS, Retain: msg.Retain}
+			if utf8.Valid(msg.Payload) {
+				line.Payload = string(msg.Payload)
+			} else {
+				line.PayloadB64 = base64.StdEncoding.EncodeToString(msg.Payload)
+			}
+			if err := enc.Encode(line); err != nil {
+				return nil, err
+			}
+			count++
+		case <-timer.C:
+			if n := client.Dropped(); n > 0 {
+				return []byte(out.String()), fmt.Errorf("captura incompleta: %d mensajes descartados", n)
+			}
+			return []byte(out.String()), nil
+		}
+	}
+	return []byte(out.String()), fmt.Errorf("captura cortada en %d mensajes", captureMaxMessages)
+}
+
+func get(u string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), httpTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err == nil && (resp.StatusCode < 200 || resp.StatusCode >= 300) {
+		err = fmt.Errorf("http status %s", resp.Status)
+	}
+	return body, err
+}
+
+func marshal(v any) ([]byte, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
@@ -0,0 +1,183 @@
+// Archivo: tools/drone-observe/internal/checks/checks.go
+// Rol: resumen uniforme (ok/warn/fail) de los checks de gobernanza para modos no interactivos.
+// No hace: chequeos propios; delega en topology, freshness, audit y limits.
+package checks
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"drone-observe/internal/audit"
+	"drone-observe/internal/config"
+	"drone-observe/internal/freshness"
+	"drone-observe/internal/limits"
+	"drone-observe/internal/topology"
+)
+
+type Status int
+
+const (
+	StatusOK Status = iota
+	StatusWarn
+	StatusFail
+)
+
+// Statuses lista los estados en orden estable para exponerlos como labels.
+var Statuses = []Status{StatusOK, StatusWarn, StatusFail}
+
+func (s Status) String() string {
+	switch s {
+	case StatusOK:
+		return "ok"
+	case StatusWarn:
+		return "warn"
+	default:
+		return "fail"
+	}
+}
+
+// MarshalText serializa el estado como texto en salidas JSON (bundle).
+func (s Status) MarshalText() ([]byte, error) { return []byte(s.String()), nil }
+
+// Nombres de checks; son los valores del label check en el exporter.
+const (
+	Topology  = "topology"
+	Freshness = "freshness"
+	Drift     = "drift"
+	Limits    = "limits"
+)
+
+// Names lista los checks de gobernanza en orden estable.
+var Names = []string{Topology, Freshness, Drift, Limits}
+
+// Result es el resumen de una ejecucion de check.
+type Result struct {
+	Check    string
+	Status   Status
+	Detail   string
+	RanAt    time.Time
+	Duration time.Duration
+}
+
+// PARTE CRITICA **********************
+// El resumen usa el peor estado de cada check, sin ponderaciones ni scores.
+// Si se suaviza (p. ej. "mayoria OK"), un componente mudo deja de alertar.
+// No agregar checks nuevos aqui sin exponerlos tambien en la ayuda y docs.
+// FIN DE PARTE CRITICA ****************
+func Run(cfg config.Config, name string) Result {
+	start := time.Now()
+	var res Result
+	switch name {
+	case Topology:
+		res = summarizeTopology(topology.Check(cfg))
+	case Freshness:
+		res = summarizeFreshness(freshness.Check(cfg))
+	case Drift:
+		findings, err := audit.Drift(cfg)
+		res = summarizeDrift(findings, err)
+	case Limits:
+		snap, err := limits.Observe(cfg)
+		res = summarizeLimits(snap, err)
+	default:
+		res = Result{Status: StatusFail, Detail: "check desconocido"}
+	}
+	res.Check = name
+	res.RanAt = start
+	res.Duration = time.Since(start)
+	return res
+}
+
+// RunAll ejecuta todos los checks de gobernanza en orden.
+func RunAll(cfg config.Config) []Result {
+	out := make([]Result, 0, len(Names))
+	for _, name := range Names {
+		out = append(out, Run(cfg, name))
+	}
+	return out
+}
+
+func summarizeTopology(items []topology.Component) Result {
+	status := StatusOK
+	var bad []string
+	for _, it := range items {
+		switch it.Status {
+		case topology.StatusSilent:
+			status = worst(status, StatusWarn)
+			bad = append(bad, it.Name+" mudo")
+		case topology.StatusFail:
+			status = worst(status, StatusFail)
+			bad = append(bad, it.Name+" fail")
+		}
+	}
+	return Result{Status: status, Detail: detailOrOK(bad)}
+}
+
+func summarizeFreshness(signals []freshness.Signal) Result {
+	status := StatusOK
+	var bad []string
+	for _, s := range signals {
+		switch s.Status {
+		case freshness.StatusWarn:
+			status = worst(status, StatusWarn)
+			bad = append(bad, fmt.Sprintf("%s hace %ds", s.Name, s.AgeSeconds))
+		case freshness.StatusFail:
+			status = worst(status, StatusFail)
+			if s.AgeSeconds < 0 {
+				bad = append(bad, s.Name+" sin datos")
+			} else {
+				bad = append(bad, fmt.Sprintf("%s hace %ds", s.Name, s.AgeSeconds))
+			}
+		}
+	}
+	return Result{Status: status, Detail: detailOrOK(bad)}
+}
+
+func summarizeDrift(findings []audit.Finding, err error) Result {
+	if err != nil {
+		return Result{Status: StatusFail, Detail: err.Error()}
+	}
+	status := StatusOK
+	counts := map[audit.Severity]int{}
+	for _, f := range findings {
+		counts[f.Severity]++
+		switch f.Severity {
+		case audit.SeverityHigh:
+			status = worst(status, StatusFail)
+		case audit.SeverityMed:
+			status = worst(status, StatusWarn)
+		}
+	}
+	if len(findings) == 0 {
+		return Result{Status: status, Detail: "sin drift"}
+	}
+	return Result{Status: status, Detail: fmt.Sprintf("alta=%d media=%d baja=%d",
+		counts[audit.SeverityHigh], counts[audit.SeverityMed], counts[audit.SeverityLow])}
+}
+
+func summarizeLimits(snap limits.Snapshot, err error) Result {
+	if err != nil {
+		return Result{Status: StatusFail, Detail: err.Error()}
+	}
+	if snap.ScrapeAgeSeconds < 0 {
+		return Result{Status: StatusWarn, Detail: "edad de scrape no observable"}
+	}
+	if len(snap.Warnings) > 0 {
+		return Result{Status: StatusWarn, Detail: strings.Join(snap.Warnings, "; ")}
+	}
+	return Result{Status: StatusOK, Detail: fmt.Sprintf("%.2f msg/s, scrape hace %ds", snap.MessageRate, snap.ScrapeAgeSeconds)}
+}
+
+func worst(a, b Status) Status {
+	if b > a {
+		return b
+	}
+	return a
+}
+
+func detailOrOK(parts []string) string {
+	if len(parts) == 0 {
+		return "ok"
+	}
+	return strings.Join(parts, ", ")
+}
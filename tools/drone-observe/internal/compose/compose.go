@@ -0,0 +1,289 @@
+// Archivo: tools/drone-observe/internal/compose/compose.go
+// Rol: leer docker-compose.yml versionado (servicios, puertos, volumenes, comando).
+// No hace: ejecutar docker ni resolver archivos .env; ${VAR:-default} usa el entorno o el default.
+package compose
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"drone-observe/internal/repo"
+)
+
+// Port es un mapeo host:contenedor (short syntax).
+type Port struct {
+	Host      string
+	Container string
+}
+
+// Volume es un montaje origen:destino[:ro] (short syntax).
+type Volume struct {
+	Source   string
+	Target   string
+	ReadOnly bool
+}
+
+type Service struct {
+	Name          string
+	ContainerName string
+	Image         string
+	Build         bool
+	Ports         []Port
+	// Expose son puertos solo visibles en la red de compose (sin publicar en el host).
+	Expose      []string
+	ExtraHosts  []string
+	Volumes     []Volume
+	Command     []string
+	Environment map[string]string
+}
+
+// File es un docker-compose.yml parseado.
+type File struct {
+	Path     string
+	Services map[string]Service
+	// Defaults son los valores por defecto escritos como ${VAR:-default} en el archivo.
+	Defaults map[string]string
+}
+
+type rawService struct {
+	ContainerName string    `yaml:"container_name"`
+	Image         string    `yaml:"image"`
+	Build         yaml.Node `yaml:"build"`
+	Ports         []string  `yaml:"ports"`
+	Expose        []string  `yaml:"expose"`
+	ExtraHosts    []string  `yaml:"extra_hosts"`
+	Volumes       []string  `yaml:"volumes"`
+	Command       yaml.Node `yaml:"command"`
+	Environment   yaml.Node `yaml:"environment"`
+}
+
+// Load lee el compose desde una ruta relativa a la raiz del repo.
+func Load(p string) (File, error) {
+	resolved, err := repo.Resolve(p)
+	if err != nil {
+		return File{}, err
+	}
+	raw, err := os.ReadFile(resolved)
+	if err != nil {
+		return File{}, err
+	}
+	var doc struct {
+		Services map[string]rawService `yaml:"services"`
+	}
+	if err := yaml.Unmarshal(raw, &doc); err != nil {
+		return File{}, fmt.Errorf("%s: %w", p, err)
+	}
+	f := File{Path: resolved, Services: map[string]Service{}, Defaults: map[string]string{}}
+	for _, m := range varRef.FindAllStringSubmatch(string(raw), -1) {
+		if m[2] != "" {
+			f.Defaults[m[1]] = m[3]
+		}
+	}
+	for name, rs := range doc.Services {
+		svc := Service{
+			Name:          name,
+			ContainerName: expand(rs.ContainerName),
+			Image:         expand(rs.Image),
+			Build:         !rs.Build.IsZero(),
+			Environment:   map[string]string{},
+		}
+		for _, p := range rs.Ports {
+			svc.Ports = append(svc.Ports, parsePort(expand(p)))
+		}
+		for _, e := range rs.Expose {
+			svc.Expose = append(svc.Expose, expand(e))
+		}
+		for _, h := range rs.ExtraHosts {
+			svc.ExtraHosts = append(svc.ExtraHosts, expand(h))
+		}
+		for _, v := range rs.Volumes {
+			svc.Volumes = append(svc.Volumes, parseVolume(expand(v)))
+		}
+		svc.Command = parseCommand(rs.Command)
+		parseEnvironment(rs.Environment, svc.Environment)
+		f.Services[name] = svc
+	}
+	return f, nil
+}
+
+// Names lista los servicios ordenados.
+func (f File) Names() []string {
+	out := make([]string, 0, len(f.Services))
+	for n := range f.Services {
+		out = append(out, n)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Lookup busca un servicio por el nombre que resuelve el DNS de compose (servicio o container_name).
+func (f File) Lookup(host string) (Service, bool) {
+	if svc, ok := f.Services[host]; ok {
+		return svc, true
+	}
+	for _, name := range f.Names() {
+		if svc := f.Services[name]; svc.ContainerName != "" && svc.ContainerName == host {
+			return svc, true
+		}
+	}
+	return Service{}, false
+}
+
+// PublishedBy devuelve el servicio que publica el puerto en el host.
+func (f File) PublishedBy(hostPort string) (Service, bool) {
+	for _, name := range f.Names() {
+		for _, p := range f.Services[name].Ports {
+			if p.Host == hostPort {
+				return f.Services[name], true
+			}
+		}
+	}
+	return Service{}, false
+}
+
+// ContainerPorts lista los puertos declarados del lado del contenedor (ports y expose).
+func (s Service) ContainerPorts() []string {
+	var out []string
+	for _, p := range s.Ports {
+		out = append(out, p.Container)
+	}
+	return append(out, s.Expose...)
+}
+
+// HasExtraHost indica si el servicio declara el alias de host en extra_hosts.
+func (s Service) HasExtraHost(host string) bool {
+	for _, h := range s.ExtraHosts {
+		// Acepta "host:ip" y "host=ip" (sintaxis nueva de compose).
+		if name, _, _ := strings.Cut(strings.Replace(h, "=", ":", 1), ":"); name == host {
+			return true
+		}
+	}
+	return false
+}
+
+// HostPath traduce una ruta del contenedor al origen de su volumen: ruta del repo (bind mount) o
+// nombre de volumen (named=true). ok=false si ningun volumen cubre la ruta.
+func (s Service) HostPath(containerPath string) (source string, named bool, ok bool) {
+	best := -1
+	for i, v := range s.Volumes {
+		if v.Target == "" || (containerPath != v.Target && !strings.HasPrefix(containerPath, strings.TrimSuffix(v.Target, "/")+"/")) {
+			continue
+		}
+		if best < 0 || len(v.Target) > len(s.Volumes[best].Target) {
+			best = i
+		}
+	}
+	if best < 0 {
+		return "", false, false
+	}
+	v := s.Volumes[best]
+	rest := strings.TrimPrefix(containerPath, v.Target)
+	if !strings.HasPrefix(v.Source, ".") && !strings.HasPrefix(v.Source, "/") {
+		return v.Source, true, true
+	}
+	return cleanRel(v.Source + rest), false, true
+}
+
+// MountOf busca el servicio que monta el archivo o directorio del repo indicado.
+func (f File) MountOf(repoPath string) (Service, Volume, bool) {
+	want := cleanRel(repoPath)
+	for _, name := range f.Names() {
+		svc := f.Services[name]
+		for _, v := range svc.Volumes {
+			if cleanRel(v.Source) == want {
+				return svc, v, true
+			}
+		}
+	}
+	return Service{}, Volume{}, false
+}
+
+// Flag devuelve el valor de un flag --name=valor o --name valor del command del servicio.
+func (s Service) Flag(name string) (string, bool) {
+	for i, arg := range s.Command {
+		if strings.HasPrefix(arg, name+"=") {
+			return strings.TrimPrefix(arg, name+"="), true
+		}
+		if arg == name && i+1 < len(s.Command) {
+			return s.Command[i+1], true
+		}
+	}
+	return "", false
+}
+
+func cleanRel(p string) string {
+	return strings.TrimPrefix(path.Clean(strings.ReplaceAll(p, "\\", "/")), "./")
+}
+
+func parsePort(s string) Port {
+	s = strings.TrimSuffix(strings.TrimSuffix(s, "/tcp"), "/udp")
+	i := strings.LastIndex(s, ":")
+	if i < 0 {
+		return Port{Container: s}
+	}
+	host := s[:i]
+	// host_ip:host_port:container_port
+	if j := strings.LastIndex(host, ":"); j >= 0 {
+		host = host[j+1:]
+	}
+	return Port{Host: host, Container: s[i+1:]}
+}
+
+func parseVolume(s string) Volume {
+	parts := strings.Split(s, ":")
+	v := Volume{Source: parts[0]}
+	if len(parts) > 1 {
+		v.Target = parts[1]
+	}
+	if len(parts) > 2 {
+		v.ReadOnly = strings.Contains(parts[2], "ro")
+	}
+	return v
+}
+
+func parseCommand(n yaml.Node) []string {
+	switch n.Kind {
+	case yaml.ScalarNode:
+		return strings.Fields(expand(n.Value))
+	case yaml.SequenceNode:
+		var out []string
+		for _, c := range n.Content {
+			out = append(out, expand(c.Value))
+		}
+		return out
+	}
+	return nil
+}
+
+func parseEnvironment(n yaml.Node, out map[string]string) {
+	switch n.Kind {
+	case yaml.SequenceNode:
+		for _, c := range n.Content {
+			k, v, _ := strings.Cut(expand(c.Value), "=")
+			out[k] = v
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			out[n.Content[i].Value] = expand(n.Content[i+1].Value)
+		}
+	}
+}
+
+var varRef = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(:?-([^}]*))?\}`)
+
+// expand resuelve ${VAR} y ${VAR:-default} como docker compose (sin leer .env).
+func expand(s string) string {
+	return varRef.ReplaceAllStringFunc(s, func(m string) string {
+		sub := varRef.FindStringSubmatch(m)
+		if v := os.Getenv(sub[1]); v != "" {
+			return v
+		}
+		return sub[3]
+	})
+}
@@ -7,26 +7,76 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"time"
 )
 
 type Config struct {
 	MQTTHost          string
 	MQTTPort          int
+	MQTTBaseTopic     string
 	BackendMetricsURL string
 	PrometheusURL     string
 	GrafanaURL        string
+	AlertmanagerURL   string
+	GrafanaUser       string
+	GrafanaPassword   string
 	MetricsDocPath    string
+	DashboardsDir     string
+	DocsDir           string
+	ProvisioningDir   string
+	PrometheusConfig  string
+	RulesFile         string
+	ComposeFile       string
+	MosquittoConf     string
+	ACLMatrix         string
+	ChaosScenario     string
 	FreshnessWarnSec  int
 	FreshnessFailSec  int
+	// At es el instante de evaluacion de los checks sobre Prometheus (--at); cero es "ahora".
+	At time.Time
+	// From es el directorio de un bundle extraido (--from); vacio es en vivo. Con From, At es el
+	// instante en que se capturo el bundle.
+	From string
+}
+
+// EvalTime devuelve el instante en que se evaluan las queries y contra el que se miden edades.
+func (c Config) EvalTime() time.Time {
+	if c.At.IsZero() {
+		return time.Now()
+	}
+	return c.At
+}
+
+// Historical indica una evaluacion en el pasado: solo vale lo que Prometheus guardo.
+func (c Config) Historical() bool {
+	return !c.At.IsZero() && !c.Offline()
+}
+
+// Offline indica que los checks leen un bundle capturado en lugar de los endpoints en vivo.
+func (c Config) Offline() bool {
+	return c.From != ""
 }
 
 const (
 	defaultMQTTHost      = "mqtt"
 	defaultMQTTPort      = 1883
+	defaultMQTTBaseTopic = "drone/alpha"
 	defaultBackendPort   = 8080
 	defaultPrometheusURL = "http://localhost:9090"
 	defaultGrafanaURL    = "http://localhost:3000"
+	defaultAlertmanager  = "http://localhost:9093"
+	defaultGrafanaUser   = "admin"
+	defaultGrafanaPass   = "admin"
 	defaultMetricsDoc    = "METRICS.md"
+	defaultDashboardsDir = "observability/grafana/dashboards"
+	defaultDocsDir       = "docs"
+	defaultProvisioning  = "observability/grafana/provisioning"
+	defaultPromConfig    = "observability/prometheus.yml"
+	defaultRulesFile     = "observability/rules/drones.rules.yml"
+	defaultComposeFile   = "docker-compose.yml"
+	defaultMosquittoConf = "mqtt/mosquitto.conf"
+	defaultACLMatrix     = "mqtt/acl/matrix.yml"
+	defaultChaosScenario = "observability/scenarios/pipeline-faults.yml"
 	defaultFreshWarnSec  = 30
 	defaultFreshFailSec  = 120
 )
@@ -49,15 +99,43 @@ func FromEnv() Config {
 	return Config{
 		MQTTHost:          mqttHost,
 		MQTTPort:          mqttPort,
+		MQTTBaseTopic:     getenv("MQTT_BASE_TOPIC", defaultMQTTBaseTopic),
 		BackendMetricsURL: backendURL,
 		PrometheusURL:     promURL,
 		GrafanaURL:        grafanaURL,
+		AlertmanagerURL:   getenv("ALERTMANAGER_URL", defaultAlertmanager),
+		GrafanaUser:       getenv("GRAFANA_ADMIN_USER", defaultGrafanaUser),
+		GrafanaPassword:   getenv("GRAFANA_ADMIN_PASSWORD", defaultGrafanaPass),
 		MetricsDocPath:    getenv("METRICS_DOC", defaultMetricsDoc),
+		DashboardsDir:     getenv("DASHBOARDS_DIR", defaultDashboardsDir),
+		DocsDir:           getenv("DOCS_DIR", defaultDocsDir),
+		ProvisioningDir:   getenv("GRAFANA_PROVISIONING_DIR", defaultProvisioning),
+		PrometheusConfig:  getenv("PROMETHEUS_CONFIG", defaultPromConfig),
+		RulesFile:         getenv("RULES_FILE", defaultRulesFile),
+		ComposeFile:       getenv("COMPOSE_FILE", defaultComposeFile),
+		MosquittoConf:     getenv("MOSQUITTO_CONF", defaultMosquittoConf),
+		ACLMatrix:         getenv("ACL_MATRIX", defaultACLMatrix),
+		ChaosScenario:     getenv("CHAOS_SCENARIO", defaultChaosScenario),
 		FreshnessWarnSec:  freshWarn,
 		FreshnessFailSec:  freshFail,
 	}
 }
 
+// Defaults devuelve el default de cada variable que lee FromEnv; drift los cruza con ${VAR:-default} de compose.
+func Defaults() map[string]string {
+	return map[string]string{
+		"MQTT_HOST":              defaultMQTTHost,
+		"MQTT_PORT":              strconv.Itoa(defaultMQTTPort),
+		"MQTT_BASE_TOPIC":        defaultMQTTBaseTopic,
+		"BACKEND_HTTP_PORT":      strconv.Itoa(defaultBackendPort),
+		"PROMETHEUS_URL":         defaultPrometheusURL,
+		"GRAFANA_URL":            defaultGrafanaURL,
+		"ALERTMANAGER_URL":       defaultAlertmanager,
+		"GRAFANA_ADMIN_USER":     defaultGrafanaUser,
+		"GRAFANA_ADMIN_PASSWORD": defaultGrafanaPass,
+	}
+}
+
 func getenv(key, fallback string) string {
 	if v := os.Getenv(key); v != "" {
 		return v
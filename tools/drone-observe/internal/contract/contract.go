@@ -0,0 +1,222 @@
+// Archivo: tools/drone-observe/internal/contract/contract.go
+// Rol: leer el contrato de METRICS.md (catalogo actual y metricas FUTURO).
+// No hace: validacion contra Prometheus; eso vive en audit y ui.
+package contract
+
+import (
+	"bufio"
+	"regexp"
+	"strings"
+
+	"drone-observe/internal/repo"
+)
+
+// Metric es una entrada del contrato.
+type Metric struct {
+	Name        string
+	Type        string
+	Unit        string
+	Description string
+	Future      bool
+}
+
+// PanelSpec es un panel de "Dashboards minimos" con su PromQL exacto.
+type PanelSpec struct {
+	Title  string
+	Expr   string
+	Future bool
+}
+
+// AlertSpec es una alerta recomendada de la seccion "Alertas" con su regla completa.
+type AlertSpec struct {
+	Name      string
+	Expr      string
+	For       string
+	Severity  string
+	SummaryES string
+	SummaryEN string
+	Future    bool
+}
+
+// Catalog agrupa las metricas del estado actual, las marcadas FUTURO, los paneles minimos y las alertas.
+type Catalog struct {
+	Path    string
+	Current []Metric
+	Future  []Metric
+	Panels  []PanelSpec
+	Alerts  []AlertSpec
+}
+
+type section int
+
+const (
+	sectionOther section = iota
+	sectionCatalog
+	sectionFuture
+	sectionDashboards
+	sectionAlerts
+)
+
+// futureBullet reconoce "- `nombre` (tipo, unidad): descripcion".
+var futureBullet = regexp.MustCompile("^-\\s*`([a-zA-Z_:][a-zA-Z0-9_:]*)`\\s*\\(([^)]*)\\)\\s*:?\\s*(.*)$")
+
+// panelBullet reconoce "- Titulo (FUTURO): `promql`".
+var panelBullet = regexp.MustCompile("^-\\s*(.+?)(\\s*\\(FUTURO\\))?\\s*:\\s*`(.+)`\\s*$")
+
+// PARTE CRITICA **********************
+// El estado actual sale solo de la tabla de la seccion "Catalogo"; FUTURO solo de su seccion de bullets;
+// los paneles minimos solo de "Dashboards minimos"; las alertas solo de la tabla de "Alertas".
+// Si se leen tablas o bullets de otras secciones, ejemplos de PromQL o alertas pasan a ser contrato.
+// No inferir metricas desde texto libre.
+// FIN DE PARTE CRITICA ****************
+func Load(path string) (Catalog, error) {
+	f, usedPath, err := repo.Open(path)
+	if err != nil {
+		return Catalog{}, err
+	}
+	defer f.Close()
+
+	cat := Catalog{Path: usedPath}
+	current := sectionOther
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "## ") {
+			current = classifySection(line)
+			continue
+		}
+		switch current {
+		case sectionCatalog:
+			if m, ok := parseTableRow(line); ok {
+				cat.Current = append(cat.Current, m)
+			}
+		case sectionFuture:
+			if m, ok := parseFutureBullet(line); ok {
+				cat.Future = append(cat.Future, m)
+			}
+		case sectionDashboards:
+			if match := panelBullet.FindStringSubmatch(line); match != nil {
+				cat.Panels = append(cat.Panels, PanelSpec{Title: match[1], Expr: match[3], Future: match[2] != ""})
+			}
+		case sectionAlerts:
+			if a, ok := parseAlertRow(line); ok {
+				cat.Alerts = append(cat.Alerts, a)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return Catalog{}, err
+	}
+	return cat, nil
+}
+
+func classifySection(header string) section {
+	title := strings.ToLower(header)
+	switch {
+	case strings.Contains(title, "catalogo"):
+		return sectionCatalog
+	case strings.Contains(title, "metricas") && strings.Contains(title, "futuro"):
+		return sectionFuture
+	case strings.Contains(title, "dashboards minimos"):
+		return sectionDashboards
+	case strings.Contains(title, "alertas"):
+		return sectionAlerts
+	}
+	return sectionOther
+}
+
+// tableCells separa una fila markdown; devuelve nil para encabezados y separadores.
+func tableCells(line, header string) []string {
+	if !strings.HasPrefix(line, "|") {
+		return nil
+	}
+	cols := strings.Split(strings.Trim(line, "|"), "|")
+	for i := range cols {
+		cols[i] = strings.TrimSpace(cols[i])
+	}
+	if cols[0] == "" || cols[0] == header || strings.HasPrefix(cols[0], "---") {
+		return nil
+	}
+	return cols
+}
+
+func parseTableRow(line string) (Metric, bool) {
+	cols := tableCells(line, "nombre")
+	if cols == nil {
+		return Metric{}, false
+	}
+	name := cols[0]
+	m := Metric{Name: name}
+	if len(cols) > 1 {
+		m.Type = cols[1]
+	}
+	if len(cols) > 2 {
+		m.Unit = cols[2]
+	}
+	if len(cols) > 3 {
+		m.Description = cols[3]
+	}
+	return m, true
+}
+
+func parseAlertRow(line string) (AlertSpec, bool) {
+	cols := tableCells(line, "alerta")
+	if len(cols) < 7 {
+		return AlertSpec{}, false
+	}
+	return AlertSpec{
+		Name:      cols[0],
+		Expr:      strings.Trim(cols[1], "`"),
+		For:       cols[2],
+		Severity:  cols[3],
+		SummaryES: cols[4],
+		SummaryEN: cols[5],
+		Future:    strings.EqualFold(cols[6], "FUTURO"),
+	}, true
+}
+
+func parseFutureBullet(line string) (Metric, bool) {
+	match := futureBullet.FindStringSubmatch(line)
+	if match == nil {
+		return Metric{}, false
+	}
+	m := Metric{Name: match[1], Description: strings.TrimSpace(match[3]), Future: true}
+	var attrs []string
+	for _, a := range strings.Split(match[2], ",") {
+		a = strings.TrimSpace(a)
+		if a != "" && !strings.EqualFold(a, "FUTURO") {
+			attrs = append(attrs, a)
+		}
+	}
+	if len(attrs) > 0 {
+		m.Type = attrs[0]
+	}
+	if len(attrs) > 1 {
+		m.Unit = attrs[1]
+	}
+	return m, true
+}
+
+// CurrentNames lista los nombres del catalogo actual en orden del documento.
+func (c Catalog) CurrentNames() []string {
+	out := make([]string, 0, len(c.Current))
+	for _, m := range c.Current {
+		out = append(out, m.Name)
+	}
+	return out
+}
+
+// Lookup busca una metrica en el catalogo actual y luego en FUTURO.
+func (c Catalog) Lookup(name string) (Metric, bool) {
+	for _, m := range c.Current {
+		if m.Name == name {
+			return m, true
+		}
+	}
+	for _, m := range c.Future {
+		if m.Name == name {
+			return m, true
+		}
+	}
+	return Metric{}, false
+}
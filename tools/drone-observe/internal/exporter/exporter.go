@@ -0,0 +1,194 @@
+// Archivo: tools/drone-observe/internal/exporter/exporter.go
+// Rol: modo servidor que ejecuta checks de gobernanza periodicamente y expone /metrics, /probe y /healthz.
+// No hace: alertas propias ni almacenamiento historico; eso es trabajo de Prometheus.
+package exporter
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"drone-observe/internal/checks"
+	"drone-observe/internal/config"
+	"drone-observe/internal/probe"
+)
+
+const (
+	DefaultListen   = ":9109"
+	DefaultInterval = 30 * time.Second
+
+	defaultProbeTimeout = 5 * time.Second
+)
+
+// Server mantiene el ultimo resultado de cada check y lo expone en formato Prometheus.
+type Server struct {
+	cfg      config.Config
+	interval time.Duration
+
+	mu      sync.RWMutex
+	results map[string]checks.Result
+	lastRun time.Time
+	runs    uint64
+}
+
+func New(cfg config.Config, interval time.Duration) *Server {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	return &Server{cfg: cfg, interval: interval, results: map[string]checks.Result{}}
+}
+
+// Handler expone /metrics, /probe y /healthz.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", s.handleMetrics)
+	mux.HandleFunc("/probe", handleProbe)
+	mux.HandleFunc("/healthz", s.handleHealthz)
+	return mux
+}
+
+// Run ejecuta los checks en bucle hasta que se cancele el contexto.
+func (s *Server) Run(ctx context.Context) {
+	s.runOnce()
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.runOnce()
+		}
+	}
+}
+
+func (s *Server) runOnce() {
+	for _, name := range checks.Names {
+		res := checks.Run(s.cfg, name)
+		s.mu.Lock()
+		s.results[name] = res
+		s.mu.Unlock()
+	}
+	s.mu.Lock()
+	s.lastRun = time.Now()
+	s.runs++
+	s.mu.Unlock()
+}
+
+// PARTE CRITICA **********************
+// Solo se exponen labels check y status: cardinalidad fija y conocida.
+// Si se agregan detalles (componente, metrica, mensaje) como labels, se rompe METRICS.md.
+// No exponer texto libre en labels; el detalle se consulta con los comandos interactivos.
+// FIN DE PARTE CRITICA ****************
+func (s *Server) handleMetrics(w http.ResponseWriter, _ *http.Request) {
+	s.mu.RLock()
+	results := make([]checks.Result, 0, len(s.results))
+	for _, r := range s.results {
+		results = append(results, r)
+	}
+	runs := s.runs
+	s.mu.RUnlock()
+	sort.Slice(results, func(i, j int) bool { return results[i].Check < results[j].Check })
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	WriteMetrics(w, results, runs)
+}
+
+// WriteMetrics escribe los resultados en formato de exposicion de texto Prometheus.
+func WriteMetrics(w io.Writer, results []checks.Result, runs uint64) {
+	fmt.Fprintln(w, "# HELP drone_observe_check_status Estado del check de gobernanza (1 en el estado actual, 0 en el resto).")
+	fmt.Fprintln(w, "# TYPE drone_observe_check_status gauge")
+	for _, r := range results {
+		for _, st := range checks.Statuses {
+			v := 0
+			if r.Status == st {
+				v = 1
+			}
+			fmt.Fprintf(w, "drone_observe_check_status{check=%q,status=%q} %d\n", r.Check, st.String(), v)
+		}
+	}
+	fmt.Fprintln(w, "# HELP drone_observe_check_last_run_timestamp_seconds Inicio de la ultima ejecucion del check (unix).")
+	fmt.Fprintln(w, "# TYPE drone_observe_check_last_run_timestamp_seconds gauge")
+	for _, r := range results {
+		fmt.Fprintf(w, "drone_observe_check_last_run_timestamp_seconds{check=%q} %d\n", r.Check, r.RanAt.Unix())
+	}
+	fmt.Fprintln(w, "# HELP drone_observe_check_duration_seconds Duracion de la ultima ejecucion del check.")
+	fmt.Fprintln(w, "# TYPE drone_observe_check_duration_seconds gauge")
+	for _, r := range results {
+		fmt.Fprintf(w, "drone_observe_check_duration_seconds{check=%q} %.3f\n", r.Check, r.Duration.Seconds())
+	}
+	fmt.Fprintln(w, "# HELP drone_observe_check_runs_total Rondas completas de checks desde el arranque.")
+	fmt.Fprintln(w, "# TYPE drone_observe_check_runs_total counter")
+	fmt.Fprintf(w, "drone_observe_check_runs_total %d\n", runs)
+}
+
+// handleProbe ejecuta un probe por request: /probe?target=<host:port|url>&module=<modulo>.
+func handleProbe(w http.ResponseWriter, r *http.Request) {
+	module := r.URL.Query().Get("module")
+	target := r.URL.Query().Get("target")
+
+	ctx, cancel := context.WithTimeout(r.Context(), probeTimeout(r))
+	defer cancel()
+
+	res, err := probe.Run(ctx, module, target)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	probe.WriteMetrics(w, res)
+}
+
+// probeTimeout usa el timeout de scrape que envia Prometheus, con margen para responder.
+func probeTimeout(r *http.Request) time.Duration {
+	if v := r.Header.Get("X-Prometheus-Scrape-Timeout-Seconds"); v != "" {
+		if secs, err := strconv.ParseFloat(v, 64); err == nil && secs > 1 {
+			return time.Duration((secs - 0.5) * float64(time.Second))
+		}
+	}
+	return defaultProbeTimeout
+}
+
+// handleHealthz responde 200 si el bucle de checks completo una ronda reciente.
+func (s *Server) handleHealthz(w http.ResponseWriter, _ *http.Request) {
+	s.mu.RLock()
+	last := s.lastRun
+	s.mu.RUnlock()
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	if last.IsZero() {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		fmt.Fprintln(w, "starting")
+		return
+	}
+	// Margen: dos intervalos mas el timeout acumulado de los checks.
+	if time.Since(last) > 2*s.interval+30*time.Second {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		fmt.Fprintf(w, "stale: ultima ronda hace %s\n", time.Since(last).Round(time.Second))
+		return
+	}
+	fmt.Fprintln(w, "ok")
+}
+
+// Serve arranca el bucle de checks y el servidor HTTP; bloquea hasta error.
+func Serve(cfg config.Config, listen string, interval time.Duration) error {
+	if listen == "" {
+		listen = DefaultListen
+	}
+	s := New(cfg, interval)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.Run(ctx)
+
+	srv := &http.Server{
+		Addr:              listen,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	return srv.ListenAndServe()
+}
@@ -0,0 +1,365 @@
+// Archivo: tools/drone-observe/internal/fleet/fleet.go
+// Rol: vista por dron agrupando series por label drone_id y topics drone/<id>/... observados.
+// No hace: control de drones ni persistencia de historicos.
+package fleet
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"drone-observe/internal/config"
+	"drone-observe/internal/freshness"
+	"drone-observe/internal/mqttclient"
+	"drone-observe/internal/prometheus"
+	"drone-observe/internal/queries"
+)
+
+// DroneLabel es el label de baja cardinalidad previsto en METRICS.md para multi-dron.
+const DroneLabel = "drone_id"
+
+// Drone resume el estado observado de un dron.
+type Drone struct {
+	ID          string
+	Battery     float64
+	HasBattery  bool
+	MessageRate float64
+	HasRate     bool
+	AgeSeconds  int
+	Freshness   freshness.Status
+	MLState     float64
+	HasML       bool
+	LastEvent   string
+	LastEventAt time.Time
+	Sources     []string
+	Series      []string
+}
+
+// Result agrupa la flota descubierta y avisos de descubrimiento.
+type Result struct {
+	Drones   []Drone
+	Warnings []string
+}
+
+// Column identifica la columna de orden de la tabla.
+type Column int
+
+const (
+	ColumnID Column = iota
+	ColumnBattery
+	ColumnRate
+	ColumnFreshness
+	ColumnML
+	ColumnEvent
+	columnCount
+)
+
+func (c Column) String() string {
+	switch c {
+	case ColumnID:
+		return "ID"
+	case ColumnBattery:
+		return "Bateria"
+	case ColumnRate:
+		return "Msg/s"
+	case ColumnFreshness:
+		return "Freshness"
+	case ColumnML:
+		return "ML"
+	default:
+		return "Ultimo evento"
+	}
+}
+
+// Next devuelve la siguiente columna de orden (ciclica).
+func (c Column) Next() Column {
+	return (c + 1) % columnCount
+}
+
+type fleetQuery struct {
+	expr  string
+	rule  prometheus.Rule
+	apply func(d *Drone, s prometheus.Series, now time.Time)
+}
+
+var fleetQueries = []fleetQuery{
+	{
+		expr: queries.BatteryPct.Expr,
+		rule: prometheus.RuleMin,
+		apply: func(d *Drone, s prometheus.Series, _ time.Time) {
+			d.Battery, d.HasBattery = s.Value, true
+		},
+	},
+	{
+		expr: queries.BatteryTimestamp.Expr,
+		rule: prometheus.RuleMin,
+		apply: func(d *Drone, s prometheus.Series, now time.Time) {
+			d.AgeSeconds = int(now.Sub(time.Unix(int64(s.Value), 0)).Seconds())
+		},
+	},
+	{
+		expr: queries.MessageRate.Expr,
+		rule: prometheus.RuleSum,
+		apply: func(d *Drone, s prometheus.Series, _ time.Time) {
+			d.MessageRate, d.HasRate = s.Value, true
+		},
+	},
+	{
+		expr: queries.MLState.Expr,
+		rule: prometheus.RuleMax,
+		apply: func(d *Drone, s prometheus.Series, _ time.Time) {
+			d.MLState, d.HasML = s.Value, true
+		},
+	},
+}
+
+// PARTE CRITICA **********************
+// Los drones se descubren solo por el label drone_id y por topics drone/<id>/... observados.
+// Las series sin drone_id (estado actual, single-dron) se asignan al id de MQTT_BASE_TOPIC.
+// No inventar drones ni completar valores faltantes; "sin datos" debe verse como tal.
+// FIN DE PARTE CRITICA ****************
+func Discover(cfg config.Config, window time.Duration) Result {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	defaultID := BaseTopicID(cfg.MQTTBaseTopic)
+	drones := map[string]*Drone{}
+	get := func(id string) *Drone {
+		d, ok := drones[id]
+		if !ok {
+			d = &Drone{ID: id, AgeSeconds: -1}
+			drones[id] = d
+		}
+		return d
+	}
+
+	var warnings []string
+	now := cfg.EvalTime()
+	for _, q := range fleetQueries {
+		vec, err := prometheus.QueryVectorAt(ctx, cfg.PrometheusURL, q.expr, now)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("%s: %s", q.expr, err.Error()))
+			continue
+		}
+		byDrone := map[string]prometheus.Vector{}
+		unlabeled := 0
+		for _, s := range vec {
+			id := s.Labels[DroneLabel]
+			if id == "" {
+				id = defaultID
+				unlabeled++
+			}
+			byDrone[id] = append(byDrone[id], s)
+		}
+		if unlabeled > 0 && len(byDrone) > 1 {
+			warnings = append(warnings, fmt.Sprintf("%s: %d series sin %s asignadas a %s", q.expr, unlabeled, DroneLabel, defaultID))
+		}
+		for id, group := range byDrone {
+			d := get(id)
+			agg, _ := group.Aggregate(q.rule)
+			q.apply(d, agg, now)
+			addSource(d, "prometheus")
+			if note := group.MultiSeriesNote(q.rule); note != "" {
+				warnings = append(warnings, fmt.Sprintf("%s [%s]: %s", q.expr, id, note))
+			}
+			for _, s := range group {
+				addSeries(d, s.LabelString())
+			}
+		}
+	}
+
+	var observed map[string]*mqttObservation
+	var err error
+	if cfg.Historical() {
+		// La observacion MQTT es en vivo: en --at solo cuenta lo que Prometheus guardo.
+		warnings = append(warnings, "MQTT: sin observacion en vivo (evaluacion historica)")
+	} else if observed, err = observeMQTT(cfg, defaultID, window); err != nil {
+		warnings = append(warnings, "MQTT: "+err.Error())
+	}
+	for id, o := range observed {
+		d := get(id)
+		addSource(d, "mqtt")
+		if !o.lastTelemetry.IsZero() {
+			age := int(now.Sub(o.lastTelemetry).Seconds())
+			if d.AgeSeconds < 0 || age < d.AgeSeconds {
+				d.AgeSeconds = age
+			}
+		}
+		if !d.HasRate && o.telemetryCount+o.eventCount > 0 {
+			d.MessageRate = float64(o.telemetryCount+o.eventCount) / window.Seconds()
+			d.HasRate = true
+		}
+		if !d.HasBattery && o.hasBattery {
+			d.Battery, d.HasBattery = o.battery, true
+		}
+		if o.lastEvent != "" {
+			d.LastEvent = o.lastEvent
+			d.LastEventAt = o.lastEventAt
+		}
+	}
+
+	out := make([]Drone, 0, len(drones))
+	for _, d := range drones {
+		if d.AgeSeconds >= 0 {
+			d.Freshness = freshness.Classify(cfg, d.AgeSeconds)
+		} else {
+			d.Freshness = freshness.StatusFail
+		}
+		out = append(out, *d)
+	}
+	Sort(out, ColumnID, false)
+	sort.Strings(warnings)
+	return Result{Drones: out, Warnings: warnings}
+}
+
+// BaseTopicID deriva el id del dron del topic base (drone/alpha -> alpha).
+func BaseTopicID(base string) string {
+	parts := strings.Split(strings.Trim(base, "/"), "/")
+	return parts[len(parts)-1]
+}
+
+// Sort ordena drones por columna; desc invierte el orden. El id desempata.
+func Sort(drones []Drone, col Column, desc bool) {
+	sort.SliceStable(drones, func(i, j int) bool {
+		// Descendente es ascendente con los operandos invertidos; negar less no es un orden estricto
+		// (dos drones iguales quedarian "menores" entre si).
+		if desc {
+			return droneLess(drones[j], drones[i], col)
+		}
+		return droneLess(drones[i], drones[j], col)
+	})
+}
+
+func droneLess(a, b Drone, col Column) bool {
+	var less, equal bool
+	switch col {
+	case ColumnBattery:
+		less, equal = a.Battery < b.Battery, a.Battery == b.Battery
+	case ColumnRate:
+		less, equal = a.MessageRate < b.MessageRate, a.MessageRate == b.MessageRate
+	case ColumnFreshness:
+		less, equal = a.AgeSeconds < b.AgeSeconds, a.AgeSeconds == b.AgeSeconds
+	case ColumnML:
+		less, equal = a.MLState < b.MLState, a.MLState == b.MLState
+	case ColumnEvent:
+		less, equal = a.LastEventAt.Before(b.LastEventAt), a.LastEventAt.Equal(b.LastEventAt)
+	}
+	if col == ColumnID || equal {
+		less = a.ID < b.ID
+	}
+	return less
+}
+
+type mqttObservation struct {
+	telemetryCount int
+	eventCount     int
+	lastTelemetry  time.Time
+	battery        float64
+	hasBattery     bool
+	lastEvent      string
+	lastEventAt    time.Time
+}
+
+// observeMQTT escucha telemetria y eventos durante la ventana y agrupa por id de topic.
+func observeMQTT(cfg config.Config, defaultID string, window time.Duration) (map[string]*mqttObservation, error) {
+	client, err := mqttclient.Dial(cfg.MQTTHost, cfg.MQTTPort, mqttclient.Options{ClientID: "drone-observe-fleet"})
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+
+	filters := []string{"drone/+/telemetry", "drone/+/event"}
+	base := strings.Trim(cfg.MQTTBaseTopic, "/")
+	if !mqttclient.MatchTopic("drone/+", base) {
+		filters = append(filters, base+"/telemetry", base+"/event")
+	}
+	for _, f := range filters {
+		if code, err := client.Subscribe(f, 1); err != nil {
+			return nil, err
+		} else if code == mqttclient.SubackFailure {
+			return nil, fmt.Errorf("suscripcion rechazada: %s", f)
+		}
+	}
+
+	out := map[string]*mqttObservation{}
+	timer := time.NewTimer(window)
+	defer timer.Stop()
+	for {
+		select {
+		case msg, ok := <-client.Messages():
+			if !ok {
+				return out, client.Err()
+			}
+			id, kind := topicID(msg.Topic, base, defaultID)
+			if id == "" {
+				continue
+			}
+			o, ok := out[id]
+			if !ok {
+				o = &mqttObservation{}
+				out[id] = o
+			}
+			recordMessage(o, kind, msg)
+		case <-timer.C:
+			return out, nil
+		}
+	}
+}
+
+func topicID(topic, base, defaultID string) (string, string) {
+	parts := strings.Split(topic, "/")
+	kind := parts[len(parts)-1]
+	if kind != "telemetry" && kind != "event" {
+		return "", ""
+	}
+	if len(parts) == 3 && parts[0] == "drone" {
+		return parts[1], kind
+	}
+	if strings.TrimSuffix(topic, "/"+kind) == base {
+		return defaultID, kind
+	}
+	return "", ""
+}
+
+func recordMessage(o *mqttObservation, kind string, msg mqttclient.Message) {
+	var payload struct {
+		BatteryPct *float64 `json:"battery_pct"`
+		Type       string   `json:"type"`
+	}
+	_ = json.Unmarshal(msg.Payload, &payload)
+
+	if kind == "telemetry" {
+		o.telemetryCount++
+		o.lastTelemetry = msg.Received
+		if payload.BatteryPct != nil {
+			o.battery, o.hasBattery = *payload.BatteryPct, true
+		}
+		return
+	}
+	o.eventCount++
+	if payload.Type != "" {
+		o.lastEvent = payload.Type
+		o.lastEventAt = msg.Received
+	}
+}
+
+func addSource(d *Drone, src string) {
+	for _, s := range d.Sources {
+		if s == src {
+			return
+		}
+	}
+	d.Sources = append(d.Sources, src)
+}
+
+func addSeries(d *Drone, labels string) {
+	for _, s := range d.Series {
+		if s == labels {
+			return
+		}
+	}
+	d.Series = append(d.Series, labels)
+}
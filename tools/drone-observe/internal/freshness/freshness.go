@@ -9,6 +9,8 @@ import (
 
 	"drone-observe/internal/config"
 	"drone-observe/internal/prometheus"
+	"drone-observe/internal/queries"
+	"drone-observe/internal/source"
 )
 
 type Status int
@@ -19,30 +21,58 @@ const (
 	StatusFail
 )
 
+// MarshalText serializa el estado como texto en salidas JSON (bundle).
+func (s Status) MarshalText() ([]byte, error) {
+	switch s {
+	case StatusOK:
+		return []byte("ok"), nil
+	case StatusWarn:
+		return []byte("warn"), nil
+	default:
+		return []byte("fail"), nil
+	}
+}
+
+// SeriesAge es la recencia de una serie concreta de la metrica.
+type SeriesAge struct {
+	Labels     string
+	AgeSeconds int
+	Status     Status
+}
+
 type Signal struct {
 	Name       string
 	AgeSeconds int
 	Status     Status
 	Detail     string
+	Series     []SeriesAge
+	Warning    string
 }
 
+// Regla de agregacion: la serie mas antigua (menor timestamp(...)) determina la edad del signal.
+const aggregateRule = prometheus.RuleMin
+
 // PARTE CRITICA **********************
-// La recencia se calcula con timestamps reales de Prometheus.
-// Si se cambia a valores inferidos, se rompe la capacidad de detectar datos viejos.
+// La recencia se calcula con timestamps reales de Prometheus: el valor de timestamp(...) es el instante
+// de la ultima muestra, y la edad se mide contra el instante de evaluacion (ahora o --at).
+// El timestamp de una consulta instantanea es el instante de evaluacion, no el de la muestra: no usarlo.
 // No usar labels ni nuevas metricas para este calculo.
 // FIN DE PARTE CRITICA ****************
 func Check(cfg config.Config) []Signal {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
+	at := cfg.EvalTime()
 	return []Signal{
-		checkMetric(ctx, cfg, "drone_battery_last_pct", "Bateria"),
-		checkMetric(ctx, cfg, "mqtt_messages_total", "MQTT mensajes"),
+		checkMetric(ctx, cfg, at, queries.BatteryTimestamp.Expr, "Bateria"),
+		checkMetric(ctx, cfg, at, queries.MessagesTimestamp.Expr, "MQTT mensajes"),
 	}
 }
 
-func checkMetric(ctx context.Context, cfg config.Config, metric, label string) Signal {
-	_, ts, ok, err := prometheus.QueryInstantWithTimestamp(ctx, cfg.PrometheusURL, metric)
+func checkMetric(ctx context.Context, cfg config.Config, at time.Time, expr, label string) Signal {
+	vec, err := source.For(cfg).Prometheus.Query(ctx, expr, at)
+	// Con timestamp(...) el valor es el instante de la muestra: la mas antigua es el menor valor.
+	oldest, ok := vec.Aggregate(aggregateRule)
 	if err != nil || !ok {
 		return Signal{
 			Name:       label,
@@ -52,18 +82,38 @@ func checkMetric(ctx context.Context, cfg config.Config, metric, label string) S
 		}
 	}
 
-	age := int(time.Since(time.Unix(int64(ts), 0)).Seconds())
-	status := StatusOK
-	if age >= cfg.FreshnessFailSec {
-		status = StatusFail
-	} else if age >= cfg.FreshnessWarnSec {
-		status = StatusWarn
+	series := make([]SeriesAge, 0, len(vec))
+	for _, s := range vec {
+		age := ageSeconds(at, s.Value)
+		series = append(series, SeriesAge{
+			Labels:     s.LabelString(),
+			AgeSeconds: age,
+			Status:     Classify(cfg, age),
+		})
 	}
 
+	age := ageSeconds(at, oldest.Value)
 	return Signal{
 		Name:       label,
 		AgeSeconds: age,
-		Status:     status,
+		Status:     Classify(cfg, age),
 		Detail:     "ok",
+		Series:     series,
+		Warning:    vec.MultiSeriesNote(aggregateRule),
+	}
+}
+
+func ageSeconds(at time.Time, ts float64) int {
+	return int(at.Sub(time.UnixMilli(int64(ts * 1000))).Seconds())
+}
+
+// Classify aplica los umbrales FRESHNESS_WARN_SEC/FRESHNESS_FAIL_SEC a una edad en segundos.
+func Classify(cfg config.Config, age int) Status {
+	if age >= cfg.FreshnessFailSec {
+		return StatusFail
+	}
+	if age >= cfg.FreshnessWarnSec {
+		return StatusWarn
 	}
+	return StatusOK
 }
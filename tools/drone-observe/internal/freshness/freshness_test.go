@@ -0,0 +1,80 @@
+package freshness
+
+import (
+	"testing"
+	"time"
+
+	"drone-observe/internal/queries"
+	"drone-observe/internal/testenv"
+)
+
+func TestCheck(t *testing.T) {
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	ago := func(d time.Duration) float64 { return float64(at.Add(-d).Unix()) }
+	tests := []struct {
+		name        string
+		battery     []testenv.Sample
+		messages    []testenv.Sample
+		wantBattery Status
+		wantAge     int
+		wantMsgs    Status
+		wantWarning string
+	}{
+		{
+			name:        "reciente",
+			battery:     []testenv.Sample{{Value: ago(5 * time.Second)}},
+			messages:    []testenv.Sample{{Value: ago(5 * time.Second)}},
+			wantBattery: StatusOK, wantAge: 5, wantMsgs: StatusOK,
+		},
+		{
+			name:        "en el umbral de warn",
+			battery:     []testenv.Sample{{Value: ago(30 * time.Second)}},
+			messages:    []testenv.Sample{{Value: ago(29 * time.Second)}},
+			wantBattery: StatusWarn, wantAge: 30, wantMsgs: StatusOK,
+		},
+		{
+			name:        "vieja",
+			battery:     []testenv.Sample{{Value: ago(10 * time.Minute)}},
+			messages:    []testenv.Sample{{Value: ago(119 * time.Second)}},
+			wantBattery: StatusFail, wantAge: 600, wantMsgs: StatusWarn,
+		},
+		{
+			name: "la serie mas antigua manda",
+			battery: []testenv.Sample{
+				{Labels: map[string]string{"drone_id": "alpha"}, Value: ago(3 * time.Second)},
+				{Labels: map[string]string{"drone_id": "bravo"}, Value: ago(45 * time.Second)},
+			},
+			messages:    []testenv.Sample{{Value: ago(time.Second)}},
+			wantBattery: StatusWarn, wantAge: 45, wantMsgs: StatusOK,
+			wantWarning: "2 series (esperada 1), regla=minimo",
+		},
+		{
+			name:        "sin datos",
+			messages:    []testenv.Sample{{Value: ago(time.Second)}},
+			wantBattery: StatusFail, wantAge: -1, wantMsgs: StatusOK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := testenv.NewStack(t)
+			s.Prometheus.Set(queries.BatteryTimestamp.Expr, tt.battery...)
+			s.Prometheus.Set(queries.MessagesTimestamp.Expr, tt.messages...)
+			cfg := s.Config()
+			cfg.At = at
+			signals := Check(cfg)
+			if len(signals) != 2 {
+				t.Fatalf("signals = %+v", signals)
+			}
+			battery, msgs := signals[0], signals[1]
+			if battery.Status != tt.wantBattery || battery.AgeSeconds != tt.wantAge {
+				t.Errorf("Bateria = %v edad %d; esperado %v edad %d", battery.Status, battery.AgeSeconds, tt.wantBattery, tt.wantAge)
+			}
+			if battery.Warning != tt.wantWarning {
+				t.Errorf("Bateria aviso = %q; esperado %q", battery.Warning, tt.wantWarning)
+			}
+			if msgs.Status != tt.wantMsgs {
+				t.Errorf("MQTT mensajes = %v (edad %d); esperado %v", msgs.Status, msgs.AgeSeconds, tt.wantMsgs)
+			}
+		})
+	}
+}
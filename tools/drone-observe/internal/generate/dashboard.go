@@ -0,0 +1,381 @@
+// Archivo: tools/drone-observe/internal/generate/dashboard.go
+// Rol: generar un dashboard Grafana (JSON de provisioning) desde el contrato METRICS.md.
+// No hace: subir el dashboard a Grafana ni editar dashboards existentes.
+package generate
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"drone-observe/internal/contract"
+	"drone-observe/internal/promql"
+)
+
+// DashboardOptions controla identidad y alcance del dashboard generado.
+type DashboardOptions struct {
+	UID           string
+	Title         string
+	IncludeFuture bool
+}
+
+const (
+	DefaultDashboardUID   = "drones-contract"
+	DefaultDashboardTitle = "Drones - Contrato de metricas"
+
+	// datasourceUID coincide con el uid provisionado en observability/grafana/provisioning.
+	datasourceUID = "Prometheus"
+	gridWidth     = 24
+)
+
+// Los tipos siguen el orden de claves de los dashboards versionados; se usan structs (no maps)
+// para que encoding/json emita siempre el mismo orden.
+type dashboardJSON struct {
+	UID           string      `json:"uid"`
+	Title         string      `json:"title"`
+	Timezone      string      `json:"timezone"`
+	SchemaVersion int         `json:"schemaVersion"`
+	Version       int         `json:"version"`
+	Refresh       string      `json:"refresh"`
+	Tags          []string    `json:"tags"`
+	Panels        []panelJSON `json:"panels"`
+}
+
+type panelJSON struct {
+	ID          int             `json:"id"`
+	Type        string          `json:"type"`
+	Title       string          `json:"title"`
+	Description string          `json:"description,omitempty"`
+	GridPos     gridPos         `json:"gridPos"`
+	Datasource  datasourceRef   `json:"datasource"`
+	Targets     []targetJSON    `json:"targets"`
+	Options     *statOptions    `json:"options,omitempty"`
+	FieldConfig fieldConfigJSON `json:"fieldConfig"`
+}
+
+type gridPos struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+	W int `json:"w"`
+	H int `json:"h"`
+}
+
+type datasourceRef struct {
+	Type string `json:"type"`
+	UID  string `json:"uid"`
+}
+
+type targetJSON struct {
+	Expr  string `json:"expr"`
+	RefID string `json:"refId"`
+}
+
+type statOptions struct {
+	ReduceOptions reduceOptions `json:"reduceOptions"`
+	Orientation   string        `json:"orientation"`
+	TextMode      string        `json:"textMode"`
+	ColorMode     string        `json:"colorMode"`
+}
+
+type reduceOptions struct {
+	Calcs  []string `json:"calcs"`
+	Fields string   `json:"fields"`
+	Values bool     `json:"values"`
+}
+
+type fieldConfigJSON struct {
+	Defaults  fieldDefaults `json:"defaults"`
+	Overrides []any         `json:"overrides"`
+}
+
+type fieldDefaults struct {
+	Unit       string          `json:"unit"`
+	Min        *float64        `json:"min,omitempty"`
+	Max        *float64        `json:"max,omitempty"`
+	Mappings   []valueMapping  `json:"mappings,omitempty"`
+	Thresholds *thresholdsJSON `json:"thresholds,omitempty"`
+}
+
+type valueMapping struct {
+	Type    string                  `json:"type"`
+	Options map[string]mappingValue `json:"options"`
+}
+
+type mappingValue struct {
+	Text  string `json:"text"`
+	Color string `json:"color,omitempty"`
+	Index int    `json:"index"`
+}
+
+type thresholdsJSON struct {
+	Mode  string          `json:"mode"`
+	Steps []thresholdStep `json:"steps"`
+}
+
+type thresholdStep struct {
+	Value *float64 `json:"value"`
+	Color string   `json:"color"`
+}
+
+// panelSpec es un panel antes de layout: sale de la seccion 6 o de una metrica sin panel.
+type panelSpec struct {
+	title     string
+	expr      string
+	metric    contract.Metric
+	series    bool // timeseries (true) o stat (false)
+	perSecond bool // la query devuelve tasa por segundo (rate/irate)
+}
+
+// PARTE CRITICA **********************
+// La salida debe ser determinista: mismo METRICS.md => mismos bytes. Sin timestamps, ids secuenciales,
+// orden del documento y structs con orden fijo de claves. Si se usa un map para el modelo o se agrega
+// un campo con hora de generacion, cada regeneracion ensucia el diff de git.
+// Los paneles salen primero de "Dashboards minimos" (PromQL exacto) y luego de metricas del catalogo
+// que no tienen panel ahi, para no inventar PromQL donde el contrato ya lo fija.
+// FIN DE PARTE CRITICA ****************
+func Dashboard(catalog contract.Catalog, opts DashboardOptions) ([]byte, error) {
+	if opts.UID == "" {
+		opts.UID = DefaultDashboardUID
+	}
+	if opts.Title == "" {
+		opts.Title = DefaultDashboardTitle
+	}
+
+	var specs []panelSpec
+	covered := map[string]struct{}{}
+	for _, ps := range catalog.Panels {
+		if ps.Future && !opts.IncludeFuture {
+			continue
+		}
+		expr, err := promql.Parse(ps.Expr)
+		if err != nil {
+			return nil, fmt.Errorf("METRICS.md %q: %w", ps.Title, err)
+		}
+		names := promql.MetricNames(expr)
+		var metric contract.Metric
+		for _, n := range names {
+			covered[n] = struct{}{}
+			if m, ok := catalog.Lookup(n); ok && metric.Name == "" {
+				metric = m
+			}
+		}
+		specs = append(specs, panelSpec{
+			title:     ps.Title,
+			expr:      expr.String(),
+			metric:    metric,
+			series:    isRangeQuery(expr),
+			perSecond: isPerSecond(expr),
+		})
+	}
+
+	metrics := catalog.Current
+	if opts.IncludeFuture {
+		metrics = append(append([]contract.Metric(nil), catalog.Current...), catalog.Future...)
+	}
+	for _, m := range metrics {
+		if _, ok := covered[m.Name]; ok || isHistogramPart(m) {
+			continue
+		}
+		specs = append(specs, metricPanel(m))
+	}
+
+	dash := dashboardJSON{
+		UID:           opts.UID,
+		Title:         opts.Title,
+		Timezone:      "browser",
+		SchemaVersion: 39,
+		Version:       1,
+		Refresh:       "10s",
+		Tags:          []string{"drones", "generado"},
+		Panels:        layout(specs),
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(dash); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// metricPanel elige el panel por tipo: counter -> timeseries de rate, gauge -> stat.
+func metricPanel(m contract.Metric) panelSpec {
+	switch m.Type {
+	case "counter":
+		return panelSpec{
+			title:     fmt.Sprintf("%s por segundo (1m)", m.Name),
+			expr:      fmt.Sprintf("rate(%s[1m])", m.Name),
+			metric:    m,
+			series:    true,
+			perSecond: true,
+		}
+	case "histograma":
+		return panelSpec{
+			title:  fmt.Sprintf("%s P95 (5m)", m.Name),
+			expr:   fmt.Sprintf("histogram_quantile(0.95, sum by (le) (rate(%s_bucket[5m])))", m.Name),
+			metric: m,
+			series: true,
+		}
+	}
+	return panelSpec{title: m.Name, expr: m.Name, metric: m}
+}
+
+// isRangeQuery indica si la expresion agrega sobre una ventana (rate, increase, *_over_time).
+func isRangeQuery(e promql.Expr) bool {
+	ranged := false
+	promql.Inspect(e, func(n promql.Expr) bool {
+		if _, ok := n.(*promql.MatrixSelector); ok {
+			ranged = true
+		}
+		return !ranged
+	})
+	return ranged
+}
+
+var histogramSuffix = regexp.MustCompile(`_(bucket|sum|count)$`)
+
+// isPerSecond indica si la funcion mas externa es rate/irate.
+func isPerSecond(e promql.Expr) bool {
+	for {
+		switch n := e.(type) {
+		case *promql.ParenExpr:
+			e = n.Expr
+		case *promql.AggregateExpr:
+			e = n.Expr
+		case *promql.Call:
+			return n.Func == "rate" || n.Func == "irate"
+		default:
+			return false
+		}
+	}
+}
+
+// isHistogramPart evita paneles sueltos para _bucket/_sum/_count de un histograma del contrato.
+func isHistogramPart(m contract.Metric) bool {
+	return m.Type == "histograma" && histogramSuffix.MatchString(m.Name)
+}
+
+func layout(specs []panelSpec) []panelJSON {
+	panels := make([]panelJSON, 0, len(specs))
+	x, y, rowH := 0, 0, 0
+	for i, s := range specs {
+		p := buildPanel(i+1, s)
+		if x+p.GridPos.W > gridWidth {
+			x, y, rowH = 0, y+rowH, 0
+		}
+		p.GridPos.X, p.GridPos.Y = x, y
+		x += p.GridPos.W
+		if p.GridPos.H > rowH {
+			rowH = p.GridPos.H
+		}
+		panels = append(panels, p)
+	}
+	return panels
+}
+
+func buildPanel(id int, s panelSpec) panelJSON {
+	p := panelJSON{
+		ID:          id,
+		Title:       s.title,
+		Description: s.metric.Description,
+		Datasource:  datasourceRef{Type: "prometheus", UID: datasourceUID},
+		Targets:     []targetJSON{{Expr: s.expr, RefID: "A"}},
+		FieldConfig: fieldConfigJSON{Overrides: []any{}},
+	}
+	if s.series {
+		p.Type = "timeseries"
+		p.GridPos = gridPos{W: 12, H: 8}
+		p.FieldConfig.Defaults.Unit = seriesUnit(s)
+		return p
+	}
+
+	p.Type = "stat"
+	p.GridPos = gridPos{W: 6, H: 6}
+	p.Options = &statOptions{
+		ReduceOptions: reduceOptions{Calcs: []string{"lastNotNull"}, Fields: ""},
+		Orientation:   "horizontal",
+		TextMode:      "value_and_name",
+		ColorMode:     "value",
+	}
+	d := &p.FieldConfig.Defaults
+	d.Unit = grafanaUnit(s.metric.Unit)
+	switch s.metric.Unit {
+	case "pct":
+		d.Min, d.Max = float(0), float(100)
+		// Mismos umbrales que el panel de bateria versionado.
+		d.Thresholds = &thresholdsJSON{Mode: "absolute", Steps: []thresholdStep{
+			{Color: "red"},
+			{Value: float(10), Color: "orange"},
+			{Value: float(20), Color: "green"},
+		}}
+	case "score":
+		d.Min, d.Max = float(0), float(1)
+	case "enum":
+		d.Mappings, d.Thresholds = enumMappings(s.metric.Description)
+	}
+	return p
+}
+
+func seriesUnit(s panelSpec) string {
+	if s.metric.Type == "counter" {
+		if s.perSecond {
+			return "cps"
+		}
+		return "short"
+	}
+	return grafanaUnit(s.metric.Unit)
+}
+
+// grafanaUnit traduce la unidad del contrato a un id de unidad de Grafana.
+func grafanaUnit(unit string) string {
+	switch unit {
+	case "pct":
+		return "percent"
+	case "ms":
+		return "ms"
+	case "dbm":
+		return "dBm"
+	case "celsius":
+		return "celsius"
+	case "hpa":
+		return "pressurehpa"
+	case "lux":
+		return "lux"
+	case "m":
+		return "lengthm"
+	case "unix":
+		return "dateTimeFromNow"
+	}
+	return "short"
+}
+
+var enumValue = regexp.MustCompile(`(\d+)=([A-Z]+)`)
+
+// enumMappings arma value mappings desde la descripcion del contrato ("0=OK,1=WARN,2=CRIT").
+func enumMappings(desc string) ([]valueMapping, *thresholdsJSON) {
+	matches := enumValue.FindAllStringSubmatch(desc, -1)
+	if len(matches) == 0 {
+		return nil, nil
+	}
+	palette := []string{"green", "orange", "red", "purple"}
+	opts := map[string]mappingValue{}
+	keys := make([]int, 0, len(matches))
+	for i, m := range matches {
+		n, _ := strconv.Atoi(m[1])
+		keys = append(keys, n)
+		opts[m[1]] = mappingValue{Text: m[2], Color: palette[min(i, len(palette)-1)], Index: i}
+	}
+	sort.Ints(keys)
+	th := &thresholdsJSON{Mode: "absolute", Steps: []thresholdStep{{Color: opts[strconv.Itoa(keys[0])].Color}}}
+	for _, k := range keys[1:] {
+		th.Steps = append(th.Steps, thresholdStep{Value: float(float64(k)), Color: opts[strconv.Itoa(k)].Color})
+	}
+	return []valueMapping{{Type: "value", Options: opts}}, th
+}
+
+func float(v float64) *float64 { return &v }
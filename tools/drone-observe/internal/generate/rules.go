@@ -0,0 +1,109 @@
+// Archivo: tools/drone-observe/internal/generate/rules.go
+// Rol: generar reglas de alerta Prometheus (YAML) desde la seccion de alertas de METRICS.md.
+// No hace: recargar Prometheus ni enviar alertas; eso es trabajo de Prometheus/Alertmanager.
+package generate
+
+import (
+	"bytes"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+
+	"drone-observe/internal/contract"
+	"drone-observe/internal/promql"
+)
+
+// RuleGroup es el nombre del grupo de reglas generado; drift lo busca en /api/v1/rules.
+const RuleGroup = "drones-contract"
+
+// Rule es una regla de alerta en formato de archivo de reglas de Prometheus.
+type Rule struct {
+	Alert       string      `yaml:"alert"`
+	Expr        string      `yaml:"expr"`
+	For         string      `yaml:"for,omitempty"`
+	Labels      ruleLabels  `yaml:"labels"`
+	Annotations annotations `yaml:"annotations"`
+}
+
+type ruleLabels struct {
+	Severity string `yaml:"severity"`
+}
+
+// annotations en ES (summary) y EN (summary_en); source apunta al contrato que las origina.
+type annotations struct {
+	Summary   string `yaml:"summary"`
+	SummaryEN string `yaml:"summary_en"`
+	Source    string `yaml:"source"`
+}
+
+type rulesFile struct {
+	Groups []ruleGroup `yaml:"groups"`
+}
+
+type ruleGroup struct {
+	Name  string `yaml:"name"`
+	Rules []Rule `yaml:"rules"`
+}
+
+const rulesHeader = "# Generado por `drone-observe generate rules` desde METRICS.md (seccion 7). No editar a mano.\n"
+
+// Rules valida y convierte las alertas del contrato; las FUTURO solo si includeFuture.
+func Rules(catalog contract.Catalog, includeFuture bool) ([]Rule, error) {
+	var out []Rule
+	seen := map[string]struct{}{}
+	for _, a := range catalog.Alerts {
+		if a.Future && !includeFuture {
+			continue
+		}
+		if _, dup := seen[a.Name]; dup {
+			return nil, fmt.Errorf("alerta duplicada en METRICS.md: %s", a.Name)
+		}
+		seen[a.Name] = struct{}{}
+		expr, err := promql.Parse(a.Expr)
+		if err != nil {
+			return nil, fmt.Errorf("alerta %s: %w", a.Name, err)
+		}
+		if expr.Type() != promql.ValueVector {
+			return nil, fmt.Errorf("alerta %s: la expresion debe ser vector, es %s", a.Name, expr.Type())
+		}
+		if a.For != "" {
+			if _, err := promql.ParseDuration(a.For); err != nil {
+				return nil, fmt.Errorf("alerta %s: for %w", a.Name, err)
+			}
+		}
+		out = append(out, Rule{
+			Alert:  a.Name,
+			Expr:   expr.String(),
+			For:    a.For,
+			Labels: ruleLabels{Severity: a.Severity},
+			Annotations: annotations{
+				Summary:   a.SummaryES,
+				SummaryEN: a.SummaryEN,
+				Source:    "METRICS.md#7",
+			},
+		})
+	}
+	return out, nil
+}
+
+// PARTE CRITICA **********************
+// Mismo contrato que el dashboard generado: salida determinista (structs con orden fijo, sin fechas).
+// El archivo versionado debe coincidir byte a byte con lo generado; drift lo compara.
+// FIN DE PARTE CRITICA ****************
+func RulesYAML(catalog contract.Catalog, includeFuture bool) ([]byte, error) {
+	rules, err := Rules(catalog, includeFuture)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	buf.WriteString(rulesHeader)
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(rulesFile{Groups: []ruleGroup{{Name: RuleGroup, Rules: rules}}}); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
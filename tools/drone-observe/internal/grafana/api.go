@@ -0,0 +1,132 @@
+// Archivo: tools/drone-observe/internal/grafana/api.go
+// Rol: cliente minimo de la API HTTP de Grafana (search y dashboards por uid).
+// No hace: escrituras ni gestion de usuarios; solo lectura con basic auth.
+package grafana
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Client consulta la API de Grafana con basic auth.
+type Client struct {
+	BaseURL  string
+	User     string
+	Password string
+	HTTP     *http.Client
+}
+
+func NewClient(baseURL, user, password string) *Client {
+	return &Client{
+		BaseURL:  strings.TrimRight(baseURL, "/"),
+		User:     user,
+		Password: password,
+		HTTP:     &http.Client{Timeout: 3 * time.Second},
+	}
+}
+
+// SearchHit es un resultado de /api/search.
+type SearchHit struct {
+	UID         string `json:"uid"`
+	Title       string `json:"title"`
+	Type        string `json:"type"`
+	FolderTitle string `json:"folderTitle"`
+}
+
+// ErrUnauthorized indica credenciales rechazadas por Grafana.
+var ErrUnauthorized = errors.New("grafana rechazo las credenciales (revisar GRAFANA_ADMIN_USER/GRAFANA_ADMIN_PASSWORD)")
+
+// Search lista los dashboards (type=dash-db) visibles para el usuario.
+func (c *Client) Search(ctx context.Context) ([]SearchHit, error) {
+	var hits []SearchHit
+	if err := c.getJSON(ctx, "/api/search?type=dash-db", &hits); err != nil {
+		return nil, err
+	}
+	return hits, nil
+}
+
+// Dashboard devuelve el modelo JSON de un dashboard por uid.
+func (c *Client) Dashboard(ctx context.Context, uid string) (map[string]any, error) {
+	var resp struct {
+		Dashboard map[string]any `json:"dashboard"`
+	}
+	if err := c.getJSON(ctx, "/api/dashboards/uid/"+url.PathEscape(uid), &resp); err != nil {
+		return nil, err
+	}
+	if resp.Dashboard == nil {
+		return nil, fmt.Errorf("respuesta sin dashboard para uid %s", uid)
+	}
+	return resp.Dashboard, nil
+}
+
+func (c *Client) getJSON(ctx context.Context, path string, out any) error {
+	status, body, err := c.get(ctx, path)
+	if err != nil {
+		return err
+	}
+	if status < 200 || status >= 300 {
+		return fmt.Errorf("%s: http status %d", path, status)
+	}
+	return json.Unmarshal(body, out)
+}
+
+func (c *Client) get(ctx context.Context, path string) (int, []byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.BaseURL+path, nil)
+	if err != nil {
+		return 0, nil, err
+	}
+	if c.User != "" {
+		req.SetBasicAuth(c.User, c.Password)
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return resp.StatusCode, nil, ErrUnauthorized
+	}
+	body, err := io.ReadAll(resp.Body)
+	return resp.StatusCode, body, err
+}
+
+// DatasourceInfo es el subconjunto de /api/datasources/name/<name>.
+type DatasourceInfo struct {
+	UID  string `json:"uid"`
+	Name string `json:"name"`
+	Type string `json:"type"`
+	URL  string `json:"url"`
+}
+
+// DatasourceByName resuelve un datasource por nombre (sirve cuando el provisioning no fija uid).
+func (c *Client) DatasourceByName(ctx context.Context, name string) (DatasourceInfo, error) {
+	var ds DatasourceInfo
+	err := c.getJSON(ctx, "/api/datasources/name/"+url.PathEscape(name), &ds)
+	return ds, err
+}
+
+// DatasourceHealth ejecuta el health check del datasource; status "OK" indica que responde.
+// Grafana responde 400 con status "ERROR" cuando el datasource falla; eso no es error de la llamada.
+func (c *Client) DatasourceHealth(ctx context.Context, uid string) (status, message string, err error) {
+	path := "/api/datasources/uid/" + url.PathEscape(uid) + "/health"
+	code, body, err := c.get(ctx, path)
+	if err != nil {
+		return "", "", err
+	}
+	var resp struct {
+		Status  string `json:"status"`
+		Message string `json:"message"`
+	}
+	if jerr := json.Unmarshal(body, &resp); jerr != nil || resp.Status == "" {
+		return "", "", fmt.Errorf("%s: http status %d", path, code)
+	}
+	return resp.Status, resp.Message, nil
+}
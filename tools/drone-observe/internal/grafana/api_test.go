@@ -0,0 +1,81 @@
+package grafana_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"drone-observe/internal/grafana"
+	"drone-observe/internal/testenv"
+)
+
+func TestSearchAndDashboard(t *testing.T) {
+	g := testenv.NewGrafana(t, "admin", "secreto")
+	g.AddDashboard("drone-overview", "Drone Overview", map[string]any{"panels": []any{map[string]any{"title": "Bateria"}}})
+	g.AddDashboard("backend", "Backend", nil)
+	c := grafana.NewClient(g.URL, "admin", "secreto")
+
+	hits, err := c.Search(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hits) != 2 || hits[0].UID != "backend" || hits[1].Title != "Drone Overview" {
+		t.Fatalf("Search = %+v", hits)
+	}
+	d, err := c.Dashboard(context.Background(), "drone-overview")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if panels, _ := d["panels"].([]any); len(panels) != 1 || d["uid"] != "drone-overview" {
+		t.Errorf("Dashboard = %v", d)
+	}
+	if _, err := c.Dashboard(context.Background(), "no-existe"); err == nil {
+		t.Error("uid inexistente sin error")
+	}
+}
+
+func TestCredentials(t *testing.T) {
+	tests := []struct {
+		name           string
+		user, password string
+		wantErr        error
+	}{
+		{"validas", "admin", "secreto", nil},
+		{"password invalida", "admin", "admin", grafana.ErrUnauthorized},
+		{"sin credenciales", "", "", grafana.ErrUnauthorized},
+	}
+	g := testenv.NewGrafana(t, "admin", "secreto")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := grafana.NewClient(g.URL, tt.user, tt.password).Search(context.Background())
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("err = %v; esperado %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDatasourceHealth(t *testing.T) {
+	g := testenv.NewGrafana(t, "admin", "admin")
+	g.AddDatasource(testenv.Datasource{UID: "prom", Name: "Prometheus", Type: "prometheus", URL: "http://prometheus:9090", Health: "OK"})
+	g.AddDatasource(testenv.Datasource{UID: "rota", Name: "Rota", Type: "prometheus", URL: "http://nada:9090", Health: "ERROR"})
+	c := grafana.NewClient(g.URL, "admin", "admin")
+
+	tests := []struct {
+		name, wantUID, wantStatus string
+	}{
+		{"Prometheus", "prom", "OK"},
+		{"Rota", "rota", "ERROR"},
+	}
+	for _, tt := range tests {
+		ds, err := c.DatasourceByName(context.Background(), tt.name)
+		if err != nil || ds.UID != tt.wantUID {
+			t.Fatalf("DatasourceByName(%s) = %+v, %v", tt.name, ds, err)
+		}
+		// Un datasource caido es status ERROR, no error de la llamada.
+		status, _, err := c.DatasourceHealth(context.Background(), ds.UID)
+		if err != nil || status != tt.wantStatus {
+			t.Errorf("DatasourceHealth(%s) = %q, %v; esperado %q", ds.UID, status, err, tt.wantStatus)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+// Archivo: tools/drone-observe/internal/grafana/dashboard.go
+// Rol: leer dashboards JSON versionados y extraer paneles y queries PromQL.
+// No hace: llamadas a la API de Grafana ni modificacion de dashboards.
+package grafana
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+)
+
+// Target es una query de un panel.
+type Target struct {
+	RefID      string      `json:"refId"`
+	Expr       string      `json:"expr"`
+	Datasource *Datasource `json:"datasource"`
+}
+
+// Datasource referencia el datasource de un panel o target.
+type Datasource struct {
+	Type string `json:"type"`
+	UID  string `json:"uid"`
+}
+
+// Panel es un panel de dashboard; los rows colapsados guardan paneles anidados.
+type Panel struct {
+	ID         int         `json:"id"`
+	Type       string      `json:"type"`
+	Title      string      `json:"title"`
+	Datasource *Datasource `json:"datasource"`
+	Targets    []Target    `json:"targets"`
+	Panels     []Panel     `json:"panels"`
+}
+
+// Dashboard es el subconjunto del modelo JSON de Grafana que usa la herramienta.
+type Dashboard struct {
+	File   string         `json:"-"`
+	Raw    map[string]any `json:"-"`
+	UID    string         `json:"uid"`
+	Title  string         `json:"title"`
+	Panels []Panel        `json:"panels"`
+}
+
+// Query es una expresion PromQL ubicada en un dashboard.
+type Query struct {
+	Dashboard string
+	Panel     string
+	RefID     string
+	Expr      string
+}
+
+// Location identifica la query para findings: archivo / panel [refId].
+func (q Query) Location() string {
+	return fmt.Sprintf("%s / %s [%s]", q.Dashboard, q.Panel, q.RefID)
+}
+
+// LoadFile lee un dashboard JSON.
+func LoadFile(path string) (Dashboard, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return Dashboard{}, err
+	}
+	var d Dashboard
+	if err := json.Unmarshal(raw, &d); err != nil {
+		return Dashboard{}, fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+	if err := json.Unmarshal(raw, &d.Raw); err != nil {
+		return Dashboard{}, fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+	d.File = filepath.Base(path)
+	return d, nil
+}
+
+// LoadDir lee todos los *.json del directorio, ordenados por nombre de archivo.
+func LoadDir(dir string) ([]Dashboard, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	out := make([]Dashboard, 0, len(files))
+	for _, f := range files {
+		d, err := LoadFile(f)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, d)
+	}
+	return out, nil
+}
+
+// AllPanels devuelve los paneles en orden, incluyendo los anidados en rows.
+func (d Dashboard) AllPanels() []Panel {
+	var out []Panel
+	var walk func([]Panel)
+	walk = func(ps []Panel) {
+		for _, p := range ps {
+			out = append(out, p)
+			walk(p.Panels)
+		}
+	}
+	walk(d.Panels)
+	return out
+}
+
+// Queries lista las expresiones no vacias de todos los targets.
+func (d Dashboard) Queries() []Query {
+	var out []Query
+	for _, p := range d.AllPanels() {
+		for _, t := range p.Targets {
+			if t.Expr == "" {
+				continue
+			}
+			out = append(out, Query{Dashboard: d.File, Panel: p.Title, RefID: t.RefID, Expr: t.Expr})
+		}
+	}
+	return out
+}
+
+var intervalVar = regexp.MustCompile(`\$__(rate_interval|interval|range)\b|\$\{__(rate_interval|interval|range)\}`)
+
+// PARTE CRITICA **********************
+// Las variables de intervalo de Grafana se reemplazan por 1m solo para poder parsear la expresion.
+// Si se reemplazan otras variables, el parseo validaria algo distinto a lo que ejecuta Grafana.
+// Variables de usuario fuera de strings quedan como error de parseo y se reportan.
+// FIN DE PARTE CRITICA ****************
+func ExpandIntervals(expr string) string {
+	return intervalVar.ReplaceAllString(expr, "1m")
+}
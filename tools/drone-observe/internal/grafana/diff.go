@@ -0,0 +1,124 @@
+// Archivo: tools/drone-observe/internal/grafana/diff.go
+// Rol: diff estructural entre el JSON versionado de un dashboard y el que sirve Grafana.
+// No hace: merge ni correccion; solo lista rutas con diferencias.
+package grafana
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// volatileKeys son campos que Grafana reescribe al cargar o guardar y no indican edicion.
+var volatileKeys = map[string]struct{}{"id": {}, "version": {}, "iteration": {}}
+
+// Change es una diferencia en una ruta del JSON (p. ej. panels[2].targets[0].expr).
+type Change struct {
+	Path string
+	Repo string
+	Live string
+}
+
+func (c Change) String() string {
+	switch {
+	case c.Repo == "":
+		return fmt.Sprintf("%s: solo en Grafana (%s)", c.Path, c.Live)
+	case c.Live == "":
+		return fmt.Sprintf("%s: solo en repo (%s)", c.Path, c.Repo)
+	}
+	return fmt.Sprintf("%s: repo %s, Grafana %s", c.Path, c.Repo, c.Live)
+}
+
+// PARTE CRITICA **********************
+// Se ignoran solo id, version e iteration (en cualquier nivel: Grafana asigna ids de panel).
+// Si se agregan mas exclusiones, una edicion real en la UI puede pasar como "sin cambios".
+// El orden de claves no importa; el orden de arrays si (orden de paneles y targets es visible).
+// FIN DE PARTE CRITICA ****************
+func Diff(repo, live map[string]any) []Change {
+	var out []Change
+	diffValue("", repo, live, &out)
+	return out
+}
+
+func diffValue(path string, a, b any, out *[]Change) {
+	switch av := a.(type) {
+	case map[string]any:
+		bv, ok := b.(map[string]any)
+		if !ok {
+			*out = append(*out, Change{Path: rootPath(path), Repo: compact(a), Live: compact(b)})
+			return
+		}
+		keys := map[string]struct{}{}
+		for k := range av {
+			keys[k] = struct{}{}
+		}
+		for k := range bv {
+			keys[k] = struct{}{}
+		}
+		sorted := make([]string, 0, len(keys))
+		for k := range keys {
+			if _, skip := volatileKeys[k]; !skip {
+				sorted = append(sorted, k)
+			}
+		}
+		sort.Strings(sorted)
+		for _, k := range sorted {
+			child := k
+			if path != "" {
+				child = path + "." + k
+			}
+			x, inA := av[k]
+			y, inB := bv[k]
+			switch {
+			case !inA:
+				*out = append(*out, Change{Path: child, Live: compact(y)})
+			case !inB:
+				*out = append(*out, Change{Path: child, Repo: compact(x)})
+			default:
+				diffValue(child, x, y, out)
+			}
+		}
+	case []any:
+		bv, ok := b.([]any)
+		if !ok {
+			*out = append(*out, Change{Path: rootPath(path), Repo: compact(a), Live: compact(b)})
+			return
+		}
+		for i := 0; i < len(av) || i < len(bv); i++ {
+			child := fmt.Sprintf("%s[%d]", path, i)
+			switch {
+			case i >= len(av):
+				*out = append(*out, Change{Path: child, Live: compact(bv[i])})
+			case i >= len(bv):
+				*out = append(*out, Change{Path: child, Repo: compact(av[i])})
+			default:
+				diffValue(child, av[i], bv[i], out)
+			}
+		}
+	default:
+		if !reflect.DeepEqual(a, b) {
+			*out = append(*out, Change{Path: rootPath(path), Repo: compact(a), Live: compact(b)})
+		}
+	}
+}
+
+func rootPath(p string) string {
+	if p == "" {
+		return "(raiz)"
+	}
+	return p
+}
+
+// compact serializa un valor en una linea, truncado para findings legibles.
+func compact(v any) string {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	s := string(raw)
+	if len(s) > 60 {
+		s = s[:57] + "..."
+	}
+	return s
+}
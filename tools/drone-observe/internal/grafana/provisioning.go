@@ -0,0 +1,118 @@
+// Archivo: tools/drone-observe/internal/grafana/provisioning.go
+// Rol: leer los YAML de provisioning de Grafana (datasources y providers de dashboards).
+// No hace: aplicar provisioning ni interpolar variables de entorno de Grafana.
+package grafana
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DatasourceDef es un datasource declarado en provisioning/datasources.
+type DatasourceDef struct {
+	Name      string `yaml:"name"`
+	Type      string `yaml:"type"`
+	UID       string `yaml:"uid"`
+	URL       string `yaml:"url"`
+	Access    string `yaml:"access"`
+	IsDefault bool   `yaml:"isDefault"`
+	File      string `yaml:"-"`
+}
+
+// Provider es un provider de dashboards en provisioning/dashboards.
+type Provider struct {
+	Name           string `yaml:"name"`
+	Folder         string `yaml:"folder"`
+	Type           string `yaml:"type"`
+	AllowUIUpdates bool   `yaml:"allowUiUpdates"`
+	Options        struct {
+		Path string `yaml:"path"`
+	} `yaml:"options"`
+	File string `yaml:"-"`
+}
+
+// Provisioning agrupa lo declarado en el directorio de provisioning.
+type Provisioning struct {
+	Dir         string
+	Datasources []DatasourceDef
+	Providers   []Provider
+}
+
+// LoadProvisioning lee datasources/*.y*ml y dashboards/*.y*ml bajo dir.
+func LoadProvisioning(dir string) (Provisioning, error) {
+	prov := Provisioning{Dir: dir}
+	dsFiles, err := yamlFiles(filepath.Join(dir, "datasources"))
+	if err != nil {
+		return prov, err
+	}
+	for _, f := range dsFiles {
+		var doc struct {
+			Datasources []DatasourceDef `yaml:"datasources"`
+		}
+		if err := readYAML(f, &doc); err != nil {
+			return prov, err
+		}
+		for _, d := range doc.Datasources {
+			d.File = filepath.Base(f)
+			prov.Datasources = append(prov.Datasources, d)
+		}
+	}
+	dbFiles, err := yamlFiles(filepath.Join(dir, "dashboards"))
+	if err != nil {
+		return prov, err
+	}
+	for _, f := range dbFiles {
+		var doc struct {
+			Providers []Provider `yaml:"providers"`
+		}
+		if err := readYAML(f, &doc); err != nil {
+			return prov, err
+		}
+		for _, p := range doc.Providers {
+			p.File = filepath.Base(f)
+			prov.Providers = append(prov.Providers, p)
+		}
+	}
+	return prov, nil
+}
+
+// Default devuelve el datasource marcado isDefault (o el unico, como hace Grafana).
+func (p Provisioning) Default() (DatasourceDef, bool) {
+	for _, d := range p.Datasources {
+		if d.IsDefault {
+			return d, true
+		}
+	}
+	if len(p.Datasources) == 1 {
+		return p.Datasources[0], true
+	}
+	return DatasourceDef{}, false
+}
+
+func yamlFiles(dir string) ([]string, error) {
+	var out []string
+	for _, pattern := range []string{"*.yml", "*.yaml"} {
+		m, err := filepath.Glob(filepath.Join(dir, pattern))
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, m...)
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+func readYAML(path string, out any) error {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := yaml.Unmarshal(raw, out); err != nil {
+		return fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
@@ -5,10 +5,13 @@ package limits
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"drone-observe/internal/config"
 	"drone-observe/internal/prometheus"
+	"drone-observe/internal/queries"
+	"drone-observe/internal/source"
 )
 
 type Snapshot struct {
@@ -16,31 +19,48 @@ type Snapshot struct {
 	SeriesCount      float64
 	MetricNameCount  float64
 	ScrapeAgeSeconds int
+	Warnings         []string
 }
 
 // PARTE CRITICA **********************
 // Se usan solo metricas observables en Prometheus para evitar heuristicas.
 // Si se agregan benchmarks, se rompe el enfoque determinista del CLI.
-// No extrapolar limites futuros; solo mostrar estado actual.
+// No extrapolar limites futuros; solo mostrar el estado en el instante evaluado (ahora o --at).
 // FIN DE PARTE CRITICA ****************
 func Observe(cfg config.Config) (Snapshot, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	rate, _, _ := prometheus.QueryInstant(ctx, cfg.PrometheusURL, "rate(mqtt_messages_total[1m])")
-	series, _, _ := prometheus.QueryInstant(ctx, cfg.PrometheusURL, "count({job=\"backend\"})")
-	names, _, _ := prometheus.QueryInstant(ctx, cfg.PrometheusURL, "count(count by(__name__) ({job=\"backend\"}))")
+	at := cfg.EvalTime()
+	prom := source.For(cfg).Prometheus
+	var warnings []string
+	query := func(expr string, rule prometheus.Rule) (prometheus.Series, bool) {
+		vec, err := prom.Query(ctx, expr, at)
+		if err != nil {
+			return prometheus.Series{}, false
+		}
+		if note := vec.MultiSeriesNote(rule); note != "" {
+			warnings = append(warnings, fmt.Sprintf("%s: %s", expr, note))
+		}
+		return vec.Aggregate(rule)
+	}
+
+	rate, _ := query(queries.MessageRate.Expr, prometheus.RuleSum)
+	series, _ := query(queries.BackendSeries.Expr, prometheus.RuleSum)
+	names, _ := query(queries.BackendMetricNames.Expr, prometheus.RuleSum)
 
-	_, ts, ok, _ := prometheus.QueryInstantWithTimestamp(ctx, cfg.PrometheusURL, "up{job=\"backend\"}")
+	// timestamp(up) es el instante del ultimo scrape; el mas antiguo es el menor valor.
+	scraped, ok := query(queries.BackendScrapeTimestamp.Expr, prometheus.RuleMin)
 	age := -1
 	if ok {
-		age = int(time.Since(time.Unix(int64(ts), 0)).Seconds())
+		age = int(at.Sub(time.UnixMilli(int64(scraped.Value * 1000))).Seconds())
 	}
 
 	return Snapshot{
-		MessageRate:      rate,
-		SeriesCount:      series,
-		MetricNameCount:  names,
+		MessageRate:      rate.Value,
+		SeriesCount:      series.Value,
+		MetricNameCount:  names.Value,
 		ScrapeAgeSeconds: age,
+		Warnings:         warnings,
 	}, nil
 }
@@ -0,0 +1,193 @@
+// Archivo: tools/drone-observe/internal/mosquitto/conf.go
+// Rol: leer mosquitto.conf versionado (listeners, autenticacion, ACL, TLS y persistencia).
+// No hace: validar el archivo como mosquitto ni seguir include_dir; solo lo que el archivo declara.
+package mosquitto
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// DefaultPort es el puerto del listener por defecto (y del "local only mode" de Mosquitto 2.x).
+const DefaultPort = 1883
+
+// Security son las opciones de autenticacion; son globales salvo con per_listener_settings true.
+type Security struct {
+	// AllowAnonymous es nil si el archivo no lo declara (default de Mosquitto 2.x: false).
+	AllowAnonymous *bool
+	PasswordFile   string
+	ACLFile        string
+	Plugins        []string
+}
+
+// Anonymous devuelve el valor efectivo de allow_anonymous.
+func (s Security) Anonymous() bool {
+	return s.AllowAnonymous != nil && *s.AllowAnonymous
+}
+
+type Listener struct {
+	Port     int
+	Bind     string
+	Protocol string
+	Line     int
+	CAFile   string
+	CertFile string
+	KeyFile  string
+	// PSKHint habilita TLS-PSK en lugar de certificados.
+	PSKHint            string
+	RequireCertificate bool
+	TLSVersion         string
+	// Security solo se completa con per_listener_settings true.
+	Security Security
+}
+
+// TLS indica si el listener cifra (certificado + clave, o PSK).
+func (l Listener) TLS() bool {
+	return (l.CertFile != "" && l.KeyFile != "") || l.PSKHint != ""
+}
+
+// Name identifica el listener en findings: "1883", "8883 (websockets)", "1883 en 127.0.0.1".
+func (l Listener) Name() string {
+	name := strconv.Itoa(l.Port)
+	if l.Bind != "" {
+		name += " en " + l.Bind
+	}
+	if l.Protocol != "" && l.Protocol != "mqtt" {
+		name += " (" + l.Protocol + ")"
+	}
+	return name
+}
+
+type Config struct {
+	Path                string
+	PerListenerSettings bool
+	Security            Security
+	Listeners           []Listener
+	Persistence         bool
+	PersistenceLocation string
+	IncludeDirs         []string
+	// Unknown son directivas que el parser no interpreta (se ignoran sin error).
+	Unknown []string
+}
+
+// SecurityFor devuelve la seguridad efectiva de un listener.
+func (c Config) SecurityFor(l Listener) Security {
+	if c.PerListenerSettings {
+		return l.Security
+	}
+	return c.Security
+}
+
+// PARTE CRITICA **********************
+// Mosquitto aplica las opciones de listener (TLS, protocol) al ultimo `listener` declarado, y las de
+// autenticacion al listener solo con per_listener_settings true; si no, son globales. Como
+// per_listener_settings puede aparecer despues, se guardan ambas vistas y se decide al final.
+// FIN DE PARTE CRITICA ****************
+func Load(path string) (Config, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Config{}, err
+	}
+	defer f.Close()
+
+	cfg := Config{Path: path}
+	var current *Listener
+	scanner := bufio.NewScanner(f)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Fields(text)
+		key, args := fields[0], fields[1:]
+		arg := strings.Join(args, " ")
+
+		security := &cfg.Security
+		if current != nil {
+			// Se anota en ambos; SecurityFor elige segun per_listener_settings.
+			security = &current.Security
+		}
+		switch key {
+		case "listener":
+			if len(args) == 0 {
+				return cfg, fmt.Errorf("%s:%d: listener sin puerto", path, line)
+			}
+			port, err := strconv.Atoi(args[0])
+			if err != nil {
+				return cfg, fmt.Errorf("%s:%d: puerto invalido %q", path, line, args[0])
+			}
+			l := Listener{Port: port, Line: line, Protocol: "mqtt"}
+			if len(args) > 1 {
+				l.Bind = args[1]
+			}
+			cfg.Listeners = append(cfg.Listeners, l)
+			current = &cfg.Listeners[len(cfg.Listeners)-1]
+		case "per_listener_settings":
+			cfg.PerListenerSettings = arg == "true"
+		case "allow_anonymous":
+			v := arg == "true"
+			security.AllowAnonymous = &v
+			if current != nil {
+				cfg.Security.AllowAnonymous = &v
+			}
+		case "password_file":
+			security.PasswordFile = arg
+			if current != nil {
+				cfg.Security.PasswordFile = arg
+			}
+		case "acl_file":
+			security.ACLFile = arg
+			if current != nil {
+				cfg.Security.ACLFile = arg
+			}
+		case "plugin", "auth_plugin":
+			security.Plugins = append(security.Plugins, arg)
+			if current != nil {
+				cfg.Security.Plugins = append(cfg.Security.Plugins, arg)
+			}
+		case "persistence":
+			cfg.Persistence = arg == "true"
+		case "persistence_location":
+			cfg.PersistenceLocation = arg
+		case "include_dir":
+			cfg.IncludeDirs = append(cfg.IncludeDirs, arg)
+		case "protocol", "cafile", "capath", "certfile", "keyfile", "psk_hint", "require_certificate", "tls_version":
+			if current == nil {
+				// Sin listener explicito estas opciones aplican al listener por defecto.
+				cfg.Listeners = append(cfg.Listeners, Listener{Port: DefaultPort, Line: line, Protocol: "mqtt"})
+				current = &cfg.Listeners[len(cfg.Listeners)-1]
+			}
+			setListenerOption(current, key, arg)
+		default:
+			cfg.Unknown = append(cfg.Unknown, key)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return cfg, err
+	}
+	return cfg, nil
+}
+
+func setListenerOption(l *Listener, key, arg string) {
+	switch key {
+	case "protocol":
+		l.Protocol = arg
+	case "cafile", "capath":
+		l.CAFile = arg
+	case "certfile":
+		l.CertFile = arg
+	case "keyfile":
+		l.KeyFile = arg
+	case "psk_hint":
+		l.PSKHint = arg
+	case "require_certificate":
+		l.RequireCertificate = arg == "true"
+	case "tls_version":
+		l.TLSVersion = arg
+	}
+}
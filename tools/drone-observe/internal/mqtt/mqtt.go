@@ -4,8 +4,8 @@
 package mqtt
 
 import (
-	"fmt"
 	"net"
+	"strconv"
 	"time"
 )
 
@@ -17,7 +17,7 @@ const dialTimeout = 2 * time.Second
 // No implementar publish/subscribe aqui; eso seria mezclar responsabilidades.
 // FIN DE PARTE CRITICA ****************
 func CheckReachable(host string, port int) error {
-	addr := fmt.Sprintf("%s:%d", host, port)
+	addr := net.JoinHostPort(host, strconv.Itoa(port))
 	conn, err := net.DialTimeout("tcp", addr, dialTimeout)
 	if err != nil {
 		return err
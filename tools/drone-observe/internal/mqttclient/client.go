@@ -0,0 +1,341 @@
+// Archivo: tools/drone-observe/internal/mqttclient/client.go
+// Rol: cliente MQTT 3.1.1 minimo (connect, subscribe, publish QoS 0/1) sin dependencias externas.
+// No hace: reconexion automatica, sesiones persistentes ni QoS 2.
+package mqttclient
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultTimeout   = 3 * time.Second
+	defaultKeepAlive = 30 * time.Second
+	messageBuffer    = 1024
+)
+
+// Options define identidad y tiempos de la conexion.
+type Options struct {
+	ClientID  string
+	Username  string
+	Password  string
+	Timeout   time.Duration
+	KeepAlive time.Duration
+	// MaxPacket es el cuerpo maximo de un paquete recibido; 0 usa DefaultMaxPacket.
+	MaxPacket int
+}
+
+// Message es un PUBLISH recibido del broker.
+type Message struct {
+	Topic    string
+	Payload  []byte
+	QoS      byte
+	Retain   bool
+	Received time.Time
+}
+
+// ConnectError indica que el broker rechazo el CONNECT con un codigo de retorno.
+type ConnectError struct {
+	Code byte
+}
+
+func (e *ConnectError) Error() string {
+	reason := "desconocido"
+	switch e.Code {
+	case 1:
+		reason = "version de protocolo no aceptada"
+	case 2:
+		reason = "client id rechazado"
+	case 3:
+		reason = "servidor no disponible"
+	case 4:
+		reason = "usuario o password invalidos"
+	case 5:
+		reason = "no autorizado"
+	}
+	return fmt.Sprintf("mqtt: conexion rechazada (%d: %s)", e.Code, reason)
+}
+
+// ErrPasswordWithoutUser: MQTT 3.1.1 (3.1.2.9) prohibe password sin username y Mosquitto corta la conexion.
+var ErrPasswordWithoutUser = errors.New("mqtt: password sin username")
+
+// Client es una conexion MQTT activa.
+type Client struct {
+	conn    net.Conn
+	timeout time.Duration
+
+	writeMu sync.Mutex
+	mu      sync.Mutex
+	nextID  uint16
+	pending map[uint16]chan Packet
+	err     error
+
+	messages chan Message
+	dropped  atomic.Int64
+	done     chan struct{}
+	once     sync.Once
+}
+
+// PARTE CRITICA **********************
+// El handshake es real (CONNECT/CONNACK) para que los checks prueben el broker y no solo TCP.
+// Si se agregan reintentos o reconexion, se ocultan fallos que el operador debe ver.
+// No reutilizar un Client entre comandos; cada check abre y cierra su conexion.
+// FIN DE PARTE CRITICA ****************
+func Dial(host string, port int, opts Options) (*Client, error) {
+	if opts.Password != "" && opts.Username == "" {
+		return nil, ErrPasswordWithoutUser
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaultTimeout
+	}
+	if opts.KeepAlive <= 0 {
+		opts.KeepAlive = defaultKeepAlive
+	}
+	if opts.ClientID == "" {
+		opts.ClientID = fmt.Sprintf("drone-observe-%d", time.Now().UnixNano()%1000000)
+	}
+	if opts.MaxPacket <= 0 {
+		opts.MaxPacket = DefaultMaxPacket
+	}
+
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(host, strconv.Itoa(port)), opts.Timeout)
+	if err != nil {
+		return nil, err
+	}
+	_ = conn.SetDeadline(time.Now().Add(opts.Timeout))
+
+	if err := WritePacket(conn, connectPacket(opts)); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	r := bufio.NewReader(conn)
+	ack, err := ReadPacketMax(r, opts.MaxPacket)
+	if err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("mqtt: sin CONNACK: %w", err)
+	}
+	if ack.Type != TypeConnack || len(ack.Body) < 2 {
+		_ = conn.Close()
+		return nil, fmt.Errorf("mqtt: respuesta inesperada al CONNECT (tipo %d)", ack.Type)
+	}
+	if ack.Body[1] != 0 {
+		_ = conn.Close()
+		return nil, &ConnectError{Code: ack.Body[1]}
+	}
+	_ = conn.SetDeadline(time.Time{})
+
+	c := &Client{
+		conn:     conn,
+		timeout:  opts.Timeout,
+		pending:  map[uint16]chan Packet{},
+		messages: make(chan Message, messageBuffer),
+		done:     make(chan struct{}),
+	}
+	go c.readLoop(r, opts.MaxPacket)
+	go c.keepAlive(opts.KeepAlive)
+	return c, nil
+}
+
+func connectPacket(opts Options) Packet {
+	flags := byte(0x02) // clean session
+	if opts.Username != "" {
+		flags |= 0x80
+	}
+	if opts.Username != "" && opts.Password != "" {
+		flags |= 0x40
+	}
+	body := AppendString(nil, "MQTT")
+	body = append(body, 0x04, flags)
+	body = binary.BigEndian.AppendUint16(body, uint16(opts.KeepAlive/time.Second))
+	body = AppendString(body, opts.ClientID)
+	if opts.Username != "" {
+		body = AppendString(body, opts.Username)
+	}
+	if opts.Username != "" && opts.Password != "" {
+		body = AppendString(body, opts.Password)
+	}
+	return Packet{Type: TypeConnect, Body: body}
+}
+
+// Messages entrega los PUBLISH recibidos; se cierra al cerrar la conexion.
+func (c *Client) Messages() <-chan Message {
+	return c.messages
+}
+
+// Dropped cuenta los mensajes descartados porque Messages() estaba lleno (consumidor lento).
+func (c *Client) Dropped() int64 {
+	return c.dropped.Load()
+}
+
+// Err devuelve el error que cerro la conexion, si lo hubo.
+func (c *Client) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// Subscribe suscribe un filtro y devuelve el codigo SUBACK (QoS otorgado o SubackFailure).
+func (c *Client) Subscribe(filter string, qos byte) (byte, error) {
+	if qos > 1 {
+		qos = 1
+	}
+	id, wait := c.register()
+	body := binary.BigEndian.AppendUint16(nil, id)
+	body = AppendString(body, filter)
+	body = append(body, qos)
+	if err := c.write(Packet{Type: TypeSubscribe, Flags: 0x02, Body: body}); err != nil {
+		c.unregister(id)
+		return 0, err
+	}
+	ack, err := c.await(id, wait)
+	if err != nil {
+		return 0, err
+	}
+	if ack.Type != TypeSuback || len(ack.Body) < 3 {
+		return 0, errors.New("mqtt: SUBACK invalido")
+	}
+	return ack.Body[2], nil
+}
+
+// Publish envia un mensaje; con QoS 1 espera el PUBACK del broker.
+func (c *Client) Publish(topic string, payload []byte, qos byte, retain bool) error {
+	if qos > 1 {
+		qos = 1
+	}
+	if qos == 0 {
+		return c.write(PublishPacket(topic, payload, 0, retain, 0))
+	}
+	id, wait := c.register()
+	if err := c.write(PublishPacket(topic, payload, qos, retain, id)); err != nil {
+		c.unregister(id)
+		return err
+	}
+	_, err := c.await(id, wait)
+	return err
+}
+
+// Close envia DISCONNECT y cierra el socket.
+func (c *Client) Close() error {
+	_ = c.write(Packet{Type: TypeDisconnect})
+	c.shutdown(nil)
+	return nil
+}
+
+func (c *Client) register() (uint16, chan Packet) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.nextID++
+	if c.nextID == 0 {
+		c.nextID = 1
+	}
+	ch := make(chan Packet, 1)
+	c.pending[c.nextID] = ch
+	return c.nextID, ch
+}
+
+func (c *Client) unregister(id uint16) {
+	c.mu.Lock()
+	delete(c.pending, id)
+	c.mu.Unlock()
+}
+
+func (c *Client) await(id uint16, wait chan Packet) (Packet, error) {
+	timer := time.NewTimer(c.timeout)
+	defer timer.Stop()
+	select {
+	case p := <-wait:
+		return p, nil
+	case <-timer.C:
+		c.unregister(id)
+		return Packet{}, errors.New("mqtt: timeout esperando ack")
+	case <-c.done:
+		if err := c.Err(); err != nil {
+			return Packet{}, err
+		}
+		return Packet{}, errors.New("mqtt: conexion cerrada")
+	}
+}
+
+func (c *Client) write(p Packet) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	_ = c.conn.SetWriteDeadline(time.Now().Add(c.timeout))
+	return WritePacket(c.conn, p)
+}
+
+// PARTE CRITICA **********************
+// El readLoop nunca se bloquea entregando mensajes: si Messages() esta lleno, descarta y cuenta (Dropped).
+// Bloquearse dejaria sin despachar PUBACK/SUBACK y Subscribe/Publish QoS 1 vencerian por timeout bajo carga.
+// FIN DE PARTE CRITICA ****************
+func (c *Client) readLoop(r *bufio.Reader, maxPacket int) {
+	defer close(c.messages)
+	for {
+		p, err := ReadPacketMax(r, maxPacket)
+		if err != nil {
+			c.shutdown(err)
+			return
+		}
+		switch p.Type {
+		case TypePublish:
+			msg, id, err := ParsePublish(p)
+			if err != nil {
+				c.shutdown(err)
+				return
+			}
+			msg.Received = time.Now()
+			if msg.QoS == 1 {
+				_ = c.write(Packet{Type: TypePuback, Body: binary.BigEndian.AppendUint16(nil, id)})
+			}
+			select {
+			case c.messages <- msg:
+			default:
+				c.dropped.Add(1)
+			}
+		case TypePuback, TypeSuback, TypeUnsuback:
+			if len(p.Body) < 2 {
+				continue
+			}
+			id := binary.BigEndian.Uint16(p.Body)
+			c.mu.Lock()
+			ch, ok := c.pending[id]
+			delete(c.pending, id)
+			c.mu.Unlock()
+			if ok {
+				ch <- p
+			}
+		}
+	}
+}
+
+func (c *Client) keepAlive(interval time.Duration) {
+	ticker := time.NewTicker(interval / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := c.write(Packet{Type: TypePingreq}); err != nil {
+				c.shutdown(err)
+				return
+			}
+		case <-c.done:
+			return
+		}
+	}
+}
+
+func (c *Client) shutdown(err error) {
+	c.once.Do(func() {
+		c.mu.Lock()
+		c.err = err
+		c.mu.Unlock()
+		close(c.done)
+		_ = c.conn.Close()
+	})
+}
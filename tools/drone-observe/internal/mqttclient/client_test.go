@@ -0,0 +1,209 @@
+package mqttclient_test
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"drone-observe/internal/mqttclient"
+	"drone-observe/internal/testenv"
+)
+
+func TestMatchTopic(t *testing.T) {
+	tests := []struct {
+		filter, topic string
+		want          bool
+	}{
+		{"drone/alpha/telemetry", "drone/alpha/telemetry", true},
+		{"drone/alpha/telemetry", "drone/alpha/event", false},
+		{"drone/+/telemetry", "drone/bravo/telemetry", true},
+		{"drone/+/telemetry", "drone/bravo/x/telemetry", false},
+		{"drone/#", "drone/alpha/event", true},
+		{"drone/#", "drone", true},
+		{"#", "drone/alpha/telemetry", true},
+		{"drone/alpha", "drone/alpha/telemetry", false},
+	}
+	for _, tt := range tests {
+		if got := mqttclient.MatchTopic(tt.filter, tt.topic); got != tt.want {
+			t.Errorf("MatchTopic(%q, %q) = %v, esperado %v", tt.filter, tt.topic, got, tt.want)
+		}
+	}
+}
+
+func TestPublishSubscribe(t *testing.T) {
+	tests := []struct {
+		name      string
+		filter    string
+		subQoS    byte
+		topic     string
+		pubQoS    byte
+		wantQoS   byte
+		delivered bool
+	}{
+		{"qos0", "drone/alpha/telemetry", 0, "drone/alpha/telemetry", 0, 0, true},
+		{"qos1 extremo a extremo", "drone/alpha/event", 1, "drone/alpha/event", 1, 1, true},
+		{"qos minimo entre publish y suscripcion", "drone/+/event", 0, "drone/alpha/event", 1, 0, true},
+		{"wildcard", "drone/#", 1, "drone/bravo/telemetry", 0, 0, true},
+		{"otro topic", "drone/alpha/event", 0, "drone/alpha/telemetry", 0, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := testenv.NewBroker(t)
+			sub := dial(t, b, "sub")
+			if code, err := sub.Subscribe(tt.filter, tt.subQoS); err != nil || code != tt.subQoS {
+				t.Fatalf("Subscribe = %d, %v", code, err)
+			}
+			pub := dial(t, b, "pub")
+			if err := pub.Publish(tt.topic, []byte(`{"seq":1}`), tt.pubQoS, false); err != nil {
+				t.Fatalf("Publish: %v", err)
+			}
+			if got := b.WaitPublished(1, time.Second); len(got) != 1 || got[0].Topic != tt.topic {
+				t.Fatalf("broker recibio %v", got)
+			}
+			select {
+			case msg := <-sub.Messages():
+				if !tt.delivered {
+					t.Fatalf("entregado %s, no esperado", msg.Topic)
+				}
+				if msg.Topic != tt.topic || string(msg.Payload) != `{"seq":1}` || msg.QoS != tt.wantQoS {
+					t.Errorf("recibido %s %q qos %d", msg.Topic, msg.Payload, msg.QoS)
+				}
+			case <-time.After(300 * time.Millisecond):
+				if tt.delivered {
+					t.Fatal("sin entrega")
+				}
+			}
+		})
+	}
+}
+
+func TestRetainedDeliveredOnSubscribe(t *testing.T) {
+	b := testenv.NewBroker(t)
+	pub := dial(t, b, "pub")
+	if err := pub.Publish("drone/alpha/status", []byte("online"), 1, true); err != nil {
+		t.Fatal(err)
+	}
+	sub := dial(t, b, "sub")
+	if _, err := sub.Subscribe("drone/+/status", 1); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case msg := <-sub.Messages():
+		if !msg.Retain || string(msg.Payload) != "online" {
+			t.Errorf("retenido = %+v", msg)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("sin retenido")
+	}
+}
+
+func TestSubscribeDenied(t *testing.T) {
+	b := testenv.NewBroker(t)
+	b.DenySubscribe("#")
+	c := dial(t, b, "sub")
+	code, err := c.Subscribe("drone/#", 0)
+	if err != nil || code != mqttclient.SubackFailure {
+		t.Fatalf("Subscribe = 0x%02x, %v; esperado 0x80", code, err)
+	}
+}
+
+func TestConnectRejected(t *testing.T) {
+	tests := []struct {
+		name     string
+		user     string
+		password string
+		code     byte
+	}{
+		{"password invalida", "alpha", "mala", 4},
+		{"anonimo", "", "", 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := testenv.NewBroker(t)
+			b.SetUsers(map[string]string{"alpha": "buena"})
+			_, err := mqttclient.Dial(b.Host, b.Port, mqttclient.Options{ClientID: "c", Username: tt.user, Password: tt.password})
+			var ce *mqttclient.ConnectError
+			if !errors.As(err, &ce) || ce.Code != tt.code {
+				t.Fatalf("Dial = %v; esperado ConnectError %d", err, tt.code)
+			}
+		})
+	}
+	b := testenv.NewBroker(t)
+	b.SetUsers(map[string]string{"alpha": "buena"})
+	c, err := mqttclient.Dial(b.Host, b.Port, mqttclient.Options{ClientID: "c", Username: "alpha", Password: "buena"})
+	if err != nil {
+		t.Fatalf("Dial con credenciales validas: %v", err)
+	}
+	c.Close()
+}
+
+func TestPasswordWithoutUser(t *testing.T) {
+	b := testenv.NewBroker(t)
+	_, err := mqttclient.Dial(b.Host, b.Port, mqttclient.Options{ClientID: "c", Password: "huerfana"})
+	if !errors.Is(err, mqttclient.ErrPasswordWithoutUser) {
+		t.Fatalf("Dial = %v; esperado ErrPasswordWithoutUser", err)
+	}
+}
+
+// Un consumidor que no lee Messages() no puede frenar los acks: Subscribe y Publish QoS 1 siguen respondiendo.
+func TestSlowConsumerKeepsAcks(t *testing.T) {
+	const flood = 1500
+	b := testenv.NewBroker(t)
+	slow, err := mqttclient.Dial(b.Host, b.Port, mqttclient.Options{ClientID: "lento", Timeout: time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer slow.Close()
+	if _, err := slow.Subscribe("drone/#", 0); err != nil {
+		t.Fatal(err)
+	}
+	pub := dial(t, b, "pub")
+	for i := 0; i < flood; i++ {
+		if err := pub.Publish("drone/alpha/telemetry", []byte("x"), 0, false); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// El PUBACK confirma que el broker ya enruto todo lo anterior hacia el consumidor lento.
+	if err := pub.Publish("otro/topic", nil, 1, false); err != nil {
+		t.Fatal(err)
+	}
+
+	// El SUBACK llega detras de la rafaga: con el readLoop bloqueado venceria por timeout.
+	if _, err := slow.Subscribe("otro/#", 1); err != nil {
+		t.Fatalf("Subscribe con Messages() lleno: %v", err)
+	}
+	if got := slow.Dropped(); got != flood-1024 {
+		t.Errorf("Dropped = %d; esperado %d", got, flood-1024)
+	}
+	if err := slow.Publish("otro/topic", []byte("y"), 1, false); err != nil {
+		t.Fatalf("Publish QoS 1 con Messages() lleno: %v", err)
+	}
+}
+
+func TestReadPacketMax(t *testing.T) {
+	// PUBLISH que declara el remaining length maximo (256 MiB) sin cuerpo.
+	r := bufio.NewReader(bytes.NewReader([]byte{0x30, 0xff, 0xff, 0xff, 0x7f}))
+	if _, err := mqttclient.ReadPacket(r); !errors.Is(err, mqttclient.ErrPacketTooLarge) {
+		t.Fatalf("ReadPacket = %v; esperado ErrPacketTooLarge", err)
+	}
+	r = bufio.NewReader(bytes.NewReader([]byte{0xd0, 0x02, 0xaa, 0xbb}))
+	if _, err := mqttclient.ReadPacketMax(r, 1); !errors.Is(err, mqttclient.ErrPacketTooLarge) {
+		t.Fatalf("ReadPacketMax(1) = %v; esperado ErrPacketTooLarge", err)
+	}
+	r = bufio.NewReader(bytes.NewReader([]byte{0xd0, 0x02, 0xaa, 0xbb}))
+	if p, err := mqttclient.ReadPacketMax(r, 2); err != nil || p.Type != mqttclient.TypePingresp || len(p.Body) != 2 {
+		t.Fatalf("ReadPacketMax(2) = %+v, %v", p, err)
+	}
+}
+
+func dial(t *testing.T, b *testenv.Broker, id string) *mqttclient.Client {
+	t.Helper()
+	c, err := mqttclient.Dial(b.Host, b.Port, mqttclient.Options{ClientID: id})
+	if err != nil {
+		t.Fatalf("Dial %s: %v", id, err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
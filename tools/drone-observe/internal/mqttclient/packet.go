@@ -0,0 +1,200 @@
+// Archivo: tools/drone-observe/internal/mqttclient/packet.go
+// Rol: codificacion minima de paquetes MQTT 3.1.1 (framing y campos basicos).
+// No hace: MQTT 5, QoS 2 ni propiedades extendidas.
+package mqttclient
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Tipos de paquete MQTT 3.1.1 (nibble alto del header fijo).
+const (
+	TypeConnect     byte = 1
+	TypeConnack     byte = 2
+	TypePublish     byte = 3
+	TypePuback      byte = 4
+	TypeSubscribe   byte = 8
+	TypeSuback      byte = 9
+	TypeUnsubscribe byte = 10
+	TypeUnsuback    byte = 11
+	TypePingreq     byte = 12
+	TypePingresp    byte = 13
+	TypeDisconnect  byte = 14
+)
+
+// SubackFailure es el codigo de retorno de SUBACK para una suscripcion rechazada.
+const SubackFailure byte = 0x80
+
+const maxRemainingLength = 268435455
+
+// DefaultMaxPacket acota el cuerpo de un paquete entrante: el protocolo permite 256 MiB y un broker roto
+// u hostil no debe poder forzar esa reserva con un solo header.
+const DefaultMaxPacket = 4 << 20
+
+// ErrPacketTooLarge indica un paquete entrante mayor al maximo aceptado.
+var ErrPacketTooLarge = errors.New("mqtt: paquete entrante demasiado grande")
+
+// Packet es un paquete MQTT con header fijo decodificado y cuerpo crudo.
+type Packet struct {
+	Type  byte
+	Flags byte
+	Body  []byte
+}
+
+// ReadPacket lee un paquete completo del stream con el limite DefaultMaxPacket.
+func ReadPacket(r *bufio.Reader) (Packet, error) {
+	return ReadPacketMax(r, DefaultMaxPacket)
+}
+
+// ReadPacketMax lee un paquete completo; si el remaining length supera max falla sin reservar el cuerpo.
+func ReadPacketMax(r *bufio.Reader, max int) (Packet, error) {
+	header, err := r.ReadByte()
+	if err != nil {
+		return Packet{}, err
+	}
+	length, err := readRemainingLength(r)
+	if err != nil {
+		return Packet{}, err
+	}
+	if length > max {
+		return Packet{}, fmt.Errorf("%w (%d bytes, maximo %d)", ErrPacketTooLarge, length, max)
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return Packet{}, err
+	}
+	return Packet{Type: header >> 4, Flags: header & 0x0f, Body: body}, nil
+}
+
+// WritePacket escribe un paquete con su header fijo.
+func WritePacket(w io.Writer, p Packet) error {
+	if len(p.Body) > maxRemainingLength {
+		return fmt.Errorf("mqtt: paquete demasiado grande (%d bytes)", len(p.Body))
+	}
+	buf := make([]byte, 0, len(p.Body)+5)
+	buf = append(buf, p.Type<<4|p.Flags&0x0f)
+	buf = appendRemainingLength(buf, len(p.Body))
+	buf = append(buf, p.Body...)
+	_, err := w.Write(buf)
+	return err
+}
+
+func readRemainingLength(r *bufio.Reader) (int, error) {
+	value := 0
+	multiplier := 1
+	for i := 0; i < 4; i++ {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		value += int(b&0x7f) * multiplier
+		if b&0x80 == 0 {
+			return value, nil
+		}
+		multiplier *= 128
+	}
+	return 0, errors.New("mqtt: remaining length invalido")
+}
+
+func appendRemainingLength(buf []byte, n int) []byte {
+	for {
+		b := byte(n % 128)
+		n /= 128
+		if n > 0 {
+			b |= 0x80
+		}
+		buf = append(buf, b)
+		if n == 0 {
+			return buf
+		}
+	}
+}
+
+// AppendString agrega un string MQTT (longitud de 2 bytes + contenido).
+func AppendString(buf []byte, s string) []byte {
+	buf = binary.BigEndian.AppendUint16(buf, uint16(len(s)))
+	return append(buf, s...)
+}
+
+// ReadString lee un string MQTT y devuelve el resto del buffer.
+func ReadString(b []byte) (string, []byte, error) {
+	if len(b) < 2 {
+		return "", nil, errors.New("mqtt: string truncado")
+	}
+	n := int(binary.BigEndian.Uint16(b))
+	if len(b) < 2+n {
+		return "", nil, errors.New("mqtt: string truncado")
+	}
+	return string(b[2 : 2+n]), b[2+n:], nil
+}
+
+// PublishPacket construye un PUBLISH; packetID se ignora con QoS 0.
+func PublishPacket(topic string, payload []byte, qos byte, retain bool, packetID uint16) Packet {
+	flags := qos << 1
+	if retain {
+		flags |= 0x01
+	}
+	body := AppendString(nil, topic)
+	if qos > 0 {
+		body = binary.BigEndian.AppendUint16(body, packetID)
+	}
+	body = append(body, payload...)
+	return Packet{Type: TypePublish, Flags: flags, Body: body}
+}
+
+// ParsePublish decodifica un PUBLISH en Message (sin timestamp de recepcion).
+func ParsePublish(p Packet) (Message, uint16, error) {
+	qos := (p.Flags >> 1) & 0x03
+	topic, rest, err := ReadString(p.Body)
+	if err != nil {
+		return Message{}, 0, err
+	}
+	var id uint16
+	if qos > 0 {
+		if len(rest) < 2 {
+			return Message{}, 0, errors.New("mqtt: publish sin packet id")
+		}
+		id = binary.BigEndian.Uint16(rest)
+		rest = rest[2:]
+	}
+	return Message{
+		Topic:   topic,
+		Payload: append([]byte(nil), rest...),
+		QoS:     qos,
+		Retain:  p.Flags&0x01 != 0,
+	}, id, nil
+}
+
+// MatchTopic indica si un topic concreto cumple un filtro con wildcards + y #.
+func MatchTopic(filter, topic string) bool {
+	fs := splitTopic(filter)
+	ts := splitTopic(topic)
+	for i, f := range fs {
+		if f == "#" {
+			return true
+		}
+		if i >= len(ts) {
+			return false
+		}
+		if f != "+" && f != ts[i] {
+			return false
+		}
+	}
+	return len(fs) == len(ts)
+}
+
+func splitTopic(s string) []string {
+	var out []string
+	start := 0
+	for i := 0; i < len(s); i++ {
+		if s[i] == '/' {
+			out = append(out, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(out, s[start:])
+}
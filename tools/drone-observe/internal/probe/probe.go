@@ -0,0 +1,269 @@
+// Archivo: tools/drone-observe/internal/probe/probe.go
+// Rol: probes puntuales contra un target (estilo blackbox_exporter) para scraping multi-target.
+// No hace: reintentos ni agregacion; cada scrape de Prometheus ejecuta un probe.
+package probe
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"drone-observe/internal/mqttclient"
+)
+
+// Nombres de modulos aceptados en /probe?module=...
+const (
+	ModuleMQTTConnect    = "mqtt_connect"
+	ModuleBackendMetrics = "backend_metrics"
+	ModuleGrafanaHealth  = "grafana_health"
+	ModuleMQTTRoundtrip  = "mqtt_roundtrip"
+)
+
+// Modules lista los modulos en orden estable para ayuda y errores.
+var Modules = []string{ModuleMQTTConnect, ModuleBackendMetrics, ModuleGrafanaHealth, ModuleMQTTRoundtrip}
+
+// El contexto del scrape acota cada probe; el timeout y el limite de cuerpo cubren exporters
+// llamados sin deadline y targets lentos o enormes.
+const (
+	httpTimeout  = 10 * time.Second
+	maxBodyBytes = 4 << 20
+)
+
+var httpClient = &http.Client{Timeout: httpTimeout}
+
+// roundtripPrefix queda fuera de drone/... para no contaminar topics del contrato.
+const roundtripPrefix = "drone-observe/probe/"
+
+// Metric es una metrica adicional especifica del modulo.
+type Metric struct {
+	Name  string
+	Help  string
+	Value float64
+}
+
+// Result es el resultado de un probe.
+type Result struct {
+	Success  bool
+	Duration time.Duration
+	Metrics  []Metric
+	Err      error
+}
+
+type moduleFunc func(ctx context.Context, target string) ([]Metric, error)
+
+var modules = map[string]moduleFunc{
+	ModuleMQTTConnect:    probeMQTTConnect,
+	ModuleBackendMetrics: probeBackendMetrics,
+	ModuleGrafanaHealth:  probeGrafanaHealth,
+	ModuleMQTTRoundtrip:  probeMQTTRoundtrip,
+}
+
+// ErrUnknownModule indica un module no soportado.
+var ErrUnknownModule = errors.New("modulo de probe desconocido")
+
+// PARTE CRITICA **********************
+// Un probe es una sola operacion real contra el target, acotada por el timeout del scrape.
+// Si se agregan reintentos, probe_success deja de reflejar lo que vio Prometheus en ese instante.
+// No cachear resultados entre scrapes.
+// FIN DE PARTE CRITICA ****************
+func Run(ctx context.Context, module, target string) (Result, error) {
+	fn, ok := modules[module]
+	if !ok {
+		return Result{}, fmt.Errorf("%w: %q (validos: %s)", ErrUnknownModule, module, strings.Join(Modules, ", "))
+	}
+	if target == "" {
+		return Result{}, errors.New("target vacio")
+	}
+	start := time.Now()
+	metrics, err := fn(ctx, target)
+	return Result{
+		Success:  err == nil,
+		Duration: time.Since(start),
+		Metrics:  metrics,
+		Err:      err,
+	}, nil
+}
+
+// WriteMetrics escribe el resultado en formato de exposicion Prometheus.
+func WriteMetrics(w io.Writer, r Result) {
+	success := 0
+	if r.Success {
+		success = 1
+	}
+	fmt.Fprintln(w, "# HELP probe_success Resultado del probe (1 = exito).")
+	fmt.Fprintln(w, "# TYPE probe_success gauge")
+	fmt.Fprintf(w, "probe_success %d\n", success)
+	fmt.Fprintln(w, "# HELP probe_duration_seconds Duracion del probe.")
+	fmt.Fprintln(w, "# TYPE probe_duration_seconds gauge")
+	fmt.Fprintf(w, "probe_duration_seconds %.6f\n", r.Duration.Seconds())
+	for _, m := range r.Metrics {
+		fmt.Fprintf(w, "# HELP %s %s\n", m.Name, m.Help)
+		fmt.Fprintf(w, "# TYPE %s gauge\n", m.Name)
+		fmt.Fprintf(w, "%s %s\n", m.Name, strconv.FormatFloat(m.Value, 'g', -1, 64))
+	}
+}
+
+func probeMQTTConnect(ctx context.Context, target string) ([]Metric, error) {
+	client, err := dialMQTT(ctx, target, "drone-observe-probe")
+	if err != nil {
+		return connackMetrics(err), err
+	}
+	_ = client.Close()
+	return connackMetrics(nil), nil
+}
+
+func probeMQTTRoundtrip(ctx context.Context, target string) ([]Metric, error) {
+	client, err := dialMQTT(ctx, target, "drone-observe-probe-rt")
+	if err != nil {
+		return connackMetrics(err), err
+	}
+	defer client.Close()
+
+	topic := fmt.Sprintf("%s%d", roundtripPrefix, time.Now().UnixNano())
+	if code, err := client.Subscribe(topic, 1); err != nil {
+		return nil, err
+	} else if code == mqttclient.SubackFailure {
+		return nil, fmt.Errorf("suscripcion rechazada: %s", topic)
+	}
+	sent := time.Now()
+	payload := []byte(strconv.FormatInt(sent.UnixNano(), 10))
+	if err := client.Publish(topic, payload, 1, false); err != nil {
+		return nil, err
+	}
+	for {
+		select {
+		case msg, ok := <-client.Messages():
+			if !ok {
+				return nil, errors.New("conexion cerrada antes del roundtrip")
+			}
+			if msg.Topic == topic && string(msg.Payload) == string(payload) {
+				return []Metric{{
+					Name:  "probe_mqtt_roundtrip_seconds",
+					Help:  "Tiempo entre publish y recepcion del mismo mensaje.",
+					Value: msg.Received.Sub(sent).Seconds(),
+				}}, nil
+			}
+		case <-ctx.Done():
+			return nil, errors.New("timeout esperando el mensaje de roundtrip")
+		}
+	}
+}
+
+func probeBackendMetrics(ctx context.Context, target string) ([]Metric, error) {
+	u := httpTarget(target, "/metrics")
+	status, body, err := httpGet(ctx, u)
+	metrics := []Metric{{Name: "probe_http_status_code", Help: "Codigo HTTP de la respuesta.", Value: float64(status)}}
+	if err != nil {
+		return metrics, err
+	}
+	samples := 0
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			samples++
+		}
+	}
+	metrics = append(metrics, Metric{Name: "probe_backend_samples", Help: "Muestras expuestas en /metrics.", Value: float64(samples)})
+	if samples == 0 {
+		return metrics, errors.New("/metrics sin muestras")
+	}
+	return metrics, nil
+}
+
+func probeGrafanaHealth(ctx context.Context, target string) ([]Metric, error) {
+	u := httpTarget(target, "/api/health")
+	status, body, err := httpGet(ctx, u)
+	metrics := []Metric{{Name: "probe_http_status_code", Help: "Codigo HTTP de la respuesta.", Value: float64(status)}}
+	if err != nil {
+		return metrics, err
+	}
+	var health struct {
+		Database string `json:"database"`
+	}
+	if err := json.Unmarshal([]byte(body), &health); err != nil {
+		return metrics, fmt.Errorf("respuesta /api/health invalida: %w", err)
+	}
+	if health.Database != "ok" {
+		return metrics, fmt.Errorf("grafana database=%q", health.Database)
+	}
+	return metrics, nil
+}
+
+func dialMQTT(ctx context.Context, target, clientID string) (*mqttclient.Client, error) {
+	host, port, err := splitHostPort(target, 1883)
+	if err != nil {
+		return nil, err
+	}
+	timeout := 3 * time.Second
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout = time.Until(deadline)
+	}
+	return mqttclient.Dial(host, port, mqttclient.Options{
+		ClientID: fmt.Sprintf("%s-%d", clientID, time.Now().UnixNano()%100000),
+		Timeout:  timeout,
+	})
+}
+
+func connackMetrics(err error) []Metric {
+	code := 0.0
+	var ce *mqttclient.ConnectError
+	if errors.As(err, &ce) {
+		code = float64(ce.Code)
+	} else if err != nil {
+		return nil
+	}
+	return []Metric{{Name: "probe_mqtt_connack_code", Help: "Codigo de retorno del CONNACK (0 = aceptado).", Value: code}}
+}
+
+func splitHostPort(target string, defaultPort int) (string, int, error) {
+	host, portStr, err := net.SplitHostPort(target)
+	if err != nil {
+		return target, defaultPort, nil
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		return "", 0, fmt.Errorf("puerto invalido en target %q", target)
+	}
+	return host, port, nil
+}
+
+func httpTarget(target, defaultPath string) string {
+	if !strings.HasPrefix(target, "http://") && !strings.HasPrefix(target, "https://") {
+		target = "http://" + target
+	}
+	rest := target[strings.Index(target, "//")+2:]
+	if !strings.Contains(rest, "/") {
+		target += defaultPath
+	}
+	return target
+}
+
+func httpGet(ctx context.Context, url string) (int, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return 0, "", err
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBodyBytes+1))
+	if err != nil {
+		return resp.StatusCode, "", err
+	}
+	if len(body) > maxBodyBytes {
+		return resp.StatusCode, "", fmt.Errorf("respuesta mayor a %d bytes", maxBodyBytes)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, "", fmt.Errorf("http status %s", resp.Status)
+	}
+	return resp.StatusCode, string(body), nil
+}
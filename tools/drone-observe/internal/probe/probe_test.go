@@ -0,0 +1,39 @@
+package probe
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"drone-observe/internal/testenv"
+)
+
+func TestBackendMetrics(t *testing.T) {
+	tests := []struct {
+		name    string
+		metrics string
+		wantErr string
+	}{
+		{"con muestras", "# TYPE mqtt_messages_total counter\nmqtt_messages_total 12\n", ""},
+		{"sin muestras", "# TYPE mqtt_messages_total counter\n", "/metrics sin muestras"},
+		{"cuerpo enorme", strings.Repeat("x 1\n", maxBodyBytes/4+1), "respuesta mayor a"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := testenv.NewBackend(t, tt.metrics)
+			res, err := Run(context.Background(), ModuleBackendMetrics, b.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantErr == "" {
+				if !res.Success {
+					t.Fatalf("probe fallo: %v", res.Err)
+				}
+				return
+			}
+			if res.Success || res.Err == nil || !strings.Contains(res.Err.Error(), tt.wantErr) {
+				t.Errorf("probe = %v, %v; esperado error %q", res.Success, res.Err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+// Archivo: tools/drone-observe/internal/prometheus/alerts.go
+// Rol: leer alertas activas (pending/firing) de Prometheus via /api/v1/alerts.
+// No hace: silencios ni notificaciones; eso es Alertmanager.
+package prometheus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Alert es una alerta tal como la evalua Prometheus.
+type Alert struct {
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations"`
+	State       string            `json:"state"`
+	ActiveAt    time.Time         `json:"activeAt"`
+	Value       string            `json:"value"`
+}
+
+// Alerts devuelve las alertas pending y firing.
+func Alerts(ctx context.Context, baseURL string) ([]Alert, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+"/api/v1/alerts", nil)
+	if err != nil {
+		return nil, err
+	}
+	client := &http.Client{Timeout: httpTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var payload struct {
+		Status string `json:"status"`
+		Error  string `json:"error"`
+		Data   struct {
+			Alerts []Alert `json:"alerts"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return nil, err
+	}
+	if payload.Status != "success" {
+		return nil, fmt.Errorf("prometheus: %s", payload.Error)
+	}
+	return payload.Data.Alerts, nil
+}
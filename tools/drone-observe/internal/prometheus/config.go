@@ -0,0 +1,98 @@
+// Archivo: tools/drone-observe/internal/prometheus/config.go
+// Rol: leer prometheus.yml versionado (global, alerting, rule_files y scrape_configs estaticos).
+// No hace: service discovery ni relabeling; solo lo declarado en el archivo.
+package prometheus
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"drone-observe/internal/promql"
+)
+
+// defaultScrapeInterval es el default de Prometheus cuando el archivo no lo declara.
+const defaultScrapeInterval = time.Minute
+
+type StaticConfig struct {
+	Targets []string          `yaml:"targets"`
+	Labels  map[string]string `yaml:"labels"`
+}
+
+// RelabelConfig es una regla de relabel_configs; solo se usa para saber si __address__ se reescribe.
+type RelabelConfig struct {
+	SourceLabels []string `yaml:"source_labels"`
+	TargetLabel  string   `yaml:"target_label"`
+	Replacement  string   `yaml:"replacement"`
+	Action       string   `yaml:"action"`
+}
+
+type ScrapeConfig struct {
+	JobName        string          `yaml:"job_name"`
+	ScrapeInterval string          `yaml:"scrape_interval"`
+	MetricsPath    string          `yaml:"metrics_path"`
+	StaticConfigs  []StaticConfig  `yaml:"static_configs"`
+	RelabelConfigs []RelabelConfig `yaml:"relabel_configs"`
+}
+
+// Addresses devuelve los host:port que Prometheus realmente scrapea. Si un relabel fija __address__
+// con un valor literal (patron exporter/probe), los targets son parametros y se scrapea ese valor.
+func (sc ScrapeConfig) Addresses() []string {
+	for _, rc := range sc.RelabelConfigs {
+		if rc.TargetLabel == "__address__" && (rc.Action == "" || rc.Action == "replace") &&
+			rc.Replacement != "" && !strings.Contains(rc.Replacement, "$") {
+			return []string{rc.Replacement}
+		}
+	}
+	var out []string
+	for _, st := range sc.StaticConfigs {
+		out = append(out, st.Targets...)
+	}
+	return out
+}
+
+// AlertmanagerConfig es una entrada de alerting.alertmanagers.
+type AlertmanagerConfig struct {
+	StaticConfigs []StaticConfig `yaml:"static_configs"`
+}
+
+type Config struct {
+	Path   string `yaml:"-"`
+	Global struct {
+		ScrapeInterval     string `yaml:"scrape_interval"`
+		EvaluationInterval string `yaml:"evaluation_interval"`
+	} `yaml:"global"`
+	Alerting struct {
+		Alertmanagers []AlertmanagerConfig `yaml:"alertmanagers"`
+	} `yaml:"alerting"`
+	RuleFiles     []string       `yaml:"rule_files"`
+	ScrapeConfigs []ScrapeConfig `yaml:"scrape_configs"`
+}
+
+func LoadConfig(path string) (Config, error) {
+	cfg := Config{Path: path}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, err
+	}
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("%s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// ScrapeInterval devuelve el intervalo del job (o el global si job es "" o no lo define).
+func (c Config) ScrapeInterval(job string) (time.Duration, error) {
+	for _, sc := range c.ScrapeConfigs {
+		if sc.JobName == job && job != "" && sc.ScrapeInterval != "" {
+			return promql.ParseDuration(sc.ScrapeInterval)
+		}
+	}
+	if c.Global.ScrapeInterval != "" {
+		return promql.ParseDuration(c.Global.ScrapeInterval)
+	}
+	return defaultScrapeInterval, nil
+}
@@ -7,8 +7,12 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -16,14 +20,124 @@ const httpTimeout = 3 * time.Second
 
 type queryResponse struct {
 	Status string `json:"status"`
+	Error  string `json:"error"`
 	Data   struct {
-		ResultType string `json:"resultType"`
-		Result     []struct {
-			Value []interface{} `json:"value"`
-		} `json:"result"`
+		ResultType string          `json:"resultType"`
+		Result     json.RawMessage `json:"result"`
 	} `json:"data"`
 }
 
+type vectorSample struct {
+	Metric map[string]string `json:"metric"`
+	Value  []interface{}     `json:"value"`
+}
+
+// Series es una serie instantanea devuelta por Prometheus con sus labels.
+type Series struct {
+	Labels    map[string]string
+	Value     float64
+	Timestamp float64
+}
+
+// LabelString devuelve los labels en formato PromQL ordenado, sin __name__.
+func (s Series) LabelString() string {
+	keys := make([]string, 0, len(s.Labels))
+	for k := range s.Labels {
+		if k == "__name__" {
+			continue
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, fmt.Sprintf("%s=%q", k, s.Labels[k]))
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+// Vector es el resultado completo de una consulta instantanea.
+type Vector []Series
+
+// Rule define como se reduce un Vector de varias series a un solo valor.
+type Rule int
+
+const (
+	// RuleSum suma valores; aplica a tasas y conteos.
+	RuleSum Rule = iota
+	// RuleMin toma el menor valor; aplica donde bajo es peor (bateria).
+	RuleMin
+	// RuleMax toma el mayor valor; aplica donde alto es peor (score, estado ML).
+	RuleMax
+	// RuleOldest toma la muestra con timestamp mas antiguo; aplica a freshness.
+	RuleOldest
+)
+
+func (r Rule) String() string {
+	switch r {
+	case RuleSum:
+		return "suma"
+	case RuleMin:
+		return "minimo"
+	case RuleMax:
+		return "maximo"
+	case RuleOldest:
+		return "mas antigua"
+	default:
+		return "desconocida"
+	}
+}
+
+// PARTE CRITICA **********************
+// La reduccion es explicita por check: nunca se toma "la primera serie".
+// Si se vuelve implicita, el resultado depende del orden de respuesta de Prometheus.
+// No cambiar la regla de un check sin documentarlo en su detalle.
+// FIN DE PARTE CRITICA ****************
+func (v Vector) Aggregate(rule Rule) (Series, bool) {
+	if len(v) == 0 {
+		return Series{}, false
+	}
+	out := v[0]
+	switch rule {
+	case RuleSum:
+		out = Series{Timestamp: v[0].Timestamp}
+		for _, s := range v {
+			out.Value += s.Value
+			if s.Timestamp < out.Timestamp {
+				out.Timestamp = s.Timestamp
+			}
+		}
+	case RuleMin:
+		for _, s := range v[1:] {
+			if s.Value < out.Value {
+				out = s
+			}
+		}
+	case RuleMax:
+		for _, s := range v[1:] {
+			if s.Value > out.Value {
+				out = s
+			}
+		}
+	case RuleOldest:
+		for _, s := range v[1:] {
+			if s.Timestamp < out.Timestamp {
+				out = s
+			}
+		}
+	}
+	return out, true
+}
+
+// MultiSeriesNote describe un resultado con varias series para una expresion
+// que deberia devolver una sola. Devuelve "" si hay una serie o ninguna.
+func (v Vector) MultiSeriesNote(rule Rule) string {
+	if len(v) <= 1 {
+		return ""
+	}
+	return fmt.Sprintf("%d series (esperada 1), regla=%s", len(v), rule)
+}
+
 func CheckReady(ctx context.Context, baseURL string) error {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+"/-/ready", nil)
 	if err != nil {
@@ -43,65 +157,119 @@ func CheckReady(ctx context.Context, baseURL string) error {
 
 // PARTE CRITICA **********************
 // Las consultas se hacen via /api/v1/query para mantener compatibilidad Prometheus.
-// Si se cambia a endpoints no estables, se rompe la validacion de contratos.
+// Retorna todas las series con labels, valor y timestamp; la reduccion la decide cada check.
+// No interpretar ausencia de datos como cero; un Vector vacio es "sin datos".
 // No agregar queries que impliquen alta cardinalidad o labels variables.
 // FIN DE PARTE CRITICA ****************
-func QueryInstant(ctx context.Context, baseURL, expr string) (float64, bool, error) {
-	val, _, ok, err := QueryInstantWithTimestamp(ctx, baseURL, expr)
-	return val, ok, err
+func QueryVector(ctx context.Context, baseURL, expr string) (Vector, error) {
+	return QueryVectorAt(ctx, baseURL, expr, time.Time{})
 }
 
-// PARTE CRITICA **********************
-// Retorna valor y timestamp de la muestra para checks de freshness.
-// Si se ignora el timestamp, se pierde la capacidad de detectar datos viejos.
-// No interpretar ausencia de datos como cero; debe marcarse como "sin datos".
-// FIN DE PARTE CRITICA ****************
-func QueryInstantWithTimestamp(ctx context.Context, baseURL, expr string) (float64, float64, bool, error) {
+// QueryVectorAt evalua la consulta en un instante dado (parametro time); cero usa el reloj de Prometheus.
+// Los timestamps de una consulta instantanea son el instante de evaluacion; la edad real de una muestra
+// se consulta con timestamp(...).
+func QueryVectorAt(ctx context.Context, baseURL, expr string, at time.Time) (Vector, error) {
 	u := fmt.Sprintf("%s/api/v1/query", baseURL)
 	q := url.Values{}
 	q.Set("query", expr)
+	if !at.IsZero() {
+		q.Set("time", FormatTime(at))
+	}
 	u = u + "?" + q.Encode()
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
 	if err != nil {
-		return 0, 0, false, err
+		return nil, err
 	}
 
 	client := &http.Client{Timeout: httpTimeout}
 	resp, err := client.Do(req)
 	if err != nil {
-		return 0, 0, false, err
+		return nil, err
 	}
 	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeQuery(body)
+}
 
+// DecodeQuery decodifica una respuesta de /api/v1/query (en vivo o archivada en un bundle).
+func DecodeQuery(body []byte) (Vector, error) {
 	var payload queryResponse
-	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
-		return 0, 0, false, err
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, err
 	}
-	if payload.Status != "success" || len(payload.Data.Result) == 0 {
-		return 0, 0, false, nil
+	if payload.Status != "success" {
+		if payload.Error != "" {
+			return nil, fmt.Errorf("prometheus: %s", payload.Error)
+		}
+		return nil, fmt.Errorf("prometheus: status %s", payload.Status)
 	}
-	if len(payload.Data.Result[0].Value) < 2 {
-		return 0, 0, false, nil
+	return decodeResult(payload.Data.ResultType, payload.Data.Result)
+}
+
+// FormatTime formatea un instante como lo acepta la API (segundos unix con milisegundos).
+func FormatTime(t time.Time) string {
+	return strconv.FormatFloat(float64(t.UnixMilli())/1000, 'f', 3, 64)
+}
+
+func decodeResult(resultType string, raw json.RawMessage) (Vector, error) {
+	switch resultType {
+	case "vector":
+		var samples []vectorSample
+		if err := json.Unmarshal(raw, &samples); err != nil {
+			return nil, err
+		}
+		out := make(Vector, 0, len(samples))
+		for _, s := range samples {
+			ts, val, ok := parseSamplePair(s.Value)
+			if !ok {
+				continue
+			}
+			out = append(out, Series{Labels: s.Metric, Value: val, Timestamp: ts})
+		}
+		return out, nil
+	case "scalar":
+		var pair []interface{}
+		if err := json.Unmarshal(raw, &pair); err != nil {
+			return nil, err
+		}
+		ts, val, ok := parseSamplePair(pair)
+		if !ok {
+			return nil, nil
+		}
+		return Vector{{Labels: map[string]string{}, Value: val, Timestamp: ts}}, nil
+	default:
+		return nil, fmt.Errorf("prometheus: resultType %q no soportado", resultType)
+	}
+}
+
+func parseSamplePair(pair []interface{}) (float64, float64, bool) {
+	if len(pair) < 2 {
+		return 0, 0, false
 	}
-	var val float64
 	var ts float64
-	switch v := payload.Data.Result[0].Value[0].(type) {
+	switch v := pair[0].(type) {
 	case string:
-		if _, err := fmt.Sscanf(v, "%f", &ts); err != nil {
-			return 0, 0, false, nil
+		parsed, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return 0, 0, false
 		}
+		ts = parsed
 	case float64:
 		ts = v
 	default:
-		return 0, 0, false, nil
+		return 0, 0, false
 	}
-	valStr, ok := payload.Data.Result[0].Value[1].(string)
+	valStr, ok := pair[1].(string)
 	if !ok {
-		return 0, 0, false, nil
+		return 0, 0, false
 	}
-	if _, err := fmt.Sscanf(valStr, "%f", &val); err != nil {
-		return 0, 0, false, nil
+	val, err := strconv.ParseFloat(valStr, 64)
+	if err != nil {
+		return 0, 0, false
 	}
-	return val, ts, true, nil
+	return ts, val, true
 }
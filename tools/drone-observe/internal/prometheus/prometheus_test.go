@@ -0,0 +1,141 @@
+package prometheus_test
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"drone-observe/internal/prometheus"
+	"drone-observe/internal/testenv"
+)
+
+func TestAggregate(t *testing.T) {
+	vec := prometheus.Vector{
+		{Labels: map[string]string{"drone_id": "a"}, Value: 40, Timestamp: 300},
+		{Labels: map[string]string{"drone_id": "b"}, Value: 10, Timestamp: 100},
+		{Labels: map[string]string{"drone_id": "c"}, Value: 25, Timestamp: 200},
+	}
+	tests := []struct {
+		rule      prometheus.Rule
+		wantValue float64
+		wantTS    float64
+	}{
+		{prometheus.RuleSum, 75, 100},
+		{prometheus.RuleMin, 10, 100},
+		{prometheus.RuleMax, 40, 300},
+		{prometheus.RuleOldest, 10, 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.rule.String(), func(t *testing.T) {
+			got, ok := vec.Aggregate(tt.rule)
+			if !ok || got.Value != tt.wantValue || got.Timestamp != tt.wantTS {
+				t.Errorf("Aggregate = %+v, %v; esperado valor %g ts %g", got, ok, tt.wantValue, tt.wantTS)
+			}
+		})
+	}
+	if _, ok := (prometheus.Vector{}).Aggregate(prometheus.RuleSum); ok {
+		t.Error("un vector vacio es sin datos, no cero")
+	}
+	if note := vec.MultiSeriesNote(prometheus.RuleMax); note != "3 series (esperada 1), regla=maximo" {
+		t.Errorf("MultiSeriesNote = %q", note)
+	}
+}
+
+func TestQueryVectorAt(t *testing.T) {
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		script  func(p *testenv.Prometheus)
+		want    []string
+		wantErr string
+	}{
+		{
+			name: "una serie",
+			script: func(p *testenv.Prometheus) {
+				p.Set("drone_battery_last_pct", testenv.Sample{Value: 87.5})
+			},
+			want: []string{"{} 87.5"},
+		},
+		{
+			name: "varias series con labels",
+			script: func(p *testenv.Prometheus) {
+				p.Set("drone_battery_last_pct",
+					testenv.Sample{Labels: map[string]string{"__name__": "drone_battery_last_pct", "drone_id": "alpha"}, Value: 50},
+					testenv.Sample{Labels: map[string]string{"drone_id": "bravo", "job": "backend"}, Value: 20})
+			},
+			want: []string{`{drone_id="alpha"} 50`, `{drone_id="bravo",job="backend"} 20`},
+		},
+		{
+			name:   "sin datos",
+			script: func(p *testenv.Prometheus) {},
+			want:   nil,
+		},
+		{
+			name: "error de Prometheus",
+			script: func(p *testenv.Prometheus) {
+				p.SetError("drone_battery_last_pct", "parse error: unexpected end of input")
+			},
+			wantErr: "prometheus: parse error: unexpected end of input",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := testenv.NewPrometheus(t)
+			tt.script(p)
+			vec, err := prometheus.QueryVectorAt(context.Background(), p.URL, "drone_battery_last_pct", at)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("err = %v; esperado %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, s := range vec {
+				got = append(got, s.LabelString()+" "+strconv.FormatFloat(s.Value, 'g', -1, 64))
+				if s.Timestamp != float64(at.Unix()) {
+					t.Errorf("timestamp = %g; esperado el instante de evaluacion %d", s.Timestamp, at.Unix())
+				}
+			}
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("series = %v; esperado %v", got, tt.want)
+			}
+			if times := p.QueryTimes(); len(times) != 1 || times[0] != prometheus.FormatTime(at) {
+				t.Errorf("parametro time = %v", times)
+			}
+		})
+	}
+}
+
+func TestCheckReady(t *testing.T) {
+	p := testenv.NewPrometheus(t)
+	if err := prometheus.CheckReady(context.Background(), p.URL); err != nil {
+		t.Fatalf("listo: %v", err)
+	}
+	p.SetReady(false)
+	if err := prometheus.CheckReady(context.Background(), p.URL); err == nil || !strings.Contains(err.Error(), "503") {
+		t.Fatalf("no listo: %v", err)
+	}
+}
+
+func TestTargets(t *testing.T) {
+	p := testenv.NewPrometheus(t)
+	p.SetTargets(
+		testenv.Target{Job: "backend", Instance: "backend:8080", Health: "up"},
+		testenv.Target{Job: "ml-analytics", Instance: "ml:9100", Health: "down", LastError: "connection refused"},
+	)
+	targets, err := prometheus.Targets(context.Background(), p.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(targets) != 2 {
+		t.Fatalf("targets = %+v", targets)
+	}
+	if targets[1].Job() != "ml-analytics" || targets[1].Instance() != "ml:9100" || targets[1].Health != "down" || targets[1].LastError != "connection refused" {
+		t.Errorf("target = %+v", targets[1])
+	}
+}
@@ -0,0 +1,65 @@
+// Archivo: tools/drone-observe/internal/prometheus/raw.go
+// Rol: leer respuestas crudas de la API (status, query, query_range) para archivarlas sin decodificar.
+// No hace: interpretar la respuesta; quien la lea despues (bundle, --from) la decodifica.
+package prometheus
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Endpoints de estado que se archivan tal cual.
+const (
+	PathTargets   = "/api/v1/targets"
+	PathRules     = "/api/v1/rules"
+	PathFlags     = "/api/v1/status/flags"
+	PathBuildInfo = "/api/v1/status/buildinfo"
+	PathTSDB      = "/api/v1/status/tsdb"
+	PathConfig    = "/api/v1/status/config"
+)
+
+// Raw devuelve el cuerpo de un GET a la API; un status HTTP fuera de 2xx es error.
+func Raw(ctx context.Context, baseURL, path string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	client := &http.Client{Timeout: httpTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return body, fmt.Errorf("%s: http status %s", path, resp.Status)
+	}
+	return body, nil
+}
+
+// RawQuery devuelve la respuesta cruda de una consulta instantanea en at (cero: reloj de Prometheus).
+func RawQuery(ctx context.Context, baseURL, expr string, at time.Time) ([]byte, error) {
+	q := url.Values{}
+	q.Set("query", expr)
+	if !at.IsZero() {
+		q.Set("time", FormatTime(at))
+	}
+	return Raw(ctx, baseURL, "/api/v1/query?"+q.Encode())
+}
+
+// RawQueryRange devuelve la respuesta cruda de /api/v1/query_range entre start y end.
+func RawQueryRange(ctx context.Context, baseURL, expr string, start, end time.Time, step time.Duration) ([]byte, error) {
+	q := url.Values{}
+	q.Set("query", expr)
+	q.Set("start", FormatTime(start))
+	q.Set("end", FormatTime(end))
+	q.Set("step", fmt.Sprintf("%gs", step.Seconds()))
+	return Raw(ctx, baseURL, "/api/v1/query_range?"+q.Encode())
+}
@@ -0,0 +1,69 @@
+// Archivo: tools/drone-observe/internal/prometheus/rules.go
+// Rol: leer reglas cargadas en Prometheus (/api/v1/rules).
+// No hace: recargar configuracion ni evaluar reglas localmente.
+package prometheus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// LoadedRule es una regla tal como la reporta Prometheus.
+type LoadedRule struct {
+	Name        string            `json:"name"`
+	Query       string            `json:"query"`
+	Duration    float64           `json:"duration"`
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations"`
+	Health      string            `json:"health"`
+	LastError   string            `json:"lastError"`
+	State       string            `json:"state"`
+	Type        string            `json:"type"`
+}
+
+// RuleGroup es un grupo de reglas cargado desde un archivo de rule_files.
+type RuleGroup struct {
+	Name  string       `json:"name"`
+	File  string       `json:"file"`
+	Rules []LoadedRule `json:"rules"`
+}
+
+// Rules devuelve los grupos de reglas cargados.
+func Rules(ctx context.Context, baseURL string) ([]RuleGroup, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+"/api/v1/rules", nil)
+	if err != nil {
+		return nil, err
+	}
+	client := &http.Client{Timeout: httpTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeRules(body)
+}
+
+// DecodeRules decodifica una respuesta de /api/v1/rules (en vivo o archivada en un bundle).
+func DecodeRules(body []byte) ([]RuleGroup, error) {
+	var payload struct {
+		Status string `json:"status"`
+		Error  string `json:"error"`
+		Data   struct {
+			Groups []RuleGroup `json:"groups"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, err
+	}
+	if payload.Status != "success" {
+		return nil, fmt.Errorf("prometheus: %s", payload.Error)
+	}
+	return payload.Data.Groups, nil
+}
@@ -0,0 +1,97 @@
+// Archivo: tools/drone-observe/internal/prometheus/targets.go
+// Rol: leer targets activos (/api/v1/targets) y metadata de metricas por target (/api/v1/targets/metadata).
+// No hace: service discovery ni targets descartados por relabeling; solo lo que Prometheus scrapea.
+package prometheus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Target es un target activo tal como lo reporta Prometheus.
+type Target struct {
+	ScrapePool         string            `json:"scrapePool"`
+	ScrapeURL          string            `json:"scrapeUrl"`
+	Labels             map[string]string `json:"labels"`
+	Health             string            `json:"health"`
+	LastError          string            `json:"lastError"`
+	LastScrape         time.Time         `json:"lastScrape"`
+	LastScrapeDuration float64           `json:"lastScrapeDuration"`
+	ScrapeInterval     string            `json:"scrapeInterval"`
+	ScrapeTimeout      string            `json:"scrapeTimeout"`
+}
+
+// Job devuelve el job del target (label job; scrapePool si el relabeling lo quito).
+func (t Target) Job() string {
+	if j := t.Labels["job"]; j != "" {
+		return j
+	}
+	return t.ScrapePool
+}
+
+// Instance devuelve el label instance (despues de relabeling).
+func (t Target) Instance() string {
+	return t.Labels["instance"]
+}
+
+// MetricMetadata es la metadata (tipo, help, unidad) que un target expone para una metrica.
+type MetricMetadata struct {
+	Target map[string]string `json:"target"`
+	Metric string            `json:"metric"`
+	Type   string            `json:"type"`
+	Help   string            `json:"help"`
+	Unit   string            `json:"unit"`
+}
+
+// Targets devuelve los targets activos.
+func Targets(ctx context.Context, baseURL string) ([]Target, error) {
+	var data struct {
+		ActiveTargets []Target `json:"activeTargets"`
+	}
+	if err := getAPI(ctx, baseURL+"/api/v1/targets?state=active", &data); err != nil {
+		return nil, err
+	}
+	return data.ActiveTargets, nil
+}
+
+// TargetMetadata devuelve la metadata de los targets de un job.
+func TargetMetadata(ctx context.Context, baseURL, job string) ([]MetricMetadata, error) {
+	q := url.Values{}
+	q.Set("match_target", fmt.Sprintf("{job=%q}", job))
+	var data []MetricMetadata
+	if err := getAPI(ctx, baseURL+"/api/v1/targets/metadata?"+q.Encode(), &data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// getAPI hace GET a un endpoint /api/v1 y decodifica data; status != success es error.
+func getAPI(ctx context.Context, u string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	client := &http.Client{Timeout: httpTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var payload struct {
+		Status string          `json:"status"`
+		Error  string          `json:"error"`
+		Data   json.RawMessage `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return fmt.Errorf("http status %s: %w", resp.Status, err)
+	}
+	if payload.Status != "success" {
+		return fmt.Errorf("prometheus: %s", payload.Error)
+	}
+	return json.Unmarshal(payload.Data, out)
+}
@@ -0,0 +1,471 @@
+// Archivo: tools/drone-observe/internal/promql/ast.go
+// Rol: AST PromQL con impresion canonica para comparar expresiones por semantica.
+// No hace: evaluacion contra series reales.
+package promql
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ValueType es el tipo PromQL de una expresion.
+type ValueType string
+
+const (
+	ValueScalar ValueType = "scalar"
+	ValueVector ValueType = "vector"
+	ValueMatrix ValueType = "matrix"
+	ValueString ValueType = "string"
+)
+
+// Expr es cualquier nodo de expresion PromQL.
+type Expr interface {
+	Type() ValueType
+	String() string
+}
+
+// MatchOp es el operador de un label matcher.
+type MatchOp string
+
+const (
+	MatchEqual     MatchOp = "="
+	MatchNotEqual  MatchOp = "!="
+	MatchRegexp    MatchOp = "=~"
+	MatchNotRegexp MatchOp = "!~"
+)
+
+type Matcher struct {
+	Name  string
+	Op    MatchOp
+	Value string
+}
+
+// matchesEmpty indica si el matcher acepta una serie sin el label (valor vacio).
+func (m Matcher) matchesEmpty() bool {
+	switch m.Op {
+	case MatchEqual:
+		return m.Value == ""
+	case MatchNotEqual:
+		return m.Value != ""
+	}
+	re, err := regexp.Compile("^(?:" + m.Value + ")$")
+	if err != nil {
+		return true
+	}
+	return re.MatchString("") == (m.Op == MatchRegexp)
+}
+
+func (m Matcher) String() string {
+	return fmt.Sprintf("%s%s%s", m.Name, m.Op, strconv.Quote(m.Value))
+}
+
+type NumberLiteral struct {
+	Val float64
+}
+
+type StringLiteral struct {
+	Val string
+}
+
+// VectorSelector selecciona series por nombre y matchers.
+type VectorSelector struct {
+	Name     string
+	Matchers []Matcher
+	Offset   time.Duration
+}
+
+// MatrixSelector es un VectorSelector con rango: metric[5m].
+type MatrixSelector struct {
+	Vector *VectorSelector
+	Range  time.Duration
+}
+
+// SubqueryExpr es expr[rango:paso].
+type SubqueryExpr struct {
+	Expr   Expr
+	Range  time.Duration
+	Step   time.Duration
+	Offset time.Duration
+}
+
+type Call struct {
+	Func string
+	Args []Expr
+}
+
+// AggregateExpr es sum/avg/count/... con agrupacion opcional.
+type AggregateExpr struct {
+	Op       string
+	Expr     Expr
+	Param    Expr
+	Grouping []string
+	Without  bool
+}
+
+// VectorMatching describe on/ignoring y group_left/group_right de un binario.
+type VectorMatching struct {
+	On        bool
+	Labels    []string
+	Card      string
+	CardLabel []string
+}
+
+type BinaryExpr struct {
+	Op         string
+	LHS, RHS   Expr
+	ReturnBool bool
+	Matching   *VectorMatching
+}
+
+type UnaryExpr struct {
+	Op   string
+	Expr Expr
+}
+
+type ParenExpr struct {
+	Expr Expr
+}
+
+func (*NumberLiteral) Type() ValueType  { return ValueScalar }
+func (*StringLiteral) Type() ValueType  { return ValueString }
+func (*VectorSelector) Type() ValueType { return ValueVector }
+func (*MatrixSelector) Type() ValueType { return ValueMatrix }
+func (*SubqueryExpr) Type() ValueType   { return ValueMatrix }
+func (*AggregateExpr) Type() ValueType  { return ValueVector }
+func (e *UnaryExpr) Type() ValueType    { return e.Expr.Type() }
+func (e *ParenExpr) Type() ValueType    { return e.Expr.Type() }
+
+func (e *Call) Type() ValueType {
+	if f, ok := functions[e.Func]; ok {
+		return f.ret
+	}
+	return ValueVector
+}
+
+func (e *BinaryExpr) Type() ValueType {
+	if e.LHS.Type() == ValueScalar && e.RHS.Type() == ValueScalar {
+		return ValueScalar
+	}
+	return ValueVector
+}
+
+// PARTE CRITICA **********************
+// String() es la forma canonica: matchers y labels de agrupacion ordenados, sin parentesis redundantes,
+// duraciones normalizadas (60s -> 1m). Dos expresiones equivalentes en sintaxis producen el mismo texto.
+// Si se cambia el formato, las comparaciones doc vs dashboard dejan de ser semanticas.
+// FIN DE PARTE CRITICA ****************
+func (e *NumberLiteral) String() string { return strconv.FormatFloat(e.Val, 'g', -1, 64) }
+func (e *StringLiteral) String() string { return strconv.Quote(e.Val) }
+
+// selectorKeywords son palabras que el parser lee como operador o modificador despues de una expresion.
+// Un selector con ese nombre (valido en Prometheus) se imprime como {__name__="..."} para que vuelva a parsear.
+var selectorKeywords = map[string]struct{}{
+	"and": {}, "or": {}, "unless": {}, "atan2": {}, "bool": {}, "on": {}, "ignoring": {},
+	"group_left": {}, "group_right": {}, "offset": {}, "by": {}, "without": {},
+}
+
+func (e *VectorSelector) String() string {
+	var ms []Matcher
+	for _, m := range e.Matchers {
+		if m.Name == "__name__" && m.Op == MatchEqual && e.Name == m.Value {
+			continue
+		}
+		ms = append(ms, m)
+	}
+	name := e.Name
+	if _, ok := selectorKeywords[strings.ToLower(name)]; ok {
+		ms = append(ms, Matcher{Name: "__name__", Op: MatchEqual, Value: name})
+		name = ""
+	}
+	sort.Slice(ms, func(i, j int) bool {
+		if ms[i].Name != ms[j].Name {
+			return ms[i].Name < ms[j].Name
+		}
+		return ms[i].Op < ms[j].Op
+	})
+	var b strings.Builder
+	b.WriteString(name)
+	if len(ms) > 0 || name == "" {
+		parts := make([]string, 0, len(ms))
+		for _, m := range ms {
+			parts = append(parts, m.String())
+		}
+		b.WriteString("{" + strings.Join(parts, ",") + "}")
+	}
+	if e.Offset != 0 {
+		b.WriteString(" offset " + FormatDuration(e.Offset))
+	}
+	return b.String()
+}
+
+func (e *MatrixSelector) String() string {
+	sel := *e.Vector
+	offset := sel.Offset
+	sel.Offset = 0
+	s := sel.String() + "[" + FormatDuration(e.Range) + "]"
+	if offset != 0 {
+		s += " offset " + FormatDuration(offset)
+	}
+	return s
+}
+
+func (e *SubqueryExpr) String() string {
+	step := ""
+	if e.Step != 0 {
+		step = FormatDuration(e.Step)
+	}
+	s := wrap(e.Expr, precedenceMax) + "[" + FormatDuration(e.Range) + ":" + step + "]"
+	if e.Offset != 0 {
+		s += " offset " + FormatDuration(e.Offset)
+	}
+	return s
+}
+
+func (e *Call) String() string {
+	args := make([]string, 0, len(e.Args))
+	for _, a := range e.Args {
+		args = append(args, unparen(a).String())
+	}
+	return e.Func + "(" + strings.Join(args, ", ") + ")"
+}
+
+func (e *AggregateExpr) String() string {
+	var b strings.Builder
+	b.WriteString(e.Op)
+	if len(e.Grouping) > 0 || e.Without {
+		labels := append([]string(nil), e.Grouping...)
+		sort.Strings(labels)
+		if e.Without {
+			b.WriteString(" without (")
+		} else {
+			b.WriteString(" by (")
+		}
+		b.WriteString(strings.Join(labels, ", ") + ") ")
+	}
+	b.WriteString("(")
+	if e.Param != nil {
+		b.WriteString(unparen(e.Param).String() + ", ")
+	}
+	b.WriteString(unparen(e.Expr).String() + ")")
+	return b.String()
+}
+
+func (e *BinaryExpr) String() string {
+	prec := precedence(e.Op)
+	lhs := wrap(e.LHS, prec)
+	// Asociatividad: ^ es asociativo a derecha, el resto a izquierda.
+	rhsPrec := prec + 1
+	if e.Op == "^" {
+		lhs = wrap(e.LHS, prec+1)
+		rhsPrec = prec
+	}
+	rhs := wrap(e.RHS, rhsPrec)
+
+	op := e.Op
+	if e.ReturnBool {
+		op += " bool"
+	}
+	if m := e.Matching.String(); m != "" {
+		op += " " + m
+	}
+	return lhs + " " + op + " " + rhs
+}
+
+// String devuelve la clausula canonica (labels ordenados); vacio si es el matching por defecto.
+func (m *VectorMatching) String() string {
+	if m == nil || (!m.On && len(m.Labels) == 0 && m.Card == "") {
+		return ""
+	}
+	labels := append([]string(nil), m.Labels...)
+	sort.Strings(labels)
+	var parts []string
+	if m.On {
+		parts = append(parts, "on ("+strings.Join(labels, ", ")+")")
+	} else if len(labels) > 0 {
+		parts = append(parts, "ignoring ("+strings.Join(labels, ", ")+")")
+	}
+	if m.Card != "" {
+		card := m.Card
+		if len(m.CardLabel) > 0 {
+			cardLabels := append([]string(nil), m.CardLabel...)
+			sort.Strings(cardLabels)
+			card += " (" + strings.Join(cardLabels, ", ") + ")"
+		}
+		parts = append(parts, card)
+	}
+	return strings.Join(parts, " ")
+}
+
+func (e *UnaryExpr) String() string {
+	// -(1) no es -1: el parser pliega -<numero> en el literal, asi que el numero conserva los parentesis.
+	if n, ok := unparen(e.Expr).(*NumberLiteral); ok {
+		return e.Op + "(" + n.String() + ")"
+	}
+	return e.Op + wrap(e.Expr, precedencePow)
+}
+
+func (e *ParenExpr) String() string {
+	return unparen(e).String()
+}
+
+// wrap imprime un hijo con parentesis solo si su precedencia es menor a la requerida.
+func wrap(e Expr, minPrec int) string {
+	inner := unparen(e)
+	if b, ok := inner.(*BinaryExpr); ok && precedence(b.Op) < minPrec {
+		return "(" + b.String() + ")"
+	}
+	if u, ok := inner.(*UnaryExpr); ok && minPrec > precedenceUnary {
+		return "(" + u.String() + ")"
+	}
+	// Un literal negativo a la izquierda de ^ se leeria como -(x ^ y).
+	if n, ok := inner.(*NumberLiteral); ok && math.Signbit(n.Val) && minPrec > precedencePow {
+		return "(" + n.String() + ")"
+	}
+	return inner.String()
+}
+
+func unparen(e Expr) Expr {
+	for {
+		p, ok := e.(*ParenExpr)
+		if !ok {
+			return e
+		}
+		e = p.Expr
+	}
+}
+
+// FormatDuration imprime una duracion en formato PromQL canonico (1h30m, 500ms).
+func FormatDuration(d time.Duration) string {
+	if d == 0 {
+		return "0s"
+	}
+	if d < 0 {
+		return "-" + FormatDuration(-d)
+	}
+	ms := int64(d / time.Millisecond)
+	var b strings.Builder
+	units := []struct {
+		name string
+		ms   int64
+	}{
+		{"y", 365 * 24 * 3600 * 1000},
+		{"w", 7 * 24 * 3600 * 1000},
+		{"d", 24 * 3600 * 1000},
+		{"h", 3600 * 1000},
+		{"m", 60 * 1000},
+		{"s", 1000},
+		{"ms", 1},
+	}
+	for _, u := range units {
+		if ms >= u.ms {
+			n := ms / u.ms
+			ms -= n * u.ms
+			b.WriteString(strconv.FormatInt(n, 10) + u.name)
+		}
+	}
+	return b.String()
+}
+
+// ParseDuration interpreta una duracion PromQL (5m, 1h30m, 2d).
+func ParseDuration(s string) (time.Duration, error) {
+	if s == "" || durationPrefix(s) != len(s) {
+		return 0, fmt.Errorf("duracion invalida %q", s)
+	}
+	var total time.Duration
+	i := 0
+	for i < len(s) {
+		j := i
+		for j < len(s) && isDigit(s[j]) {
+			j++
+		}
+		n, err := strconv.ParseInt(s[i:j], 10, 64)
+		if err != nil {
+			return 0, err
+		}
+		u := durationUnitAt(s[j:])
+		var unit time.Duration
+		switch u {
+		case "ms":
+			unit = time.Millisecond
+		case "s":
+			unit = time.Second
+		case "m":
+			unit = time.Minute
+		case "h":
+			unit = time.Hour
+		case "d":
+			unit = 24 * time.Hour
+		case "w":
+			unit = 7 * 24 * time.Hour
+		case "y":
+			unit = 365 * 24 * time.Hour
+		}
+		total += time.Duration(n) * unit
+		i = j + len(u)
+	}
+	return total, nil
+}
+
+// Inspect recorre el AST en profundidad; si fn devuelve false no desciende en ese nodo.
+func Inspect(e Expr, fn func(Expr) bool) {
+	if e == nil || !fn(e) {
+		return
+	}
+	switch n := e.(type) {
+	case *MatrixSelector:
+		Inspect(n.Vector, fn)
+	case *SubqueryExpr:
+		Inspect(n.Expr, fn)
+	case *Call:
+		for _, a := range n.Args {
+			Inspect(a, fn)
+		}
+	case *AggregateExpr:
+		Inspect(n.Param, fn)
+		Inspect(n.Expr, fn)
+	case *BinaryExpr:
+		Inspect(n.LHS, fn)
+		Inspect(n.RHS, fn)
+	case *UnaryExpr:
+		Inspect(n.Expr, fn)
+	case *ParenExpr:
+		Inspect(n.Expr, fn)
+	}
+}
+
+// MetricName devuelve el nombre de metrica de un selector (por nombre o __name__="...").
+func (e *VectorSelector) MetricName() string {
+	if e.Name != "" {
+		return e.Name
+	}
+	for _, m := range e.Matchers {
+		if m.Name == "__name__" && m.Op == MatchEqual {
+			return m.Value
+		}
+	}
+	return ""
+}
+
+// MetricNames lista, ordenados y sin duplicados, los nombres de metrica que consulta la expresion.
+func MetricNames(e Expr) []string {
+	set := map[string]struct{}{}
+	Inspect(e, func(n Expr) bool {
+		if vs, ok := n.(*VectorSelector); ok {
+			if name := vs.MetricName(); name != "" {
+				set[name] = struct{}{}
+			}
+		}
+		return true
+	})
+	out := make([]string, 0, len(set))
+	for k := range set {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,123 @@
+package promql
+
+import (
+	"errors"
+	"testing"
+)
+
+// Casos tomados de promql/parser/parse_test.go de Prometheus (v2.53/v3): fijan que el subconjunto que
+// acepta este parser coincide con la gramatica real. Las diferencias conocidas estan en doc.go.
+
+func TestConformanceValid(t *testing.T) {
+	tests := []struct {
+		input, want string
+	}{
+		{"0755", "493"},
+		{"-0755", "-493"},
+		{"0xc", "12"},
+		{".5", "0.5"},
+		{"5.", "5"},
+		{"5e-3", "0.005"},
+		{"+Inf", "+Inf"},
+		{"-Inf", "-Inf"},
+		{"+1 + -2 * 1", "(+ 1 (* -2 1))"},
+		{"1 + 2/(3*1)", "(+ 1 (/ 2 (paren (* 3 1))))"},
+		{"1 < bool 2 - 1 * 2", "(< bool 1 (- 2 (* 1 2)))"},
+		{"-some_metric", "(- some_metric)"},
+		{" +some_metric", "some_metric"},
+		{"foo * sum", "(* foo sum)"},
+		{"foo == bool 1", "(== bool foo 1)"},
+		{"foo + bar or bla and blub", "(or (+ foo bar) (and bla blub))"},
+		{"foo and bar unless baz or qux", "(or (unless (and foo bar) baz) qux)"},
+		{"bar + on(foo) bla / on(baz, buz) group_right(test) blub", "(+ on (foo) bar (/ on (baz, buz) group_right (test) bla blub))"},
+		{"foo * on(test,blub) group_left bar", "(* on (blub, test) group_left foo bar)"},
+		{"foo and on() bar", "(and on () foo bar)"},
+		{"foo / on(test,blub) group_left(bar) bar", "(/ on (blub, test) group_left (bar) foo bar)"},
+		{"foo - ignoring(test,blub) group_right(bar,foo) bar", "(- ignoring (blub, test) group_right (bar, foo) foo bar)"},
+		{"min", "min"},
+		{"foo offset 5m", "(sel foo offset 5m)"},
+		{"foo offset -7m", "(sel foo offset -7m)"},
+		{"foo OFFSET 1h30m", "(sel foo offset 1h30m)"},
+		{"foo OFFSET 1m30ms", "(sel foo offset 1m30ms)"},
+		{`foo:bar{a="bc"}`, `foo:bar{a="bc"}`},
+		{"foo{NaN='bc'}", `foo{NaN="bc"}`},
+		{"foo{bar='}'}", `foo{bar="}"}`},
+		{`foo{a="b", foo!="bar", test=~"test", bar!~"baz",}`, `foo{a="b",bar!~"baz",foo!="bar",test=~"test"}`},
+		{`{x!=""}`, `{x!=""}`},
+		{`test{a="b"}[5y] OFFSET 3d`, `(matrix (sel test{a="b"} offset 3d) 5y)`},
+		{"sum (some_metric) without (foo)", "(sum without (foo) some_metric)"},
+		{"sum by ()(some_metric)", "(sum some_metric)"},
+		{"sum by (foo,bar,)(some_metric)", "(sum by (bar,foo) some_metric)"},
+		{`count_values("value", some_metric)`, `(count_values "value" some_metric)`},
+		{"sum without(and, by, avg, count, alert, annotations)(some_metric)", "(sum without (alert,and,annotations,avg,by,count) some_metric)"},
+		{"round(some_metric, 5)", "(call round some_metric 5)"},
+		{`foo{bar="baz"}[10m5s:1h6ms]`, `(subquery foo{bar="baz"} 10m5s 1h6ms)`},
+		{"foo[10m:]", "(subquery foo 10m 0s)"},
+		{`min_over_time(rate(foo{bar="baz"}[2s])[5m:] offset 4m)[4m:3s]`, `(subquery (call min_over_time (subquery (call rate (matrix foo{bar="baz"} 2s)) 5m 0s offset 4m)) 4m 3s)`},
+		{"some_metric OFFSET 1m [10m:5s]", "(subquery (sel some_metric offset 1m) 10m 5s)"},
+		{`(foo + bar{nm="val"})[5m:] offset 10m`, `(subquery (paren (+ foo bar{nm="val"})) 5m 0s offset 10m)`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			e, err := Parse(tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := dump(e); got != tt.want {
+				t.Errorf("Parse(%q) = %s; esperado %s", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConformanceStrings(t *testing.T) {
+	tests := []struct {
+		input, want string
+	}{
+		{`"double-quoted string \" with escaped quote"`, `double-quoted string " with escaped quote`},
+		{`'single-quoted string \' with escaped quote'`, `single-quoted string ' with escaped quote`},
+		{"`backtick-quoted string`", "backtick-quoted string"},
+		{`"\a\b\f\n\r\t\v\\\" - \xFF\377\u1234\U00010111\U0001011111☺"`, "\a\b\f\n\r\t\v\\\" - \xFF\377\u1234\U00010111\U0001011111☺"},
+		{`'\a\b\f\n\r\t\v\\\' - \xFF\377\u1234\U00010111\U0001011111☺'`, "\a\b\f\n\r\t\v\\' - \xFF\377\u1234\U00010111\U0001011111☺"},
+		{"`" + `\a\b\f\n\r\t\v\\\"\' - \xFF\377\u1234\U00010111\U0001011111☺` + "`", `\a\b\f\n\r\t\v\\\"\' - \xFF\377\u1234\U00010111\U0001011111☺`},
+	}
+	for _, tt := range tests {
+		e, err := Parse(tt.input)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.input, err)
+			continue
+		}
+		if s, ok := e.(*StringLiteral); !ok || s.Val != tt.want {
+			t.Errorf("Parse(%q) = %s; esperado %q", tt.input, dump(e), tt.want)
+		}
+	}
+}
+
+// Entradas que Prometheus rechaza; los mensajes difieren (castellano), solo se fija que sean error.
+func TestConformanceInvalid(t *testing.T) {
+	for _, input := range []string{
+		"", "# just a comment\n\n", "1+", ".", "2.5.", "100..4", "0deadbeef", "1 /", "*1", "(1))", "((1)", "(",
+		"1 and 1", "1 == 1", "1 or 1", "1 unless 1", "1 !~ 1", "1 =~ 1", `-"string"`, "-test[5m]", "*test",
+		"1 offset 1d", "foo offset 1s offset 2s", "a - on(b) ignoring(c) d",
+		"foo and 1", "1 and foo", "foo or 1", "1 or on(bar) foo", "foo == on(bar) 10", "foo + group_left(baz) bar",
+		"foo and on(bar) group_left(baz) bar", "foo or on(bar) group_right(baz) bar",
+		`http_requests{group="production"} + on(instance) group_left(job,instance) cpu_count{type="smp"}`,
+		"foo + bool bar", "foo + bool 10", "foo and bool 10",
+		"{", "}", "some{", "some}", "some_metric{a=b}", `some_metric{a:b="b"}`, `foo{a*"b"}`, `foo{a>="b"}`,
+		`some_metric{a="\xff"}`, "foo{gibberish}", "foo{1}", "{}", `{x=""}`, `{x=~".*"}`, `{x!~".+"}`, `{x!="a"}`,
+		`foo{__name__="bar"}`, `foo{a=~"("}`, "foo::b{gibberish}", "foo[5mm]", "foo[5m1]", "foo[0m]", `foo["5m"]`, "foo[]",
+		"sum without(==)(some_metric)", "sum some_metric by (test)", "sum (some_metric) by test", "sum () by (test)",
+		"MIN keep_common (some_metric)", "sum (some_metric) without (test) by (test)", "sum without (test) (some_metric) by (test)",
+		"sum by (a:b) (foo)", "topk(some_metric)", "topk(some_metric,)", "topk(some_metric, other_metric)",
+		"count_values(5, other_metric)", "floor()", "floor(some_metric, other_metric)", "floor(some_metric, 1)", "floor(1)",
+		"hour(some_metric, some_metric, some_metric)", "time(some_metric)", "non_existent_function_far_bar()",
+		"rate(some_metric)", "`\\``", `"\`, `"\c"`, `"\x."`,
+		"test[5d] OFFSET 10s [10m:5s]", `(foo + bar{nm="val"})[5m:][10m:5s]`, "rate(food[1m])[1h] offset 1h",
+	} {
+		_, err := Parse(input)
+		var pe *ParseError
+		if !errors.As(err, &pe) {
+			t.Errorf("Parse(%q) = %v; esperado ParseError", input, err)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+// Archivo: tools/drone-observe/internal/promql/diff.go
+// Rol: comparar dos expresiones PromQL por AST y describir diferencias semanticas.
+// No hace: equivalencias algebraicas (a+b vs b+a se reportan como distintas).
+package promql
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Equal indica si dos expresiones son iguales en forma canonica.
+func Equal(a, b Expr) bool {
+	return a.String() == b.String()
+}
+
+// Diff describe las diferencias entre a (esperada) y b (observada), del nodo mas externo al mas interno.
+// Devuelve nil si son equivalentes.
+func Diff(a, b Expr) []string {
+	var out []string
+	diffNode(unparen(a), unparen(b), &out)
+	return out
+}
+
+func diffNode(a, b Expr, out *[]string) {
+	if a.String() == b.String() {
+		return
+	}
+	if reflect.TypeOf(a) != reflect.TypeOf(b) {
+		*out = append(*out, fmt.Sprintf("%s vs %s", a, b))
+		return
+	}
+	switch x := a.(type) {
+	case *Call:
+		y := b.(*Call)
+		if x.Func != y.Func {
+			*out = append(*out, fmt.Sprintf("funcion %s vs %s", x.Func, y.Func))
+		}
+		if len(x.Args) != len(y.Args) {
+			*out = append(*out, fmt.Sprintf("argumentos %d vs %d", len(x.Args), len(y.Args)))
+			return
+		}
+		for i := range x.Args {
+			diffNode(unparen(x.Args[i]), unparen(y.Args[i]), out)
+		}
+	case *MatrixSelector:
+		y := b.(*MatrixSelector)
+		if x.Range != y.Range {
+			*out = append(*out, fmt.Sprintf("rango %s vs %s", FormatDuration(x.Range), FormatDuration(y.Range)))
+		}
+		diffNode(x.Vector, y.Vector, out)
+	case *SubqueryExpr:
+		y := b.(*SubqueryExpr)
+		if x.Range != y.Range || x.Step != y.Step {
+			*out = append(*out, fmt.Sprintf("subquery [%s:%s] vs [%s:%s]",
+				FormatDuration(x.Range), FormatDuration(x.Step), FormatDuration(y.Range), FormatDuration(y.Step)))
+		}
+		if x.Offset != y.Offset {
+			*out = append(*out, fmt.Sprintf("offset %s vs %s", FormatDuration(x.Offset), FormatDuration(y.Offset)))
+		}
+		diffNode(unparen(x.Expr), unparen(y.Expr), out)
+	case *VectorSelector:
+		y := b.(*VectorSelector)
+		if x.MetricName() != y.MetricName() {
+			*out = append(*out, fmt.Sprintf("metrica %s vs %s", x.MetricName(), y.MetricName()))
+		}
+		if xm, ym := matcherSet(x), matcherSet(y); xm != ym {
+			*out = append(*out, fmt.Sprintf("matchers {%s} vs {%s}", xm, ym))
+		}
+		if x.Offset != y.Offset {
+			*out = append(*out, fmt.Sprintf("offset %s vs %s", FormatDuration(x.Offset), FormatDuration(y.Offset)))
+		}
+	case *AggregateExpr:
+		y := b.(*AggregateExpr)
+		if x.Op != y.Op {
+			*out = append(*out, fmt.Sprintf("agregacion %s vs %s", x.Op, y.Op))
+		}
+		if x.Without != y.Without || sortedJoin(x.Grouping) != sortedJoin(y.Grouping) {
+			*out = append(*out, fmt.Sprintf("agrupacion %s vs %s", groupingString(x), groupingString(y)))
+		}
+		if (x.Param == nil) != (y.Param == nil) {
+			*out = append(*out, "parametro de agregacion distinto")
+		} else if x.Param != nil {
+			diffNode(unparen(x.Param), unparen(y.Param), out)
+		}
+		diffNode(unparen(x.Expr), unparen(y.Expr), out)
+	case *BinaryExpr:
+		y := b.(*BinaryExpr)
+		if x.Op != y.Op || x.ReturnBool != y.ReturnBool {
+			*out = append(*out, fmt.Sprintf("operador %s vs %s", binaryOpString(x), binaryOpString(y)))
+		}
+		if xm, ym := x.Matching.String(), y.Matching.String(); xm != ym {
+			*out = append(*out, fmt.Sprintf("vector matching %s vs %s", matchingString(xm), matchingString(ym)))
+		}
+		diffNode(unparen(x.LHS), unparen(y.LHS), out)
+		diffNode(unparen(x.RHS), unparen(y.RHS), out)
+	case *UnaryExpr:
+		diffNode(unparen(x.Expr), unparen(b.(*UnaryExpr).Expr), out)
+	default:
+		*out = append(*out, fmt.Sprintf("%s vs %s", a, b))
+	}
+}
+
+func matcherSet(v *VectorSelector) string {
+	var parts []string
+	for _, m := range v.Matchers {
+		if m.Name == "__name__" {
+			continue
+		}
+		parts = append(parts, m.String())
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ",")
+}
+
+func sortedJoin(labels []string) string {
+	s := append([]string(nil), labels...)
+	sort.Strings(s)
+	return strings.Join(s, ",")
+}
+
+func groupingString(a *AggregateExpr) string {
+	switch {
+	case a.Without:
+		return "without (" + sortedJoin(a.Grouping) + ")"
+	case len(a.Grouping) > 0:
+		return "by (" + sortedJoin(a.Grouping) + ")"
+	}
+	return "sin agrupacion"
+}
+
+func matchingString(m string) string {
+	if m == "" {
+		return "por defecto"
+	}
+	return m
+}
+
+func binaryOpString(b *BinaryExpr) string {
+	if b.ReturnBool {
+		return b.Op + " bool"
+	}
+	return b.Op
+}
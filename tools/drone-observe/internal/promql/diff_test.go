@@ -0,0 +1,46 @@
+package promql
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want []string
+	}{
+		{"rate(mqtt_messages_total[1m])", "rate( mqtt_messages_total[60s] )", nil},
+		{`up{job="backend",instance="b:8080"}`, `up{instance="b:8080",job="backend"}`, nil},
+		{"rate(mqtt_messages_total[1m])", "irate(mqtt_messages_total[5m])", []string{"funcion rate vs irate", "rango 1m vs 5m"}},
+		{"sum by (job) (up)", "sum without (job) (up)", []string{"agrupacion by (job) vs without (job)"}},
+		{"a > 1", "a > bool 1", []string{"operador > vs > bool"}},
+		{"a * on (job) b", "a * ignoring (job) b", []string{"vector matching on (job) vs ignoring (job)"}},
+		{"a * on (job) group_left (env) b", "a * on (job) b", []string{"vector matching on (job) group_left (env) vs on (job)"}},
+		{"a * b", "a * on (instance, job) b", []string{"vector matching por defecto vs on (instance, job)"}},
+		// Un cambio en un hijo no oculta el cambio de matching del mismo nodo.
+		{"rate(a[1m]) / on (job) b", "rate(a[5m]) / ignoring (job) b", []string{"vector matching on (job) vs ignoring (job)", "rango 1m vs 5m"}},
+		{"-a", "+a", []string{"-a vs a"}},
+		{"a offset 5m", "a offset 1m", []string{"offset 5m vs 1m"}},
+		{"a", "rate(a[1m])", []string{"a vs rate(a[1m])"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.a+" | "+tt.b, func(t *testing.T) {
+			a, err := Parse(tt.a)
+			if err != nil {
+				t.Fatal(err)
+			}
+			b, err := Parse(tt.b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := Diff(a, b)
+			if strings.Join(got, "; ") != strings.Join(tt.want, "; ") {
+				t.Errorf("Diff = %q; esperado %q", got, tt.want)
+			}
+			if Equal(a, b) != (len(tt.want) == 0) {
+				t.Errorf("Equal = %v con diferencias %q", Equal(a, b), got)
+			}
+		})
+	}
+}
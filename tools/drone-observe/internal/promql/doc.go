@@ -0,0 +1,23 @@
+// Archivo: tools/drone-observe/internal/promql/doc.go
+// Rol: documentacion del paquete y de sus diferencias con el parser y el motor de Prometheus.
+// No hace: codigo; las diferencias se fijan con tests (conformance_test.go, eval_test.go).
+
+// Package promql es un parser y evaluador PromQL propio, sin depender del modulo de Prometheus (que arrastra
+// TSDB, Kubernetes y cientos de dependencias a un CLI de diagnostico). Lo usan lint-queries, drift, generate,
+// test (reglas offline), targets y la auditoria de reglas y dashboards.
+//
+// No es el parser de Prometheus. Acepta un subconjunto de la gramatica y, dentro de ese subconjunto, produce
+// los mismos arboles: conformance_test.go replica casos de promql/parser/parse_test.go (v2.53/v3) validos e
+// invalidos, y FuzzParseRoundTrip fija que String() vuelve a parsear al mismo arbol. Diferencias conocidas:
+//
+//   - Sin modificador @ ni start()/end(): se rechazan con error explicito.
+//   - Sin histogramas nativos (ni literales {{...}}), expresiones de duracion (5m*2), nombres de metrica o
+//     label entre comillas (UTF-8) ni funciones/agregaciones fuera de functions.go (info, limitk, sort_by_label...).
+//   - Los mensajes de error estan en castellano y la posicion es un offset en bytes, no linea:columna.
+//   - String() es canonico (matchers y labels ordenados, sin parentesis redundantes), no el formato de
+//     Prometheus; las comparaciones de drift dependen de esa forma.
+//
+// El evaluador replica Prometheus 3 para floats (lookback abierto a izquierda, stale markers, extrapolacion de
+// rate/increase) y cubre las funciones de functions.go; no hay histogramas nativos ni limites de muestras.
+// Los resultados de ruletest son compatibles con promtool en la medida en que lo es este evaluador.
+package promql
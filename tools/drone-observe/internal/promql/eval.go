@@ -0,0 +1,607 @@
+// Archivo: tools/drone-observe/internal/promql/eval.go
+// Rol: evaluar PromQL sobre un Storage en memoria (tests de reglas offline).
+// No hace: consultar Prometheus; la semantica replica Prometheus para el subconjunto que acepta Parse.
+package promql
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// Sample es un elemento de un vector instantaneo; T en milisegundos desde epoch.
+type Sample struct {
+	Labels Labels
+	T      int64
+	V      float64
+}
+
+type Vector []Sample
+
+// Value es el resultado de una evaluacion; solo el campo que corresponde a Type es valido.
+type Value struct {
+	Type   ValueType
+	Scalar float64
+	Vector Vector
+	Matrix []Series
+	String string
+}
+
+// Evaluator evalua expresiones en un instante sobre Storage.
+type Evaluator struct {
+	Storage *Storage
+	// Lookback es la ventana de un selector instantaneo (default 5m, como Prometheus).
+	Lookback time.Duration
+	// Step es el paso de subqueries sin paso explicito (default 1m; en reglas es el evaluation_interval).
+	Step time.Duration
+}
+
+const (
+	defaultLookback = 5 * time.Minute
+	defaultStep     = time.Minute
+)
+
+// Eval evalua e en ts (milisegundos desde epoch).
+func (ev *Evaluator) Eval(e Expr, ts int64) (Value, error) {
+	return ev.eval(e, ts)
+}
+
+func (ev *Evaluator) lookback() int64 {
+	if ev.Lookback > 0 {
+		return ev.Lookback.Milliseconds()
+	}
+	return defaultLookback.Milliseconds()
+}
+
+func (ev *Evaluator) step() int64 {
+	if ev.Step > 0 {
+		return ev.Step.Milliseconds()
+	}
+	return defaultStep.Milliseconds()
+}
+
+func (ev *Evaluator) eval(e Expr, ts int64) (Value, error) {
+	switch n := e.(type) {
+	case *NumberLiteral:
+		return Value{Type: ValueScalar, Scalar: n.Val}, nil
+	case *StringLiteral:
+		return Value{Type: ValueString, String: n.Val}, nil
+	case *ParenExpr:
+		return ev.eval(n.Expr, ts)
+	case *VectorSelector:
+		v, err := ev.selectVector(n, ts)
+		return Value{Type: ValueVector, Vector: v}, err
+	case *MatrixSelector, *SubqueryExpr:
+		m, _, _, err := ev.matrix(n, ts)
+		return Value{Type: ValueMatrix, Matrix: m}, err
+	case *Call:
+		return ev.call(n, ts)
+	case *AggregateExpr:
+		v, err := ev.aggregate(n, ts)
+		return Value{Type: ValueVector, Vector: v}, err
+	case *BinaryExpr:
+		return ev.binary(n, ts)
+	case *UnaryExpr:
+		val, err := ev.eval(n.Expr, ts)
+		if err != nil {
+			return val, err
+		}
+		if val.Type == ValueScalar {
+			val.Scalar = -val.Scalar
+			return val, nil
+		}
+		out := make(Vector, 0, len(val.Vector))
+		for _, s := range val.Vector {
+			out = append(out, Sample{Labels: s.Labels.withoutName(), T: ts, V: -s.V})
+		}
+		return Value{Type: ValueVector, Vector: out}, nil
+	}
+	return Value{}, fmt.Errorf("expresion no soportada: %s", e)
+}
+
+func (ev *Evaluator) vector(e Expr, ts int64) (Vector, error) {
+	val, err := ev.eval(e, ts)
+	if err != nil {
+		return nil, err
+	}
+	if val.Type != ValueVector {
+		return nil, fmt.Errorf("%s: se esperaba vector, llego %s", e, val.Type)
+	}
+	return val.Vector, nil
+}
+
+func (ev *Evaluator) scalar(e Expr, ts int64) (float64, error) {
+	val, err := ev.eval(e, ts)
+	if err != nil {
+		return 0, err
+	}
+	if val.Type != ValueScalar {
+		return 0, fmt.Errorf("%s: se esperaba escalar, llego %s", e, val.Type)
+	}
+	return val.Scalar, nil
+}
+
+func (ev *Evaluator) selectVector(vs *VectorSelector, ts int64) (Vector, error) {
+	series, err := ev.Storage.selectSeries(vs)
+	if err != nil {
+		return nil, err
+	}
+	ref := ts - vs.Offset.Milliseconds()
+	out := Vector{}
+	for _, ser := range series {
+		if p, ok := lastPoint(ser.Points, ref-ev.lookback(), ref); ok {
+			out = append(out, Sample{Labels: ser.Labels, T: p.T, V: p.V})
+		}
+	}
+	return out, nil
+}
+
+// matrix evalua un selector de rango o una subquery; devuelve tambien los extremos (start, end] del rango.
+func (ev *Evaluator) matrix(e Expr, ts int64) ([]Series, int64, int64, error) {
+	switch n := unparen(e).(type) {
+	case *MatrixSelector:
+		series, err := ev.Storage.selectSeries(n.Vector)
+		if err != nil {
+			return nil, 0, 0, err
+		}
+		end := ts - n.Vector.Offset.Milliseconds()
+		start := end - n.Range.Milliseconds()
+		var out []Series
+		for _, ser := range series {
+			if pts := window(ser.Points, start, end); len(pts) > 0 {
+				out = append(out, Series{Labels: ser.Labels, Points: pts})
+			}
+		}
+		return out, start, end, nil
+	case *SubqueryExpr:
+		step := n.Step.Milliseconds()
+		if step <= 0 {
+			step = ev.step()
+		}
+		end := ts - n.Offset.Milliseconds()
+		start := end - n.Range.Milliseconds()
+		first := (start / step) * step
+		if first <= start {
+			first += step
+		}
+		byKey := map[string]*Series{}
+		var keys []string
+		for t := first; t <= end; t += step {
+			val, err := ev.eval(n.Expr, t)
+			if err != nil {
+				return nil, 0, 0, err
+			}
+			samples := val.Vector
+			if val.Type == ValueScalar {
+				samples = Vector{{Labels: Labels{}, V: val.Scalar}}
+			}
+			for _, s := range samples {
+				key := s.Labels.String()
+				ser, ok := byKey[key]
+				if !ok {
+					ser = &Series{Labels: s.Labels}
+					byKey[key] = ser
+					keys = append(keys, key)
+				}
+				ser.Points = append(ser.Points, Point{T: t, V: s.V})
+			}
+		}
+		out := make([]Series, 0, len(keys))
+		for _, k := range keys {
+			out = append(out, *byKey[k])
+		}
+		return out, start, end, nil
+	}
+	return nil, 0, 0, fmt.Errorf("%s: se esperaba rango", e)
+}
+
+// PARTE CRITICA **********************
+// Reglas de labels y matching de Prometheus: aritmetica y comparaciones con bool descartan __name__;
+// comparaciones sin bool filtran y conservan el valor del lado izquierdo (o del vector si el otro es escalar).
+// Si esto diverge de Prometheus, un test de reglas pasa aca y la alerta real no dispara (o al reves).
+// FIN DE PARTE CRITICA ****************
+func (ev *Evaluator) binary(b *BinaryExpr, ts int64) (Value, error) {
+	lhs, err := ev.eval(b.LHS, ts)
+	if err != nil {
+		return Value{}, err
+	}
+	rhs, err := ev.eval(b.RHS, ts)
+	if err != nil {
+		return Value{}, err
+	}
+
+	switch {
+	case lhs.Type == ValueScalar && rhs.Type == ValueScalar:
+		v, keep := binop(b.Op, lhs.Scalar, rhs.Scalar)
+		if isComparison(b.Op) {
+			v = boolValue(keep)
+		}
+		return Value{Type: ValueScalar, Scalar: v}, nil
+	case lhs.Type == ValueVector && rhs.Type == ValueVector:
+		var v Vector
+		if isSetOp(b.Op) {
+			v = setOp(b, lhs.Vector, rhs.Vector)
+		} else if v, err = vectorBinop(b, lhs.Vector, rhs.Vector, ts); err != nil {
+			return Value{}, err
+		}
+		return Value{Type: ValueVector, Vector: v}, nil
+	case lhs.Type == ValueVector:
+		return Value{Type: ValueVector, Vector: vectorScalar(b, lhs.Vector, rhs.Scalar, false, ts)}, nil
+	default:
+		return Value{Type: ValueVector, Vector: vectorScalar(b, rhs.Vector, lhs.Scalar, true, ts)}, nil
+	}
+}
+
+// binop aplica op; para comparaciones devuelve el valor izquierdo y si la comparacion se cumple.
+func binop(op string, l, r float64) (float64, bool) {
+	switch op {
+	case "+":
+		return l + r, true
+	case "-":
+		return l - r, true
+	case "*":
+		return l * r, true
+	case "/":
+		return l / r, true
+	case "%":
+		return math.Mod(l, r), true
+	case "^":
+		return math.Pow(l, r), true
+	case "atan2":
+		return math.Atan2(l, r), true
+	case "==":
+		return l, l == r
+	case "!=":
+		return l, l != r
+	case ">":
+		return l, l > r
+	case "<":
+		return l, l < r
+	case ">=":
+		return l, l >= r
+	case "<=":
+		return l, l <= r
+	}
+	return math.NaN(), false
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func dropsName(b *BinaryExpr) bool {
+	return !isComparison(b.Op) || b.ReturnBool
+}
+
+func vectorScalar(b *BinaryExpr, vec Vector, scalar float64, swap bool, ts int64) Vector {
+	out := Vector{}
+	for _, s := range vec {
+		l, r := s.V, scalar
+		if swap {
+			l, r = r, l
+		}
+		v, keep := binop(b.Op, l, r)
+		if isComparison(b.Op) {
+			v = s.V
+		}
+		if b.ReturnBool {
+			v, keep = boolValue(keep), true
+		}
+		if !keep {
+			continue
+		}
+		labels := s.Labels
+		if dropsName(b) {
+			labels = labels.withoutName()
+		}
+		out = append(out, Sample{Labels: labels, T: ts, V: v})
+	}
+	return out
+}
+
+// signature es la clave de matching: labels de on(...) o todos menos __name__ e ignoring(...).
+func signature(l Labels, m *VectorMatching) string {
+	out := Labels{}
+	if m != nil && m.On {
+		for _, name := range m.Labels {
+			if v, ok := l[name]; ok {
+				out[name] = v
+			}
+		}
+		return out.String()
+	}
+	out = l.withoutName()
+	if m != nil {
+		for _, name := range m.Labels {
+			delete(out, name)
+		}
+	}
+	return out.String()
+}
+
+func setOp(b *BinaryExpr, lhs, rhs Vector) Vector {
+	rhsSigs := map[string]struct{}{}
+	for _, s := range rhs {
+		rhsSigs[signature(s.Labels, b.Matching)] = struct{}{}
+	}
+	out := Vector{}
+	switch b.Op {
+	case "and", "unless":
+		for _, s := range lhs {
+			_, ok := rhsSigs[signature(s.Labels, b.Matching)]
+			if ok == (b.Op == "and") {
+				out = append(out, s)
+			}
+		}
+	case "or":
+		lhsSigs := map[string]struct{}{}
+		for _, s := range lhs {
+			lhsSigs[signature(s.Labels, b.Matching)] = struct{}{}
+			out = append(out, s)
+		}
+		for _, s := range rhs {
+			if _, ok := lhsSigs[signature(s.Labels, b.Matching)]; !ok {
+				out = append(out, s)
+			}
+		}
+	}
+	return out
+}
+
+func vectorBinop(b *BinaryExpr, lhs, rhs Vector, ts int64) (Vector, error) {
+	card := ""
+	if b.Matching != nil {
+		card = b.Matching.Card
+	}
+	many, one := lhs, rhs
+	if card == "group_right" {
+		many, one = rhs, lhs
+	}
+
+	oneBySig := map[string]Sample{}
+	for _, s := range one {
+		sig := signature(s.Labels, b.Matching)
+		if _, dup := oneBySig[sig]; dup {
+			return nil, fmt.Errorf("%s: varias series para la firma %s en el lado 'one'", b.Op, sig)
+		}
+		oneBySig[sig] = s
+	}
+
+	out := Vector{}
+	matched := map[string]struct{}{}
+	seen := map[string]struct{}{}
+	for _, ms := range many {
+		sig := signature(ms.Labels, b.Matching)
+		os, ok := oneBySig[sig]
+		if !ok {
+			continue
+		}
+		if card == "" {
+			if _, dup := matched[sig]; dup {
+				return nil, fmt.Errorf("%s: matching muchos-a-muchos para %s (usar group_left/group_right)", b.Op, sig)
+			}
+			matched[sig] = struct{}{}
+		}
+		l, r := ms.V, os.V
+		if card == "group_right" {
+			l, r = r, l
+		}
+		v, keep := binop(b.Op, l, r)
+		if b.ReturnBool {
+			v, keep = boolValue(keep), true
+		}
+		if !keep {
+			continue
+		}
+		labels := resultLabels(b, ms.Labels, os.Labels)
+		key := labels.String()
+		if _, dup := seen[key]; dup {
+			return nil, fmt.Errorf("%s: resultado con series duplicadas %s", b.Op, key)
+		}
+		seen[key] = struct{}{}
+		out = append(out, Sample{Labels: labels, T: ts, V: v})
+	}
+	return out, nil
+}
+
+func resultLabels(b *BinaryExpr, many, one Labels) Labels {
+	out := many.copy()
+	if dropsName(b) {
+		delete(out, "__name__")
+	}
+	m := b.Matching
+	switch {
+	case m == nil:
+	case m.Card == "" && m.On:
+		kept := Labels{}
+		for _, name := range m.Labels {
+			if v, ok := out[name]; ok {
+				kept[name] = v
+			}
+		}
+		out = kept
+	case m.Card == "":
+		for _, name := range m.Labels {
+			delete(out, name)
+		}
+	default:
+		for _, name := range m.CardLabel {
+			if v := one[name]; v != "" {
+				out[name] = v
+			} else {
+				delete(out, name)
+			}
+		}
+	}
+	return out
+}
+
+type aggGroup struct {
+	labels  Labels
+	samples Vector
+}
+
+func (ev *Evaluator) aggregate(a *AggregateExpr, ts int64) (Vector, error) {
+	vec, err := ev.vector(a.Expr, ts)
+	if err != nil {
+		return nil, err
+	}
+	var param float64
+	var valueLabel string
+	if a.Param != nil {
+		val, err := ev.eval(a.Param, ts)
+		if err != nil {
+			return nil, err
+		}
+		param, valueLabel = val.Scalar, val.String
+	}
+
+	groups := map[string]*aggGroup{}
+	var keys []string
+	for _, s := range vec {
+		gl := Labels{}
+		if a.Without {
+			gl = s.Labels.withoutName()
+			for _, name := range a.Grouping {
+				delete(gl, name)
+			}
+		} else {
+			for _, name := range a.Grouping {
+				if v, ok := s.Labels[name]; ok {
+					gl[name] = v
+				}
+			}
+		}
+		key := gl.String()
+		g, ok := groups[key]
+		if !ok {
+			g = &aggGroup{labels: gl}
+			groups[key] = g
+			keys = append(keys, key)
+		}
+		g.samples = append(g.samples, s)
+	}
+	sort.Strings(keys)
+
+	out := Vector{}
+	for _, k := range keys {
+		g := groups[k]
+		switch a.Op {
+		case "topk", "bottomk":
+			samples := append(Vector(nil), g.samples...)
+			sort.SliceStable(samples, func(i, j int) bool {
+				if a.Op == "topk" {
+					return samples[i].V > samples[j].V
+				}
+				return samples[i].V < samples[j].V
+			})
+			n := int(param)
+			if n > len(samples) {
+				n = len(samples)
+			}
+			for _, s := range samples[:max(n, 0)] {
+				out = append(out, Sample{Labels: s.Labels, T: ts, V: s.V})
+			}
+		case "count_values":
+			counts := map[string]float64{}
+			var values []string
+			for _, s := range g.samples {
+				v := formatValue(s.V)
+				if _, ok := counts[v]; !ok {
+					values = append(values, v)
+				}
+				counts[v]++
+			}
+			sort.Strings(values)
+			for _, v := range values {
+				l := g.labels.copy()
+				l[valueLabel] = v
+				out = append(out, Sample{Labels: l, T: ts, V: counts[v]})
+			}
+		default:
+			values := make([]float64, 0, len(g.samples))
+			for _, s := range g.samples {
+				values = append(values, s.V)
+			}
+			out = append(out, Sample{Labels: g.labels, T: ts, V: aggregateValues(a.Op, param, values)})
+		}
+	}
+	return out, nil
+}
+
+func aggregateValues(op string, param float64, values []float64) float64 {
+	n := float64(len(values))
+	switch op {
+	case "sum", "avg":
+		sum := 0.0
+		for _, v := range values {
+			sum += v
+		}
+		if op == "avg" {
+			return sum / n
+		}
+		return sum
+	case "count":
+		return n
+	case "group":
+		return 1
+	case "min", "max":
+		out := values[0]
+		for _, v := range values[1:] {
+			if (op == "min" && v < out) || (op == "max" && v > out) || math.IsNaN(out) {
+				out = v
+			}
+		}
+		return out
+	case "stddev", "stdvar":
+		return stdvar(values, op == "stddev")
+	case "quantile":
+		return quantile(param, values)
+	}
+	return math.NaN()
+}
+
+func stdvar(values []float64, sqrt bool) float64 {
+	mean := 0.0
+	for _, v := range values {
+		mean += v
+	}
+	mean /= float64(len(values))
+	variance := 0.0
+	for _, v := range values {
+		variance += (v - mean) * (v - mean)
+	}
+	variance /= float64(len(values))
+	if sqrt {
+		return math.Sqrt(variance)
+	}
+	return variance
+}
+
+// quantile interpola linealmente entre los valores ordenados (misma formula que Prometheus).
+func quantile(q float64, values []float64) float64 {
+	switch {
+	case len(values) == 0 || math.IsNaN(q):
+		return math.NaN()
+	case q < 0:
+		return math.Inf(-1)
+	case q > 1:
+		return math.Inf(1)
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	n := float64(len(sorted))
+	rank := q * (n - 1)
+	lower := math.Max(0, math.Floor(rank))
+	upper := math.Min(n-1, lower+1)
+	weight := rank - math.Floor(rank)
+	return sorted[int(lower)]*(1-weight) + sorted[int(upper)]*weight
+}
+
+func formatValue(v float64) string {
+	return (&NumberLiteral{Val: v}).String()
+}
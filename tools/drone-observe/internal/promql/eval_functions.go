@@ -0,0 +1,501 @@
+// Archivo: tools/drone-observe/internal/promql/eval_functions.go
+// Rol: implementacion de las funciones PromQL declaradas en functions.go.
+// No hace: chequeo de tipos de argumentos; Parse ya lo garantiza.
+package promql
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var mathFunctions = map[string]func(float64) float64{
+	"abs": math.Abs, "ceil": math.Ceil, "floor": math.Floor, "exp": math.Exp,
+	"ln": math.Log, "log2": math.Log2, "log10": math.Log10, "sqrt": math.Sqrt,
+	"sgn": func(v float64) float64 {
+		switch {
+		case v > 0:
+			return 1
+		case v < 0:
+			return -1
+		}
+		return v
+	},
+}
+
+// rangeFunctions reducen las muestras de un rango (start, end] a un valor; false = sin resultado.
+var rangeFunctions = map[string]func(points []Point, start, end int64) (float64, bool){
+	"rate": func(p []Point, start, end int64) (float64, bool) {
+		return extrapolatedRate(p, start, end, true, true)
+	},
+	"increase": func(p []Point, start, end int64) (float64, bool) {
+		return extrapolatedRate(p, start, end, true, false)
+	},
+	"delta": func(p []Point, start, end int64) (float64, bool) {
+		return extrapolatedRate(p, start, end, false, false)
+	},
+	"irate":  func(p []Point, _, _ int64) (float64, bool) { return instantDelta(p, true) },
+	"idelta": func(p []Point, _, _ int64) (float64, bool) { return instantDelta(p, false) },
+	"deriv": func(p []Point, _, end int64) (float64, bool) {
+		if len(p) < 2 {
+			return 0, false
+		}
+		slope, _ := linearRegression(p, end)
+		return slope, true
+	},
+	"changes": func(p []Point, _, _ int64) (float64, bool) {
+		n := 0.0
+		for i := 1; i < len(p); i++ {
+			if p[i].V != p[i-1].V {
+				n++
+			}
+		}
+		return n, true
+	},
+	"resets": func(p []Point, _, _ int64) (float64, bool) {
+		n := 0.0
+		for i := 1; i < len(p); i++ {
+			if p[i].V < p[i-1].V {
+				n++
+			}
+		}
+		return n, true
+	},
+	"avg_over_time":     overTime("avg"),
+	"min_over_time":     overTime("min"),
+	"max_over_time":     overTime("max"),
+	"sum_over_time":     overTime("sum"),
+	"count_over_time":   overTime("count"),
+	"stddev_over_time":  overTime("stddev"),
+	"stdvar_over_time":  overTime("stdvar"),
+	"present_over_time": overTime("group"),
+	"last_over_time": func(p []Point, _, _ int64) (float64, bool) {
+		return p[len(p)-1].V, true
+	},
+}
+
+func overTime(op string) func([]Point, int64, int64) (float64, bool) {
+	return func(p []Point, _, _ int64) (float64, bool) {
+		return aggregateValues(op, 0, pointValues(p)), true
+	}
+}
+
+func pointValues(p []Point) []float64 {
+	out := make([]float64, 0, len(p))
+	for _, pt := range p {
+		out = append(out, pt.V)
+	}
+	return out
+}
+
+func (ev *Evaluator) call(c *Call, ts int64) (Value, error) {
+	if fn, ok := rangeFunctions[c.Func]; ok {
+		v, err := ev.rangeCall(c.Args[0], ts, c.Func == "last_over_time", fn)
+		return Value{Type: ValueVector, Vector: v}, err
+	}
+	if fn, ok := mathFunctions[c.Func]; ok {
+		v, err := ev.mapVector(c.Args[0], ts, fn)
+		return Value{Type: ValueVector, Vector: v}, err
+	}
+
+	vector := func(v Vector, err error) (Value, error) {
+		return Value{Type: ValueVector, Vector: v}, err
+	}
+	switch c.Func {
+	case "time":
+		return Value{Type: ValueScalar, Scalar: float64(ts) / 1000}, nil
+	case "pi":
+		return Value{Type: ValueScalar, Scalar: math.Pi}, nil
+	case "vector":
+		s, err := ev.scalar(c.Args[0], ts)
+		return vector(Vector{{Labels: Labels{}, T: ts, V: s}}, err)
+	case "scalar":
+		v, err := ev.vector(c.Args[0], ts)
+		if err != nil {
+			return Value{}, err
+		}
+		s := math.NaN()
+		if len(v) == 1 {
+			s = v[0].V
+		}
+		return Value{Type: ValueScalar, Scalar: s}, nil
+	case "timestamp":
+		v, err := ev.vector(c.Args[0], ts)
+		out := make(Vector, 0, len(v))
+		for _, s := range v {
+			out = append(out, Sample{Labels: s.Labels.withoutName(), T: ts, V: float64(s.T) / 1000})
+		}
+		return vector(out, err)
+	case "absent", "absent_over_time":
+		return vector(ev.absent(c.Args[0], ts))
+	case "quantile_over_time":
+		q, err := ev.scalar(c.Args[0], ts)
+		if err != nil {
+			return Value{}, err
+		}
+		return vector(ev.rangeCall(c.Args[1], ts, false, func(p []Point, _, _ int64) (float64, bool) {
+			return quantile(q, pointValues(p)), true
+		}))
+	case "predict_linear":
+		secs, err := ev.scalar(c.Args[1], ts)
+		if err != nil {
+			return Value{}, err
+		}
+		return vector(ev.rangeCall(c.Args[0], ts, false, func(p []Point, _, end int64) (float64, bool) {
+			if len(p) < 2 {
+				return 0, false
+			}
+			slope, intercept := linearRegression(p, end)
+			return intercept + slope*secs, true
+		}))
+	case "round", "clamp", "clamp_min", "clamp_max":
+		params := make([]float64, 0, 2)
+		for _, a := range c.Args[1:] {
+			s, err := ev.scalar(a, ts)
+			if err != nil {
+				return Value{}, err
+			}
+			params = append(params, s)
+		}
+		return vector(ev.mapVector(c.Args[0], ts, func(v float64) float64 {
+			switch c.Func {
+			case "round":
+				toNearest := 1.0
+				if len(params) > 0 {
+					toNearest = params[0]
+				}
+				inv := 1 / toNearest
+				return math.Floor(v*inv+0.5) / inv
+			case "clamp":
+				return math.Max(params[0], math.Min(params[1], v))
+			case "clamp_min":
+				return math.Max(params[0], v)
+			}
+			return math.Min(params[0], v)
+		}))
+	case "sort", "sort_desc":
+		v, err := ev.vector(c.Args[0], ts)
+		out := append(Vector(nil), v...)
+		sort.SliceStable(out, func(i, j int) bool {
+			if c.Func == "sort" {
+				return out[i].V < out[j].V
+			}
+			return out[i].V > out[j].V
+		})
+		return vector(out, err)
+	case "histogram_quantile":
+		return vector(ev.histogramQuantile(c, ts))
+	case "label_replace", "label_join":
+		return vector(ev.labelFunc(c, ts))
+	case "day_of_month", "day_of_week", "day_of_year", "days_in_month", "hour", "minute", "month", "year":
+		return vector(ev.dateFunc(c, ts))
+	}
+	return Value{}, fmt.Errorf("funcion %s no soportada por el evaluador offline", c.Func)
+}
+
+func (ev *Evaluator) rangeCall(arg Expr, ts int64, keepName bool, fn func([]Point, int64, int64) (float64, bool)) (Vector, error) {
+	series, start, end, err := ev.matrix(arg, ts)
+	if err != nil {
+		return nil, err
+	}
+	out := Vector{}
+	for _, ser := range series {
+		v, ok := fn(ser.Points, start, end)
+		if !ok {
+			continue
+		}
+		labels := ser.Labels
+		if !keepName {
+			labels = labels.withoutName()
+		}
+		out = append(out, Sample{Labels: labels, T: ts, V: v})
+	}
+	return out, nil
+}
+
+func (ev *Evaluator) mapVector(arg Expr, ts int64, fn func(float64) float64) (Vector, error) {
+	v, err := ev.vector(arg, ts)
+	if err != nil {
+		return nil, err
+	}
+	out := make(Vector, 0, len(v))
+	for _, s := range v {
+		out = append(out, Sample{Labels: s.Labels.withoutName(), T: ts, V: fn(s.V)})
+	}
+	return out, nil
+}
+
+// PARTE CRITICA **********************
+// extrapolatedRate replica rate/increase/delta de Prometheus: extrapola hasta los bordes del rango
+// (o hasta cero en counters) y corrige resets. increase(x[5m]) == 0 depende de este calculo exacto.
+// FIN DE PARTE CRITICA ****************
+func extrapolatedRate(points []Point, start, end int64, counter, perSecond bool) (float64, bool) {
+	if len(points) < 2 {
+		return 0, false
+	}
+	first, last := points[0], points[len(points)-1]
+	result := last.V - first.V
+	if counter {
+		prev := first.V
+		for _, p := range points[1:] {
+			if p.V < prev {
+				result += prev
+			}
+			prev = p.V
+		}
+	}
+
+	sampled := float64(last.T-first.T) / 1000
+	avg := sampled / float64(len(points)-1)
+	toStart := float64(first.T-start) / 1000
+	toEnd := float64(end-last.T) / 1000
+	// Mismo orden que Prometheus: primero el umbral (medio intervalo si la serie empieza dentro
+	// del rango) y despues el recorte al cero del counter sobre esa distancia.
+	threshold := avg * 1.1
+	if toStart >= threshold {
+		toStart = avg / 2
+	}
+	if counter && result > 0 && first.V >= 0 {
+		if toZero := sampled * (first.V / result); toZero < toStart {
+			toStart = toZero
+		}
+	}
+	if toEnd >= threshold {
+		toEnd = avg / 2
+	}
+	result *= (sampled + toStart + toEnd) / sampled
+	if perSecond {
+		result /= float64(end-start) / 1000
+	}
+	return result, true
+}
+
+func instantDelta(points []Point, rate bool) (float64, bool) {
+	if len(points) < 2 {
+		return 0, false
+	}
+	prev, last := points[len(points)-2], points[len(points)-1]
+	result := last.V - prev.V
+	if !rate {
+		return result, true
+	}
+	if last.V < prev.V {
+		result = last.V
+	}
+	dt := float64(last.T-prev.T) / 1000
+	if dt == 0 {
+		return 0, false
+	}
+	return result / dt, true
+}
+
+// linearRegression devuelve pendiente (por segundo) y valor estimado en at.
+func linearRegression(points []Point, at int64) (slope, intercept float64) {
+	var n, sumX, sumY, sumXY, sumX2 float64
+	for _, p := range points {
+		x := float64(p.T-at) / 1000
+		n++
+		sumX += x
+		sumY += p.V
+		sumXY += x * p.V
+		sumX2 += x * x
+	}
+	covXY := sumXY - sumX*sumY/n
+	varX := sumX2 - sumX*sumX/n
+	slope = covXY / varX
+	intercept = sumY/n - slope*sumX/n
+	return slope, intercept
+}
+
+// absent devuelve 1 si el argumento no tiene series; los labels salen de los matchers '=' del selector.
+func (ev *Evaluator) absent(arg Expr, ts int64) (Vector, error) {
+	val, err := ev.eval(arg, ts)
+	if err != nil {
+		return nil, err
+	}
+	if len(val.Vector) > 0 || len(val.Matrix) > 0 {
+		return Vector{}, nil
+	}
+	labels := Labels{}
+	var vs *VectorSelector
+	switch n := unparen(arg).(type) {
+	case *VectorSelector:
+		vs = n
+	case *MatrixSelector:
+		vs = n.Vector
+	}
+	if vs != nil {
+		for _, m := range vs.Matchers {
+			if m.Op == MatchEqual && m.Name != "__name__" {
+				labels[m.Name] = m.Value
+			}
+		}
+	}
+	return Vector{{Labels: labels, T: ts, V: 1}}, nil
+}
+
+type bucket struct {
+	upper float64
+	count float64
+}
+
+func (ev *Evaluator) histogramQuantile(c *Call, ts int64) (Vector, error) {
+	q, err := ev.scalar(c.Args[0], ts)
+	if err != nil {
+		return nil, err
+	}
+	v, err := ev.vector(c.Args[1], ts)
+	if err != nil {
+		return nil, err
+	}
+	groups := map[string]*Labels{}
+	buckets := map[string][]bucket{}
+	var keys []string
+	for _, s := range v {
+		upper, err := strconv.ParseFloat(s.Labels["le"], 64)
+		if err != nil {
+			continue
+		}
+		l := s.Labels.withoutName()
+		delete(l, "le")
+		key := l.String()
+		if _, ok := groups[key]; !ok {
+			groups[key] = &l
+			keys = append(keys, key)
+		}
+		buckets[key] = append(buckets[key], bucket{upper: upper, count: s.V})
+	}
+	sort.Strings(keys)
+	out := Vector{}
+	for _, k := range keys {
+		out = append(out, Sample{Labels: *groups[k], T: ts, V: bucketQuantile(q, buckets[k])})
+	}
+	return out, nil
+}
+
+func bucketQuantile(q float64, buckets []bucket) float64 {
+	switch {
+	case math.IsNaN(q):
+		return math.NaN()
+	case q < 0:
+		return math.Inf(-1)
+	case q > 1:
+		return math.Inf(1)
+	}
+	sort.Slice(buckets, func(i, j int) bool { return buckets[i].upper < buckets[j].upper })
+	if len(buckets) < 2 || !math.IsInf(buckets[len(buckets)-1].upper, 1) {
+		return math.NaN()
+	}
+	for i := 1; i < len(buckets); i++ {
+		if buckets[i].count < buckets[i-1].count {
+			buckets[i].count = buckets[i-1].count
+		}
+	}
+	total := buckets[len(buckets)-1].count
+	if total == 0 {
+		return math.NaN()
+	}
+	rank := q * total
+	b := sort.Search(len(buckets)-1, func(i int) bool { return buckets[i].count >= rank })
+	if b == len(buckets)-1 {
+		return buckets[len(buckets)-2].upper
+	}
+	if b == 0 && buckets[0].upper <= 0 {
+		return buckets[0].upper
+	}
+	start, end, count := 0.0, buckets[b].upper, buckets[b].count
+	if b > 0 {
+		start = buckets[b-1].upper
+		count -= buckets[b-1].count
+		rank -= buckets[b-1].count
+	}
+	return start + (end-start)*(rank/count)
+}
+
+func (ev *Evaluator) labelFunc(c *Call, ts int64) (Vector, error) {
+	v, err := ev.vector(c.Args[0], ts)
+	if err != nil {
+		return nil, err
+	}
+	strs := make([]string, 0, len(c.Args)-1)
+	for _, a := range c.Args[1:] {
+		val, err := ev.eval(a, ts)
+		if err != nil {
+			return nil, err
+		}
+		strs = append(strs, val.String)
+	}
+	dst := strs[0]
+	var re *regexp.Regexp
+	if c.Func == "label_replace" {
+		if re, err = regexp.Compile("^(?:" + strs[3] + ")$"); err != nil {
+			return nil, fmt.Errorf("label_replace: regex invalida: %v", err)
+		}
+	}
+	out := make(Vector, 0, len(v))
+	for _, s := range v {
+		var value string
+		if c.Func == "label_join" {
+			parts := make([]string, 0, len(strs)-2)
+			for _, src := range strs[2:] {
+				parts = append(parts, s.Labels[src])
+			}
+			value = strings.Join(parts, strs[1])
+		} else {
+			src := s.Labels[strs[2]]
+			idx := re.FindStringSubmatchIndex(src)
+			if idx == nil {
+				out = append(out, s)
+				continue
+			}
+			value = string(re.ExpandString(nil, strs[1], src, idx))
+		}
+		l := s.Labels.copy()
+		if value == "" {
+			delete(l, dst)
+		} else {
+			l[dst] = value
+		}
+		out = append(out, Sample{Labels: l, T: s.T, V: s.V})
+	}
+	return out, nil
+}
+
+func (ev *Evaluator) dateFunc(c *Call, ts int64) (Vector, error) {
+	v := Vector{{Labels: Labels{}, T: ts, V: float64(ts) / 1000}}
+	if len(c.Args) > 0 {
+		var err error
+		if v, err = ev.vector(c.Args[0], ts); err != nil {
+			return nil, err
+		}
+	}
+	out := make(Vector, 0, len(v))
+	for _, s := range v {
+		t := time.Unix(int64(s.V), 0).UTC()
+		var r int
+		switch c.Func {
+		case "day_of_month":
+			r = t.Day()
+		case "day_of_week":
+			r = int(t.Weekday())
+		case "day_of_year":
+			r = t.YearDay()
+		case "days_in_month":
+			r = time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+		case "hour":
+			r = t.Hour()
+		case "minute":
+			r = t.Minute()
+		case "month":
+			r = int(t.Month())
+		case "year":
+			r = t.Year()
+		}
+		out = append(out, Sample{Labels: s.Labels.withoutName(), T: ts, V: float64(r)})
+	}
+	return out, nil
+}
@@ -0,0 +1,189 @@
+package promql
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLookbackAndStaleness(t *testing.T) {
+	s := NewStorage()
+	add(t, s, `a{job="x"}`, Point{T: 0, V: 1})
+	add(t, s, `b{job="x"}`, Point{T: 0, V: 1}, Point{T: ms(time.Minute), V: 2}, Point{T: ms(2 * time.Minute), Stale: true})
+
+	tests := []struct {
+		expr     string
+		at       time.Duration
+		lookback time.Duration
+		want     string
+	}{
+		{"a", 4*time.Minute + 59*time.Second, 0, `a{job="x"} 1`},
+		// Lookback abierto a izquierda (Prometheus 3): la muestra en t=0 ya no se ve en 5m.
+		{"a", 5 * time.Minute, 0, ``},
+		{"a", 5 * time.Minute, 10 * time.Minute, `a{job="x"} 1`},
+		{"a offset 1m", 5*time.Minute + 59*time.Second, 0, `a{job="x"} 1`},
+		{"a offset 1m", 6 * time.Minute, 0, ``},
+		{"b", 90 * time.Second, 0, `b{job="x"} 2`},
+		// El stale marker corta la serie en el acto, sin esperar el lookback.
+		{"b", 2 * time.Minute, 0, ``},
+		{"b", 3 * time.Minute, 0, ``},
+		{"count_over_time(b[5m])", 3 * time.Minute, 0, `{job="x"} 2`},
+		{`absent(b{job="x"})`, 3 * time.Minute, 0, `{job="x"} 1`},
+		{"absent(b)", time.Minute, 0, ``},
+		{"timestamp(b)", 90 * time.Second, 0, `{job="x"} 60`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr+"@"+tt.at.String(), func(t *testing.T) {
+			ev := &Evaluator{Storage: s, Lookback: tt.lookback}
+			if got := evalString(t, ev, tt.expr, tt.at); got != tt.want {
+				t.Errorf("%s en %s = %q; esperado %q", tt.expr, tt.at, got, tt.want)
+			}
+		})
+	}
+}
+
+// Casos de promql/promqltest/testdata/functions.test (Prometheus 3, rangos abiertos a izquierda).
+func TestExtrapolatedRate(t *testing.T) {
+	s := NewStorage()
+	load(t, s, 5*time.Minute, `http_requests_total{path="/foo"}`, 0, 10, 10)
+	load(t, s, 5*time.Minute, `http_requests_total{path="/bar"}`, 0, 18, 10)
+	load(t, s, 5*time.Minute, `http_requests_total{path="/dings"}`, 10, 10, 10)
+	load(t, s, 5*time.Minute, `http_requests_total{path="/bumms"}`, 1, 10, 10)
+	// Counter con reset en 4m y gauge que baja y sube.
+	for i, v := range []float64{0, 10, 20, 30, 0, 10} {
+		add(t, s, "resets_total", Point{T: ms(time.Duration(i) * time.Minute), V: v})
+	}
+	for i, v := range []float64{10, 5, 20} {
+		add(t, s, "temperature", Point{T: ms(time.Duration(i) * time.Minute), V: v})
+	}
+
+	tests := []struct {
+		expr string
+		at   time.Duration
+		want string
+	}{
+		{"increase(http_requests_total[50m])", 50 * time.Minute,
+			`{path="/bar"} 180|{path="/bumms"} 100|{path="/dings"} 100|{path="/foo"} 100`},
+		// dings llegaria a cero en t=-5m: se extrapola solo medio intervalo (2m30s).
+		// bumms llegaria a cero en t=-30s: se extrapola hasta ahi y no mas.
+		{"increase(http_requests_total[100m])", 50 * time.Minute,
+			`{path="/bar"} 180|{path="/bumms"} 101|{path="/dings"} 105|{path="/foo"} 100`},
+		{`rate(http_requests_total{path="/foo"}[50m])`, 50 * time.Minute, `{path="/foo"} 0.03333333333333333`},
+		{"increase(resets_total[5m])", 5 * time.Minute, `{} 37.5`},
+		{"resets(resets_total[5m])", 5 * time.Minute, `{} 1`},
+		{"irate(resets_total[5m])", 5 * time.Minute, `{} 0.16666666666666666`},
+		{"delta(temperature[2m])", 2 * time.Minute, `{} 30`},
+		{"idelta(temperature[2m])", 2 * time.Minute, `{} 15`},
+		// Una sola muestra en el rango no alcanza para extrapolar.
+		{"rate(temperature[1m])", 2 * time.Minute, ``},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			if got := evalString(t, &Evaluator{Storage: s}, tt.expr, tt.at); got != tt.want {
+				t.Errorf("%s en %s = %q; esperado %q", tt.expr, tt.at, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVectorMatching(t *testing.T) {
+	s := NewStorage()
+	add(t, s, `requests{instance="a",job="api",method="get"}`, Point{V: 10})
+	add(t, s, `requests{instance="b",job="api",method="get"}`, Point{V: 20})
+	add(t, s, `limits{env="prod",job="api"}`, Point{V: 100})
+	add(t, s, `cpu{instance="a",job="api"}`, Point{V: 0.5})
+	add(t, s, `mem{instance="a",job="api"}`, Point{V: 2})
+
+	tests := []struct {
+		expr    string
+		want    string
+		wantErr string
+	}{
+		{expr: "cpu + mem", want: `{instance="a",job="api"} 2.5`},
+		{expr: "requests / on (job) group_left (env) limits",
+			want: `{env="prod",instance="a",job="api",method="get"} 0.1|{env="prod",instance="b",job="api",method="get"} 0.2`},
+		{expr: "limits * on (job) group_right (env) requests",
+			want: `{env="prod",instance="a",job="api",method="get"} 1000|{env="prod",instance="b",job="api",method="get"} 2000`},
+		{expr: "requests / on (job) limits", wantErr: "muchos-a-muchos"},
+		{expr: "requests / ignoring (instance, method) limits", want: ``},
+		{expr: "cpu * ignoring (instance) group_left limits", want: ``},
+		{expr: "requests * on (instance) cpu", want: `{instance="a"} 5`},
+		// Comparacion sin bool filtra y conserva __name__; con bool lo descarta.
+		{expr: "requests > 15", want: `requests{instance="b",job="api",method="get"} 20`},
+		{expr: "requests > bool 15", want: `{instance="a",job="api",method="get"} 0|{instance="b",job="api",method="get"} 1`},
+		{expr: "requests > on (instance) group_left cpu", want: `requests{instance="a",job="api",method="get"} 10`},
+		{expr: "requests and on (instance) cpu", want: `requests{instance="a",job="api",method="get"} 10`},
+		{expr: "requests unless on (instance) cpu", want: `requests{instance="b",job="api",method="get"} 20`},
+		{expr: "cpu or on (instance) requests", want: `cpu{instance="a",job="api"} 0.5|requests{instance="b",job="api",method="get"} 20`},
+		{expr: "sum by (job) (requests)", want: `{job="api"} 30`},
+		{expr: "sum without (instance) (requests)", want: `{job="api",method="get"} 30`},
+		{expr: "topk(1, requests)", want: `requests{instance="b",job="api",method="get"} 20`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			ev := &Evaluator{Storage: s}
+			if tt.wantErr != "" {
+				e, err := Parse(tt.expr)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if _, err := ev.Eval(e, 0); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v; esperado %q", err, tt.wantErr)
+				}
+				return
+			}
+			if got := evalString(t, ev, tt.expr, 0); got != tt.want {
+				t.Errorf("%s = %q; esperado %q", tt.expr, got, tt.want)
+			}
+		})
+	}
+}
+
+func ms(d time.Duration) int64 { return d.Milliseconds() }
+
+// add agrega muestras a la serie sel.
+func add(t *testing.T, s *Storage, sel string, points ...Point) {
+	t.Helper()
+	l, err := LabelsFromSelector(sel)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range points {
+		if err := s.Add(l, p); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// load replica "load <step>" de promqltest: start+inc x n son n+1 muestras desde t=0.
+func load(t *testing.T, s *Storage, step time.Duration, sel string, start, inc float64, n int) {
+	t.Helper()
+	for i := 0; i <= n; i++ {
+		add(t, s, sel, Point{T: ms(time.Duration(i) * step), V: start + float64(i)*inc})
+	}
+}
+
+// evalString evalua expr y devuelve "labels valor" ordenados, separados por '|'.
+func evalString(t *testing.T, ev *Evaluator, expr string, at time.Duration) string {
+	t.Helper()
+	e, err := Parse(expr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	val, err := ev.Eval(e, ms(at))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out []string
+	for _, s := range val.Vector {
+		l := s.Labels.String()
+		if l == "" {
+			l = "{}"
+		}
+		out = append(out, l+" "+strconv.FormatFloat(s.V, 'g', -1, 64))
+	}
+	sort.Strings(out)
+	return strings.Join(out, "|")
+}
@@ -0,0 +1,72 @@
+// Archivo: tools/drone-observe/internal/promql/functions.go
+// Rol: firmas de funciones PromQL soportadas (tipos de argumentos y retorno).
+// No hace: implementacion de las funciones.
+package promql
+
+import "fmt"
+
+type function struct {
+	args     []ValueType
+	optional int  // cantidad de argumentos finales opcionales
+	variadic bool // el ultimo tipo puede repetirse
+	ret      ValueType
+}
+
+func (f function) check(c *Call) error {
+	minArgs := len(f.args) - f.optional
+	if len(c.Args) < minArgs || (!f.variadic && len(c.Args) > len(f.args)) {
+		return fmt.Errorf("%s: cantidad de argumentos invalida (%d)", c.Func, len(c.Args))
+	}
+	for i, a := range c.Args {
+		want := f.args[len(f.args)-1]
+		if i < len(f.args) {
+			want = f.args[i]
+		}
+		if got := a.Type(); got != want {
+			return fmt.Errorf("%s: argumento %d debe ser %s, llego %s", c.Func, i+1, want, got)
+		}
+	}
+	return nil
+}
+
+var (
+	vecToVec     = function{args: []ValueType{ValueVector}, ret: ValueVector}
+	matrixToVec  = function{args: []ValueType{ValueMatrix}, ret: ValueVector}
+	optVecToVec  = function{args: []ValueType{ValueVector}, optional: 1, ret: ValueVector}
+	scalarMatrix = function{args: []ValueType{ValueScalar, ValueMatrix}, ret: ValueVector}
+)
+
+var functions = map[string]function{
+	"abs": vecToVec, "ceil": vecToVec, "floor": vecToVec, "exp": vecToVec,
+	"ln": vecToVec, "log2": vecToVec, "log10": vecToVec, "sqrt": vecToVec, "sgn": vecToVec,
+	"sort": vecToVec, "sort_desc": vecToVec, "timestamp": vecToVec, "absent": vecToVec,
+
+	"round":     {args: []ValueType{ValueVector, ValueScalar}, optional: 1, ret: ValueVector},
+	"clamp":     {args: []ValueType{ValueVector, ValueScalar, ValueScalar}, ret: ValueVector},
+	"clamp_min": {args: []ValueType{ValueVector, ValueScalar}, ret: ValueVector},
+	"clamp_max": {args: []ValueType{ValueVector, ValueScalar}, ret: ValueVector},
+
+	"rate": matrixToVec, "irate": matrixToVec, "increase": matrixToVec,
+	"delta": matrixToVec, "idelta": matrixToVec, "deriv": matrixToVec,
+	"changes": matrixToVec, "resets": matrixToVec, "absent_over_time": matrixToVec,
+	"avg_over_time": matrixToVec, "min_over_time": matrixToVec, "max_over_time": matrixToVec,
+	"sum_over_time": matrixToVec, "count_over_time": matrixToVec, "last_over_time": matrixToVec,
+	"stddev_over_time": matrixToVec, "stdvar_over_time": matrixToVec, "present_over_time": matrixToVec,
+
+	"quantile_over_time": scalarMatrix,
+	"predict_linear":     {args: []ValueType{ValueMatrix, ValueScalar}, ret: ValueVector},
+	"holt_winters":       {args: []ValueType{ValueMatrix, ValueScalar, ValueScalar}, ret: ValueVector},
+	"histogram_quantile": {args: []ValueType{ValueScalar, ValueVector}, ret: ValueVector},
+
+	"scalar": {args: []ValueType{ValueVector}, ret: ValueScalar},
+	"vector": {args: []ValueType{ValueScalar}, ret: ValueVector},
+	"time":   {ret: ValueScalar},
+	"pi":     {ret: ValueScalar},
+
+	"label_replace": {args: []ValueType{ValueVector, ValueString, ValueString, ValueString, ValueString}, ret: ValueVector},
+	"label_join":    {args: []ValueType{ValueVector, ValueString, ValueString, ValueString}, variadic: true, ret: ValueVector},
+
+	"day_of_month": optVecToVec, "day_of_week": optVecToVec, "day_of_year": optVecToVec,
+	"days_in_month": optVecToVec, "hour": optVecToVec, "minute": optVecToVec,
+	"month": optVecToVec, "year": optVecToVec,
+}
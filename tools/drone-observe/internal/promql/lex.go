@@ -0,0 +1,244 @@
+// Archivo: tools/drone-observe/internal/promql/lex.go
+// Rol: lexer PromQL (identificadores, numeros, duraciones, strings y operadores).
+// No hace: parseo ni validacion semantica; eso vive en parse.go.
+package promql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokNumber
+	tokDuration
+	tokString
+	tokOp
+	tokLParen
+	tokRParen
+	tokLBrace
+	tokRBrace
+	tokLBracket
+	tokRBracket
+	tokComma
+	tokColon
+	tokAt
+)
+
+type token struct {
+	kind tokenKind
+	val  string
+	pos  int
+}
+
+// is indica si t es alguna de las palabras clave kw; como en Prometheus, sin distinguir mayusculas.
+func (t token) is(kw ...string) bool {
+	if t.kind != tokIdent {
+		return false
+	}
+	for _, k := range kw {
+		if strings.EqualFold(t.val, k) {
+			return true
+		}
+	}
+	return false
+}
+
+func (t token) String() string {
+	if t.kind == tokEOF {
+		return "fin de expresion"
+	}
+	return fmt.Sprintf("%q", t.val)
+}
+
+// Operadores de dos caracteres antes que los de uno para que el lexer sea greedy.
+var operators = []string{"==", "!=", ">=", "<=", "=~", "!~", "+", "-", "*", "/", "%", "^", ">", "<", "="}
+
+func lex(input string) ([]token, error) {
+	var out []token
+	i := 0
+	for i < len(input) {
+		c := input[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '#':
+			for i < len(input) && input[i] != '\n' {
+				i++
+			}
+		case c == '(':
+			out = append(out, token{tokLParen, "(", i})
+			i++
+		case c == ')':
+			out = append(out, token{tokRParen, ")", i})
+			i++
+		case c == '{':
+			out = append(out, token{tokLBrace, "{", i})
+			i++
+		case c == '}':
+			out = append(out, token{tokRBrace, "}", i})
+			i++
+		case c == '[':
+			out = append(out, token{tokLBracket, "[", i})
+			i++
+		case c == ']':
+			out = append(out, token{tokRBracket, "]", i})
+			i++
+		case c == ',':
+			out = append(out, token{tokComma, ",", i})
+			i++
+		case c == ':':
+			out = append(out, token{tokColon, ":", i})
+			i++
+		case c == '@':
+			out = append(out, token{tokAt, "@", i})
+			i++
+		case c == '"' || c == '\'' || c == '`':
+			s, n, err := lexString(input[i:])
+			if err != nil {
+				return nil, &ParseError{Pos: i, Msg: err.Error()}
+			}
+			out = append(out, token{tokString, s, i})
+			i += n
+		case isDigit(c) || (c == '.' && i+1 < len(input) && isDigit(input[i+1])):
+			tok, n := lexNumberOrDuration(input[i:])
+			tok.pos = i
+			out = append(out, tok)
+			i += n
+		case isIdentStart(c):
+			j := i + 1
+			for j < len(input) && isIdentChar(input[j]) {
+				j++
+			}
+			out = append(out, token{tokIdent, input[i:j], i})
+			i = j
+		default:
+			matched := false
+			for _, op := range operators {
+				if strings.HasPrefix(input[i:], op) {
+					out = append(out, token{tokOp, op, i})
+					i += len(op)
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, &ParseError{Pos: i, Msg: fmt.Sprintf("caracter inesperado %q", c)}
+			}
+		}
+	}
+	return append(out, token{tokEOF, "", len(input)}), nil
+}
+
+// lexString decodifica los escapes como strconv.UnquoteChar (\x, \u, octal, ...), igual que Prometheus;
+// un escape desconocido es error. Entre backticks no hay escapes.
+func lexString(s string) (string, int, error) {
+	quote := s[0]
+	var b strings.Builder
+	for i := 1; i < len(s); {
+		c := s[i]
+		if c == quote {
+			return b.String(), i + 1, nil
+		}
+		if quote == '`' {
+			b.WriteByte(c)
+			i++
+			continue
+		}
+		r, multibyte, tail, err := strconv.UnquoteChar(s[i:], quote)
+		if err != nil {
+			if c == '\\' && i+1 < len(s) {
+				return "", 0, fmt.Errorf("escape invalido %q en string", s[i:i+2])
+			}
+			break
+		}
+		if r < utf8.RuneSelf || !multibyte {
+			b.WriteByte(byte(r))
+		} else {
+			b.WriteRune(r)
+		}
+		i = len(s) - len(tail)
+	}
+	return "", 0, fmt.Errorf("string sin cerrar")
+}
+
+func lexNumberOrDuration(s string) (token, int) {
+	// Duraciones: secuencias <entero><unidad> (5m, 1h30m, 500ms).
+	if n := durationPrefix(s); n > 0 {
+		return token{kind: tokDuration, val: s[:n]}, n
+	}
+	i := 0
+	if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
+		i = 2
+		for i < len(s) && strings.ContainsRune("0123456789abcdefABCDEF", rune(s[i])) {
+			i++
+		}
+		return token{kind: tokNumber, val: s[:i]}, i
+	}
+	for i < len(s) && (isDigit(s[i]) || s[i] == '.') {
+		i++
+	}
+	if i < len(s) && (s[i] == 'e' || s[i] == 'E') {
+		j := i + 1
+		if j < len(s) && (s[j] == '+' || s[j] == '-') {
+			j++
+		}
+		if j < len(s) && isDigit(s[j]) {
+			i = j
+			for i < len(s) && isDigit(s[i]) {
+				i++
+			}
+		}
+	}
+	return token{kind: tokNumber, val: s[:i]}, i
+}
+
+func durationPrefix(s string) int {
+	i := 0
+	units := 0
+	for i < len(s) && isDigit(s[i]) {
+		j := i
+		for j < len(s) && isDigit(s[j]) {
+			j++
+		}
+		u := durationUnitAt(s[j:])
+		if u == "" {
+			break
+		}
+		i = j + len(u)
+		units++
+	}
+	// ':' puede seguir a una duracion en subqueries ([5m:1m]).
+	if units == 0 || (i < len(s) && s[i] != ':' && isIdentChar(s[i])) {
+		return 0
+	}
+	return i
+}
+
+func durationUnitAt(s string) string {
+	for _, u := range []string{"ms", "s", "m", "h", "d", "w", "y"} {
+		if strings.HasPrefix(s, u) {
+			// "m" no debe capturar el inicio de "ms" ya probado; "5min" no es duracion.
+			rest := s[len(u):]
+			if rest != "" && unicode.IsLetter(rune(rest[0])) && !strings.ContainsRune("smhdwy", rune(rest[0])) {
+				return ""
+			}
+			return u
+		}
+	}
+	return ""
+}
+
+func isDigit(c byte) bool { return c >= '0' && c <= '9' }
+
+func isIdentStart(c byte) bool {
+	return c == '_' || c == ':' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isIdentChar(c byte) bool { return isIdentStart(c) || isDigit(c) }
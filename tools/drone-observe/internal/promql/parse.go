@@ -0,0 +1,567 @@
+// Archivo: tools/drone-observe/internal/promql/parse.go
+// Rol: parser PromQL de descenso recursivo con chequeo de tipos basico.
+// No hace: consultas a Prometheus ni optimizacion de expresiones.
+package promql
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ParseError indica un error de sintaxis o de tipos con su posicion (byte) en la expresion.
+type ParseError struct {
+	Pos int
+	Msg string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("posicion %d: %s", e.Pos, e.Msg)
+}
+
+// Precedencias de operadores binarios (mayor liga mas fuerte).
+const (
+	precedenceOr    = 1
+	precedenceAnd   = 2
+	precedenceCmp   = 3
+	precedenceAdd   = 4
+	precedenceMul   = 5
+	precedenceUnary = 6
+	precedencePow   = 7
+	precedenceMax   = 8
+)
+
+func precedence(op string) int {
+	switch op {
+	case "or":
+		return precedenceOr
+	case "and", "unless":
+		return precedenceAnd
+	case "==", "!=", ">", "<", ">=", "<=":
+		return precedenceCmp
+	case "+", "-":
+		return precedenceAdd
+	case "*", "/", "%", "atan2":
+		return precedenceMul
+	case "^":
+		return precedencePow
+	}
+	return 0
+}
+
+func isComparison(op string) bool { return precedence(op) == precedenceCmp }
+
+func isSetOp(op string) bool { return op == "and" || op == "or" || op == "unless" }
+
+var aggregations = map[string]struct{}{
+	"sum": {}, "avg": {}, "count": {}, "min": {}, "max": {}, "group": {},
+	"stddev": {}, "stdvar": {}, "topk": {}, "bottomk": {}, "quantile": {}, "count_values": {},
+}
+
+type parser struct {
+	toks []token
+	pos  int
+}
+
+// PARTE CRITICA **********************
+// Parse acepta el subconjunto de PromQL que usan dashboards y reglas del repo, con tipos verificados.
+// Si se relaja el chequeo de tipos, una expresion invalida en Grafana pasaria como valida en drift.
+// No agregar funciones sin declarar su firma en functions.go.
+// FIN DE PARTE CRITICA ****************
+func Parse(input string) (Expr, error) {
+	toks, err := lex(input)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{toks: toks}
+	if p.peek().kind == tokEOF {
+		return nil, &ParseError{Pos: 0, Msg: "expresion vacia"}
+	}
+	expr, err := p.parseExpr(precedenceOr)
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, p.errorf(t, "token inesperado %s", t)
+	}
+	return expr, nil
+}
+
+func (p *parser) peek() token { return p.toks[p.pos] }
+
+func (p *parser) next() token {
+	t := p.toks[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) errorf(t token, format string, args ...any) error {
+	return &ParseError{Pos: t.pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *parser) expect(kind tokenKind, what string) (token, error) {
+	t := p.next()
+	if t.kind != kind {
+		return t, p.errorf(t, "se esperaba %s, llego %s", what, t)
+	}
+	return t, nil
+}
+
+// binaryOp devuelve el operador binario del token actual, si lo es.
+func (p *parser) binaryOp() string {
+	t := p.peek()
+	switch t.kind {
+	case tokOp:
+		if t.val != "=" && t.val != "=~" && t.val != "!~" {
+			return t.val
+		}
+	case tokIdent:
+		switch strings.ToLower(t.val) {
+		case "and", "or", "unless", "atan2":
+			return strings.ToLower(t.val)
+		}
+	}
+	return ""
+}
+
+func (p *parser) parseExpr(minPrec int) (Expr, error) {
+	lhs, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op := p.binaryOp()
+		prec := precedence(op)
+		if op == "" || prec < minPrec {
+			return lhs, nil
+		}
+		opTok := p.next()
+		bin := &BinaryExpr{Op: op, LHS: lhs}
+		if err := p.parseBinaryModifiers(bin); err != nil {
+			return nil, err
+		}
+		next := prec + 1
+		if op == "^" {
+			next = prec
+		}
+		if bin.RHS, err = p.parseExpr(next); err != nil {
+			return nil, err
+		}
+		if err := checkBinary(bin); err != nil {
+			return nil, p.errorf(opTok, "%v", err)
+		}
+		lhs = bin
+	}
+}
+
+func (p *parser) parseBinaryModifiers(bin *BinaryExpr) error {
+	if t := p.peek(); t.is("bool") {
+		p.next()
+		if !isComparison(bin.Op) {
+			return p.errorf(t, "bool solo aplica a comparaciones")
+		}
+		bin.ReturnBool = true
+	}
+	t := p.peek()
+	if !t.is("on", "ignoring") {
+		return nil
+	}
+	p.next()
+	labels, err := p.parseLabelList()
+	if err != nil {
+		return err
+	}
+	bin.Matching = &VectorMatching{On: t.is("on"), Labels: labels}
+	if t := p.peek(); t.is("group_left", "group_right") {
+		p.next()
+		bin.Matching.Card = strings.ToLower(t.val)
+		if p.peek().kind == tokLParen {
+			if bin.Matching.CardLabel, err = p.parseLabelList(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (p *parser) parseLabelList() ([]string, error) {
+	if _, err := p.expect(tokLParen, "'('"); err != nil {
+		return nil, err
+	}
+	var labels []string
+	for p.peek().kind != tokRParen {
+		t, err := p.expectLabel()
+		if err != nil {
+			return nil, err
+		}
+		labels = append(labels, t.val)
+		if p.peek().kind != tokComma {
+			break
+		}
+		p.next()
+	}
+	if _, err := p.expect(tokRParen, "')'"); err != nil {
+		return nil, err
+	}
+	return labels, nil
+}
+
+// expectLabel lee un nombre de label: identificador sin ':' (los ':' solo valen en nombres de metrica).
+func (p *parser) expectLabel() (token, error) {
+	t, err := p.expect(tokIdent, "nombre de label")
+	if err != nil {
+		return t, err
+	}
+	if strings.Contains(t.val, ":") {
+		return t, p.errorf(t, "nombre de label invalido %s", t)
+	}
+	return t, nil
+}
+
+func (p *parser) parseUnary() (Expr, error) {
+	t := p.peek()
+	if t.kind == tokOp && (t.val == "-" || t.val == "+") {
+		p.next()
+		operand, err := p.parseExpr(precedencePow)
+		if err != nil {
+			return nil, err
+		}
+		if ty := operand.Type(); ty != ValueScalar && ty != ValueVector {
+			return nil, p.errorf(t, "operador unario sobre %s", ty)
+		}
+		if t.val == "+" {
+			return operand, nil
+		}
+		if n, ok := operand.(*NumberLiteral); ok {
+			return &NumberLiteral{Val: -n.Val}, nil
+		}
+		return &UnaryExpr{Op: "-", Expr: operand}, nil
+	}
+	return p.parsePostfix()
+}
+
+func (p *parser) parsePostfix() (Expr, error) {
+	expr, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		switch {
+		case t.kind == tokLBracket:
+			if expr, err = p.parseRange(expr); err != nil {
+				return nil, err
+			}
+		case t.is("offset"):
+			p.next()
+			sign := time.Duration(1)
+			if n := p.peek(); n.kind == tokOp && n.val == "-" {
+				p.next()
+				sign = -1
+			}
+			d, err := p.expect(tokDuration, "duracion de offset")
+			if err != nil {
+				return nil, err
+			}
+			offset, _ := ParseDuration(d.val)
+			var target *time.Duration
+			switch e := expr.(type) {
+			case *VectorSelector:
+				target = &e.Offset
+			case *MatrixSelector:
+				target = &e.Vector.Offset
+			case *SubqueryExpr:
+				target = &e.Offset
+			default:
+				return nil, p.errorf(t, "offset solo aplica a selectores y subqueries")
+			}
+			if *target != 0 {
+				return nil, p.errorf(t, "offset duplicado")
+			}
+			*target = sign * offset
+		case t.kind == tokAt:
+			return nil, p.errorf(t, "modificador @ no soportado")
+		default:
+			return expr, nil
+		}
+	}
+}
+
+func (p *parser) parseRange(expr Expr) (Expr, error) {
+	open := p.next()
+	d, err := p.expect(tokDuration, "duracion de rango")
+	if err != nil {
+		return nil, err
+	}
+	rng, _ := ParseDuration(d.val)
+	if rng <= 0 {
+		return nil, p.errorf(d, "rango debe ser mayor a cero")
+	}
+	if p.peek().kind == tokColon {
+		p.next()
+		sub := &SubqueryExpr{Expr: expr, Range: rng}
+		if p.peek().kind == tokDuration {
+			step, _ := ParseDuration(p.next().val)
+			sub.Step = step
+		}
+		if _, err := p.expect(tokRBracket, "']'"); err != nil {
+			return nil, err
+		}
+		if expr.Type() != ValueVector {
+			return nil, p.errorf(open, "subquery requiere vector, llego %s", expr.Type())
+		}
+		return sub, nil
+	}
+	if _, err := p.expect(tokRBracket, "']'"); err != nil {
+		return nil, err
+	}
+	vs, ok := expr.(*VectorSelector)
+	if !ok {
+		return nil, p.errorf(open, "rango solo aplica a selectores (usar [rango:paso] para subqueries)")
+	}
+	if vs.Offset != 0 {
+		return nil, p.errorf(open, "offset debe ir despues del rango")
+	}
+	return &MatrixSelector{Vector: vs, Range: rng}, nil
+}
+
+func (p *parser) parsePrimary() (Expr, error) {
+	t := p.peek()
+	switch t.kind {
+	case tokNumber:
+		p.next()
+		v, err := parseNumber(t.val)
+		if err != nil {
+			return nil, p.errorf(t, "numero invalido %s", t)
+		}
+		return &NumberLiteral{Val: v}, nil
+	case tokString:
+		p.next()
+		return &StringLiteral{Val: t.val}, nil
+	case tokLParen:
+		p.next()
+		inner, err := p.parseExpr(precedenceOr)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(tokRParen, "')'"); err != nil {
+			return nil, err
+		}
+		return &ParenExpr{Expr: inner}, nil
+	case tokLBrace:
+		return p.parseSelector("", t)
+	case tokIdent:
+		p.next()
+		lower := strings.ToLower(t.val)
+		if lower == "inf" || lower == "nan" {
+			v, _ := strconv.ParseFloat(lower, 64)
+			return &NumberLiteral{Val: v}, nil
+		}
+		if _, ok := aggregations[lower]; ok && (p.peek().kind == tokLParen || p.isGroupingKeyword()) {
+			return p.parseAggregation(t)
+		}
+		if p.peek().kind == tokLParen {
+			return p.parseCall(t)
+		}
+		return p.parseSelector(t.val, t)
+	case tokEOF:
+		return nil, p.errorf(t, "expresion incompleta")
+	}
+	return nil, p.errorf(t, "token inesperado %s", t)
+}
+
+// parseNumber sigue a Prometheus: enteros con base por prefijo (0x hexa, 0 octal) y si no, float.
+func parseNumber(s string) (float64, error) {
+	if n, err := strconv.ParseInt(s, 0, 64); err == nil {
+		return float64(n), nil
+	}
+	return strconv.ParseFloat(s, 64)
+}
+
+func (p *parser) isGroupingKeyword() bool {
+	t := p.peek()
+	return t.is("by", "without")
+}
+
+func (p *parser) parseSelector(name string, start token) (Expr, error) {
+	vs := &VectorSelector{Name: name}
+	if p.peek().kind == tokLBrace {
+		p.next()
+		for p.peek().kind != tokRBrace {
+			label, err := p.expectLabel()
+			if err != nil {
+				return nil, err
+			}
+			if label.val == "__name__" && name != "" {
+				return nil, p.errorf(label, "nombre de metrica definido dos veces")
+			}
+			op := p.next()
+			if op.kind != tokOp || (op.val != "=" && op.val != "!=" && op.val != "=~" && op.val != "!~") {
+				return nil, p.errorf(op, "operador de matcher invalido %s", op)
+			}
+			val, err := p.expect(tokString, "valor entre comillas")
+			if err != nil {
+				return nil, err
+			}
+			if !utf8.ValidString(val.val) {
+				return nil, p.errorf(val, "valor con UTF-8 invalido")
+			}
+			m := Matcher{Name: label.val, Op: MatchOp(op.val), Value: val.val}
+			if m.Op == MatchRegexp || m.Op == MatchNotRegexp {
+				if _, err := regexp.Compile(m.Value); err != nil {
+					return nil, p.errorf(val, "regex invalida: %v", err)
+				}
+			}
+			vs.Matchers = append(vs.Matchers, m)
+			if p.peek().kind != tokComma {
+				break
+			}
+			p.next()
+		}
+		if _, err := p.expect(tokRBrace, "'}'"); err != nil {
+			return nil, err
+		}
+	}
+	if vs.MetricName() == "" {
+		// Como Prometheus: hace falta un matcher que no acepte el string vacio ({x!=""} vale, {x=~".*"} no).
+		nonEmpty := false
+		for _, m := range vs.Matchers {
+			if !m.matchesEmpty() {
+				nonEmpty = true
+			}
+		}
+		if !nonEmpty {
+			return nil, p.errorf(start, "selector sin nombre necesita al menos un matcher no vacio")
+		}
+	}
+	return vs, nil
+}
+
+func (p *parser) parseAggregation(op token) (Expr, error) {
+	agg := &AggregateExpr{Op: strings.ToLower(op.val)}
+	var err error
+	if p.isGroupingKeyword() {
+		agg.Without = p.next().is("without")
+		if agg.Grouping, err = p.parseLabelList(); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := p.expect(tokLParen, "'('"); err != nil {
+		return nil, err
+	}
+	first, err := p.parseExpr(precedenceOr)
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind == tokComma {
+		p.next()
+		agg.Param = first
+		if agg.Expr, err = p.parseExpr(precedenceOr); err != nil {
+			return nil, err
+		}
+	} else {
+		agg.Expr = first
+	}
+	if _, err := p.expect(tokRParen, "')'"); err != nil {
+		return nil, err
+	}
+	if p.isGroupingKeyword() {
+		if agg.Grouping != nil || agg.Without {
+			return nil, p.errorf(p.peek(), "agrupacion duplicada en %s", op.val)
+		}
+		agg.Without = p.next().is("without")
+		if agg.Grouping, err = p.parseLabelList(); err != nil {
+			return nil, err
+		}
+	}
+	if err := checkAggregation(agg); err != nil {
+		return nil, p.errorf(op, "%v", err)
+	}
+	return agg, nil
+}
+
+func (p *parser) parseCall(name token) (Expr, error) {
+	fn, ok := functions[name.val]
+	if !ok {
+		return nil, p.errorf(name, "funcion desconocida %q", name.val)
+	}
+	p.next()
+	call := &Call{Func: name.val}
+	for p.peek().kind != tokRParen {
+		arg, err := p.parseExpr(precedenceOr)
+		if err != nil {
+			return nil, err
+		}
+		call.Args = append(call.Args, arg)
+		if p.peek().kind != tokComma {
+			break
+		}
+		p.next()
+	}
+	if _, err := p.expect(tokRParen, "')'"); err != nil {
+		return nil, err
+	}
+	if err := fn.check(call); err != nil {
+		return nil, p.errorf(name, "%v", err)
+	}
+	return call, nil
+}
+
+func checkBinary(b *BinaryExpr) error {
+	lt, rt := b.LHS.Type(), b.RHS.Type()
+	for _, t := range []ValueType{lt, rt} {
+		if t != ValueScalar && t != ValueVector {
+			return fmt.Errorf("operador %s no acepta %s", b.Op, t)
+		}
+	}
+	if isSetOp(b.Op) && (lt != ValueVector || rt != ValueVector) {
+		return fmt.Errorf("operador %s requiere vectores en ambos lados", b.Op)
+	}
+	if isComparison(b.Op) && lt == ValueScalar && rt == ValueScalar && !b.ReturnBool {
+		return fmt.Errorf("comparacion entre escalares requiere bool")
+	}
+	if b.Matching != nil && (lt != ValueVector || rt != ValueVector) {
+		return fmt.Errorf("on/ignoring solo aplica entre vectores")
+	}
+	if m := b.Matching; m != nil && m.Card != "" {
+		if isSetOp(b.Op) {
+			return fmt.Errorf("%s no admite %s", b.Op, m.Card)
+		}
+		if m.On {
+			for _, l := range m.CardLabel {
+				for _, on := range m.Labels {
+					if l == on {
+						return fmt.Errorf("label %q no puede estar en on y en %s a la vez", l, m.Card)
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func checkAggregation(a *AggregateExpr) error {
+	if a.Expr.Type() != ValueVector {
+		return fmt.Errorf("%s requiere vector, llego %s", a.Op, a.Expr.Type())
+	}
+	switch a.Op {
+	case "topk", "bottomk", "quantile":
+		if a.Param == nil || a.Param.Type() != ValueScalar {
+			return fmt.Errorf("%s requiere parametro escalar", a.Op)
+		}
+	case "count_values":
+		if a.Param == nil || a.Param.Type() != ValueString {
+			return fmt.Errorf("count_values requiere parametro string")
+		}
+	default:
+		if a.Param != nil {
+			return fmt.Errorf("%s no acepta parametro", a.Op)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,344 @@
+package promql
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// Los arboles esperados siguen el parser de Prometheus (generated_parser.y): mismas precedencias,
+// ^ asociativo a derecha, unario con precedencia de * y plegado de -<numero> en el literal.
+func TestParseAST(t *testing.T) {
+	tests := []struct {
+		name, input, want string
+	}{
+		// Precedencia y asociatividad.
+		{"mul antes que suma", "a + b * c", "(+ a (* b c))"},
+		{"suma a izquierda", "a - b - c", "(- (- a b) c)"},
+		{"mul a izquierda", "a / b * c", "(* (/ a b) c)"},
+		{"pow a derecha", "2 ^ 3 ^ 2", "(^ 2 (^ 3 2))"},
+		{"pow antes que mul", "a * b ^ c", "(* a (^ b c))"},
+		{"comparacion bajo suma", "a > b + 1", "(> a (+ b 1))"},
+		{"and antes que or", "a or b and c", "(or a (and b c))"},
+		{"unless con and", "a unless b and c", "(and (unless a b) c)"},
+		{"atan2 como mul", "a + b atan2 c", "(+ a (atan2 b c))"},
+		{"operadores logicos sin distinguir mayusculas", "a AND b", "(and a b)"},
+		{"parentesis explicitos", "(a + b) * c", "(* (paren (+ a b)) c)"},
+
+		// Menos unario contra ^ y contra literales.
+		{"menos sobre pow", "-2 ^ 2", "(- (^ 2 2))"},
+		{"menos sobre selector en pow", "-a ^ 2", "(- (^ a 2))"},
+		{"menos liga como mul", "-a * b", "(* (- a) b)"},
+		{"menos antes de suma", "-a + b", "(+ (- a) b)"},
+		{"menos en rhs", "a * -b", "(* a (- b))"},
+		{"literal negativo plegado", "-1 + a", "(+ -1 a)"},
+		{"pow sobre literal negativo", "2 ^ -1", "(^ 2 -1)"},
+		{"mas unario es identidad", "+a", "a"},
+
+		// Literales numericos contra duraciones.
+		{"entero", "5", "5"},
+		{"decimal", "1.5", "1.5"},
+		{"exponente", "1e3", "1000"},
+		{"exponente negativo", "2.5e-3", "0.0025"},
+		{"hexadecimal", "0x1F", "31"},
+		{"infinito", "+Inf", "+Inf"},
+		{"nan", "NaN", "NaN"},
+		{"rango simple", "a[5m]", "(matrix a 5m)"},
+		{"rango compuesto", "a[1h30m]", "(matrix a 1h30m)"},
+		{"rango en ms", "a[500ms]", "(matrix a 500ms)"},
+		{"numero despues de duracion en rango", "rate(a[5m]) * 60", "(* (call rate (matrix a 5m)) 60)"},
+
+		// offset.
+		{"offset en selector", "a offset 5m", "(sel a offset 5m)"},
+		{"offset en rango", "rate(a[5m] offset 1h)", "(call rate (matrix (sel a offset 1h) 5m))"},
+		{"offset en subquery", "a[10m:1m] offset 1d", "(subquery a 10m 1m offset 1d)"},
+
+		// Subqueries.
+		{"subquery de funcion", "max_over_time(rate(a[5m])[30m:1m])", "(call max_over_time (subquery (call rate (matrix a 5m)) 30m 1m))"},
+		{"subquery sin paso", "avg_over_time(a[1h:])", "(call avg_over_time (subquery a 1h 0s))"},
+		{"subquery de binario", "(a + b)[5m:]", "(subquery (paren (+ a b)) 5m 0s)"},
+
+		// Selectores, agregaciones y matching.
+		{"matchers", `a{job="x",env!~"dev|qa"}`, `a{env!~"dev|qa",job="x"}`},
+		{"selector por __name__", `{__name__="up",job="x"}`, `{__name__="up",job="x"}`},
+		{"agrupacion previa", "sum by (job) (a)", "(sum by (job) a)"},
+		{"agrupacion posterior", "sum(a) without (instance)", "(sum without (instance) a)"},
+		{"agregacion con parametro", "topk(3, a)", "(topk 3 a)"},
+		{"count_values", `count_values("v", a)`, `(count_values "v" a)`},
+		{"on con group_left", "a * on (job) group_left (env) b", "(* on (job) group_left (env) a b)"},
+		{"ignoring", "a / ignoring (instance) b", "(/ ignoring (instance) a b)"},
+		{"bool", "a > bool 1", "(> bool a 1)"},
+		{"comentario", "a # resto\n+ b", "(+ a b)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.input, err)
+			}
+			if got := dump(e); got != tt.want {
+				t.Errorf("Parse(%q) = %s; esperado %s", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name, input string
+		pos         int
+		msg         string
+	}{
+		{"vacia", "  ", 0, "expresion vacia"},
+		{"incompleta", "a + ", 4, "expresion incompleta"},
+		{"parentesis sin cerrar", "sum(rate(a[5m])", 15, "se esperaba ')', llego fin de expresion"},
+		{"token sobrante", "a b", 2, `token inesperado "b"`},
+		{"duracion como numero", "a > 5m", 4, `token inesperado "5m"`},
+		{"numero como rango", "a[5]", 2, `se esperaba duracion de rango, llego "5"`},
+		{"rango cero", "a[0s]", 2, "rango debe ser mayor a cero"},
+		{"rango sobre funcion", "rate(a[5m])[1m]", 11, "rango solo aplica a selectores"},
+		{"offset antes del rango", "a offset 5m [1m]", 12, "offset debe ir despues del rango"},
+		{"offset duplicado", "a offset 5m offset 1m", 12, "offset duplicado"},
+		{"nombre de metrica dos veces", `a{__name__="b"}`, 2, "nombre de metrica definido dos veces"},
+		{"label con dos puntos", `a{b:c="d"}`, 2, `nombre de label invalido "b:c"`},
+		{"regex invalida", `a{b=~"("}`, 5, "regex invalida: error parsing regexp: missing closing )"},
+		{"group_left en set op", "a and on (x) group_left b", 2, "and no admite group_left"},
+		{"offset sobre agregacion", "sum(a) offset 5m", 7, "offset solo aplica a selectores y subqueries"},
+		{"modificador @", "a @ 1609746000", 2, "modificador @ no soportado"},
+		{"funcion desconocida", "foo(a)", 0, `funcion desconocida "foo"`},
+		{"tipo de argumento", "rate(a)", 0, "rate"},
+		{"escalares sin bool", "1 > 2", 2, "comparacion entre escalares requiere bool"},
+		{"set op con escalar", "a and 1", 2, "operador and requiere vectores en ambos lados"},
+		{"bool fuera de comparacion", "a + bool b", 4, "bool solo aplica a comparaciones"},
+		{"matching con escalar", "a * on (job) 2", 2, "on/ignoring solo aplica entre vectores"},
+		{"selector vacio", `{job=""}`, 0, "selector sin nombre necesita al menos un matcher no vacio"},
+		{"string sin cerrar", `a{job="x}`, 6, "string sin cerrar"},
+		{"escape desconocido", `a{job="\q"}`, 6, `escape invalido "\\q"`},
+		{"escape incompleto", `a{job="\x0"}`, 6, `escape invalido "\\x"`},
+		{"caracter invalido", "a $ b", 2, `caracter inesperado '$'`},
+		{"topk sin parametro", "topk(a)", 0, "topk requiere parametro escalar"},
+		{"agrupacion duplicada", "sum by (a) (x) by (b)", 15, "agrupacion duplicada en sum"},
+		{"subquery sobre rango", "a[5m][10m:1m]", 5, "subquery requiere vector, llego matrix"},
+		{"unario sobre rango", "-a[5m]", 0, "operador unario sobre matrix"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.input)
+			var pe *ParseError
+			if !errors.As(err, &pe) {
+				t.Fatalf("Parse(%q) = %v; esperado ParseError", tt.input, err)
+			}
+			if pe.Pos != tt.pos || !strings.Contains(pe.Msg, tt.msg) {
+				t.Errorf("Parse(%q) = posicion %d %q; esperado posicion %d %q", tt.input, pe.Pos, pe.Msg, tt.pos, tt.msg)
+			}
+		})
+	}
+}
+
+func TestStringRoundTrip(t *testing.T) {
+	tests := []struct {
+		input, want string
+	}{
+		{"rate(mqtt_messages_total[60s])", "rate(mqtt_messages_total[1m])"},
+		{"sum(rate(a[5m])) by (job, instance)", "sum by (instance, job) (rate(a[5m]))"},
+		{`a{job="x", env="prod"}`, `a{env="prod",job="x"}`},
+		{"a + (b * c)", "a + b * c"},
+		{"(a + b) * c", "(a + b) * c"},
+		{"a - (b - c)", "a - (b - c)"},
+		{"(a - b) - c", "a - b - c"},
+		{"2 ^ (3 ^ 2)", "2 ^ 3 ^ 2"},
+		{"(2 ^ 3) ^ 2", "(2 ^ 3) ^ 2"},
+		{"-(a ^ 2)", "-a ^ 2"},
+		{"(-a) ^ 2", "(-a) ^ 2"},
+		{"-(a + b)", "-(a + b)"},
+		{"a * on(job) group_left(z, env) b", "a * on (job) group_left (env, z) b"},
+		{"a > bool 0", "a > bool 0"},
+		{"rate(a[5m] offset 60m)", "rate(a[5m] offset 1h)"},
+		{"max_over_time((a + b)[1h:30s] offset 5m)", "max_over_time((a + b)[1h:30s] offset 5m)"},
+		{"topk(3, sum by (job) (a))", "topk(3, sum by (job) (a))"},
+		{"histogram_quantile(0.9, sum by (le) (rate(h_bucket[5m])))", "histogram_quantile(0.9, sum by (le) (rate(h_bucket[5m])))"},
+		{"time() - timestamp(a)", "time() - timestamp(a)"},
+		{"((a))", "a"},
+		{"a offset -5m", "a offset -5m"},
+		{"rate(a[5m] offset -90s)", "rate(a[5m] offset -1m30s)"},
+		{"0755 + 0x1f", "493 + 31"},
+		// Escapes decodificados como strconv.UnquoteChar; la forma canonica usa strconv.Quote.
+		{`a{x="\x00"}`, `a{x="\x00"}`},
+		{`a{x='\u00e9'}`, `a{x="é"}`},
+		{`a{x="\101\t\\"}`, `a{x="A\t\\"}`},
+		{"a{x=`\\d+`}", `a{x="\\d+"}`},
+		// Selectores con nombre de palabra clave y literales que el parser plegaria.
+		{"A And(on)", `A and {__name__="on"}`},
+		{"a + (bool)", `a + {__name__="bool"}`},
+		{"-(0)", "-(0)"},
+		{"(-1) ^ 2", "(-1) ^ 2"},
+		{"a * ON(job) GROUP_LEFT b", "a * on (job) group_left b"},
+		{"SUM BY (job) (a)", "sum by (job) (a)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			e, err := Parse(tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := e.String()
+			if got != tt.want {
+				t.Errorf("String() = %q; esperado %q", got, tt.want)
+			}
+			// La forma canonica se vuelve a parsear al mismo arbol (salvo parentesis redundantes).
+			again, err := Parse(got)
+			if err != nil {
+				t.Fatalf("Parse(String()) = %v", err)
+			}
+			if again.String() != got || dump(unparenAll(again)) != dump(unparenAll(e)) {
+				t.Errorf("round-trip: %s vs %s", dump(again), dump(e))
+			}
+		})
+	}
+}
+
+// FuzzParseRoundTrip: todo lo que parsea imprime una forma canonica que vuelve a parsear al mismo arbol.
+func FuzzParseRoundTrip(f *testing.F) {
+	for _, seed := range []string{
+		"rate(mqtt_messages_total[1m])", `a{job="x",env=~"p.*"} offset 5m`, "sum by (job) (rate(a[5m])) > bool 0",
+		"a * on (job) group_left (env) b", "-a ^ 2", "max_over_time((a + b)[1h:30s])", `"\x00"`, `'\u00e9'`,
+		"A And(on)", "a + (bool)", "(offset) offset 1m", "-(0)", "(-1) ^ 2", "topk(3, a) unless ignoring (x) b", "1 + -2 * 3",
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, input string) {
+		e, err := Parse(input)
+		if err != nil {
+			return
+		}
+		got := e.String()
+		again, err := Parse(got)
+		if err != nil {
+			t.Fatalf("%q imprime %q que no parsea: %v", input, got, err)
+		}
+		if again.String() != got || dump(unparenAll(again)) != dump(unparenAll(e)) {
+			t.Fatalf("%q: round-trip %s vs %s", input, dump(again), dump(e))
+		}
+	})
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+		err  bool
+	}{
+		{in: "5m", want: "5m"},
+		{in: "90s", want: "1m30s"},
+		{in: "1h30m", want: "1h30m"},
+		{in: "36h", want: "1d12h"},
+		{in: "1500ms", want: "1s500ms"},
+		{in: "2w", want: "2w"},
+		{in: "5", err: true},
+		{in: "5min", err: true},
+		{in: "m", err: true},
+		{in: "", err: true},
+	}
+	for _, tt := range tests {
+		d, err := ParseDuration(tt.in)
+		if tt.err {
+			if err == nil {
+				t.Errorf("ParseDuration(%q) = %v; esperado error", tt.in, d)
+			}
+			continue
+		}
+		if err != nil || FormatDuration(d) != tt.want {
+			t.Errorf("ParseDuration(%q) = %s, %v; esperado %s", tt.in, FormatDuration(d), err, tt.want)
+		}
+	}
+}
+
+// dump imprime el arbol como s-expression para fijar su estructura, no solo su texto.
+func dump(e Expr) string {
+	switch n := e.(type) {
+	case *NumberLiteral:
+		return strconv.FormatFloat(n.Val, 'g', -1, 64)
+	case *StringLiteral:
+		return strconv.Quote(n.Val)
+	case *VectorSelector:
+		if n.Offset != 0 {
+			sel := *n
+			sel.Offset = 0
+			return "(sel " + sel.String() + " offset " + FormatDuration(n.Offset) + ")"
+		}
+		return n.String()
+	case *MatrixSelector:
+		return "(matrix " + dump(n.Vector) + " " + FormatDuration(n.Range) + ")"
+	case *SubqueryExpr:
+		s := "(subquery " + dump(n.Expr) + " " + FormatDuration(n.Range) + " " + FormatDuration(n.Step)
+		if n.Offset != 0 {
+			s += " offset " + FormatDuration(n.Offset)
+		}
+		return s + ")"
+	case *Call:
+		parts := []string{"call", n.Func}
+		for _, a := range n.Args {
+			parts = append(parts, dump(a))
+		}
+		return "(" + strings.Join(parts, " ") + ")"
+	case *AggregateExpr:
+		parts := []string{n.Op}
+		if g := groupingString(n); g != "sin agrupacion" {
+			parts = append(parts, g)
+		}
+		if n.Param != nil {
+			parts = append(parts, dump(n.Param))
+		}
+		parts = append(parts, dump(n.Expr))
+		return "(" + strings.Join(parts, " ") + ")"
+	case *BinaryExpr:
+		op := n.Op
+		if n.ReturnBool {
+			op += " bool"
+		}
+		if m := n.Matching.String(); m != "" {
+			op += " " + m
+		}
+		return "(" + op + " " + dump(n.LHS) + " " + dump(n.RHS) + ")"
+	case *UnaryExpr:
+		return "(" + n.Op + " " + dump(n.Expr) + ")"
+	case *ParenExpr:
+		return "(paren " + dump(n.Expr) + ")"
+	}
+	return "?"
+}
+
+// unparenAll quita todos los ParenExpr del arbol (String() solo conserva los necesarios).
+func unparenAll(e Expr) Expr {
+	switch n := unparen(e).(type) {
+	case *SubqueryExpr:
+		c := *n
+		c.Expr = unparenAll(n.Expr)
+		return &c
+	case *Call:
+		c := *n
+		c.Args = make([]Expr, len(n.Args))
+		for i, a := range n.Args {
+			c.Args[i] = unparenAll(a)
+		}
+		return &c
+	case *AggregateExpr:
+		c := *n
+		if n.Param != nil {
+			c.Param = unparenAll(n.Param)
+		}
+		c.Expr = unparenAll(n.Expr)
+		return &c
+	case *BinaryExpr:
+		c := *n
+		c.LHS, c.RHS = unparenAll(n.LHS), unparenAll(n.RHS)
+		return &c
+	case *UnaryExpr:
+		c := *n
+		c.Expr = unparenAll(n.Expr)
+		return &c
+	default:
+		return n
+	}
+}
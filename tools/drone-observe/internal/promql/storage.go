@@ -0,0 +1,201 @@
+// Archivo: tools/drone-observe/internal/promql/storage.go
+// Rol: series en memoria (labels + muestras) para evaluar PromQL sin Prometheus.
+// No hace: persistencia, compactacion ni retencion; el storage vive lo que dura un test.
+package promql
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Labels es el conjunto de labels de una serie, incluido __name__.
+type Labels map[string]string
+
+// Name devuelve el nombre de metrica (__name__).
+func (l Labels) Name() string { return l["__name__"] }
+
+// String imprime la serie como selector canonico: name{a="x",b="y"}.
+// Se usa tambien como clave unica de la serie.
+func (l Labels) String() string {
+	names := make([]string, 0, len(l))
+	for k := range l {
+		if k != "__name__" {
+			names = append(names, k)
+		}
+	}
+	sort.Strings(names)
+	parts := make([]string, 0, len(names))
+	for _, k := range names {
+		parts = append(parts, k+"="+strconv.Quote(l[k]))
+	}
+	if len(parts) == 0 && l.Name() != "" {
+		return l.Name()
+	}
+	return l.Name() + "{" + strings.Join(parts, ",") + "}"
+}
+
+func (l Labels) copy() Labels {
+	out := make(Labels, len(l))
+	for k, v := range l {
+		out[k] = v
+	}
+	return out
+}
+
+func (l Labels) withoutName() Labels {
+	out := l.copy()
+	delete(out, "__name__")
+	return out
+}
+
+// LabelsFromSelector convierte un selector con matchers de igualdad (metric{a="x"}) en Labels.
+func LabelsFromSelector(s string) (Labels, error) {
+	if strings.TrimSpace(s) == "" || strings.TrimSpace(s) == "{}" {
+		return Labels{}, nil
+	}
+	e, err := Parse(s)
+	if err != nil {
+		return nil, err
+	}
+	vs, ok := e.(*VectorSelector)
+	if !ok || vs.Offset != 0 {
+		return nil, fmt.Errorf("serie %q: se esperaba un selector simple", s)
+	}
+	l := Labels{}
+	if vs.Name != "" {
+		l["__name__"] = vs.Name
+	}
+	for _, m := range vs.Matchers {
+		if m.Op != MatchEqual {
+			return nil, fmt.Errorf("serie %q: solo se admiten matchers '='", s)
+		}
+		l[m.Name] = m.Value
+	}
+	return l, nil
+}
+
+// Point es una muestra; T en milisegundos desde epoch. Stale marca el fin de la serie (staleness de Prometheus).
+type Point struct {
+	T     int64
+	V     float64
+	Stale bool
+}
+
+type Series struct {
+	Labels Labels
+	Points []Point
+}
+
+// Storage guarda series en memoria. Las muestras de cada serie deben agregarse en orden de tiempo.
+type Storage struct {
+	series map[string]*Series
+	keys   []string
+}
+
+func NewStorage() *Storage {
+	return &Storage{series: map[string]*Series{}}
+}
+
+// Add agrega una muestra; si ya existe una muestra con el mismo T, la reemplaza.
+func (s *Storage) Add(l Labels, p Point) error {
+	key := l.String()
+	ser, ok := s.series[key]
+	if !ok {
+		ser = &Series{Labels: l.copy()}
+		s.series[key] = ser
+		s.keys = append(s.keys, key)
+	}
+	if n := len(ser.Points); n > 0 {
+		last := ser.Points[n-1]
+		switch {
+		case last.T == p.T:
+			ser.Points[n-1] = p
+			return nil
+		case last.T > p.T:
+			return fmt.Errorf("%s: muestra fuera de orden (%d < %d)", key, p.T, last.T)
+		}
+	}
+	ser.Points = append(ser.Points, p)
+	return nil
+}
+
+// Series devuelve las series en orden de alta.
+func (s *Storage) Series() []*Series {
+	out := make([]*Series, 0, len(s.keys))
+	for _, k := range s.keys {
+		out = append(out, s.series[k])
+	}
+	return out
+}
+
+func (s *Storage) selectSeries(vs *VectorSelector) ([]*Series, error) {
+	type compiled struct {
+		m  Matcher
+		re *regexp.Regexp
+	}
+	matchers := make([]compiled, 0, len(vs.Matchers))
+	for _, m := range vs.Matchers {
+		c := compiled{m: m}
+		if m.Op == MatchRegexp || m.Op == MatchNotRegexp {
+			re, err := regexp.Compile("^(?:" + m.Value + ")$")
+			if err != nil {
+				return nil, fmt.Errorf("regex invalida en %s: %v", m, err)
+			}
+			c.re = re
+		}
+		matchers = append(matchers, c)
+	}
+
+	var out []*Series
+	for _, ser := range s.Series() {
+		if vs.Name != "" && ser.Labels.Name() != vs.Name {
+			continue
+		}
+		ok := true
+		for _, c := range matchers {
+			v := ser.Labels[c.m.Name]
+			switch c.m.Op {
+			case MatchEqual:
+				ok = v == c.m.Value
+			case MatchNotEqual:
+				ok = v != c.m.Value
+			case MatchRegexp:
+				ok = c.re.MatchString(v)
+			case MatchNotRegexp:
+				ok = !c.re.MatchString(v)
+			}
+			if !ok {
+				break
+			}
+		}
+		if ok {
+			out = append(out, ser)
+		}
+	}
+	return out, nil
+}
+
+// lastPoint devuelve la ultima muestra en (from, to]; una muestra stale cuenta como ausencia.
+func lastPoint(points []Point, from, to int64) (Point, bool) {
+	i := sort.Search(len(points), func(i int) bool { return points[i].T > to })
+	if i == 0 || points[i-1].T <= from || points[i-1].Stale {
+		return Point{}, false
+	}
+	return points[i-1], true
+}
+
+// window devuelve las muestras no stale en (from, to] (rango abierto a izquierda, como Prometheus 3).
+func window(points []Point, from, to int64) []Point {
+	lo := sort.Search(len(points), func(i int) bool { return points[i].T > from })
+	hi := sort.Search(len(points), func(i int) bool { return points[i].T > to })
+	out := make([]Point, 0, hi-lo)
+	for _, p := range points[lo:hi] {
+		if !p.Stale {
+			out = append(out, p)
+		}
+	}
+	return out
+}
@@ -0,0 +1,87 @@
+// Archivo: tools/drone-observe/internal/queries/queries.go
+// Rol: registro central de las consultas PromQL que ejecuta el CLI.
+// No hace: ejecutar consultas ni validarlas; `lint-queries` las parsea desde aca.
+package queries
+
+// Query es una consulta PromQL con nombre estable y los paquetes que la usan.
+type Query struct {
+	Name  string
+	Expr  string
+	Users []string
+	// Unbounded marca una cardinalidad sin cota aceptada: lint-queries la reporta como baja.
+	Unbounded bool
+}
+
+// PARTE CRITICA **********************
+// Toda consulta que el CLI envia a Prometheus debe declararse aca y usarse via .Expr.
+// Un literal PromQL fuera del registro no pasa por lint-queries y un typo vuelve a verse como "sin datos".
+// FIN DE PARTE CRITICA ****************
+var (
+	MessageRate = Query{
+		Name:  "mqtt.message_rate",
+		Expr:  "rate(mqtt_messages_total[1m])",
+		Users: []string{"topology", "ui/health", "ui/telemetry", "limits", "fleet"},
+	}
+	MessagesTimestamp = Query{
+		Name:  "mqtt.messages_timestamp",
+		Expr:  "timestamp(mqtt_messages_total)",
+		Users: []string{"freshness"},
+	}
+	BatteryPct = Query{
+		Name:  "drone.battery_pct",
+		Expr:  "drone_battery_last_pct",
+		Users: []string{"ui/telemetry", "fleet"},
+	}
+	BatteryTimestamp = Query{
+		Name:  "drone.battery_timestamp",
+		Expr:  "timestamp(drone_battery_last_pct)",
+		Users: []string{"freshness", "fleet"},
+	}
+	MLAnomalyScore = Query{
+		Name:  "ml.anomaly_score",
+		Expr:  "ml_anomaly_score",
+		Users: []string{"ui/llm"},
+	}
+	MLState = Query{
+		Name:  "ml.state",
+		Expr:  "ml_state",
+		Users: []string{"ui/llm", "fleet"},
+	}
+	BackendSeries = Query{
+		Name:  "limits.backend_series",
+		Expr:  `count({job="backend"})`,
+		Users: []string{"limits"},
+		// Es el costo de medir cardinalidad: se ve en el lint sin cortar CI.
+		Unbounded: true,
+	}
+	BackendMetricNames = Query{
+		Name:  "limits.backend_metric_names",
+		Expr:  `count(count by(__name__) ({job="backend"}))`,
+		Users: []string{"limits"},
+		// Es el costo de medir cardinalidad: se ve en el lint sin cortar CI.
+		Unbounded: true,
+	}
+	BackendScrapeTimestamp = Query{
+		Name:  "limits.backend_scrape_timestamp",
+		Expr:  `timestamp(up{job="backend"})`,
+		Users: []string{"limits"},
+	}
+	ScrapeUp = Query{
+		Name:  "topology.scrape_up",
+		Expr:  "up",
+		Users: []string{"topology"},
+	}
+	ScrapeSamples = Query{
+		Name:  "targets.scrape_samples",
+		Expr:  "scrape_samples_scraped",
+		Users: []string{"targets"},
+	}
+)
+
+// All lista el registro en orden estable.
+func All() []Query {
+	return []Query{
+		MessageRate, MessagesTimestamp, BatteryPct, BatteryTimestamp,
+		MLAnomalyScore, MLState, BackendSeries, BackendMetricNames, BackendScrapeTimestamp, ScrapeUp, ScrapeSamples,
+	}
+}
@@ -0,0 +1,265 @@
+// Archivo: tools/drone-observe/internal/recording/format.go
+// Rol: formato binario compacto de grabaciones MQTT (.mqrec): escritura y lectura secuencial.
+// No hace: conectarse al broker; record y replay le pasan mensajes ya recibidos o por publicar.
+package recording
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"drone-observe/internal/mqttclient"
+)
+
+// Version del formato; cambia si cambia la codificacion de registros.
+const Version = 1
+
+// Extension sugerida para grabaciones.
+const Extension = ".mqrec"
+
+var magic = [4]byte{'D', 'O', 'M', 'Q'}
+
+// Flags de cada registro.
+const (
+	flagQoS      byte = 0x03
+	flagRetain   byte = 0x04
+	flagNewTopic byte = 0x08
+)
+
+// Limites de lectura: un archivo corrupto no debe pedir gigas de memoria.
+const (
+	maxTopicLen   = 65535
+	maxPayloadLen = 268435455
+	maxTopics     = 1 << 20
+)
+
+// Header es la cabecera del archivo: cuando empezo la grabacion y que filtros se suscribieron.
+type Header struct {
+	Start   time.Time
+	Filters string
+}
+
+// PARTE CRITICA **********************
+// Layout: "DOMQ" | version (1 byte) | start (unix ns, int64 BE) | filtros (uvarint len + bytes),
+// luego un registro por mensaje:
+//   delta ns desde el mensaje anterior (uvarint) | flags (qos bits 0-1, retain bit 2, topic nuevo bit 3)
+//   | topic nuevo (uvarint len + bytes) o indice de un topic ya visto (uvarint) | payload (uvarint len + bytes)
+// El delta y la tabla de topics hacen que un mensaje de telemetria ocupe ~payload + 4 bytes.
+// Los registros no tienen trailer: una grabacion cortada (kill -9) se lee hasta el ultimo registro completo.
+// FIN DE PARTE CRITICA ****************
+
+// Writer agrega mensajes a una grabacion.
+type Writer struct {
+	f      *os.File
+	w      *bufio.Writer
+	last   time.Time
+	topics map[string]uint64
+	count  int
+}
+
+// Create crea path y escribe la cabecera.
+func Create(path string, h Header) (*Writer, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	w := &Writer{f: f, w: bufio.NewWriter(f), last: h.Start, topics: map[string]uint64{}}
+	buf := append(append([]byte{}, magic[:]...), Version)
+	buf = binary.BigEndian.AppendUint64(buf, uint64(h.Start.UnixNano()))
+	buf = appendBytes(buf, []byte(h.Filters))
+	if _, err := w.w.Write(buf); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return w, nil
+}
+
+// Write agrega msg; Received debe ser no decreciente (orden de llegada).
+func (w *Writer) Write(msg mqttclient.Message) error {
+	delta := msg.Received.Sub(w.last)
+	if delta < 0 {
+		delta = 0
+	} else {
+		w.last = msg.Received
+	}
+	flags := msg.QoS & flagQoS
+	if msg.Retain {
+		flags |= flagRetain
+	}
+	idx, seen := w.topics[msg.Topic]
+	if !seen {
+		flags |= flagNewTopic
+		w.topics[msg.Topic] = uint64(len(w.topics))
+	}
+
+	buf := binary.AppendUvarint(nil, uint64(delta))
+	buf = append(buf, flags)
+	if seen {
+		buf = binary.AppendUvarint(buf, idx)
+	} else {
+		buf = appendBytes(buf, []byte(msg.Topic))
+	}
+	buf = appendBytes(buf, msg.Payload)
+	if _, err := w.w.Write(buf); err != nil {
+		return err
+	}
+	w.count++
+	return nil
+}
+
+// Count devuelve los mensajes escritos.
+func (w *Writer) Count() int { return w.count }
+
+// Topics devuelve los topics distintos escritos.
+func (w *Writer) Topics() int { return len(w.topics) }
+
+// Flush vuelca el buffer; record lo llama periodicamente para que un corte pierda poco.
+func (w *Writer) Flush() error { return w.w.Flush() }
+
+// Close vuelca el buffer y cierra el archivo.
+func (w *Writer) Close() error {
+	if err := w.w.Flush(); err != nil {
+		_ = w.f.Close()
+		return err
+	}
+	return w.f.Close()
+}
+
+// Reader lee una grabacion en orden.
+type Reader struct {
+	r      *bufio.Reader
+	closer io.Closer
+	header Header
+	last   time.Time
+	topics []string
+}
+
+// Open abre path y valida la cabecera.
+func Open(path string) (*Reader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	r, err := NewReader(f)
+	if err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	r.closer = f
+	return r, nil
+}
+
+// NewReader lee la cabecera de src.
+func NewReader(src io.Reader) (*Reader, error) {
+	r := &Reader{r: bufio.NewReader(src)}
+	var fixed [13]byte
+	if _, err := io.ReadFull(r.r, fixed[:]); err != nil {
+		return nil, errors.New("no es una grabacion de drone-observe (cabecera incompleta)")
+	}
+	if [4]byte(fixed[:4]) != magic {
+		return nil, errors.New("no es una grabacion de drone-observe")
+	}
+	if fixed[4] != Version {
+		return nil, fmt.Errorf("version de grabacion %d no soportada (esperada %d)", fixed[4], Version)
+	}
+	r.header.Start = time.Unix(0, int64(binary.BigEndian.Uint64(fixed[5:]))).UTC()
+	filters, err := r.readBytes(maxTopicLen)
+	if err != nil {
+		return nil, fmt.Errorf("cabecera: %w", err)
+	}
+	r.header.Filters = string(filters)
+	r.last = r.header.Start
+	return r, nil
+}
+
+// Header devuelve la cabecera leida.
+func (r *Reader) Header() Header { return r.header }
+
+// Next devuelve el siguiente mensaje; io.EOF al terminar limpio y io.ErrUnexpectedEOF si el ultimo
+// registro quedo cortado.
+func (r *Reader) Next() (mqttclient.Message, error) {
+	delta, err := binary.ReadUvarint(r.r)
+	if err == io.EOF {
+		return mqttclient.Message{}, io.EOF
+	}
+	if err != nil {
+		return mqttclient.Message{}, truncated(err)
+	}
+	flags, err := r.r.ReadByte()
+	if err != nil {
+		return mqttclient.Message{}, truncated(err)
+	}
+	var topic string
+	if flags&flagNewTopic != 0 {
+		b, err := r.readBytes(maxTopicLen)
+		if err != nil {
+			return mqttclient.Message{}, err
+		}
+		if len(r.topics) >= maxTopics {
+			return mqttclient.Message{}, fmt.Errorf("mas de %d topics distintos", maxTopics)
+		}
+		topic = string(b)
+		r.topics = append(r.topics, topic)
+	} else {
+		idx, err := binary.ReadUvarint(r.r)
+		if err != nil {
+			return mqttclient.Message{}, truncated(err)
+		}
+		if idx >= uint64(len(r.topics)) {
+			return mqttclient.Message{}, fmt.Errorf("indice de topic %d invalido (archivo corrupto)", idx)
+		}
+		topic = r.topics[idx]
+	}
+	payload, err := r.readBytes(maxPayloadLen)
+	if err != nil {
+		return mqttclient.Message{}, err
+	}
+	r.last = r.last.Add(time.Duration(delta))
+	return mqttclient.Message{
+		Topic:    topic,
+		Payload:  payload,
+		QoS:      flags & flagQoS,
+		Retain:   flags&flagRetain != 0,
+		Received: r.last,
+	}, nil
+}
+
+// Close cierra el archivo si el Reader lo abrio.
+func (r *Reader) Close() error {
+	if r.closer == nil {
+		return nil
+	}
+	return r.closer.Close()
+}
+
+func (r *Reader) readBytes(max uint64) ([]byte, error) {
+	n, err := binary.ReadUvarint(r.r)
+	if err != nil {
+		return nil, truncated(err)
+	}
+	if n > max {
+		return nil, fmt.Errorf("longitud %d fuera de rango (archivo corrupto)", n)
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(r.r, b); err != nil {
+		return nil, truncated(err)
+	}
+	return b, nil
+}
+
+func appendBytes(buf, b []byte) []byte {
+	buf = binary.AppendUvarint(buf, uint64(len(b)))
+	return append(buf, b...)
+}
+
+// truncated normaliza un EOF a mitad de registro.
+func truncated(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
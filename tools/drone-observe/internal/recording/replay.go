@@ -0,0 +1,186 @@
+// Archivo: tools/drone-observe/internal/recording/replay.go
+// Rol: republicar una grabacion respetando sus tiempos (original, escalado o sin espera) y reescribir topics.
+// No hace: elegir broker ni decidir si el destino es seguro; eso lo resuelve el comando replay.
+package recording
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"drone-observe/internal/mqttclient"
+)
+
+// Publisher publica un mensaje; *mqttclient.Client lo implementa.
+type Publisher interface {
+	Publish(topic string, payload []byte, qos byte, retain bool) error
+}
+
+// Rewrite reemplaza el prefijo From por To en cada topic (por niveles: drone/a no matchea drone/ab).
+type Rewrite struct {
+	From string
+	To   string
+}
+
+// ParseRewrite lee "<desde>=<hacia>".
+func ParseRewrite(s string) (Rewrite, error) {
+	from, to, ok := strings.Cut(s, "=")
+	from, to = strings.Trim(from, "/"), strings.Trim(to, "/")
+	if !ok || from == "" || to == "" {
+		return Rewrite{}, fmt.Errorf("rewrite %q: esperado <prefijo>=<prefijo>", s)
+	}
+	if strings.ContainsAny(from+to, "+#") {
+		return Rewrite{}, fmt.Errorf("rewrite %q: sin wildcards", s)
+	}
+	return Rewrite{From: from, To: to}, nil
+}
+
+// Apply devuelve topic con el prefijo reescrito; sin Rewrite o sin match lo devuelve igual.
+func (rw Rewrite) Apply(topic string) string {
+	if rw.From == "" {
+		return topic
+	}
+	if topic == rw.From {
+		return rw.To
+	}
+	if rest, ok := strings.CutPrefix(topic, rw.From+"/"); ok {
+		return rw.To + "/" + rest
+	}
+	return topic
+}
+
+// Summary describe una grabacion sin republicarla.
+type Summary struct {
+	Header   Header
+	Messages int
+	Bytes    int
+	Duration time.Duration
+	Topics   []string
+	// Truncated indica que el ultimo registro quedo cortado (grabacion interrumpida).
+	Truncated bool
+}
+
+// Scan recorre path completo y resume su contenido.
+func Scan(path string) (Summary, error) {
+	r, err := Open(path)
+	if err != nil {
+		return Summary{}, err
+	}
+	defer r.Close()
+	s := Summary{Header: r.Header()}
+	seen := map[string]bool{}
+	var first time.Time
+	for {
+		msg, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			s.Truncated = true
+			break
+		}
+		if err != nil {
+			return s, fmt.Errorf("%s: mensaje %d: %w", path, s.Messages+1, err)
+		}
+		if s.Messages == 0 {
+			first = msg.Received
+		}
+		s.Messages++
+		s.Bytes += len(msg.Payload)
+		s.Duration = msg.Received.Sub(first)
+		if !seen[msg.Topic] {
+			seen[msg.Topic] = true
+			s.Topics = append(s.Topics, msg.Topic)
+		}
+	}
+	sort.Strings(s.Topics)
+	return s, nil
+}
+
+// Options controla la republicacion.
+type Options struct {
+	// Speed multiplica la velocidad original (2 = el doble de rapido); 0 publica sin esperar.
+	Speed   float64
+	Rewrite Rewrite
+}
+
+// Result resume una republicacion.
+type Result struct {
+	Published int
+	Elapsed   time.Duration
+	// MaxLag es el mayor atraso respecto del horario programado (publicaciones QoS 1 lentas).
+	MaxLag    time.Duration
+	Truncated bool
+}
+
+// PARTE CRITICA **********************
+// Cada mensaje se programa contra el inicio del replay (offset original / Speed), no contra el mensaje
+// anterior: un PUBACK lento atrasa un mensaje pero no corre toda la secuencia, y los intervalos entre
+// mensajes (lo que ven los detectores de gaps y el ML) quedan como en el campo.
+// Un error de publish corta el replay: seguir con la conexion caida daria una secuencia con huecos
+// que parece un bug del backend.
+// FIN DE PARTE CRITICA ****************
+func Replay(ctx context.Context, r *Reader, pub Publisher, opts Options) (Result, error) {
+	var res Result
+	start := time.Now()
+	var first time.Time
+	for {
+		msg, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			res.Truncated = true
+			break
+		}
+		if err != nil {
+			res.Elapsed = time.Since(start)
+			return res, fmt.Errorf("mensaje %d: %w", res.Published+1, err)
+		}
+		if res.Published == 0 {
+			first = msg.Received
+		}
+		if opts.Speed > 0 {
+			due := start.Add(time.Duration(float64(msg.Received.Sub(first)) / opts.Speed))
+			if wait := time.Until(due); wait > 0 {
+				timer := time.NewTimer(wait)
+				select {
+				case <-timer.C:
+				case <-ctx.Done():
+					timer.Stop()
+					res.Elapsed = time.Since(start)
+					return res, ctx.Err()
+				}
+			} else if -wait > res.MaxLag {
+				res.MaxLag = -wait
+			}
+		} else if ctx.Err() != nil {
+			res.Elapsed = time.Since(start)
+			return res, ctx.Err()
+		}
+		topic := opts.Rewrite.Apply(msg.Topic)
+		if err := pub.Publish(topic, msg.Payload, msg.QoS, msg.Retain); err != nil {
+			res.Elapsed = time.Since(start)
+			return res, fmt.Errorf("publish %s (mensaje %d): %w", topic, res.Published+1, err)
+		}
+		res.Published++
+	}
+	res.Elapsed = time.Since(start)
+	return res, nil
+}
+
+// Collides devuelve los topics (ya reescritos) que caen bajo base/#.
+func Collides(topics []string, rw Rewrite, base string) []string {
+	filter := strings.Trim(base, "/") + "/#"
+	var out []string
+	for _, t := range topics {
+		if t = rw.Apply(t); mqttclient.MatchTopic(filter, t) {
+			out = append(out, t)
+		}
+	}
+	return out
+}
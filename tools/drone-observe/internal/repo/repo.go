@@ -0,0 +1,48 @@
+// Archivo: tools/drone-observe/internal/repo/repo.go
+// Rol: resolver rutas de artefactos versionados relativas a la raiz del repo.
+// No hace: busqueda recursiva ni rutas absolutas fuera del repo.
+package repo
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// PARTE CRITICA **********************
+// Se buscan rutas relativas controladas para evitar fallos al ejecutar desde tools/drone-observe.
+// Si se expanden rutas arbitrarias, se pierde determinismo y trazabilidad del contrato.
+// No usar paths absolutos hardcodeados aqui.
+// FIN DE PARTE CRITICA ****************
+func Resolve(path string) (string, error) {
+	if filepath.IsAbs(path) {
+		if _, err := os.Stat(path); err != nil {
+			return "", err
+		}
+		return path, nil
+	}
+	candidates := []string{
+		path,
+		filepath.Join("..", path),
+		filepath.Join("..", "..", path),
+	}
+	for _, p := range candidates {
+		if _, err := os.Stat(p); err == nil {
+			return p, nil
+		}
+	}
+	return "", fmt.Errorf("no se encontro %s en rutas conocidas", path)
+}
+
+// Open abre un archivo versionado y devuelve la ruta efectiva usada.
+func Open(path string) (*os.File, string, error) {
+	p, err := Resolve(path)
+	if err != nil {
+		return nil, "", err
+	}
+	f, err := os.Open(p)
+	if err != nil {
+		return nil, "", err
+	}
+	return f, p, nil
+}
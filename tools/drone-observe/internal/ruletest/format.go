@@ -0,0 +1,234 @@
+// Archivo: tools/drone-observe/internal/ruletest/format.go
+// Rol: formato de tests de reglas de `promtool test rules` y carga de archivos de reglas.
+// No hace: evaluacion; eso vive en run.go sobre el evaluador de internal/promql.
+package ruletest
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"drone-observe/internal/promql"
+)
+
+// TestFile es el YAML de promtool: rule_files + grupos de tests.
+type TestFile struct {
+	RuleFiles          []string    `yaml:"rule_files"`
+	EvaluationInterval string      `yaml:"evaluation_interval"`
+	Tests              []TestGroup `yaml:"tests"`
+}
+
+type TestGroup struct {
+	Name           string        `yaml:"name"`
+	Interval       string        `yaml:"interval"`
+	InputSeries    []InputSeries `yaml:"input_series"`
+	AlertRuleTests []AlertTest   `yaml:"alert_rule_test"`
+	PromQLTests    []PromQLTest  `yaml:"promql_expr_test"`
+}
+
+// InputSeries es una serie sintetica; Values usa la notacion expandible de promtool (0+10x5 _ stale).
+type InputSeries struct {
+	Series string `yaml:"series"`
+	Values string `yaml:"values"`
+}
+
+type AlertTest struct {
+	EvalTime  string     `yaml:"eval_time"`
+	Alertname string     `yaml:"alertname"`
+	ExpAlerts []ExpAlert `yaml:"exp_alerts"`
+}
+
+type ExpAlert struct {
+	ExpLabels      map[string]string `yaml:"exp_labels"`
+	ExpAnnotations map[string]string `yaml:"exp_annotations"`
+}
+
+type PromQLTest struct {
+	Expr       string      `yaml:"expr"`
+	EvalTime   string      `yaml:"eval_time"`
+	ExpSamples []ExpSample `yaml:"exp_samples"`
+}
+
+type ExpSample struct {
+	Labels string  `yaml:"labels"`
+	Value  float64 `yaml:"value"`
+}
+
+// Rule es una regla de alerta o de recording ya parseada.
+type Rule struct {
+	Group       string
+	Alert       string
+	Record      string
+	Expr        promql.Expr
+	For         time.Duration
+	Labels      map[string]string
+	Annotations map[string]string
+}
+
+type ruleFile struct {
+	Groups []struct {
+		Name  string `yaml:"name"`
+		Rules []struct {
+			Alert       string            `yaml:"alert"`
+			Record      string            `yaml:"record"`
+			Expr        string            `yaml:"expr"`
+			For         string            `yaml:"for"`
+			Labels      map[string]string `yaml:"labels"`
+			Annotations map[string]string `yaml:"annotations"`
+		} `yaml:"rules"`
+	} `yaml:"groups"`
+}
+
+func loadTestFile(path string) (TestFile, error) {
+	var tf TestFile
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return tf, err
+	}
+	if err := yaml.Unmarshal(data, &tf); err != nil {
+		return tf, fmt.Errorf("%s: %w", path, err)
+	}
+	return tf, nil
+}
+
+// LoadRules lee archivos de reglas (globs relativos a dir) en orden de grupo y de regla.
+func LoadRules(dir string, patterns []string) ([]Rule, error) {
+	var rules []Rule
+	for _, pattern := range patterns {
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(dir, pattern)
+		}
+		files, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, err
+		}
+		if len(files) == 0 {
+			return nil, fmt.Errorf("rule_files: %s no existe", pattern)
+		}
+		for _, file := range files {
+			loaded, err := loadRuleFile(file)
+			if err != nil {
+				return nil, err
+			}
+			rules = append(rules, loaded...)
+		}
+	}
+	return rules, nil
+}
+
+func loadRuleFile(path string) ([]Rule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var rf ruleFile
+	if err := yaml.Unmarshal(data, &rf); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	var rules []Rule
+	for _, g := range rf.Groups {
+		for _, r := range g.Rules {
+			name := r.Alert
+			if name == "" {
+				name = r.Record
+			}
+			expr, err := promql.Parse(r.Expr)
+			if err != nil {
+				return nil, fmt.Errorf("%s: regla %s: %v", path, name, err)
+			}
+			rule := Rule{Group: g.Name, Alert: r.Alert, Record: r.Record, Expr: expr, Labels: r.Labels, Annotations: r.Annotations}
+			if r.For != "" {
+				if rule.For, err = promql.ParseDuration(r.For); err != nil {
+					return nil, fmt.Errorf("%s: regla %s: for: %v", path, name, err)
+				}
+			}
+			rules = append(rules, rule)
+		}
+	}
+	return rules, nil
+}
+
+// value es una muestra expandida de input_series: ausente (_), stale o numero.
+type value struct {
+	v       float64
+	missing bool
+	stale   bool
+}
+
+var expandPattern = regexp.MustCompile(`^(_|[-+]?[^-+x]+(?:e[-+]?\d+)?)(?:([-+])([^x]+))?x(\d+)$`)
+
+// PARTE CRITICA **********************
+// Notacion de promtool: "a+bxn" son n+1 valores (a, a+b, ..., a+n*b); "axn" repite a n+1 veces;
+// "_xn" son n ausencias; "_" ausencia; "stale" marca fin de serie. Un off-by-one aca corre todos los tiempos.
+// FIN DE PARTE CRITICA ****************
+func expandValues(s string) ([]value, error) {
+	var out []value
+	for _, field := range strings.Fields(s) {
+		switch field {
+		case "_":
+			out = append(out, value{missing: true})
+			continue
+		case "stale":
+			out = append(out, value{stale: true})
+			continue
+		}
+		m := expandPattern.FindStringSubmatch(field)
+		if m == nil {
+			v, err := parseFloat(field)
+			if err != nil {
+				return nil, fmt.Errorf("valor invalido %q", field)
+			}
+			out = append(out, value{v: v})
+			continue
+		}
+		n, err := strconv.Atoi(m[4])
+		if err != nil {
+			return nil, fmt.Errorf("valor invalido %q", field)
+		}
+		if m[1] == "_" {
+			if m[2] != "" {
+				return nil, fmt.Errorf("valor invalido %q", field)
+			}
+			for i := 0; i < n; i++ {
+				out = append(out, value{missing: true})
+			}
+			continue
+		}
+		start, err := parseFloat(m[1])
+		if err != nil {
+			return nil, fmt.Errorf("valor invalido %q", field)
+		}
+		step := 0.0
+		if m[2] != "" {
+			if step, err = parseFloat(m[3]); err != nil {
+				return nil, fmt.Errorf("valor invalido %q", field)
+			}
+			if m[2] == "-" {
+				step = -step
+			}
+		}
+		for i := 0; i <= n; i++ {
+			out = append(out, value{v: start + float64(i)*step})
+		}
+	}
+	return out, nil
+}
+
+func parseFloat(s string) (float64, error) {
+	switch strings.ToLower(strings.TrimPrefix(s, "+")) {
+	case "inf":
+		return math.Inf(1), nil
+	case "-inf":
+		return math.Inf(-1), nil
+	case "nan":
+		return math.NaN(), nil
+	}
+	return strconv.ParseFloat(s, 64)
+}
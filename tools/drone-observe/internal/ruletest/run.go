@@ -0,0 +1,366 @@
+// Archivo: tools/drone-observe/internal/ruletest/run.go
+// Rol: simular la evaluacion de reglas en el tiempo y verificar alertas y queries esperadas.
+// No hace: notificaciones, keep_firing_for ni estado de Alertmanager; solo alertas en estado firing.
+package ruletest
+
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"drone-observe/internal/promql"
+)
+
+const defaultInterval = time.Minute
+
+// GroupResult es el resultado de un grupo de tests; sin Failures = OK.
+type GroupResult struct {
+	Name     string
+	Failures []string
+}
+
+type Result struct {
+	File   string
+	Groups []GroupResult
+}
+
+// Failed indica si algun grupo fallo.
+func (r Result) Failed() bool {
+	for _, g := range r.Groups {
+		if len(g.Failures) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Alert es una alerta en estado firing en un instante de la simulacion.
+type Alert struct {
+	Labels      promql.Labels
+	Annotations map[string]string
+}
+
+func (a Alert) String() string {
+	return fmt.Sprintf("labels:%s annotations:%s", a.Labels, promql.Labels(a.Annotations))
+}
+
+// Run ejecuta un archivo de tests; rule_files se resuelven relativos al archivo.
+func Run(path string) (Result, error) {
+	res := Result{File: path}
+	tf, err := loadTestFile(path)
+	if err != nil {
+		return res, err
+	}
+	rules, err := LoadRules(filepath.Dir(path), tf.RuleFiles)
+	if err != nil {
+		return res, err
+	}
+	evalInterval := defaultInterval
+	if tf.EvaluationInterval != "" {
+		if evalInterval, err = promql.ParseDuration(tf.EvaluationInterval); err != nil {
+			return res, fmt.Errorf("evaluation_interval: %v", err)
+		}
+	}
+	for i, tg := range tf.Tests {
+		name := tg.Name
+		if name == "" {
+			name = fmt.Sprintf("test %d", i+1)
+		}
+		failures, err := runGroup(tg, rules, evalInterval)
+		if err != nil {
+			failures = append(failures, err.Error())
+		}
+		res.Groups = append(res.Groups, GroupResult{Name: name, Failures: failures})
+	}
+	return res, nil
+}
+
+type activeAlert struct {
+	Alert
+	activeAt int64
+}
+
+type snapshot struct {
+	ts     int64
+	firing map[string][]Alert
+}
+
+// PARTE CRITICA **********************
+// Simulacion como Prometheus: las reglas se evaluan en orden cada evaluation_interval desde t=0;
+// recording rules escriben en el storage (visible para reglas siguientes) y una alerta pasa a firing
+// cuando lleva `for` activa. Los asserts de alert_rule_test ven la ultima evaluacion <= eval_time.
+// FIN DE PARTE CRITICA ****************
+func runGroup(tg TestGroup, rules []Rule, evalInterval time.Duration) ([]string, error) {
+	interval := evalInterval
+	if tg.Interval != "" {
+		var err error
+		if interval, err = promql.ParseDuration(tg.Interval); err != nil {
+			return nil, fmt.Errorf("interval: %v", err)
+		}
+	}
+
+	storage := promql.NewStorage()
+	for _, in := range tg.InputSeries {
+		labels, err := promql.LabelsFromSelector(in.Series)
+		if err != nil {
+			return nil, err
+		}
+		values, err := expandValues(in.Values)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", in.Series, err)
+		}
+		for i, v := range values {
+			if v.missing {
+				continue
+			}
+			p := promql.Point{T: int64(i) * interval.Milliseconds(), V: v.v, Stale: v.stale}
+			if err := storage.Add(labels, p); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	var maxEval time.Duration
+	evalTimes := map[string]time.Duration{}
+	for _, t := range tg.AlertRuleTests {
+		d, err := promql.ParseDuration(t.EvalTime)
+		if err != nil {
+			return nil, fmt.Errorf("eval_time: %v", err)
+		}
+		evalTimes[t.EvalTime] = d
+		maxEval = max(maxEval, d)
+	}
+	for _, t := range tg.PromQLTests {
+		d, err := promql.ParseDuration(t.EvalTime)
+		if err != nil {
+			return nil, fmt.Errorf("eval_time: %v", err)
+		}
+		evalTimes[t.EvalTime] = d
+		maxEval = max(maxEval, d)
+	}
+
+	ev := &promql.Evaluator{Storage: storage, Step: evalInterval}
+	active := map[int]map[string]*activeAlert{}
+	recorded := map[int]map[string]struct{}{}
+	var snapshots []snapshot
+	for ts := int64(0); ts <= maxEval.Milliseconds(); ts += evalInterval.Milliseconds() {
+		firing := map[string][]Alert{}
+		for i, r := range rules {
+			val, err := ev.Eval(r.Expr, ts)
+			if err != nil {
+				return nil, fmt.Errorf("t=%s regla %s%s: %v", promql.FormatDuration(time.Duration(ts)*time.Millisecond), r.Alert, r.Record, err)
+			}
+			samples := val.Vector
+			if val.Type == promql.ValueScalar {
+				samples = promql.Vector{{Labels: promql.Labels{}, T: ts, V: val.Scalar}}
+			}
+			if r.Record != "" {
+				recorded[i], err = record(storage, r, samples, ts, recorded[i])
+				if err != nil {
+					return nil, err
+				}
+				continue
+			}
+			if active[i], err = updateAlerts(r, samples, ts, active[i]); err != nil {
+				return nil, err
+			}
+			for _, a := range active[i] {
+				if ts-a.activeAt >= r.For.Milliseconds() {
+					firing[r.Alert] = append(firing[r.Alert], a.Alert)
+				}
+			}
+		}
+		snapshots = append(snapshots, snapshot{ts: ts, firing: firing})
+	}
+
+	var failures []string
+	for _, t := range tg.AlertRuleTests {
+		at := evalTimes[t.EvalTime].Milliseconds()
+		var got []Alert
+		for _, s := range snapshots {
+			if s.ts <= at {
+				got = s.firing[t.Alertname]
+			}
+		}
+		exp := make([]Alert, 0, len(t.ExpAlerts))
+		for _, e := range t.ExpAlerts {
+			l := promql.Labels{"alertname": t.Alertname}
+			for k, v := range e.ExpLabels {
+				l[k] = v
+			}
+			ann := e.ExpAnnotations
+			if ann == nil {
+				ann = map[string]string{}
+			}
+			exp = append(exp, Alert{Labels: l, Annotations: ann})
+		}
+		if expStr, gotStr := alertStrings(exp), alertStrings(got); expStr != gotStr {
+			failures = append(failures, fmt.Sprintf("alertname: %s, time: %s\n  exp: %s\n  got: %s", t.Alertname, t.EvalTime, expStr, gotStr))
+		}
+	}
+	for _, t := range tg.PromQLTests {
+		if f := checkExpr(ev, t, evalTimes[t.EvalTime].Milliseconds()); f != "" {
+			failures = append(failures, f)
+		}
+	}
+	return failures, nil
+}
+
+func record(storage *promql.Storage, r Rule, samples promql.Vector, ts int64, prev map[string]struct{}) (map[string]struct{}, error) {
+	current := map[string]struct{}{}
+	var stale []promql.Labels
+	for _, s := range samples {
+		l := promql.Labels{}
+		for k, v := range s.Labels {
+			l[k] = v
+		}
+		for k, v := range r.Labels {
+			l[k] = v
+		}
+		l["__name__"] = r.Record
+		current[l.String()] = struct{}{}
+		if err := storage.Add(l, promql.Point{T: ts, V: s.V}); err != nil {
+			return nil, err
+		}
+	}
+	// Una serie que deja de producirse recibe un stale marker, igual que en Prometheus.
+	for _, ser := range storage.Series() {
+		key := ser.Labels.String()
+		if _, was := prev[key]; was {
+			if _, still := current[key]; !still {
+				stale = append(stale, ser.Labels)
+			}
+		}
+	}
+	for _, l := range stale {
+		if err := storage.Add(l, promql.Point{T: ts, Stale: true}); err != nil {
+			return nil, err
+		}
+	}
+	return current, nil
+}
+
+func updateAlerts(r Rule, samples promql.Vector, ts int64, prev map[string]*activeAlert) (map[string]*activeAlert, error) {
+	next := map[string]*activeAlert{}
+	for _, s := range samples {
+		base := map[string]string{}
+		for k, v := range s.Labels {
+			if k != "__name__" {
+				base[k] = v
+			}
+		}
+		labels := promql.Labels{}
+		for k, v := range base {
+			labels[k] = v
+		}
+		for k, v := range r.Labels {
+			expanded, err := expand(v, base, s.V)
+			if err != nil {
+				return nil, fmt.Errorf("regla %s: label %s: %v", r.Alert, k, err)
+			}
+			labels[k] = expanded
+		}
+		labels["alertname"] = r.Alert
+		annotations := map[string]string{}
+		for k, v := range r.Annotations {
+			expanded, err := expand(v, base, s.V)
+			if err != nil {
+				return nil, fmt.Errorf("regla %s: annotation %s: %v", r.Alert, k, err)
+			}
+			annotations[k] = expanded
+		}
+		key := labels.String()
+		a := &activeAlert{Alert: Alert{Labels: labels, Annotations: annotations}, activeAt: ts}
+		if old, ok := prev[key]; ok {
+			a.activeAt = old.activeAt
+		}
+		next[key] = a
+	}
+	return next, nil
+}
+
+// expand resuelve templates de labels/annotations con $labels y $value, como Prometheus.
+func expand(text string, labels map[string]string, value float64) (string, error) {
+	if !strings.Contains(text, "{{") {
+		return text, nil
+	}
+	tmpl, err := template.New("").Option("missingkey=zero").Parse("{{$labels := .Labels}}{{$value := .Value}}" + text)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	err = tmpl.Execute(&b, struct {
+		Labels map[string]string
+		Value  float64
+	}{labels, value})
+	return b.String(), err
+}
+
+func alertStrings(alerts []Alert) string {
+	parts := make([]string, 0, len(alerts))
+	for _, a := range alerts {
+		parts = append(parts, a.String())
+	}
+	sort.Strings(parts)
+	return "[" + strings.Join(parts, ", ") + "]"
+}
+
+func checkExpr(ev *promql.Evaluator, t PromQLTest, at int64) string {
+	expr, err := promql.Parse(t.Expr)
+	if err != nil {
+		return fmt.Sprintf("expr: %s: %v", t.Expr, err)
+	}
+	val, err := ev.Eval(expr, at)
+	if err != nil {
+		return fmt.Sprintf("expr: %s, time: %s: %v", t.Expr, t.EvalTime, err)
+	}
+	got := val.Vector
+	if val.Type == promql.ValueScalar {
+		got = promql.Vector{{Labels: promql.Labels{}, V: val.Scalar}}
+	}
+
+	exp := make(promql.Vector, 0, len(t.ExpSamples))
+	for _, s := range t.ExpSamples {
+		l, err := promql.LabelsFromSelector(s.Labels)
+		if err != nil {
+			return fmt.Sprintf("expr: %s: exp_samples: %v", t.Expr, err)
+		}
+		exp = append(exp, promql.Sample{Labels: l, V: s.Value})
+	}
+	sortSamples(exp)
+	sortSamples(got)
+	ok := len(exp) == len(got)
+	for i := 0; ok && i < len(exp); i++ {
+		ok = exp[i].Labels.String() == got[i].Labels.String() && almostEqual(exp[i].V, got[i].V)
+	}
+	if ok {
+		return ""
+	}
+	return fmt.Sprintf("expr: %s, time: %s\n  exp: %s\n  got: %s", t.Expr, t.EvalTime, samplesString(exp), samplesString(got))
+}
+
+func sortSamples(v promql.Vector) {
+	sort.Slice(v, func(i, j int) bool { return v[i].Labels.String() < v[j].Labels.String() })
+}
+
+func samplesString(v promql.Vector) string {
+	parts := make([]string, 0, len(v))
+	for _, s := range v {
+		parts = append(parts, fmt.Sprintf("%s %g", s.Labels, s.V))
+	}
+	return "[" + strings.Join(parts, ", ") + "]"
+}
+
+func almostEqual(a, b float64) bool {
+	if math.IsNaN(a) || math.IsNaN(b) {
+		return math.IsNaN(a) && math.IsNaN(b)
+	}
+	if a == b {
+		return true
+	}
+	return math.Abs(a-b) <= 1e-6*math.Max(math.Abs(a), math.Abs(b))
+}
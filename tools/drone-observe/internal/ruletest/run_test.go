@@ -0,0 +1,158 @@
+package ruletest
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+const testRules = `groups:
+  - name: prueba
+    rules:
+      - record: job:x:max
+        expr: max by (job) (x)
+      - alert: ValorAlto
+        expr: job:x:max > 10
+        for: 2m
+        labels:
+          severity: warning
+        annotations:
+          summary: 'valor {{ $value }} en {{ $labels.job }}'
+`
+
+func TestRunPendingToFiring(t *testing.T) {
+	res := runFile(t, `rule_files: [rules.yml]
+evaluation_interval: 1m
+tests:
+  - name: for
+    interval: 1m
+    input_series:
+      - series: 'x{job="api",instance="a"}'
+        values: '0 20x4 0'
+    alert_rule_test:
+      # Activa desde 1m: pending en 1m y 2m, firing desde 3m (for: 2m).
+      - eval_time: 1m
+        alertname: ValorAlto
+      - eval_time: 2m
+        alertname: ValorAlto
+      - eval_time: 3m
+        alertname: ValorAlto
+        exp_alerts:
+          - exp_labels: {job: api, severity: warning}
+            exp_annotations: {summary: 'valor 20 en api'}
+      # Entre evaluaciones se ve la ultima (3m); en 6m la expresion ya es falsa.
+      - eval_time: 3m30s
+        alertname: ValorAlto
+        exp_alerts:
+          - exp_labels: {job: api, severity: warning}
+            exp_annotations: {summary: 'valor 20 en api'}
+      - eval_time: 6m
+        alertname: ValorAlto
+    promql_expr_test:
+      - expr: job:x:max
+        eval_time: 2m
+        exp_samples:
+          - labels: 'job:x:max{job="api"}'
+            value: 20
+`)
+	if res.Failed() {
+		t.Fatalf("fallos inesperados: %v", res.Groups)
+	}
+}
+
+// Los mensajes de fallo siguen el formato de promtool para que el diff sea familiar.
+func TestRunFailures(t *testing.T) {
+	res := runFile(t, `rule_files: [rules.yml]
+tests:
+  - name: expectativas erroneas
+    interval: 1m
+    input_series:
+      - series: 'x{job="api"}'
+        values: '20x5'
+    alert_rule_test:
+      # promtool falla: en 1m la alerta sigue pending.
+      - eval_time: 1m
+        alertname: ValorAlto
+        exp_alerts:
+          - exp_labels: {job: api, severity: warning}
+    promql_expr_test:
+      - expr: x * 2
+        eval_time: 1m
+        exp_samples:
+          - labels: '{job="api"}'
+            value: 41
+`)
+	if len(res.Groups) != 1 {
+		t.Fatalf("grupos = %+v", res.Groups)
+	}
+	failures := res.Groups[0].Failures
+	want := []string{
+		"alertname: ValorAlto, time: 1m\n" +
+			"  exp: [labels:{alertname=\"ValorAlto\",job=\"api\",severity=\"warning\"} annotations:{}]\n" +
+			"  got: []",
+		"expr: x * 2, time: 1m\n" +
+			"  exp: [{job=\"api\"} 41]\n" +
+			"  got: [{job=\"api\"} 40]",
+	}
+	if strings.Join(failures, "\n---\n") != strings.Join(want, "\n---\n") {
+		t.Errorf("fallos:\n%s\nesperado:\n%s", strings.Join(failures, "\n---\n"), strings.Join(want, "\n---\n"))
+	}
+}
+
+func TestExpandValues(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"1+1x2", "1 2 3"},
+		{"5x2", "5 5 5"},
+		{"10-2x2", "10 8 6"},
+		{"-2+3x1", "-2 1"},
+		{"_x3 1", "_ _ _ 1"},
+		{"1 _ stale 2", "1 _ stale 2"},
+		{"1e3 +Inf", "1000 +Inf"},
+	}
+	for _, tt := range tests {
+		values, err := expandValues(tt.in)
+		if err != nil {
+			t.Fatalf("expandValues(%q): %v", tt.in, err)
+		}
+		var got []string
+		for _, v := range values {
+			switch {
+			case v.missing:
+				got = append(got, "_")
+			case v.stale:
+				got = append(got, "stale")
+			default:
+				got = append(got, strconv.FormatFloat(v.v, 'g', -1, 64))
+			}
+		}
+		if strings.Join(got, " ") != tt.want {
+			t.Errorf("expandValues(%q) = %q; esperado %q", tt.in, strings.Join(got, " "), tt.want)
+		}
+	}
+	if _, err := expandValues("1+x2"); err == nil {
+		t.Error("1+x2 sin error")
+	}
+}
+
+// runFile escribe las reglas y el test en un directorio temporal y corre Run.
+func runFile(t *testing.T, test string) Result {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "rules.yml"), []byte(testRules), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "rules.test.yml")
+	if err := os.WriteFile(path, []byte(test), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	res, err := Run(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res
+}
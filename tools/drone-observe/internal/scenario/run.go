@@ -0,0 +1,152 @@
+// Archivo: tools/drone-observe/internal/scenario/run.go
+// Rol: correr un escenario paso a paso: inyectar trafico y esperar los estados de cada check con plazo.
+// No hace: imprimir el reporte ni conectar al broker; eso lo inyecta cmd/chaos.go.
+package scenario
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"drone-observe/internal/config"
+	"drone-observe/internal/sim"
+)
+
+// StatesFunc devuelve la evaluacion en vivo de un check (item -> estado); ui.LiveStates la implementa.
+type StatesFunc func(check string) (func(cfg config.Config) map[string]string, bool)
+
+// Env es lo que el runner necesita del exterior.
+type Env struct {
+	Cfg    config.Config
+	Dial   sim.Dialer
+	States StatesFunc
+	// Progress recibe una linea por evento (inicio de paso, deteccion); puede ser nil.
+	Progress func(string)
+	Seed     int64
+}
+
+// ExpectResult es el resultado de una expectativa; Latency es desde el inicio del paso.
+type ExpectResult struct {
+	Expect   Expect
+	Met      bool
+	Latency  time.Duration
+	Observed string
+}
+
+type StepResult struct {
+	Step    Step
+	Expects []ExpectResult
+}
+
+// Report es el resultado del escenario; Err indica que se corto antes de terminar (publish o contexto).
+type Report struct {
+	Scenario Scenario
+	Steps    []StepResult
+	Err      error
+}
+
+// Failed indica si alguna expectativa no se cumplio o la corrida se corto.
+func (r Report) Failed() bool {
+	if r.Err != nil {
+		return true
+	}
+	for _, st := range r.Steps {
+		for _, e := range st.Expects {
+			if !e.Met {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Run ejecuta los pasos en orden con una sola conexion (un dron, como el edge).
+func Run(ctx context.Context, s Scenario, env Env) Report {
+	report := Report{Scenario: s}
+	client, err := env.Dial("drone-observe-chaos")
+	if err != nil {
+		report.Err = err
+		return report
+	}
+	defer client.Close()
+	tr := newTraffic(s.Topic, s.Rate, env.Seed)
+	for i, step := range s.Steps {
+		env.progress(fmt.Sprintf("[%d/%d] %s: %s durante %s", i+1, len(s.Steps), step.Name, step.Action, step.Duration()))
+		res, err := runStep(ctx, s, step, tr, client, env)
+		report.Steps = append(report.Steps, res)
+		if err != nil {
+			report.Err = fmt.Errorf("step %s: %w", step.Name, err)
+			return report
+		}
+	}
+	return report
+}
+
+// PARTE CRITICA **********************
+// El trafico corre en su goroutine durante todo For; las expectativas se evaluan cada Poll desde el inicio del
+// paso. La latencia es el instante en que arranco la evaluacion que vio el estado esperado (no cuando
+// termino: health puede tardar segundos y el dato ya era ese). Cada check se evalua una vez por ronda aunque
+// varias expectativas lo usen. Una expectativa cumplida no se vuelve a mirar; una vencida queda FALLO con el
+// ultimo estado visto. El paso siempre dura For completo para que el siguiente arranque desde el mismo lugar.
+// FIN DE PARTE CRITICA ****************
+func runStep(ctx context.Context, s Scenario, step Step, tr *traffic, pub sim.Publisher, env Env) (StepResult, error) {
+	res := StepResult{Step: step, Expects: make([]ExpectResult, len(step.Expect))}
+	for i, e := range step.Expect {
+		res.Expects[i] = ExpectResult{Expect: e, Observed: "sin evaluar"}
+	}
+	start := time.Now()
+	stepCtx, cancel := context.WithDeadline(ctx, start.Add(step.Duration()))
+	defer cancel()
+	trafficErr := make(chan error, 1)
+	go func() { trafficErr <- tr.run(stepCtx, step, pub) }()
+
+	ticker := time.NewTicker(s.PollInterval())
+	defer ticker.Stop()
+	for {
+		evalAt := time.Now()
+		elapsed := evalAt.Sub(start)
+		cache := map[string]map[string]string{}
+		for i := range res.Expects {
+			r := &res.Expects[i]
+			if r.Met || elapsed > r.Expect.Deadline() {
+				continue
+			}
+			states, ok := cache[r.Expect.Check]
+			if !ok {
+				states = env.evaluate(r.Expect.Check)
+				cache[r.Expect.Check] = states
+			}
+			state, found := states[r.Expect.Item]
+			if !found {
+				state = "ausente"
+			}
+			r.Observed = state
+			if found && r.Expect.State.Has(state) {
+				r.Met, r.Latency = true, elapsed
+				env.progress(fmt.Sprintf("  %s/%s = %s en %s", r.Expect.Check, r.Expect.Item, state, elapsed.Round(time.Second)))
+			}
+		}
+		select {
+		case err := <-trafficErr:
+			if err != nil {
+				return res, err
+			}
+			return res, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (env Env) evaluate(check string) map[string]string {
+	fn, ok := env.States(check)
+	if !ok {
+		return nil
+	}
+	return fn(env.Cfg)
+}
+
+func (env Env) progress(line string) {
+	if env.Progress != nil {
+		env.Progress(line)
+	}
+}
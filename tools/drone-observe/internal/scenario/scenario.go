@@ -0,0 +1,226 @@
+// Archivo: tools/drone-observe/internal/scenario/scenario.go
+// Rol: leer y validar escenarios de chaos (pasos con trafico inyectado y estados esperados por check).
+// No hace: publicar ni evaluar checks; eso es Run.
+package scenario
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"drone-observe/internal/acl"
+)
+
+// Acciones de un paso: que publica el dron del escenario mientras dura el paso.
+const (
+	ActionPublish   = "publish"
+	ActionStop      = "stop"
+	ActionMalformed = "malformed"
+	ActionFlood     = "flood"
+	ActionAnomaly   = "anomaly"
+)
+
+// Actions lista las acciones en orden estable para errores y ayuda.
+var Actions = []string{ActionPublish, ActionStop, ActionMalformed, ActionFlood, ActionAnomaly}
+
+const (
+	defaultRate        = 1
+	defaultFloodFactor = 100
+	defaultPoll        = 2 * time.Second
+	minPoll            = 500 * time.Millisecond
+)
+
+// States es uno o varios estados aceptados ("state: FAIL" o "state: [WARN, FAIL]").
+type States []string
+
+func (s *States) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*s = States{node.Value}
+		return nil
+	}
+	var list []string
+	if err := node.Decode(&list); err != nil {
+		return err
+	}
+	*s = list
+	return nil
+}
+
+// Has indica si state esta entre los aceptados (sin distinguir mayusculas).
+func (s States) Has(state string) bool {
+	for _, v := range s {
+		if strings.EqualFold(v, state) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s States) String() string { return strings.Join(s, "|") }
+
+// Expect es un estado esperado de un item de un check antes de Within (medido desde el inicio del paso).
+type Expect struct {
+	Check  string `yaml:"check"`
+	Item   string `yaml:"item"`
+	State  States `yaml:"state"`
+	Within string `yaml:"within"`
+
+	within time.Duration
+}
+
+// Deadline devuelve el plazo ya validado.
+func (e Expect) Deadline() time.Duration { return e.within }
+
+// Step es un tramo del escenario: una accion durante For y los estados que deben observarse.
+type Step struct {
+	Name   string `yaml:"name"`
+	Action string `yaml:"action"`
+	For    string `yaml:"for"`
+	// Factor multiplica Rate en flood (default 100).
+	Factor float64  `yaml:"factor"`
+	Expect []Expect `yaml:"expect"`
+
+	duration time.Duration
+}
+
+// Duration devuelve la duracion ya validada.
+func (s Step) Duration() time.Duration { return s.duration }
+
+type Scenario struct {
+	Path string `yaml:"-"`
+	Name string `yaml:"name"`
+	// Broker es host:port; --broker lo reemplaza y sin ninguno se usa MQTT_HOST:MQTT_PORT.
+	Broker string `yaml:"broker"`
+	// Topic es el topic base donde se inyecta (default MQTT_BASE_TOPIC, el que consumen backend y ML).
+	Topic string `yaml:"topic"`
+	// Rate es la telemetria por segundo del dron del escenario (default 1, como el edge).
+	Rate float64 `yaml:"rate"`
+	// Poll es el intervalo entre evaluaciones de los checks (default 2s).
+	Poll  string `yaml:"poll"`
+	Steps []Step `yaml:"steps"`
+
+	poll time.Duration
+}
+
+// PollInterval devuelve el intervalo de evaluacion ya validado.
+func (s Scenario) PollInterval() time.Duration { return s.poll }
+
+// Duration es la suma de los pasos.
+func (s Scenario) Duration() time.Duration {
+	var total time.Duration
+	for _, st := range s.Steps {
+		total += st.duration
+	}
+	return total
+}
+
+// Load lee y valida un escenario; checks son los checks que el runner sabe evaluar.
+func Load(path string, checks []string) (Scenario, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Scenario{}, err
+	}
+	var s Scenario
+	if err := yaml.Unmarshal(data, &s); err != nil {
+		return Scenario{}, fmt.Errorf("%s: %w", path, err)
+	}
+	s.Path = path
+	if err := s.validate(checks); err != nil {
+		return Scenario{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return s, nil
+}
+
+func (s *Scenario) validate(checks []string) error {
+	if s.Name == "" {
+		return fmt.Errorf("falta name")
+	}
+	if s.Broker != "" {
+		if _, _, err := acl.SplitBroker(s.Broker); err != nil {
+			return err
+		}
+	}
+	if strings.ContainsAny(s.Topic, "+#") {
+		return fmt.Errorf("topic %s: sin wildcards", s.Topic)
+	}
+	if s.Rate == 0 {
+		s.Rate = defaultRate
+	}
+	if s.Rate < 0 {
+		return fmt.Errorf("rate negativo")
+	}
+	s.poll = defaultPoll
+	if s.Poll != "" {
+		d, err := time.ParseDuration(s.Poll)
+		if err != nil || d < minPoll {
+			return fmt.Errorf("poll %q invalido (minimo %s)", s.Poll, minPoll)
+		}
+		s.poll = d
+	}
+	if len(s.Steps) == 0 {
+		return fmt.Errorf("sin steps")
+	}
+	for i := range s.Steps {
+		st := &s.Steps[i]
+		where := fmt.Sprintf("step %d (%s)", i+1, st.Name)
+		if st.Name == "" {
+			return fmt.Errorf("step %d: falta name", i+1)
+		}
+		if !contains(Actions, st.Action) {
+			return fmt.Errorf("%s: action %q invalida (validas: %s)", where, st.Action, strings.Join(Actions, ", "))
+		}
+		d, err := time.ParseDuration(st.For)
+		if err != nil || d <= 0 {
+			return fmt.Errorf("%s: for %q invalido", where, st.For)
+		}
+		st.duration = d
+		switch {
+		case st.Factor < 0 || (st.Factor > 0 && st.Action != ActionFlood):
+			return fmt.Errorf("%s: factor solo aplica a flood y debe ser > 0", where)
+		case st.Action == ActionFlood && st.Factor == 0:
+			st.Factor = defaultFloodFactor
+		}
+		for j := range st.Expect {
+			if err := st.Expect[j].validate(checks, d); err != nil {
+				return fmt.Errorf("%s: expect %d: %w", where, j+1, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (e *Expect) validate(checks []string, stepFor time.Duration) error {
+	if !contains(checks, e.Check) {
+		return fmt.Errorf("check %q invalido (validos: %s)", e.Check, strings.Join(checks, ", "))
+	}
+	if e.Item == "" {
+		return fmt.Errorf("falta item (p. ej. el nombre de la fila del check)")
+	}
+	if len(e.State) == 0 {
+		return fmt.Errorf("falta state")
+	}
+	e.within = stepFor
+	if e.Within != "" {
+		d, err := time.ParseDuration(e.Within)
+		if err != nil || d <= 0 {
+			return fmt.Errorf("within %q invalido", e.Within)
+		}
+		if d > stepFor {
+			return fmt.Errorf("within %s mayor que el for del step (%s)", d, stepFor)
+		}
+		e.within = d
+	}
+	return nil
+}
+
+func contains(list []string, v string) bool {
+	for _, s := range list {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,123 @@
+// Archivo: tools/drone-observe/internal/scenario/traffic.go
+// Rol: trafico del dron del escenario segun la accion del paso (sano, cortado, malformado, flood, anomalia).
+// No hace: evaluar checks; solo publica mientras dura el paso.
+package scenario
+
+import (
+	"context"
+	"encoding/json"
+	"math/rand"
+	"time"
+
+	"drone-observe/internal/mqttclient"
+	"drone-observe/internal/sim"
+)
+
+// Cada tick de anomaly publica anomalyBurst muestras normales y despues un pico; el modelo puntua la ultima.
+const anomalyBurst = 5
+
+// traffic mantiene el estado del dron entre pasos: seq y bateria siguen como en un edge que no se reinicia.
+type traffic struct {
+	topic string
+	rate  float64
+	rng   *rand.Rand
+	drone *sim.Drone
+	stats sim.Stats
+}
+
+func newTraffic(topic string, rate float64, seed int64) *traffic {
+	rng := rand.New(rand.NewSource(seed))
+	return &traffic{
+		topic: topic,
+		rate:  rate,
+		rng:   rng,
+		drone: sim.NewDrone("chaos", topic, sim.Faults{}, rng),
+	}
+}
+
+// run publica segun la accion hasta que ctx termina; devuelve el primer error de publish.
+func (t *traffic) run(ctx context.Context, step Step, pub sim.Publisher) error {
+	if step.Action == ActionStop {
+		<-ctx.Done()
+		return nil
+	}
+	rate := t.rate
+	if step.Action == ActionFlood {
+		rate *= step.Factor
+	}
+	period := time.Duration(float64(time.Second) / rate)
+	next := time.Now()
+	for {
+		for _, msg := range t.tick(step.Action, time.Now()) {
+			if err := pub.Publish(msg.Topic, msg.Payload, msg.QoS, false); err != nil {
+				return err
+			}
+		}
+		// Se agenda contra el reloj y no con sleep(period): a 100x el costo del publish no baja la tasa.
+		next = next.Add(period)
+		wait := time.Until(next)
+		if wait <= 0 {
+			if ctx.Err() != nil {
+				return nil
+			}
+			continue
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+	}
+}
+
+// PARTE CRITICA **********************
+// ml_state es la puntuacion de la ULTIMA muestra contra una ventana de 60: si anomaly publicara solo picos, la
+// ventana se llenaria de picos y dejarian de ser raros. Por eso cada tick manda una rafaga normal y cierra con
+// un pico (bateria y altitud lejos de la tendencia). En anomaly no se publica BATTERY_LOW: el ML baja CRIT a
+// WARN durante 120s despues de ese evento y el escenario mediria la gracia en lugar de la deteccion.
+// FIN DE PARTE CRITICA ****************
+func (t *traffic) tick(action string, now time.Time) []mqttclient.Message {
+	switch action {
+	case ActionMalformed:
+		// Se corta el payload del mismo dron (y no de un sim.Drone con malformed=1) para que seq no se reinicie.
+		out := t.drone.Tick(now, &t.stats)
+		for i := range out {
+			out[i].Payload = out[i].Payload[:len(out[i].Payload)/2]
+		}
+		return out
+	case ActionAnomaly:
+		var out []mqttclient.Message
+		var last sim.Telemetry
+		for i := 0; i < anomalyBurst; i++ {
+			for _, msg := range t.drone.Tick(now, &t.stats) {
+				if msg.QoS == 0 {
+					out = append(out, msg)
+					_ = json.Unmarshal(msg.Payload, &last)
+				}
+			}
+		}
+		return append(out, t.spike(last, now))
+	}
+	return t.drone.Tick(now, &t.stats)
+}
+
+// spike es una telemetria valida con saltos que el edge nunca produce (bateria +-40..60, altitud +50..100 m).
+func (t *traffic) spike(last sim.Telemetry, now time.Time) mqttclient.Message {
+	jump := 40 + t.rng.Intn(21)
+	battery := last.BatteryPct - jump
+	if battery < 0 {
+		battery = last.BatteryPct + jump
+	}
+	if battery > 100 {
+		battery = 100
+	}
+	body, _ := json.Marshal(sim.Telemetry{
+		Seq:        last.Seq,
+		TS:         float64(now.UnixMicro()) / 1e6,
+		BatteryPct: battery,
+		AltitudeM:  last.AltitudeM + 50 + 50*t.rng.Float64(),
+	})
+	return mqttclient.Message{Topic: t.topic + "/telemetry", Payload: body}
+}
@@ -0,0 +1,135 @@
+// Archivo: tools/drone-observe/internal/sim/drone.go
+// Rol: modelo de un dron virtual: telemetria y eventos segun EVENTS.md, con fallas inyectables.
+// No hace: conexiones MQTT ni tiempos reales; Tick recibe el instante y devuelve que publicar.
+package sim
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"drone-observe/internal/mqttclient"
+)
+
+// Ciclo de bateria del edge (edge/mavlink_to_mqtt/main.py): 100 - (seq % 120), BATTERY_LOW en 25.
+const (
+	batteryCycle = 120
+	batteryLow   = 25
+)
+
+// Telemetry es el payload de <topic>/telemetry (EVENTS.md 6.1), QoS 0.
+type Telemetry struct {
+	Seq        int     `json:"seq"`
+	TS         float64 `json:"ts"`
+	BatteryPct int     `json:"battery_pct"`
+	AltitudeM  float64 `json:"altitude_m"`
+}
+
+// Event es el payload de <topic>/event (EVENTS.md 6.2), QoS 1.
+type Event struct {
+	TS         float64 `json:"ts"`
+	Type       string  `json:"type"`
+	Severity   string  `json:"severity"`
+	BatteryPct int     `json:"battery_pct"`
+}
+
+// Stats cuenta lo publicado por todos los drones; se lee mientras corren.
+type Stats struct {
+	Telemetry atomic.Int64
+	Events    atomic.Int64
+	Dropouts  atomic.Int64
+	Dropped   atomic.Int64
+	Malformed atomic.Int64
+	SeqResets atomic.Int64
+	Errors    atomic.Int64
+}
+
+// Drone es el estado de un dron virtual; no es seguro para uso concurrente (un goroutine por dron).
+type Drone struct {
+	ID    string
+	Topic string
+	// Skew es el desvio fijo del reloj del dron (falla skew); se suma a ts.
+	Skew time.Duration
+
+	faults      Faults
+	rng         *rand.Rand
+	seq         int
+	offset      int
+	silentUntil time.Time
+}
+
+// NewDrone crea un dron con fase de bateria y skew aleatorios (segun rng) para que la flota no publique en fase.
+func NewDrone(id, topic string, faults Faults, rng *rand.Rand) *Drone {
+	d := &Drone{ID: id, Topic: topic, faults: faults, rng: rng, offset: rng.Intn(batteryCycle)}
+	if faults.Skew > 0 {
+		d.Skew = time.Duration(rng.Int63n(int64(2*faults.Skew)+1)) - faults.Skew
+	}
+	return d
+}
+
+// PARTE CRITICA **********************
+// Sin fallas, cada Tick produce exactamente lo que produce el edge real: telemetria {seq, ts, battery_pct,
+// altitude_m} con QoS 0 y, cuando battery_pct llega a 25, BATTERY_LOW con QoS 1. Cambiar campos o QoS
+// aca hace que la carga simulada deje de validar el backend y los checks contra el contrato.
+// Las fallas se aplican sobre ese flujo, nunca en lugar de el: dropout calla al dron (seq sigue avanzando,
+// como un enlace caido), malformed corta el JSON, seq-reset reinicia seq como un reboot del edge.
+// FIN DE PARTE CRITICA ****************
+func (d *Drone) Tick(now time.Time, stats *Stats) []mqttclient.Message {
+	if d.faults.SeqReset > 0 && d.seq > 0 && d.rng.Float64() < d.faults.SeqReset {
+		d.seq = 0
+		stats.SeqResets.Add(1)
+	}
+	d.seq++
+	battery := 100 - (d.seq+d.offset)%batteryCycle
+	altitude := 10 + 2*d.rng.Float64()
+
+	if now.Before(d.silentUntil) {
+		stats.Dropped.Add(1)
+		return nil
+	}
+	if d.faults.Dropout > 0 && d.rng.Float64() < d.faults.Dropout {
+		d.silentUntil = now.Add(d.faults.DropoutFor)
+		stats.Dropouts.Add(1)
+		stats.Dropped.Add(1)
+		return nil
+	}
+
+	ts := float64(now.Add(d.Skew).UnixMicro()) / 1e6
+	out := []mqttclient.Message{d.message("telemetry", Telemetry{Seq: d.seq, TS: ts, BatteryPct: battery, AltitudeM: altitude}, 0, stats)}
+	stats.Telemetry.Add(1)
+	if battery == batteryLow {
+		out = append(out, d.message("event", Event{TS: ts, Type: "BATTERY_LOW", Severity: "warning", BatteryPct: battery}, 1, stats))
+		stats.Events.Add(1)
+	}
+	return out
+}
+
+func (d *Drone) message(kind string, payload any, qos byte, stats *Stats) mqttclient.Message {
+	body, _ := json.Marshal(payload)
+	if d.faults.Malformed > 0 && d.rng.Float64() < d.faults.Malformed {
+		body = body[:len(body)/2]
+		stats.Malformed.Add(1)
+	}
+	return mqttclient.Message{Topic: d.Topic + "/" + kind, Payload: body, QoS: qos}
+}
+
+// TopicFor expande {id} en la plantilla de topic; sin {id} todos los drones comparten el topic.
+func TopicFor(template, id string) string {
+	return strings.Trim(strings.ReplaceAll(template, "{id}", id), "/")
+}
+
+// IDs genera n ids con prefijo y ancho fijo (sim01..sim20).
+func IDs(prefix string, n int) []string {
+	width := len(fmt.Sprint(n))
+	if width < 2 {
+		width = 2
+	}
+	ids := make([]string, n)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("%s%0*d", prefix, width, i+1)
+	}
+	return ids
+}
@@ -0,0 +1,198 @@
+// Archivo: tools/drone-observe/internal/sim/sim.go
+// Rol: correr N drones virtuales contra un broker, cada uno con su conexion, cadencia y jitter.
+// No hace: medir al backend; la carga se observa con limits, targets y fleet.
+package sim
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const defaultDropoutFor = 10 * time.Second
+
+// Faults son las fallas inyectables; probabilidades por mensaje en [0,1].
+type Faults struct {
+	// Dropout es la probabilidad de que el dron se calle DropoutFor (enlace caido).
+	Dropout    float64
+	DropoutFor time.Duration
+	// Malformed es la probabilidad de publicar el JSON cortado.
+	Malformed float64
+	// Skew es el desvio maximo del reloj; cada dron toma un desvio fijo en [-Skew, +Skew].
+	Skew time.Duration
+	// SeqReset es la probabilidad de reiniciar seq (reboot del edge).
+	SeqReset float64
+}
+
+// ParseFaults lee "dropout=0.01,dropout-for=20s,malformed=0.02,skew=30s,seq-reset=0.001".
+func ParseFaults(s string) (Faults, error) {
+	f := Faults{DropoutFor: defaultDropoutFor}
+	if strings.TrimSpace(s) == "" {
+		return f, nil
+	}
+	for _, part := range strings.Split(s, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			return f, fmt.Errorf("falla %q: esperado <nombre>=<valor>", part)
+		}
+		var err error
+		switch key {
+		case "dropout":
+			f.Dropout, err = parseProbability(value)
+		case "malformed":
+			f.Malformed, err = parseProbability(value)
+		case "seq-reset":
+			f.SeqReset, err = parseProbability(value)
+		case "dropout-for":
+			f.DropoutFor, err = parsePositiveDuration(value)
+		case "skew":
+			f.Skew, err = parsePositiveDuration(value)
+		default:
+			return f, fmt.Errorf("falla desconocida %q (validas: dropout, dropout-for, malformed, skew, seq-reset)", key)
+		}
+		if err != nil {
+			return f, fmt.Errorf("falla %s: %w", key, err)
+		}
+	}
+	return f, nil
+}
+
+// String resume las fallas activas; vacio si no hay.
+func (f Faults) String() string {
+	var parts []string
+	if f.Dropout > 0 {
+		parts = append(parts, fmt.Sprintf("dropout=%g (%s)", f.Dropout, f.DropoutFor))
+	}
+	if f.Malformed > 0 {
+		parts = append(parts, fmt.Sprintf("malformed=%g", f.Malformed))
+	}
+	if f.Skew > 0 {
+		parts = append(parts, fmt.Sprintf("skew=+-%s", f.Skew))
+	}
+	if f.SeqReset > 0 {
+		parts = append(parts, fmt.Sprintf("seq-reset=%g", f.SeqReset))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Publisher publica en el broker; *mqttclient.Client lo implementa.
+type Publisher interface {
+	Publish(topic string, payload []byte, qos byte, retain bool) error
+	Close() error
+}
+
+// Dialer abre la conexion de un dron.
+type Dialer func(clientID string) (Publisher, error)
+
+// Config define la flota.
+type Config struct {
+	IDs []string
+	// Topic es la plantilla de topic base con {id}; se publica en <topic>/telemetry y <topic>/event.
+	Topic string
+	// Rate es la telemetria por segundo de cada dron (el edge usa 1).
+	Rate float64
+	// Jitter es la variacion del periodo como fraccion (0.2 = +-20%).
+	Jitter float64
+	Faults Faults
+	Seed   int64
+}
+
+// DroneError es la caida de un dron (conexion o publish); el resto de la flota sigue.
+type DroneError struct {
+	ID  string
+	Err error
+}
+
+func (e DroneError) Error() string { return fmt.Sprintf("%s: %v", e.ID, e.Err) }
+
+// PARTE CRITICA **********************
+// Un goroutine y una conexion por dron: el broker ve N clientes como en el campo, no un cliente con N veces
+// el trafico. Cada dron arranca con una fase aleatoria dentro del periodo para que la flota no publique en
+// rafagas sincronizadas. Todo lo aleatorio sale de Seed: dos corridas con la misma semilla generan la misma
+// secuencia de payloads y fallas (los instantes dependen del reloj).
+// Un dron que pierde la conexion se detiene y se reporta; no reconecta, igual que el edge actual.
+// FIN DE PARTE CRITICA ****************
+func Run(ctx context.Context, cfg Config, dial Dialer, stats *Stats) []DroneError {
+	period := time.Duration(float64(time.Second) / cfg.Rate)
+	seeds := rand.New(rand.NewSource(cfg.Seed))
+
+	var mu sync.Mutex
+	var failures []DroneError
+	var wg sync.WaitGroup
+	for _, id := range cfg.IDs {
+		rng := rand.New(rand.NewSource(seeds.Int63()))
+		d := NewDrone(id, TopicFor(cfg.Topic, id), cfg.Faults, rng)
+		phase := time.Duration(rng.Int63n(int64(period)))
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := fly(ctx, d, dial, period, phase, cfg.Jitter, stats); err != nil {
+				stats.Errors.Add(1)
+				mu.Lock()
+				failures = append(failures, DroneError{ID: d.ID, Err: err})
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	sort.Slice(failures, func(i, j int) bool { return failures[i].ID < failures[j].ID })
+	return failures
+}
+
+func fly(ctx context.Context, d *Drone, dial Dialer, period, phase time.Duration, jitter float64, stats *Stats) error {
+	if !sleep(ctx, phase) {
+		return nil
+	}
+	client, err := dial("drone-observe-sim-" + d.ID)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	for {
+		for _, msg := range d.Tick(time.Now(), stats) {
+			if err := client.Publish(msg.Topic, msg.Payload, msg.QoS, false); err != nil {
+				return err
+			}
+		}
+		wait := period
+		if jitter > 0 {
+			wait = time.Duration(float64(period) * (1 + jitter*(2*d.rng.Float64()-1)))
+		}
+		if !sleep(ctx, wait) {
+			return nil
+		}
+	}
+}
+
+// sleep espera d o hasta ctx; devuelve false si ctx termino.
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func parseProbability(v string) (float64, error) {
+	p, err := strconv.ParseFloat(v, 64)
+	if err != nil || p < 0 || p > 1 {
+		return 0, fmt.Errorf("probabilidad invalida %q (0..1)", v)
+	}
+	return p, nil
+}
+
+func parsePositiveDuration(v string) (time.Duration, error) {
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("duracion invalida %q", v)
+	}
+	return d, nil
+}
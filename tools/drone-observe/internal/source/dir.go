@@ -0,0 +1,260 @@
+// Archivo: tools/drone-observe/internal/source/dir.go
+// Rol: origen de datos leido de un bundle extraido (layout de drone-observe bundle).
+// No hace: evaluar PromQL; solo responde las queries que el bundle archivo, tal como las respondio Prometheus.
+package source
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"drone-observe/internal/config"
+	"drone-observe/internal/grafana"
+	"drone-observe/internal/prometheus"
+)
+
+// FormatVersion identifica el layout del bundle; cambia si se mueven o renombran archivos.
+const FormatVersion = 1
+
+// Layout del bundle; bundle escribe y Dir lee las mismas rutas.
+const (
+	ManifestFile       = "manifest.json"
+	SumsFile           = "SHA256SUMS"
+	ConfigFile         = "config.json"
+	BackendMetricsFile = "backend/metrics.txt"
+	QueriesFile        = "prometheus/queries.json"
+	RulesFile          = "prometheus/rules.json"
+	GrafanaSearchFile  = "grafana/search.json"
+	GrafanaDashboards  = "grafana/dashboards"
+)
+
+// QueryResult es la respuesta cruda de una consulta instantanea archivada en prometheus/queries.json.
+type QueryResult struct {
+	Expr     string          `json:"expr"`
+	Response json.RawMessage `json:"response,omitempty"`
+	Error    string          `json:"error,omitempty"`
+}
+
+// QueryFile es el contenido de prometheus/queries.json: todas las queries evaluadas en un mismo instante.
+type QueryFile struct {
+	Time    time.Time     `json:"time"`
+	Results []QueryResult `json:"results"`
+}
+
+// Dir lee un bundle extraido.
+type Dir struct {
+	Path string
+}
+
+// Open ubica el bundle en path (el directorio extraido o el directorio donde se extrajo) y verifica el formato.
+func Open(path string) (Dir, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return Dir{}, err
+	}
+	if !info.IsDir() {
+		return Dir{}, fmt.Errorf("%s no es un directorio (extraer antes: tar xzf %s)", path, path)
+	}
+	d := Dir{Path: path}
+	if _, err := os.Stat(filepath.Join(path, ManifestFile)); errors.Is(err, os.ErrNotExist) {
+		// tar xzf deja el bundle en un subdirectorio con el nombre del archivo.
+		matches, _ := filepath.Glob(filepath.Join(path, "*", ManifestFile))
+		if len(matches) != 1 {
+			return Dir{}, fmt.Errorf("%s: sin %s (no es un bundle de drone-observe)", path, ManifestFile)
+		}
+		d.Path = filepath.Dir(matches[0])
+	}
+	var manifest struct {
+		Tool   string `json:"tool"`
+		Format int    `json:"format"`
+	}
+	if err := d.readJSON(ManifestFile, &manifest); err != nil {
+		return Dir{}, err
+	}
+	if manifest.Tool != "drone-observe" || manifest.Format != FormatVersion {
+		return Dir{}, fmt.Errorf("%s: formato %s/%d no soportado (esperado drone-observe/%d)", d.Path, manifest.Tool, manifest.Format, FormatVersion)
+	}
+	return d, nil
+}
+
+// Verify recalcula los sha256 de SHA256SUMS y devuelve los archivos que no coinciden o faltan.
+func (d Dir) Verify() ([]string, error) {
+	data, err := d.read(SumsFile)
+	if err != nil {
+		return nil, err
+	}
+	var bad []string
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		want, name, ok := strings.Cut(line, "  ")
+		if !ok {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(d.Path, filepath.FromSlash(name)))
+		if err != nil {
+			bad = append(bad, name+" (falta)")
+			continue
+		}
+		if sum := sha256.Sum256(content); hex.EncodeToString(sum[:]) != want {
+			bad = append(bad, name)
+		}
+	}
+	return bad, nil
+}
+
+// Apply devuelve cfg leyendo del bundle: From, At (instante de las queries) y los endpoints capturados,
+// para que los checks que comparan endpoints contra compose juzguen la config del campo.
+func (d Dir) Apply(cfg config.Config) (config.Config, error) {
+	at, err := d.QueryTime()
+	if err != nil {
+		return cfg, err
+	}
+	var captured config.Config
+	if err := d.readJSON(ConfigFile, &captured); err != nil {
+		return cfg, err
+	}
+	cfg.From, cfg.At = d.Path, at
+	cfg.MQTTHost, cfg.MQTTPort, cfg.MQTTBaseTopic = captured.MQTTHost, captured.MQTTPort, captured.MQTTBaseTopic
+	cfg.BackendMetricsURL, cfg.PrometheusURL = captured.BackendMetricsURL, captured.PrometheusURL
+	cfg.GrafanaURL, cfg.AlertmanagerURL = captured.GrafanaURL, captured.AlertmanagerURL
+	return cfg, nil
+}
+
+// Query devuelve la respuesta archivada de expr; el bundle tiene un unico instante y at se ignora.
+func (d Dir) Query(_ context.Context, expr string, _ time.Time) (prometheus.Vector, error) {
+	var file QueryFile
+	if err := d.readJSON(QueriesFile, &file); err != nil {
+		return nil, err
+	}
+	for _, r := range file.Results {
+		if r.Expr != expr {
+			continue
+		}
+		if r.Error != "" {
+			return nil, fmt.Errorf("al capturar: %s", r.Error)
+		}
+		return prometheus.DecodeQuery(r.Response)
+	}
+	return nil, fmt.Errorf("query no archivada en el bundle: %s", expr)
+}
+
+func (d Dir) Rules(_ context.Context) ([]prometheus.RuleGroup, error) {
+	data, err := d.readOK(RulesFile)
+	if err != nil {
+		return nil, err
+	}
+	return prometheus.DecodeRules(data)
+}
+
+func (d Dir) Metrics(_ context.Context) (string, error) {
+	data, err := d.readOK(BackendMetricsFile)
+	return string(data), err
+}
+
+func (d Dir) Search(_ context.Context) ([]grafana.SearchHit, error) {
+	var hits []grafana.SearchHit
+	err := d.readJSON(GrafanaSearchFile, &hits)
+	return hits, err
+}
+
+func (d Dir) Dashboard(_ context.Context, uid string) (map[string]any, error) {
+	name, err := DashboardFile(uid)
+	if err != nil {
+		return nil, err
+	}
+	var model map[string]any
+	err = d.readJSON(name, &model)
+	return model, err
+}
+
+// DashboardFile es la ruta del dashboard uid dentro del bundle. El uid sale de grafana/search.json (o de
+// Grafana al armar el bundle): uno con separadores o ".." leeria o escribiria fuera del bundle.
+func DashboardFile(uid string) (string, error) {
+	if uid == "" || uid == "." || strings.Contains(uid, "..") || strings.ContainsAny(uid, `/\`) {
+		return "", fmt.Errorf("uid de dashboard invalido %q", uid)
+	}
+	return filepath.Join(GrafanaDashboards, uid+".json"), nil
+}
+
+// QueryTime devuelve el instante en que se evaluaron las queries archivadas.
+func (d Dir) QueryTime() (time.Time, error) {
+	var file QueryFile
+	if err := d.readJSON(QueriesFile, &file); err != nil {
+		return time.Time{}, err
+	}
+	if file.Time.IsZero() {
+		return time.Time{}, fmt.Errorf("%s sin time", QueriesFile)
+	}
+	return file.Time, nil
+}
+
+func (d Dir) readJSON(name string, out any) error {
+	data, err := d.read(name)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return nil
+}
+
+// PARTE CRITICA **********************
+// Un archivo ausente se explica con el manifest: si el bundle registro un error al recolectarlo, ese
+// error es el dato (p. ej. "connection refused" en el campo) y se reporta en lugar de "no existe".
+// Un archivo presente con error (el cuerpo de un 500) es evidencia, pero en vivo el check habria visto
+// el error: readOK lo devuelve como error para que el veredicto offline sea el mismo.
+// FIN DE PARTE CRITICA ****************
+func (d Dir) readOK(name string) ([]byte, error) {
+	data, err := d.read(name)
+	if err != nil {
+		return nil, err
+	}
+	if cause := d.collectError(name); cause != "" {
+		return nil, fmt.Errorf("al capturar: %s", cause)
+	}
+	return data, nil
+}
+
+func (d Dir) read(name string) ([]byte, error) {
+	data, err := os.ReadFile(filepath.Join(d.Path, filepath.FromSlash(name)))
+	if err == nil {
+		return data, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if cause := d.collectError(name); cause != "" {
+		return nil, fmt.Errorf("no capturado en el bundle: %s", cause)
+	}
+	return nil, fmt.Errorf("%s no esta en el bundle", name)
+}
+
+func (d Dir) collectError(name string) string {
+	name = filepath.ToSlash(name)
+	data, err := os.ReadFile(filepath.Join(d.Path, ManifestFile))
+	if err != nil {
+		return ""
+	}
+	var manifest struct {
+		Entries []struct {
+			Path  string `json:"path"`
+			Error string `json:"error"`
+		} `json:"entries"`
+	}
+	if json.Unmarshal(data, &manifest) != nil {
+		return ""
+	}
+	for _, e := range manifest.Entries {
+		if e.Path == name {
+			return e.Error
+		}
+	}
+	return ""
+}
@@ -0,0 +1,36 @@
+package source
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDirDashboardUID(t *testing.T) {
+	root := t.TempDir()
+	bundle := filepath.Join(root, "bundle")
+	if err := os.MkdirAll(filepath.Join(bundle, GrafanaDashboards), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		filepath.Join(bundle, GrafanaDashboards, "drones.json"): `{"uid":"drones"}`,
+		// Fuera de grafana/dashboards y fuera del bundle: un search.json armado no debe alcanzarlos.
+		filepath.Join(bundle, "config.json"): `{"secreto":true}`,
+		filepath.Join(root, "x.json"):        `{"secreto":true}`,
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	d := Dir{Path: bundle}
+	if model, err := d.Dashboard(context.Background(), "drones"); err != nil || model["uid"] != "drones" {
+		t.Fatalf("Dashboard(drones) = %v, %v", model, err)
+	}
+	for _, uid := range []string{"../../x", "../config", `..\config`, "a/b", "..", ""} {
+		if model, err := d.Dashboard(context.Background(), uid); err == nil {
+			t.Errorf("Dashboard(%q) = %v; esperado error", uid, model)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+// Archivo: tools/drone-observe/internal/source/source.go
+// Rol: origenes de datos de los checks (Prometheus, /metrics del backend, Grafana) en vivo o desde un bundle.
+// No hace: decidir que consulta cada check; los checks piden lo mismo a cualquiera de los dos origenes.
+package source
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"drone-observe/internal/config"
+	"drone-observe/internal/grafana"
+	"drone-observe/internal/prometheus"
+)
+
+const httpTimeout = 3 * time.Second
+
+// Prometheus responde consultas instantaneas y reglas cargadas.
+type Prometheus interface {
+	Query(ctx context.Context, expr string, at time.Time) (prometheus.Vector, error)
+	Rules(ctx context.Context) ([]prometheus.RuleGroup, error)
+}
+
+// Backend devuelve el texto de /metrics del backend.
+type Backend interface {
+	Metrics(ctx context.Context) (string, error)
+}
+
+// Grafana devuelve los dashboards tal como los sirve Grafana; *grafana.Client la implementa.
+type Grafana interface {
+	Search(ctx context.Context) ([]grafana.SearchHit, error)
+	Dashboard(ctx context.Context, uid string) (map[string]any, error)
+}
+
+// Set agrupa los origenes de una ejecucion.
+type Set struct {
+	Prometheus Prometheus
+	Backend    Backend
+	Grafana    Grafana
+}
+
+// PARTE CRITICA **********************
+// El origen sale de la config (--from), no de cada check: un check no sabe si lee en vivo o un bundle,
+// asi el veredicto offline es el mismo codigo que el veredicto en vivo. Lo que un bundle no guarda
+// (p. ej. health de datasources) lo omite cada check preguntando cfg.Offline().
+// FIN DE PARTE CRITICA ****************
+func For(cfg config.Config) Set {
+	if cfg.Offline() {
+		d := Dir{Path: cfg.From}
+		return Set{Prometheus: d, Backend: d, Grafana: d}
+	}
+	return Set{
+		Prometheus: livePrometheus{baseURL: cfg.PrometheusURL},
+		Backend:    liveBackend{url: cfg.BackendMetricsURL},
+		Grafana:    grafana.NewClient(cfg.GrafanaURL, cfg.GrafanaUser, cfg.GrafanaPassword),
+	}
+}
+
+type livePrometheus struct {
+	baseURL string
+}
+
+func (p livePrometheus) Query(ctx context.Context, expr string, at time.Time) (prometheus.Vector, error) {
+	return prometheus.QueryVectorAt(ctx, p.baseURL, expr, at)
+}
+
+func (p livePrometheus) Rules(ctx context.Context) ([]prometheus.RuleGroup, error) {
+	return prometheus.Rules(ctx, p.baseURL)
+}
+
+type liveBackend struct {
+	url string
+}
+
+func (b liveBackend) Metrics(ctx context.Context) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, b.url, nil)
+	if err != nil {
+		return "", err
+	}
+	client := &http.Client{Timeout: httpTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", fmt.Errorf("http status %s", resp.Status)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	return string(body), nil
+}
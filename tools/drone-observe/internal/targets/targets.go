@@ -0,0 +1,159 @@
+// Archivo: tools/drone-observe/internal/targets/targets.go
+// Rol: salud de scrape por job (/api/v1/targets, metadata y scrape_samples_scraped) vs prometheus.yml versionado.
+// No hace: reiniciar targets ni editar prometheus.yml; solo reporta lo que Prometheus ve.
+package targets
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"drone-observe/internal/config"
+	"drone-observe/internal/prometheus"
+	"drone-observe/internal/promql"
+	"drone-observe/internal/queries"
+	"drone-observe/internal/repo"
+)
+
+// Target es el estado de scrape de una instancia.
+type Target struct {
+	Job        string
+	Instance   string
+	Health     string
+	LastError  string
+	LastScrape time.Time
+	Duration   time.Duration
+	// Interval es el scrape_interval efectivo segun Prometheus; Configured el de prometheus.yml versionado.
+	Interval   time.Duration
+	Configured time.Duration
+	Samples    float64
+	HasSamples bool
+}
+
+// Up indica si el ultimo scrape fue exitoso.
+func (t Target) Up() bool { return t.Health == "up" }
+
+// Stale indica si el ultimo scrape es mas viejo que dos intervalos (scrape atrasado o colgado).
+func (t Target) Stale(now time.Time) bool {
+	return t.Interval > 0 && !t.LastScrape.IsZero() && now.Sub(t.LastScrape) > 2*t.Interval
+}
+
+type Job struct {
+	Name    string
+	Targets []Target
+	// Metadata es la cantidad de metricas con metadata (TYPE/HELP) expuestas por los targets del job.
+	Metadata    int
+	MetadataErr string
+	// Versioned indica que el job esta declarado en prometheus.yml versionado.
+	Versioned bool
+}
+
+// Up devuelve cuantos targets del job estan up y el total.
+func (j Job) Up() (up, total int) {
+	for _, t := range j.Targets {
+		if t.Up() {
+			up++
+		}
+	}
+	return up, len(j.Targets)
+}
+
+type Report struct {
+	Jobs     []Job
+	Warnings []string
+}
+
+// PARTE CRITICA **********************
+// Solo se reporta lo observable en la API de Prometheus; prometheus.yml versionado se usa como referencia
+// del intervalo esperado y de los jobs que deberian existir. Si Prometheus corre otra config, se avisa
+// en lugar de "corregir" el valor: la diferencia es justamente el hallazgo.
+// FIN DE PARTE CRITICA ****************
+func Check(cfg config.Config) (Report, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 8*time.Second)
+	defer cancel()
+
+	active, err := prometheus.Targets(ctx, cfg.PrometheusURL)
+	if err != nil {
+		return Report{}, fmt.Errorf("prometheus /api/v1/targets: %w", err)
+	}
+
+	var report Report
+	versioned := map[string]prometheus.ScrapeConfig{}
+	var pc prometheus.Config
+	if path, err := repo.Resolve(cfg.PrometheusConfig); err != nil {
+		report.Warnings = append(report.Warnings, err.Error()+" (sin intervalo configurado de referencia)")
+	} else if pc, err = prometheus.LoadConfig(path); err != nil {
+		report.Warnings = append(report.Warnings, err.Error())
+	} else {
+		for _, sc := range pc.ScrapeConfigs {
+			versioned[sc.JobName] = sc
+		}
+	}
+
+	samples := map[string]float64{}
+	if vec, err := prometheus.QueryVector(ctx, cfg.PrometheusURL, queries.ScrapeSamples.Expr); err != nil {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("%s: %v", queries.ScrapeSamples.Expr, err))
+	} else {
+		for _, s := range vec {
+			samples[s.Labels["job"]+"\x00"+s.Labels["instance"]] = s.Value
+		}
+	}
+
+	jobs := map[string]*Job{}
+	for _, at := range active {
+		name := at.Job()
+		j, ok := jobs[name]
+		if !ok {
+			_, inFile := versioned[name]
+			j = &Job{Name: name, Versioned: inFile}
+			jobs[name] = j
+		}
+		t := Target{
+			Job:        name,
+			Instance:   at.Instance(),
+			Health:     at.Health,
+			LastError:  at.LastError,
+			LastScrape: at.LastScrape,
+			Duration:   time.Duration(at.LastScrapeDuration * float64(time.Second)),
+		}
+		if d, err := promql.ParseDuration(at.ScrapeInterval); err == nil {
+			t.Interval = d
+		}
+		if len(versioned) > 0 {
+			if d, err := pc.ScrapeInterval(name); err == nil {
+				t.Configured = d
+			}
+		}
+		t.Samples, t.HasSamples = samples[name+"\x00"+t.Instance]
+		j.Targets = append(j.Targets, t)
+	}
+	for name := range versioned {
+		if _, ok := jobs[name]; !ok {
+			jobs[name] = &Job{Name: name, Versioned: true}
+			report.Warnings = append(report.Warnings, fmt.Sprintf("job %s declarado en prometheus.yml sin targets activos (config cargada distinta de la versionada?)", name))
+		}
+	}
+
+	for _, j := range jobs {
+		if len(j.Targets) > 0 {
+			if md, err := prometheus.TargetMetadata(ctx, cfg.PrometheusURL, j.Name); err != nil {
+				j.MetadataErr = err.Error()
+			} else {
+				metrics := map[string]struct{}{}
+				for _, m := range md {
+					metrics[m.Metric] = struct{}{}
+				}
+				j.Metadata = len(metrics)
+			}
+		}
+		sort.Slice(j.Targets, func(a, b int) bool { return j.Targets[a].Instance < j.Targets[b].Instance })
+		report.Jobs = append(report.Jobs, *j)
+		if !j.Versioned && len(versioned) > 0 {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("job %s scrapeado por Prometheus pero no declarado en prometheus.yml versionado", j.Name))
+		}
+	}
+	sort.Slice(report.Jobs, func(a, b int) bool { return report.Jobs[a].Name < report.Jobs[b].Name })
+	sort.Strings(report.Warnings)
+	return report, nil
+}
@@ -0,0 +1,60 @@
+// Archivo: tools/drone-observe/internal/testenv/backend.go
+// Rol: backend falso en proceso para tests: /metrics con texto de exposicion guionado.
+// No hace: consumir MQTT ni contar mensajes; el texto es el que el test fija.
+package testenv
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// Backend sirve /metrics del backend Rust con un texto fijo.
+type Backend struct {
+	// URL es la de /metrics, como BACKEND_METRICS_URL.
+	URL string
+
+	mu      sync.Mutex
+	metrics string
+	status  int
+}
+
+// NewBackend arranca un backend falso que responde 200 con metrics; se cierra al terminar el test.
+func NewBackend(t testing.TB, metrics string) *Backend {
+	t.Helper()
+	b := &Backend{metrics: metrics, status: http.StatusOK}
+	srv := httptest.NewServer(http.HandlerFunc(b.serve))
+	t.Cleanup(srv.Close)
+	b.URL = srv.URL + "/metrics"
+	return b
+}
+
+// SetMetrics reemplaza el texto de exposicion.
+func (b *Backend) SetMetrics(metrics string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.metrics = metrics
+}
+
+// SetStatus fija el codigo HTTP de /metrics (p. ej. 500 para un backend roto).
+func (b *Backend) SetStatus(code int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.status = code
+}
+
+func (b *Backend) serve(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/metrics" {
+		http.NotFound(w, r)
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.status != http.StatusOK {
+		http.Error(w, http.StatusText(b.status), b.status)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	_, _ = w.Write([]byte(b.metrics))
+}
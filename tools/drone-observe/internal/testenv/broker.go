@@ -0,0 +1,374 @@
+// Archivo: tools/drone-observe/internal/testenv/broker.go
+// Rol: broker MQTT 3.1.1 minimo embebido para tests (connect, subscribe, publish QoS 0/1, retain, ping).
+// No hace: QoS 2, sesiones persistentes, wills ni ACLs por topic de publish; solo lo que usa el CLI.
+package testenv
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"net"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"drone-observe/internal/mqttclient"
+)
+
+// Broker es un broker en 127.0.0.1 con puerto libre; guarda todo lo publicado para que el test lo lea.
+type Broker struct {
+	Host string
+	Port int
+
+	ln        net.Listener
+	mu        sync.Mutex
+	cond      *sync.Cond
+	conns     map[*brokerConn]struct{}
+	published []mqttclient.Message
+	retained  map[string]mqttclient.Message
+	users     map[string]string
+	deny      []string
+	closed    bool
+}
+
+type brokerConn struct {
+	conn    net.Conn
+	writeMu sync.Mutex
+	filters map[string]byte
+	nextID  uint16
+}
+
+// NewBroker arranca el broker; se cierra (junto con sus conexiones) al terminar el test.
+func NewBroker(t testing.TB) *Broker {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("testenv: broker: %v", err)
+	}
+	b := &Broker{ln: ln, conns: map[*brokerConn]struct{}{}, retained: map[string]mqttclient.Message{}}
+	b.cond = sync.NewCond(&b.mu)
+	addr := ln.Addr().(*net.TCPAddr)
+	b.Host, b.Port = addr.IP.String(), addr.Port
+	go b.accept()
+	t.Cleanup(b.Close)
+	return b
+}
+
+// Addr devuelve host:port, como --broker.
+func (b *Broker) Addr() string { return net.JoinHostPort(b.Host, strconv.Itoa(b.Port)) }
+
+// SetUsers exige usuario y password (CONNACK 4 si no coinciden, 5 si no hay credenciales); nil acepta todo.
+func (b *Broker) SetUsers(users map[string]string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.users = users
+}
+
+// DenySubscribe rechaza con SUBACK 0x80 las suscripciones que caen bajo filter.
+func (b *Broker) DenySubscribe(filter string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.deny = append(b.deny, filter)
+}
+
+// Published devuelve una copia de lo publicado por los clientes, en orden de llegada.
+func (b *Broker) Published() []mqttclient.Message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]mqttclient.Message(nil), b.published...)
+}
+
+// WaitPublished espera hasta tener n mensajes publicados o timeout; devuelve lo que haya.
+func (b *Broker) WaitPublished(n int, timeout time.Duration) []mqttclient.Message {
+	timer := time.AfterFunc(timeout, func() {
+		b.mu.Lock()
+		b.cond.Broadcast()
+		b.mu.Unlock()
+	})
+	defer timer.Stop()
+	deadline := time.Now().Add(timeout)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for len(b.published) < n && !b.closed && time.Now().Before(deadline) {
+		b.cond.Wait()
+	}
+	return append([]mqttclient.Message(nil), b.published...)
+}
+
+// Close cierra el listener y corta a todos los clientes.
+func (b *Broker) Close() {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return
+	}
+	b.closed = true
+	conns := make([]*brokerConn, 0, len(b.conns))
+	for c := range b.conns {
+		conns = append(conns, c)
+	}
+	b.cond.Broadcast()
+	b.mu.Unlock()
+	_ = b.ln.Close()
+	for _, c := range conns {
+		_ = c.conn.Close()
+	}
+}
+
+func (b *Broker) accept() {
+	for {
+		conn, err := b.ln.Accept()
+		if err != nil {
+			return
+		}
+		c := &brokerConn{conn: conn, filters: map[string]byte{}}
+		b.mu.Lock()
+		if b.closed {
+			b.mu.Unlock()
+			_ = conn.Close()
+			return
+		}
+		b.conns[c] = struct{}{}
+		b.mu.Unlock()
+		go b.serve(c)
+	}
+}
+
+// PARTE CRITICA **********************
+// El broker habla el mismo framing que mqttclient (ReadPacket/WritePacket) pero implementa el lado servidor:
+// un cliente que no manda CONNECT primero se corta, como mosquitto. La entrega respeta el QoS minimo entre
+// publish y suscripcion; los PUBACK de los clientes se ignoran (no hay reintentos). Lo publicado se guarda
+// antes de entregar para que WaitPublished no dependa de que haya suscriptores.
+// FIN DE PARTE CRITICA ****************
+func (b *Broker) serve(c *brokerConn) {
+	defer func() {
+		b.mu.Lock()
+		delete(b.conns, c)
+		b.mu.Unlock()
+		_ = c.conn.Close()
+	}()
+	r := bufio.NewReader(c.conn)
+	first, err := mqttclient.ReadPacket(r)
+	if err != nil || first.Type != mqttclient.TypeConnect {
+		return
+	}
+	code := b.authorize(first)
+	if err := c.write(mqttclient.Packet{Type: mqttclient.TypeConnack, Body: []byte{0, code}}); err != nil || code != 0 {
+		return
+	}
+	for {
+		p, err := mqttclient.ReadPacket(r)
+		if err != nil {
+			return
+		}
+		switch p.Type {
+		case mqttclient.TypePublish:
+			msg, id, err := mqttclient.ParsePublish(p)
+			if err != nil {
+				return
+			}
+			if msg.QoS == 1 {
+				_ = c.write(mqttclient.Packet{Type: mqttclient.TypePuback, Body: binary.BigEndian.AppendUint16(nil, id)})
+			}
+			b.route(msg)
+		case mqttclient.TypeSubscribe:
+			if err := b.subscribe(c, p); err != nil {
+				return
+			}
+		case mqttclient.TypeUnsubscribe:
+			if len(p.Body) < 2 {
+				return
+			}
+			rest := p.Body[2:]
+			for len(rest) > 0 {
+				var filter string
+				if filter, rest, err = mqttclient.ReadString(rest); err != nil {
+					return
+				}
+				b.mu.Lock()
+				delete(c.filters, filter)
+				b.mu.Unlock()
+			}
+			_ = c.write(mqttclient.Packet{Type: mqttclient.TypeUnsuback, Body: p.Body[:2]})
+		case mqttclient.TypePingreq:
+			_ = c.write(mqttclient.Packet{Type: mqttclient.TypePingresp})
+		case mqttclient.TypeDisconnect:
+			return
+		}
+	}
+}
+
+// authorize devuelve el codigo de CONNACK para el CONNECT recibido.
+func (b *Broker) authorize(p mqttclient.Packet) byte {
+	user, pass, err := connectCredentials(p.Body)
+	if err != nil {
+		return 2
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.users == nil {
+		return 0
+	}
+	if user == "" {
+		return 5
+	}
+	if want, ok := b.users[user]; !ok || want != pass {
+		return 4
+	}
+	return 0
+}
+
+func connectCredentials(body []byte) (user, pass string, err error) {
+	if _, body, err = mqttclient.ReadString(body); err != nil {
+		return "", "", err
+	}
+	if len(body) < 4 {
+		return "", "", errors.New("connect corto")
+	}
+	flags := body[1]
+	body = body[4:]
+	if _, body, err = mqttclient.ReadString(body); err != nil {
+		return "", "", err
+	}
+	if flags&0x04 != 0 {
+		if _, body, err = mqttclient.ReadString(body); err != nil {
+			return "", "", err
+		}
+		if _, body, err = mqttclient.ReadString(body); err != nil {
+			return "", "", err
+		}
+	}
+	if flags&0x80 != 0 {
+		if user, body, err = mqttclient.ReadString(body); err != nil {
+			return "", "", err
+		}
+	}
+	if flags&0x40 != 0 {
+		if pass, _, err = mqttclient.ReadString(body); err != nil {
+			return "", "", err
+		}
+	}
+	return user, pass, nil
+}
+
+func (b *Broker) subscribe(c *brokerConn, p mqttclient.Packet) error {
+	if len(p.Body) < 2 {
+		return errors.New("subscribe corto")
+	}
+	ack := append([]byte(nil), p.Body[:2]...)
+	rest := p.Body[2:]
+	var granted []string
+	for len(rest) > 0 {
+		filter, tail, err := mqttclient.ReadString(rest)
+		if err != nil || len(tail) < 1 {
+			return errors.New("subscribe invalido")
+		}
+		qos := tail[0]
+		rest = tail[1:]
+		if qos > 1 {
+			qos = 1
+		}
+		b.mu.Lock()
+		denied := false
+		for _, d := range b.deny {
+			if d == filter || mqttclient.MatchTopic(d, filter) {
+				denied = true
+			}
+		}
+		if !denied {
+			c.filters[filter] = qos
+			granted = append(granted, filter)
+		}
+		b.mu.Unlock()
+		if denied {
+			ack = append(ack, mqttclient.SubackFailure)
+		} else {
+			ack = append(ack, qos)
+		}
+	}
+	if err := c.write(mqttclient.Packet{Type: mqttclient.TypeSuback, Body: ack}); err != nil {
+		return err
+	}
+	// Retenidos despues del SUBACK, como mosquitto.
+	b.mu.Lock()
+	var pending []mqttclient.Message
+	for _, msg := range b.retained {
+		for _, f := range granted {
+			if mqttclient.MatchTopic(f, msg.Topic) {
+				pending = append(pending, msg)
+				break
+			}
+		}
+	}
+	b.mu.Unlock()
+	for _, msg := range pending {
+		_ = c.deliver(msg, msg.QoS)
+	}
+	return nil
+}
+
+func (b *Broker) route(msg mqttclient.Message) {
+	msg.Received = time.Now()
+	type target struct {
+		c   *brokerConn
+		qos byte
+	}
+	b.mu.Lock()
+	b.published = append(b.published, msg)
+	if msg.Retain {
+		if len(msg.Payload) == 0 {
+			delete(b.retained, msg.Topic)
+		} else {
+			b.retained[msg.Topic] = msg
+		}
+	}
+	var targets []target
+	for c := range b.conns {
+		best, matched := byte(0), false
+		for f, qos := range c.filters {
+			if mqttclient.MatchTopic(f, msg.Topic) {
+				matched = true
+				if qos > best {
+					best = qos
+				}
+			}
+		}
+		if matched {
+			targets = append(targets, target{c, best})
+		}
+	}
+	b.cond.Broadcast()
+	b.mu.Unlock()
+	for _, t := range targets {
+		qos := msg.QoS
+		if t.qos < qos {
+			qos = t.qos
+		}
+		// Un mensaje entregado en vivo no lleva retain (MQTT 3.1.1, 3.3.1.3).
+		live := msg
+		live.Retain = false
+		_ = t.c.deliver(live, qos)
+	}
+}
+
+func (c *brokerConn) deliver(msg mqttclient.Message, qos byte) error {
+	var id uint16
+	if qos == 1 {
+		c.writeMu.Lock()
+		c.nextID++
+		if c.nextID == 0 {
+			c.nextID = 1
+		}
+		id = c.nextID
+		c.writeMu.Unlock()
+	}
+	return c.write(mqttclient.PublishPacket(msg.Topic, msg.Payload, qos, msg.Retain, id))
+}
+
+func (c *brokerConn) write(p mqttclient.Packet) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	_ = c.conn.SetWriteDeadline(time.Now().Add(3 * time.Second))
+	return mqttclient.WritePacket(c.conn, p)
+}
@@ -0,0 +1,54 @@
+// Archivo: tools/drone-observe/internal/testenv/golden.go
+// Rol: comparar salidas de texto (View() de los modelos, reportes) contra archivos golden en testdata/.
+// No hace: normalizar contenido variable (puertos, duraciones); el test guiona datos deterministas.
+package testenv
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "reescribe los archivos golden de testdata/ con la salida actual")
+
+var ansi = regexp.MustCompile(`\x1b\[[0-9;?]*[A-Za-z]`)
+
+// StripANSI quita los escapes de color y cursor; lipgloss los emite o no segun la terminal de quien corre.
+func StripANSI(s string) string {
+	return ansi.ReplaceAllString(s, "")
+}
+
+// Golden compara got (sin ANSI y sin espacios al final de linea) con testdata/<name>.golden.
+// Con go test -update reescribe el archivo en lugar de comparar.
+func Golden(t testing.TB, name, got string) {
+	t.Helper()
+	got = normalize(got)
+	path := filepath.Join("testdata", name+".golden")
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%s: %v (generar con go test -run %s -update)", path, err, t.Name())
+	}
+	if got != string(want) {
+		t.Errorf("%s no coincide (regenerar con -update si el cambio es intencional)\n--- obtenido\n%s\n--- esperado\n%s", path, got, want)
+	}
+}
+
+func normalize(s string) string {
+	lines := strings.Split(StripANSI(s), "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimRight(l, " ")
+	}
+	return strings.TrimRight(strings.Join(lines, "\n"), "\n") + "\n"
+}
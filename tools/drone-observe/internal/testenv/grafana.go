@@ -0,0 +1,140 @@
+// Archivo: tools/drone-observe/internal/testenv/grafana.go
+// Rol: Grafana falso en proceso para tests: /api/health, search, dashboards por uid y datasources, con basic auth.
+// No hace: renderizar paneles ni consultar datasources; sirve los modelos JSON que el test carga.
+package testenv
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// Datasource es un datasource provisionado con el resultado de su health check.
+type Datasource struct {
+	UID    string
+	Name   string
+	Type   string
+	URL    string
+	Health string
+}
+
+// Grafana sirve la API de lectura que usa el CLI.
+type Grafana struct {
+	URL string
+
+	mu          sync.Mutex
+	user, pass  string
+	healthy     bool
+	dashboards  map[string]map[string]any
+	datasources []Datasource
+}
+
+// NewGrafana arranca un Grafana sano con las credenciales dadas; se cierra al terminar el test.
+func NewGrafana(t testing.TB, user, password string) *Grafana {
+	t.Helper()
+	g := &Grafana{user: user, pass: password, healthy: true, dashboards: map[string]map[string]any{}}
+	srv := httptest.NewServer(http.HandlerFunc(g.serve))
+	t.Cleanup(srv.Close)
+	g.URL = srv.URL
+	return g
+}
+
+// SetHealthy define la respuesta de /api/health (200 o 503).
+func (g *Grafana) SetHealthy(healthy bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.healthy = healthy
+}
+
+// AddDashboard publica un dashboard; model es el JSON del dashboard (uid y title se completan).
+func (g *Grafana) AddDashboard(uid, title string, model map[string]any) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	m := map[string]any{}
+	for k, v := range model {
+		m[k] = v
+	}
+	m["uid"], m["title"] = uid, title
+	g.dashboards[uid] = m
+}
+
+// AddDatasource agrega un datasource; Health es el status de su health check ("OK" o "ERROR").
+func (g *Grafana) AddDatasource(ds Datasource) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.datasources = append(g.datasources, ds)
+}
+
+func (g *Grafana) serve(w http.ResponseWriter, r *http.Request) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if r.URL.Path == "/api/health" {
+		if !g.healthy {
+			http.Error(w, `{"database":"failing"}`, http.StatusServiceUnavailable)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]string{"database": "ok", "version": "testenv"})
+		return
+	}
+	// Como Grafana con anonimo deshabilitado: toda la API salvo /api/health pide basic auth.
+	if user, pass, ok := r.BasicAuth(); !ok || user != g.user || pass != g.pass {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"message": "invalid username or password"})
+		return
+	}
+	path := r.URL.Path
+	switch {
+	case path == "/api/search":
+		uids := make([]string, 0, len(g.dashboards))
+		for uid := range g.dashboards {
+			uids = append(uids, uid)
+		}
+		sort.Strings(uids)
+		hits := make([]map[string]any, 0, len(uids))
+		for _, uid := range uids {
+			hits = append(hits, map[string]any{"uid": uid, "title": g.dashboards[uid]["title"], "type": "dash-db"})
+		}
+		writeJSON(w, http.StatusOK, hits)
+	case strings.HasPrefix(path, "/api/dashboards/uid/"):
+		d, ok := g.dashboards[strings.TrimPrefix(path, "/api/dashboards/uid/")]
+		if !ok {
+			writeJSON(w, http.StatusNotFound, map[string]string{"message": "Dashboard not found"})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"dashboard": d, "meta": map[string]any{"provisioned": true}})
+	case strings.HasPrefix(path, "/api/datasources/name/"):
+		name := strings.TrimPrefix(path, "/api/datasources/name/")
+		for _, ds := range g.datasources {
+			if ds.Name == name {
+				writeJSON(w, http.StatusOK, map[string]string{"uid": ds.UID, "name": ds.Name, "type": ds.Type, "url": ds.URL})
+				return
+			}
+		}
+		writeJSON(w, http.StatusNotFound, map[string]string{"message": "Data source not found"})
+	case strings.HasPrefix(path, "/api/datasources/uid/") && strings.HasSuffix(path, "/health"):
+		uid := strings.TrimSuffix(strings.TrimPrefix(path, "/api/datasources/uid/"), "/health")
+		for _, ds := range g.datasources {
+			if ds.UID == uid {
+				code := http.StatusOK
+				if ds.Health != "OK" {
+					// Grafana responde 400 con status ERROR cuando el datasource falla.
+					code = http.StatusBadRequest
+				}
+				writeJSON(w, code, map[string]string{"status": ds.Health, "message": "testenv"})
+				return
+			}
+		}
+		writeJSON(w, http.StatusNotFound, map[string]string{"message": "Data source not found"})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,197 @@
+// Archivo: tools/drone-observe/internal/testenv/prometheus.go
+// Rol: Prometheus falso en proceso para tests: respuestas guionadas de /api/v1/query, /-/ready y /api/v1/targets.
+// No hace: evaluar PromQL; cada expresion devuelve exactamente lo que el test guiono (o vector vacio).
+package testenv
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Sample es una serie de un resultado guionado.
+type Sample struct {
+	Labels map[string]string
+	Value  float64
+}
+
+// Target es un target activo como lo sirve /api/v1/targets.
+type Target struct {
+	Job       string
+	Instance  string
+	Health    string
+	LastError string
+}
+
+type scripted struct {
+	samples []Sample
+	err     string
+}
+
+type rawResponse struct {
+	status int
+	body   string
+}
+
+// Prometheus sirve la API HTTP con resultados guionados; es seguro cambiar el guion mientras corre.
+type Prometheus struct {
+	URL string
+
+	mu       sync.Mutex
+	results  map[string]scripted
+	ready    bool
+	targets  []Target
+	raw      map[string]rawResponse
+	queries  []string
+	queryAts []string
+}
+
+// NewPrometheus arranca un Prometheus falso listo y sin series; se cierra al terminar el test.
+func NewPrometheus(t testing.TB) *Prometheus {
+	t.Helper()
+	p := &Prometheus{results: map[string]scripted{}, raw: map[string]rawResponse{}, ready: true}
+	srv := httptest.NewServer(http.HandlerFunc(p.serve))
+	t.Cleanup(srv.Close)
+	p.URL = srv.URL
+	return p
+}
+
+// Set guiona el resultado de expr (comparacion exacta del texto); sin samples es "sin datos".
+func (p *Prometheus) Set(expr string, samples ...Sample) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.results[expr] = scripted{samples: samples}
+}
+
+// SetError hace que expr responda status error con msg (como una query invalida).
+func (p *Prometheus) SetError(expr, msg string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.results[expr] = scripted{err: msg}
+}
+
+// SetReady define la respuesta de /-/ready (200 o 503).
+func (p *Prometheus) SetReady(ready bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.ready = ready
+}
+
+// SetTargets reemplaza los targets activos.
+func (p *Prometheus) SetTargets(targets ...Target) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.targets = targets
+}
+
+// Handle sirve body crudo en path (p. ej. /api/v1/rules o /api/v1/alerts); tiene prioridad sobre el resto.
+func (p *Prometheus) Handle(path string, status int, body string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.raw[path] = rawResponse{status: status, body: body}
+}
+
+// Queries devuelve las expresiones consultadas, en orden.
+func (p *Prometheus) Queries() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string(nil), p.queries...)
+}
+
+// QueryTimes devuelve el parametro time de cada consulta ("" si se evaluo en el reloj de Prometheus).
+func (p *Prometheus) QueryTimes() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string(nil), p.queryAts...)
+}
+
+func (p *Prometheus) serve(w http.ResponseWriter, r *http.Request) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if raw, ok := p.raw[r.URL.Path]; ok {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(raw.status)
+		_, _ = w.Write([]byte(raw.body))
+		return
+	}
+	switch r.URL.Path {
+	case "/-/ready":
+		if !p.ready {
+			http.Error(w, "Service Unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte("Prometheus Server is Ready.\n"))
+	case "/api/v1/query":
+		p.serveQuery(w, r)
+	case "/api/v1/targets":
+		p.serveTargets(w)
+	case "/api/v1/rules":
+		writeAPI(w, map[string]any{"groups": []any{}})
+	case "/api/v1/alerts":
+		writeAPI(w, map[string]any{"alerts": []any{}})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// PARTE CRITICA **********************
+// El formato de respuesta es el de Prometheus real ([ts, "valor"] con el valor como string): los tests
+// ejercitan el mismo decodificador que en vivo. ts es el parametro time (o el reloj del test), igual que una
+// consulta instantanea real; la edad de una muestra se guiona como valor de timestamp(...), no con ts.
+// FIN DE PARTE CRITICA ****************
+func (p *Prometheus) serveQuery(w http.ResponseWriter, r *http.Request) {
+	_ = r.ParseForm()
+	expr := r.Form.Get("query")
+	at := r.Form.Get("time")
+	p.queries = append(p.queries, expr)
+	p.queryAts = append(p.queryAts, at)
+
+	res := p.results[expr]
+	if res.err != "" {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]any{"status": "error", "errorType": "bad_data", "error": res.err})
+		return
+	}
+	ts := float64(time.Now().UnixMilli()) / 1000
+	if at != "" {
+		if v, err := strconv.ParseFloat(at, 64); err == nil {
+			ts = v
+		}
+	}
+	result := make([]map[string]any, 0, len(res.samples))
+	for _, s := range res.samples {
+		labels := s.Labels
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		result = append(result, map[string]any{
+			"metric": labels,
+			"value":  []any{ts, strconv.FormatFloat(s.Value, 'f', -1, 64)},
+		})
+	}
+	writeAPI(w, map[string]any{"resultType": "vector", "result": result})
+}
+
+func (p *Prometheus) serveTargets(w http.ResponseWriter) {
+	active := make([]map[string]any, 0, len(p.targets))
+	for _, t := range p.targets {
+		active = append(active, map[string]any{
+			"scrapePool": t.Job,
+			"scrapeUrl":  "http://" + t.Instance + "/metrics",
+			"labels":     map[string]string{"job": t.Job, "instance": t.Instance},
+			"health":     t.Health,
+			"lastError":  t.LastError,
+		})
+	}
+	writeAPI(w, map[string]any{"activeTargets": active, "droppedTargets": []any{}})
+}
+
+func writeAPI(w http.ResponseWriter, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{"status": "success", "data": data})
+}
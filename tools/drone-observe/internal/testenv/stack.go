@@ -0,0 +1,57 @@
+// Archivo: tools/drone-observe/internal/testenv/stack.go
+// Rol: levantar el stack falso completo (Prometheus, backend, Grafana, broker) y la config que apunta a el.
+// No hace: cargar datos; cada test guiona lo que necesita sobre los dobles.
+package testenv
+
+import (
+	"testing"
+
+	"drone-observe/internal/config"
+)
+
+// Credenciales del Grafana falso; coinciden con los defaults de GRAFANA_ADMIN_USER/PASSWORD.
+const (
+	GrafanaUser     = "admin"
+	GrafanaPassword = "admin"
+)
+
+// Stack agrupa los dobles de un test.
+type Stack struct {
+	Prometheus *Prometheus
+	Backend    *Backend
+	Grafana    *Grafana
+	Broker     *Broker
+}
+
+// NewStack arranca todos los dobles sanos: Prometheus listo sin series, /metrics vacio, Grafana sano y broker.
+func NewStack(t testing.TB) *Stack {
+	t.Helper()
+	return &Stack{
+		Prometheus: NewPrometheus(t),
+		Backend:    NewBackend(t, ""),
+		Grafana:    NewGrafana(t, GrafanaUser, GrafanaPassword),
+		Broker:     NewBroker(t),
+	}
+}
+
+// PARTE CRITICA **********************
+// La config no sale de FromEnv: variables del entorno de quien corre los tests (PROMETHEUS_URL, MQTT_HOST)
+// no deben poder apuntar un test al stack real. Los paths de archivos quedan vacios; un test que los lee
+// los fija con rutas a su testdata.
+// FIN DE PARTE CRITICA ****************
+func (s *Stack) Config() config.Config {
+	return config.Config{
+		MQTTHost:          s.Broker.Host,
+		MQTTPort:          s.Broker.Port,
+		MQTTBaseTopic:     "drone/alpha",
+		BackendMetricsURL: s.Backend.URL,
+		PrometheusURL:     s.Prometheus.URL,
+		GrafanaURL:        s.Grafana.URL,
+		// Alertmanager no tiene doble: un puerto cerrado hace que los checks lo reporten como no accesible.
+		AlertmanagerURL:  "http://127.0.0.1:1",
+		GrafanaUser:      GrafanaUser,
+		GrafanaPassword:  GrafanaPassword,
+		FreshnessWarnSec: 30,
+		FreshnessFailSec: 120,
+	}
+}
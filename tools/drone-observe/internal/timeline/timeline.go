@@ -0,0 +1,99 @@
+// Archivo: tools/drone-observe/internal/timeline/timeline.go
+// Rol: evaluar un check en instantes sucesivos del pasado y reducirlo a transiciones de estado por item.
+// No hace: consultar Prometheus por si mismo; cada check se evalua con su propia logica y --at.
+package timeline
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Absent es el estado de un item que no aparece en una evaluacion (p. ej. una serie que aun no existia).
+const Absent = "ausente"
+
+// MaxEvaluations acota la cantidad de instantes de un rango: cada uno repite todas las queries del check.
+const MaxEvaluations = 1000
+
+// Eval evalua un check en un instante y devuelve estados discretos por item.
+type Eval func(at time.Time) map[string]string
+
+// Transition es el instante en que un item paso a un estado.
+type Transition struct {
+	At    time.Time
+	State string
+}
+
+type Item struct {
+	Key         string
+	Transitions []Transition
+}
+
+// Changes cuenta las transiciones posteriores al estado inicial.
+func (i Item) Changes() int { return len(i.Transitions) - 1 }
+
+type Timeline struct {
+	From, To    time.Time
+	Step        time.Duration
+	Evaluations int
+	Items       []Item
+}
+
+// Instants devuelve los instantes de evaluacion desde from hasta to inclusive.
+func Instants(from, to time.Time, step time.Duration) ([]time.Time, error) {
+	if step <= 0 {
+		return nil, fmt.Errorf("paso invalido: %s", step)
+	}
+	if !from.Before(to) {
+		return nil, fmt.Errorf("rango vacio: %s no es anterior a %s", from.Format(time.RFC3339), to.Format(time.RFC3339))
+	}
+	n := int(to.Sub(from)/step) + 1
+	if n > MaxEvaluations {
+		return nil, fmt.Errorf("%d evaluaciones (maximo %d): usar un --step mayor", n, MaxEvaluations)
+	}
+	out := make([]time.Time, 0, n)
+	for t := from; !t.After(to); t = t.Add(step) {
+		out = append(out, t)
+	}
+	return out, nil
+}
+
+// PARTE CRITICA **********************
+// Se registra solo el primer estado de cada item y sus cambios; la linea de tiempo no promedia ni
+// suaviza. Un item ausente en una evaluacion pasa a "ausente" en vez de conservar su ultimo estado:
+// que una serie deje de existir es justamente lo que se busca despues de un incidente.
+// FIN DE PARTE CRITICA ****************
+func Run(from, to time.Time, step time.Duration, eval Eval) (Timeline, error) {
+	instants, err := Instants(from, to, step)
+	if err != nil {
+		return Timeline{}, err
+	}
+	tl := Timeline{From: from, To: to, Step: step, Evaluations: len(instants)}
+	items := map[string]*Item{}
+	for i, at := range instants {
+		states := eval(at)
+		for key := range states {
+			if _, ok := items[key]; !ok {
+				it := &Item{Key: key}
+				if i > 0 {
+					it.Transitions = append(it.Transitions, Transition{At: from, State: Absent})
+				}
+				items[key] = it
+			}
+		}
+		for key, it := range items {
+			state, ok := states[key]
+			if !ok {
+				state = Absent
+			}
+			if last := it.Transitions; len(last) == 0 || last[len(last)-1].State != state {
+				it.Transitions = append(it.Transitions, Transition{At: at, State: state})
+			}
+		}
+	}
+	for _, it := range items {
+		tl.Items = append(tl.Items, *it)
+	}
+	sort.Slice(tl.Items, func(a, b int) bool { return tl.Items[a].Key < tl.Items[b].Key })
+	return tl, nil
+}
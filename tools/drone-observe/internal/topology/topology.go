@@ -7,11 +7,14 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"sort"
+	"strings"
 	"time"
 
 	"drone-observe/internal/config"
 	"drone-observe/internal/mqtt"
 	"drone-observe/internal/prometheus"
+	"drone-observe/internal/queries"
 )
 
 type Status int
@@ -22,6 +25,18 @@ const (
 	StatusFail
 )
 
+// MarshalText serializa el estado como texto en salidas JSON (bundle).
+func (s Status) MarshalText() ([]byte, error) {
+	switch s {
+	case StatusOK:
+		return []byte("ok"), nil
+	case StatusSilent:
+		return []byte("mudo"), nil
+	default:
+		return []byte("fail"), nil
+	}
+}
+
 type Component struct {
 	Name   string
 	Status Status
@@ -30,10 +45,41 @@ type Component struct {
 
 const httpTimeout = 3 * time.Second
 
+// jobComponents mapea jobs de prometheus.yml a componentes; un target down pone el componente en FAIL.
+var jobComponents = map[string]string{
+	"backend":      "Backend Rust",
+	"ml-analytics": "ML Analytics",
+}
+
+// observerJobs son jobs del propio CLI (serve y /probe): observan la topologia, no son componentes.
+var observerJobs = map[string]struct{}{"drone-observe": {}, "drone-observe-probe": {}}
+
+// Jobs lista los jobs que deben existir en prometheus.yml para observar la topologia.
+func Jobs() []string {
+	out := make([]string, 0, len(jobComponents))
+	for job := range jobComponents {
+		out = append(out, job)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// IsObserverJob indica si el job es del propio drone-observe.
+func IsObserverJob(job string) bool {
+	_, ok := observerJobs[job]
+	return ok
+}
+
+// ComponentForJob devuelve el componente de un job ("" si el job no es parte de la topologia).
+func ComponentForJob(job string) string {
+	return jobComponents[job]
+}
+
 // PARTE CRITICA **********************
 // La topologia se construye solo con checks explicitos y observables.
 // Si se agregan supuestos ocultos, se degrada la gobernanza y la trazabilidad.
 // No usar discovery dinamico ni inferencias de infraestructura.
+// Con --at solo se evalua lo que Prometheus guardo; lo que no tiene historial se omite, no se inventa.
 // FIN DE PARTE CRITICA ****************
 func Check(cfg config.Config) []Component {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -41,19 +87,32 @@ func Check(cfg config.Config) []Component {
 
 	var out []Component
 
+	at := cfg.EvalTime()
 	edge := Component{Name: "Edge"}
-	rate, ok, err := prometheus.QueryInstant(ctx, cfg.PrometheusURL, "rate(mqtt_messages_total[1m])")
+	vec, err := prometheus.QueryVectorAt(ctx, cfg.PrometheusURL, queries.MessageRate.Expr, at)
+	rate, ok := vec.Aggregate(prometheus.RuleSum)
 	if err != nil || !ok {
 		edge.Status = StatusFail
 		edge.Detail = "telemetria no observable"
-	} else if rate <= 0 {
+	} else if rate.Value <= 0 {
 		edge.Status = StatusSilent
 		edge.Detail = "telemetria silenciosa"
 	} else {
 		edge.Status = StatusOK
 	}
+	if note := vec.MultiSeriesNote(prometheus.RuleSum); note != "" {
+		if edge.Detail != "" {
+			edge.Detail += "; "
+		}
+		edge.Detail += note
+	}
 	out = append(out, edge)
 
+	if cfg.Historical() {
+		// Broker, Prometheus y Grafana solo se verifican en vivo; en el pasado queda lo scrapeado.
+		return append(out, scrapeComponents(ctx, cfg, at)...)
+	}
+
 	mqttC := Component{Name: "MQTT Broker"}
 	if err := mqtt.CheckReachable(cfg.MQTTHost, cfg.MQTTPort); err != nil {
 		mqttC.Status = StatusFail
@@ -63,6 +122,8 @@ func Check(cfg config.Config) []Component {
 	}
 	out = append(out, mqttC)
 
+	active, targetsErr := prometheus.Targets(ctx, cfg.PrometheusURL)
+
 	backend := Component{Name: "Backend Rust"}
 	if err := httpGetOK(ctx, cfg.BackendMetricsURL); err != nil {
 		backend.Status = StatusFail
@@ -70,8 +131,32 @@ func Check(cfg config.Config) []Component {
 	} else {
 		backend.Status = StatusOK
 	}
+	// El backend puede responder localmente y aun asi no ser scrapeado (red de compose, target mal escrito).
+	if down := downTargets(active, "backend"); backend.Status == StatusOK && down != "" {
+		backend.Status = StatusFail
+		backend.Detail = "target Prometheus down: " + down
+	}
 	out = append(out, backend)
 
+	// ML Analytics no tiene URL propia en la config: solo se observa via su target en Prometheus.
+	ml := Component{Name: "ML Analytics"}
+	switch {
+	case targetsErr != nil:
+		ml.Status = StatusSilent
+		ml.Detail = "targets no observables: " + targetsErr.Error()
+	case !hasTargets(active, "ml-analytics"):
+		ml.Status = StatusSilent
+		ml.Detail = "sin target ml-analytics en Prometheus"
+	default:
+		if down := downTargets(active, "ml-analytics"); down != "" {
+			ml.Status = StatusFail
+			ml.Detail = "target Prometheus down: " + down
+		} else {
+			ml.Status = StatusOK
+		}
+	}
+	out = append(out, ml)
+
 	prom := Component{Name: "Prometheus"}
 	if err := prometheus.CheckReady(ctx, cfg.PrometheusURL); err != nil {
 		prom.Status = StatusFail
@@ -93,6 +178,69 @@ func Check(cfg config.Config) []Component {
 	return out
 }
 
+// scrapeComponents evalua los componentes con job a partir de la serie up en el instante dado.
+// Sin series, Backend es FAIL (debe scrapearse siempre) y ML Analytics MUDO, como en vivo.
+func scrapeComponents(ctx context.Context, cfg config.Config, at time.Time) []Component {
+	vec, err := prometheus.QueryVectorAt(ctx, cfg.PrometheusURL, queries.ScrapeUp.Expr, at)
+	missing := map[string]Status{"backend": StatusFail, "ml-analytics": StatusSilent}
+	out := make([]Component, 0, len(jobComponents))
+	for _, job := range Jobs() {
+		c := Component{Name: ComponentForJob(job)}
+		var down []string
+		seen := false
+		for _, s := range vec {
+			if s.Labels["job"] != job {
+				continue
+			}
+			seen = true
+			if s.Value != 1 {
+				down = append(down, s.Labels["instance"])
+			}
+		}
+		switch {
+		case err != nil:
+			c.Status = missing[job]
+			c.Detail = "up no observable: " + err.Error()
+		case !seen:
+			c.Status = missing[job]
+			c.Detail = fmt.Sprintf("sin serie up{job=%q} en Prometheus", job)
+		case len(down) > 0:
+			c.Status = StatusFail
+			c.Detail = "target Prometheus down: " + strings.Join(down, ", ")
+		default:
+			c.Status = StatusOK
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+func hasTargets(active []prometheus.Target, job string) bool {
+	for _, t := range active {
+		if t.Job() == job {
+			return true
+		}
+	}
+	return false
+}
+
+// downTargets resume los targets no-up de un job ("" si todos estan up o no hay targets).
+func downTargets(active []prometheus.Target, job string) string {
+	var parts []string
+	for _, t := range active {
+		// "unknown" es un target aun no scrapeado (Prometheus recien iniciado), no una falla.
+		if t.Job() != job || t.Health == "up" || t.Health == "unknown" {
+			continue
+		}
+		detail := t.Instance() + " " + t.Health
+		if t.LastError != "" {
+			detail += ": " + t.LastError
+		}
+		parts = append(parts, detail)
+	}
+	return strings.Join(parts, "; ")
+}
+
 func httpGetOK(ctx context.Context, url string) error {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
@@ -0,0 +1,182 @@
+package topology
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"drone-observe/internal/queries"
+	"drone-observe/internal/testenv"
+)
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name   string
+		script func(s *testenv.Stack)
+		want   map[string]Status
+		detail map[string]string
+	}{
+		{
+			name:   "todo sano",
+			script: func(s *testenv.Stack) {},
+			want: map[string]Status{
+				"Edge": StatusOK, "MQTT Broker": StatusOK, "Backend Rust": StatusOK,
+				"ML Analytics": StatusOK, "Prometheus": StatusOK, "Grafana": StatusOK,
+			},
+		},
+		{
+			name: "edge callado",
+			script: func(s *testenv.Stack) {
+				s.Prometheus.Set(queries.MessageRate.Expr, testenv.Sample{Value: 0})
+			},
+			want:   map[string]Status{"Edge": StatusSilent},
+			detail: map[string]string{"Edge": "telemetria silenciosa"},
+		},
+		{
+			name: "rate sin series",
+			script: func(s *testenv.Stack) {
+				s.Prometheus.Set(queries.MessageRate.Expr)
+			},
+			want:   map[string]Status{"Edge": StatusFail},
+			detail: map[string]string{"Edge": "telemetria no observable"},
+		},
+		{
+			name: "backend responde pero su target esta down",
+			script: func(s *testenv.Stack) {
+				s.Prometheus.SetTargets(
+					testenv.Target{Job: "backend", Instance: "backend:8080", Health: "down", LastError: "context deadline exceeded"},
+					testenv.Target{Job: "ml-analytics", Instance: "ml-analytics:9100", Health: "up"},
+				)
+			},
+			want:   map[string]Status{"Backend Rust": StatusFail, "ML Analytics": StatusOK},
+			detail: map[string]string{"Backend Rust": "target Prometheus down: backend:8080 down: context deadline exceeded"},
+		},
+		{
+			name: "backend /metrics caido",
+			script: func(s *testenv.Stack) {
+				s.Backend.SetStatus(http.StatusInternalServerError)
+			},
+			want:   map[string]Status{"Backend Rust": StatusFail},
+			detail: map[string]string{"Backend Rust": "http status 500 Internal Server Error"},
+		},
+		{
+			name: "ml sin target",
+			script: func(s *testenv.Stack) {
+				s.Prometheus.SetTargets(testenv.Target{Job: "backend", Instance: "backend:8080", Health: "up"})
+			},
+			want:   map[string]Status{"ML Analytics": StatusSilent},
+			detail: map[string]string{"ML Analytics": "sin target ml-analytics en Prometheus"},
+		},
+		{
+			name: "target recien descubierto no es falla",
+			script: func(s *testenv.Stack) {
+				s.Prometheus.SetTargets(
+					testenv.Target{Job: "backend", Instance: "backend:8080", Health: "unknown"},
+					testenv.Target{Job: "ml-analytics", Instance: "ml-analytics:9100", Health: "unknown"},
+				)
+			},
+			want: map[string]Status{"Backend Rust": StatusOK, "ML Analytics": StatusOK},
+		},
+		{
+			name: "prometheus y grafana no listos",
+			script: func(s *testenv.Stack) {
+				s.Prometheus.SetReady(false)
+				s.Grafana.SetHealthy(false)
+			},
+			want: map[string]Status{"Prometheus": StatusFail, "Grafana": StatusFail},
+			detail: map[string]string{
+				"Prometheus": "prometheus not ready: 503 Service Unavailable",
+				"Grafana":    "http status 503 Service Unavailable",
+			},
+		},
+		{
+			name: "broker caido",
+			script: func(s *testenv.Stack) {
+				s.Broker.Close()
+			},
+			want: map[string]Status{"MQTT Broker": StatusFail},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := healthyStack(t)
+			tt.script(s)
+			got := map[string]Component{}
+			for _, c := range Check(s.Config()) {
+				got[c.Name] = c
+			}
+			if len(got) != 6 {
+				t.Fatalf("componentes = %v", got)
+			}
+			for name, want := range tt.want {
+				if got[name].Status != want {
+					t.Errorf("%s = %v (%s); esperado %v", name, got[name].Status, got[name].Detail, want)
+				}
+			}
+			for name, want := range tt.detail {
+				if got[name].Detail != want {
+					t.Errorf("%s detalle = %q; esperado %q", name, got[name].Detail, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckHistorical(t *testing.T) {
+	tests := []struct {
+		name string
+		up   []testenv.Sample
+		want map[string]Status
+	}{
+		{
+			name: "up de ambos jobs",
+			up: []testenv.Sample{
+				{Labels: map[string]string{"job": "backend", "instance": "backend:8080"}, Value: 1},
+				{Labels: map[string]string{"job": "ml-analytics", "instance": "ml-analytics:9100"}, Value: 1},
+			},
+			want: map[string]Status{"Backend Rust": StatusOK, "ML Analytics": StatusOK},
+		},
+		{
+			name: "backend down en el pasado",
+			up: []testenv.Sample{
+				{Labels: map[string]string{"job": "backend", "instance": "backend:8080"}, Value: 0},
+			},
+			want: map[string]Status{"Backend Rust": StatusFail, "ML Analytics": StatusSilent},
+		},
+		{
+			name: "sin historial de up",
+			want: map[string]Status{"Backend Rust": StatusFail, "ML Analytics": StatusSilent},
+		},
+	}
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := healthyStack(t)
+			s.Prometheus.Set(queries.ScrapeUp.Expr, tt.up...)
+			cfg := s.Config()
+			cfg.At = at
+			comps := Check(cfg)
+			// Con --at solo queda lo que Prometheus guardo: Edge y los componentes con job.
+			if len(comps) != 3 || comps[0].Name != "Edge" {
+				t.Fatalf("componentes = %+v", comps)
+			}
+			for _, c := range comps[1:] {
+				if c.Status != tt.want[c.Name] {
+					t.Errorf("%s = %v (%s); esperado %v", c.Name, c.Status, c.Detail, tt.want[c.Name])
+				}
+			}
+		})
+	}
+}
+
+// healthyStack arranca el stack falso con flujo de mensajes y los dos targets up.
+func healthyStack(t *testing.T) *testenv.Stack {
+	t.Helper()
+	s := testenv.NewStack(t)
+	s.Prometheus.Set(queries.MessageRate.Expr, testenv.Sample{Value: 1.2})
+	s.Prometheus.SetTargets(
+		testenv.Target{Job: "backend", Instance: "backend:8080", Health: "up"},
+		testenv.Target{Job: "ml-analytics", Instance: "ml-analytics:9100", Health: "up"},
+	)
+	return s
+}
@@ -0,0 +1,325 @@
+// Archivo: tools/drone-observe/internal/ui/alerts.go
+// Rol: TUI de alertas agrupadas por severidad con silencios de Alertmanager (crear/expirar con confirmacion).
+// No hace: editar reglas ni rutas de Alertmanager; solo silencios.
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"drone-observe/internal/alertmanager"
+	"drone-observe/internal/alerts"
+	"drone-observe/internal/config"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/bubbles/spinner"
+)
+
+const alertsRefresh = 5 * time.Second
+
+type alertsMsg struct {
+	Snapshot alerts.Snapshot
+}
+
+// alertsTickMsg lleva la generacion del snapshot que lo programo; los ticks viejos se descartan.
+type alertsTickMsg struct {
+	gen int
+}
+
+type alertsActionMsg struct {
+	Status string
+	Err    error
+}
+
+type alertsFocus int
+
+const (
+	focusAlerts alertsFocus = iota
+	focusSilences
+)
+
+// pendingAction es una accion sobre Alertmanager esperando confirmacion (y/n).
+type pendingAction struct {
+	prompt string
+	run    tea.Cmd
+}
+
+type alertsModel struct {
+	cfg        config.Config
+	spinner    spinner.Model
+	snap       alerts.Snapshot
+	done       bool
+	focus      alertsFocus
+	cursor     int
+	silenceFor time.Duration
+	confirm    *pendingAction
+	status     string
+	statusErr  bool
+	gen        int
+}
+
+func RunAlerts(cfg config.Config, silenceFor time.Duration) error {
+	s := spinner.New()
+	s.Spinner = spinner.Line
+	m := alertsModel{cfg: cfg, spinner: s, silenceFor: silenceFor}
+	p := tea.NewProgram(m, tea.WithAltScreen())
+	_, err := p.Run()
+	return err
+}
+
+func (m alertsModel) Init() tea.Cmd {
+	return tea.Batch(m.spinner.Tick, alertsCmd(m.cfg))
+}
+
+func alertsCmd(cfg config.Config) tea.Cmd {
+	return func() tea.Msg {
+		return alertsMsg{Snapshot: alerts.Collect(cfg)}
+	}
+}
+
+func alertsTickCmd(gen int) tea.Cmd {
+	return tea.Tick(alertsRefresh, func(time.Time) tea.Msg { return alertsTickMsg{gen: gen} })
+}
+
+// items aplana los grupos en el orden en que se muestran.
+func (m alertsModel) items() []alerts.Item {
+	var out []alerts.Item
+	for _, g := range m.snap.Groups {
+		out = append(out, g.Items...)
+	}
+	return out
+}
+
+func (m alertsModel) rows() int {
+	if m.focus == focusSilences {
+		return len(m.snap.Silences)
+	}
+	return len(m.items())
+}
+
+func (m alertsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch v := msg.(type) {
+	case alertsMsg:
+		m.snap = v.Snapshot
+		m.done = true
+		m.clampCursor()
+		// Refrescos manuales y acciones tambien traen snapshots: solo sigue vivo el tick del ultimo.
+		m.gen++
+		return m, alertsTickCmd(m.gen)
+	case alertsTickMsg:
+		if v.gen != m.gen {
+			return m, nil
+		}
+		return m, alertsCmd(m.cfg)
+	case alertsActionMsg:
+		m.status, m.statusErr = v.Status, v.Err != nil
+		if v.Err != nil {
+			m.status = v.Err.Error()
+		}
+		return m, alertsCmd(m.cfg)
+	case tea.KeyMsg:
+		return m.handleKey(v.String())
+	}
+	var cmd tea.Cmd
+	m.spinner, cmd = m.spinner.Update(msg)
+	return m, cmd
+}
+
+// PARTE CRITICA **********************
+// Crear o expirar un silencio cambia que alertas notifica Alertmanager: siempre pasa por confirmacion
+// explicita (y) y cualquier otra tecla cancela. No agregar atajos que actuen sin confirmar.
+// FIN DE PARTE CRITICA ****************
+func (m alertsModel) handleKey(key string) (tea.Model, tea.Cmd) {
+	if m.confirm != nil {
+		action := m.confirm
+		m.confirm = nil
+		if key == "y" || key == "Y" {
+			m.status, m.statusErr = "Enviando a Alertmanager...", false
+			return m, action.run
+		}
+		m.status, m.statusErr = "Cancelado.", false
+		return m, nil
+	}
+
+	switch key {
+	case "q", "ctrl+c":
+		return m, tea.Quit
+	case "tab":
+		m.focus = 1 - m.focus
+		m.cursor = 0
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "down", "j":
+		if m.cursor < m.rows()-1 {
+			m.cursor++
+		}
+	case "r":
+		return m, alertsCmd(m.cfg)
+	case "s":
+		items := m.items()
+		if m.focus != focusAlerts || m.cursor >= len(items) {
+			return m, nil
+		}
+		it := items[m.cursor]
+		if it.Silenced() {
+			m.status, m.statusErr = fmt.Sprintf("%s ya esta silenciada (%s)", it.Name, strings.Join(it.SilencedBy, ", ")), false
+			return m, nil
+		}
+		cfg, d := m.cfg, m.silenceFor
+		m.confirm = &pendingAction{
+			prompt: fmt.Sprintf("Silenciar %s %s por %s? (y/n)", it.Name, it.LabelString(), d),
+			run: func() tea.Msg {
+				id, err := alerts.CreateSilence(cfg, it, d)
+				return alertsActionMsg{Status: fmt.Sprintf("Silencio %s creado para %s.", shortID(id), it.Name), Err: err}
+			},
+		}
+	case "x":
+		if m.focus != focusSilences || m.cursor >= len(m.snap.Silences) {
+			return m, nil
+		}
+		sil := m.snap.Silences[m.cursor]
+		cfg := m.cfg
+		m.confirm = &pendingAction{
+			prompt: fmt.Sprintf("Expirar silencio %s %s? (y/n)", shortID(sil.ID), matchersString(sil.Matchers)),
+			run: func() tea.Msg {
+				err := alerts.ExpireSilence(cfg, sil.ID)
+				return alertsActionMsg{Status: fmt.Sprintf("Silencio %s expirado.", shortID(sil.ID)), Err: err}
+			},
+		}
+	}
+	return m, nil
+}
+
+func (m *alertsModel) clampCursor() {
+	if m.cursor >= m.rows() {
+		m.cursor = m.rows() - 1
+	}
+	if m.cursor < 0 {
+		m.cursor = 0
+	}
+}
+
+func (m alertsModel) View() string {
+	title := TitleStyle.Render("drone-observe alerts")
+	sub := WarnStyle.Render(fmt.Sprintf("Prometheus %s · Alertmanager %s", m.cfg.PrometheusURL, m.cfg.AlertmanagerURL))
+	ts := ""
+	if !m.snap.UpdatedAt.IsZero() {
+		ts = fmt.Sprintf("Ultima actualizacion: %s", m.snap.UpdatedAt.Format(time.RFC3339))
+	}
+
+	var body strings.Builder
+	body.WriteString(fmt.Sprintf("%s\n%s\n%s\n%s\n", title, sub, SubtitleStyle.Render(ts), strings.Repeat("─", 44)))
+	if !m.done {
+		body.WriteString("\n" + m.spinner.View())
+		return BoxStyle.Render(body.String())
+	}
+
+	m.writeAlerts(&body)
+	body.WriteString("\n")
+	m.writeSilences(&body)
+	if errs := alertsErrors(m.snap); errs != "" {
+		body.WriteString("\n" + errs)
+	}
+
+	body.WriteString("\n")
+	switch {
+	case m.confirm != nil:
+		body.WriteString(WarnStyle.Render(m.confirm.prompt) + "\n")
+	case m.status != "" && m.statusErr:
+		body.WriteString(FailStyle.Render(m.status) + "\n")
+	case m.status != "":
+		body.WriteString(OKStyle.Render(m.status) + "\n")
+	}
+	body.WriteString("↑/↓ seleccionar, 'tab' alertas/silencios, 's' silenciar, 'x' expirar silencio, 'r' refrescar, 'q' salir.\n")
+	return BoxStyle.Render(body.String())
+}
+
+func (m alertsModel) writeAlerts(body *strings.Builder) {
+	header := fmt.Sprintf("Alertas (%d)", len(m.items()))
+	if m.focus == focusAlerts {
+		header = "» " + header
+	}
+	body.WriteString(HeaderStyle.Render(header) + "\n")
+	if len(m.snap.Groups) == 0 {
+		body.WriteString(OKStyle.Render("  Sin alertas pending/firing") + "\n")
+		return
+	}
+	i := 0
+	for _, g := range m.snap.Groups {
+		body.WriteString(severityStyle(g.Severity).Render(fmt.Sprintf("%s (%d)", severityLabel(g.Severity), len(g.Items))) + "\n")
+		for _, it := range g.Items {
+			cursor := "  "
+			if m.focus == focusAlerts && i == m.cursor {
+				cursor = HeaderStyle.Render("› ")
+			}
+			body.WriteString(fmt.Sprintf("%s%s %s  %s\n", cursor, it.Name, SubtitleStyle.Render(it.LabelString()), alertState(it)))
+			if it.Summary != "" {
+				body.WriteString("    " + SubtitleStyle.Render(it.Summary) + "\n")
+			}
+			i++
+		}
+	}
+}
+
+func (m alertsModel) writeSilences(body *strings.Builder) {
+	header := fmt.Sprintf("Silencios (%d)", len(m.snap.Silences))
+	if m.focus == focusSilences {
+		header = "» " + header
+	}
+	body.WriteString(HeaderStyle.Render(header) + "\n")
+	if len(m.snap.Silences) == 0 {
+		body.WriteString(SubtitleStyle.Render("  Sin silencios activos") + "\n")
+		return
+	}
+	for i, s := range m.snap.Silences {
+		cursor := "  "
+		if m.focus == focusSilences && i == m.cursor {
+			cursor = HeaderStyle.Render("› ")
+		}
+		left := time.Until(s.EndsAt).Round(time.Minute)
+		body.WriteString(fmt.Sprintf("%s%s %s  %s hasta %s (quedan %s) por %s\n",
+			cursor, shortID(s.ID), matchersString(s.Matchers), s.State(), s.EndsAt.Local().Format("15:04"), left, s.CreatedBy))
+	}
+}
+
+func alertState(it alerts.Item) string {
+	var parts []string
+	switch it.PromState {
+	case "firing":
+		parts = append(parts, FailStyle.Render("firing"))
+	case "pending":
+		parts = append(parts, WarnStyle.Render("pending"))
+	case "":
+		parts = append(parts, SubtitleStyle.Render("no evaluada por Prometheus"))
+	}
+	switch {
+	case it.Silenced():
+		parts = append(parts, SubtitleStyle.Render("silenciada"))
+	case it.AMState == "suppressed":
+		parts = append(parts, SubtitleStyle.Render("inhibida"))
+	case it.AMState == "" && it.PromState == "firing":
+		parts = append(parts, WarnStyle.Render("sin Alertmanager"))
+	}
+	if !it.Since.IsZero() {
+		parts = append(parts, SubtitleStyle.Render("desde "+time.Since(it.Since).Round(time.Second).String()))
+	}
+	return strings.Join(parts, " · ")
+}
+
+func matchersString(ms []alertmanager.Matcher) string {
+	parts := make([]string, 0, len(ms))
+	for _, m := range ms {
+		parts = append(parts, m.String())
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+func shortID(id string) string {
+	if len(id) > 8 {
+		return id[:8]
+	}
+	return id
+}
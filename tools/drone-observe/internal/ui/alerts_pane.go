@@ -0,0 +1,129 @@
+// Archivo: tools/drone-observe/internal/ui/alerts_pane.go
+// Rol: panel compacto de alertas (Prometheus + Alertmanager) embebido en health, telemetry y llm.
+// No hace: acciones sobre silencios; eso vive en la vista completa `alerts`.
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"drone-observe/internal/alerts"
+	"drone-observe/internal/config"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	alertsPaneRefresh = 10 * time.Second
+	alertsPaneMaxRows = 3
+)
+
+type alertsPaneMsg struct {
+	Snapshot alerts.Snapshot
+}
+
+type alertsPaneTickMsg struct{}
+
+// alertsPane guarda el ultimo snapshot; cada vista lo actualiza con handle.
+type alertsPane struct {
+	snap   alerts.Snapshot
+	loaded bool
+}
+
+func alertsPaneCmd(cfg config.Config) tea.Cmd {
+	return func() tea.Msg {
+		return alertsPaneMsg{Snapshot: alerts.Collect(cfg)}
+	}
+}
+
+func alertsPaneTickCmd() tea.Cmd {
+	return tea.Tick(alertsPaneRefresh, func(time.Time) tea.Msg { return alertsPaneTickMsg{} })
+}
+
+// handle procesa los mensajes del panel; ok=false si el mensaje no era del panel.
+func (p *alertsPane) handle(cfg config.Config, msg tea.Msg) (tea.Cmd, bool) {
+	switch v := msg.(type) {
+	case alertsPaneMsg:
+		p.snap = v.Snapshot
+		p.loaded = true
+		return alertsPaneTickCmd(), true
+	case alertsPaneTickMsg:
+		return alertsPaneCmd(cfg), true
+	}
+	return nil, false
+}
+
+func (p alertsPane) View() string {
+	var b strings.Builder
+	b.WriteString(HeaderStyle.Render("Alertas") + " ")
+	if !p.loaded {
+		b.WriteString(SubtitleStyle.Render("consultando..."))
+		return b.String()
+	}
+
+	firing, silenced := p.snap.Counts()
+	var parts []string
+	for _, g := range p.snap.Groups {
+		n := firing[g.Severity]
+		if n == 0 {
+			continue
+		}
+		label := fmt.Sprintf("%d %s", n, severityLabel(g.Severity))
+		parts = append(parts, severityStyle(g.Severity).Render(label))
+	}
+	if len(parts) == 0 {
+		b.WriteString(OKStyle.Render("sin alertas firing"))
+	} else {
+		b.WriteString(strings.Join(parts, "  "))
+	}
+	if silenced > 0 {
+		b.WriteString(SubtitleStyle.Render(fmt.Sprintf("  (%d silenciadas)", silenced)))
+	}
+	b.WriteString("\n")
+
+	rows := 0
+	for _, g := range p.snap.Groups {
+		for _, it := range g.Items {
+			if it.Silenced() || (it.PromState != "firing" && it.AMState != "active") {
+				continue
+			}
+			if rows == alertsPaneMaxRows {
+				b.WriteString(SubtitleStyle.Render("  ... ver 'drone-observe alerts'") + "\n")
+				return b.String() + alertsErrors(p.snap)
+			}
+			b.WriteString(fmt.Sprintf("  %s %s\n", severityStyle(g.Severity).Render(it.Name), SubtitleStyle.Render(it.LabelString())))
+			rows++
+		}
+	}
+	return b.String() + alertsErrors(p.snap)
+}
+
+func alertsErrors(snap alerts.Snapshot) string {
+	var b strings.Builder
+	if snap.PrometheusErr != "" {
+		b.WriteString(FailStyle.Render("  Prometheus /api/v1/alerts: "+snap.PrometheusErr) + "\n")
+	}
+	if snap.AlertmanagerErr != "" {
+		b.WriteString(WarnStyle.Render("  Alertmanager: "+snap.AlertmanagerErr) + "\n")
+	}
+	return b.String()
+}
+
+func severityStyle(sev string) lipgloss.Style {
+	switch sev {
+	case "critical":
+		return FailStyle
+	case "warning":
+		return WarnStyle
+	}
+	return SubtitleStyle
+}
+
+func severityLabel(sev string) string {
+	if sev == "" {
+		return "sin severidad"
+	}
+	return sev
+}
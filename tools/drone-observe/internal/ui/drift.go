@@ -6,6 +6,7 @@ package ui
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"drone-observe/internal/audit"
 	"drone-observe/internal/config"
@@ -24,19 +25,26 @@ type driftModel struct {
 	spinner  spinner.Model
 	findings []audit.Finding
 	done     bool
+	watch    time.Duration
+	tracker  *changeTracker
+	running  bool
 }
 
-func RunDrift(cfg config.Config) error {
-	m := newDriftModel(cfg)
+func RunDrift(cfg config.Config, watch time.Duration) error {
+	m := newDriftModel(cfg, watch)
 	p := tea.NewProgram(m, tea.WithAltScreen())
 	_, err := p.Run()
 	return err
 }
 
-func newDriftModel(cfg config.Config) driftModel {
+func newDriftModel(cfg config.Config, watch time.Duration) driftModel {
 	s := spinner.New()
 	s.Spinner = spinner.Line
-	return driftModel{cfg: cfg, spinner: s}
+	m := driftModel{cfg: cfg, spinner: s, watch: watch}
+	if watch > 0 {
+		m.tracker = newChangeTracker()
+	}
+	return m
 }
 
 func (m driftModel) Init() tea.Cmd {
@@ -60,7 +68,19 @@ func (m driftModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case driftMsg:
 		m.findings = v.Findings
 		m.done = true
+		m.running = false
+		if m.tracker != nil {
+			states := map[string]string{}
+			for _, f := range m.findings {
+				states[driftKey(f)] = string(f.Severity)
+			}
+			m.tracker.observe(time.Now(), states)
+			return m, watchTickCmd(m.watch)
+		}
 		return m, nil
+	case watchTickMsg:
+		m.running = true
+		return m, driftCmd(m.cfg)
 	case tea.KeyMsg:
 		if v.String() == "q" || v.String() == "ctrl+c" {
 			return m, tea.Quit
@@ -76,7 +96,14 @@ func (m driftModel) View() string {
 	sub := WarnStyle.Render("Desviaciones tecnicas")
 
 	var body strings.Builder
-	body.WriteString(fmt.Sprintf("%s\n%s\n%s\n", title, sub, strings.Repeat("─", 44)))
+	body.WriteString(fmt.Sprintf("%s\n%s\n", title, sub))
+	if h := historyHeader(m.cfg, "drift"); h != "" {
+		body.WriteString(h + "\n")
+	}
+	if h := m.tracker.header(m.watch, m.running); h != "" {
+		body.WriteString(h + "\n")
+	}
+	body.WriteString(strings.Repeat("─", 44) + "\n")
 
 	if !m.done {
 		body.WriteString("\n" + m.spinner.View())
@@ -85,19 +112,25 @@ func (m driftModel) View() string {
 
 	if len(m.findings) == 0 {
 		body.WriteString(OKStyle.Render("Sin drift detectado") + "\n")
+		m.tracker.writeResolved(&body)
 		body.WriteString("\nPresiona 'q' para salir.\n")
 		return BoxStyle.Render(body.String())
 	}
 
 	for _, f := range m.findings {
 		line := fmt.Sprintf("%s %s - %s", formatSeverity(f.Severity), f.Item, f.Detail)
-		body.WriteString(line + "\n")
+		body.WriteString(m.tracker.decorate(driftKey(f), line) + "\n")
 	}
+	m.tracker.writeResolved(&body)
 
 	body.WriteString("\nPresiona 'q' para salir.\n")
 	return BoxStyle.Render(body.String())
 }
 
+func driftKey(f audit.Finding) string {
+	return f.Item + " - " + f.Detail
+}
+
 func formatSeverity(s audit.Severity) string {
 	switch s {
 	case audit.SeverityHigh:
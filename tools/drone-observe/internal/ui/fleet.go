@@ -0,0 +1,260 @@
+// Archivo: tools/drone-observe/internal/ui/fleet.go
+// Rol: TUI de flota por drone_id con tabla ordenable y detalle por dron.
+// No hace: comandos a drones ni historicos.
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"drone-observe/internal/config"
+	"drone-observe/internal/fleet"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/bubbles/spinner"
+)
+
+const (
+	fleetRefresh = 5 * time.Second
+	fleetWindow  = 3 * time.Second
+)
+
+type fleetMsg struct {
+	Result    fleet.Result
+	UpdatedAt time.Time
+}
+
+type fleetTickMsg struct{}
+
+type fleetModel struct {
+	cfg        config.Config
+	spinner    spinner.Model
+	result     fleet.Result
+	lastUpdate time.Time
+	done       bool
+	sortCol    fleet.Column
+	desc       bool
+	cursor     int
+	detail     string
+}
+
+func RunFleet(cfg config.Config, drone string) error {
+	m := newFleetModel(cfg, drone)
+	p := tea.NewProgram(m, tea.WithAltScreen())
+	_, err := p.Run()
+	return err
+}
+
+func newFleetModel(cfg config.Config, drone string) fleetModel {
+	s := spinner.New()
+	s.Spinner = spinner.Line
+	return fleetModel{cfg: cfg, spinner: s, detail: drone}
+}
+
+func (m fleetModel) Init() tea.Cmd {
+	return tea.Batch(m.spinner.Tick, fleetCmd(m.cfg))
+}
+
+// PARTE CRITICA **********************
+// La flota se refresca con el mismo descubrimiento explicito (drone_id + topics).
+// Si se cachean drones que ya no se observan, la vista miente durante un incidente.
+// No agregar acciones sobre drones desde esta vista.
+// FIN DE PARTE CRITICA ****************
+func fleetCmd(cfg config.Config) tea.Cmd {
+	return func() tea.Msg {
+		return fleetMsg{Result: fleet.Discover(cfg, fleetWindow), UpdatedAt: time.Now()}
+	}
+}
+
+func fleetTickCmd() tea.Cmd {
+	return tea.Tick(fleetRefresh, func(time.Time) tea.Msg { return fleetTickMsg{} })
+}
+
+func (m fleetModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch v := msg.(type) {
+	case fleetMsg:
+		m.result = v.Result
+		m.lastUpdate = v.UpdatedAt
+		m.done = true
+		fleet.Sort(m.result.Drones, m.sortCol, m.desc)
+		m.clampCursor()
+		return m, fleetTickCmd()
+	case fleetTickMsg:
+		return m, fleetCmd(m.cfg)
+	case tea.KeyMsg:
+		switch v.String() {
+		case "q", "ctrl+c":
+			return m, tea.Quit
+		case "up", "k":
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case "down", "j":
+			if m.cursor < len(m.result.Drones)-1 {
+				m.cursor++
+			}
+		case "s":
+			m.sortCol = m.sortCol.Next()
+			fleet.Sort(m.result.Drones, m.sortCol, m.desc)
+		case "r":
+			m.desc = !m.desc
+			fleet.Sort(m.result.Drones, m.sortCol, m.desc)
+		case "enter":
+			if m.cursor < len(m.result.Drones) {
+				m.detail = m.result.Drones[m.cursor].ID
+			}
+		case "esc", "backspace":
+			m.detail = ""
+		}
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.spinner, cmd = m.spinner.Update(msg)
+	return m, cmd
+}
+
+func (m *fleetModel) clampCursor() {
+	if m.cursor >= len(m.result.Drones) {
+		m.cursor = len(m.result.Drones) - 1
+	}
+	if m.cursor < 0 {
+		m.cursor = 0
+	}
+}
+
+func (m fleetModel) View() string {
+	title := TitleStyle.Render("drone-observe fleet")
+	sub := WarnStyle.Render(fmt.Sprintf("Drones por %s y topics drone/<id>/... (ventana MQTT %s)", fleet.DroneLabel, fleetWindow))
+	ts := ""
+	if !m.lastUpdate.IsZero() {
+		ts = fmt.Sprintf("Ultima actualizacion: %s", m.lastUpdate.Format(time.RFC3339))
+	}
+
+	var body strings.Builder
+	body.WriteString(fmt.Sprintf("%s\n%s\n%s\n", title, sub, SubtitleStyle.Render(ts)))
+	if h := historyHeader(m.cfg, "fleet"); h != "" {
+		body.WriteString(h + "\n")
+	}
+	body.WriteString(strings.Repeat("─", 44) + "\n")
+
+	if !m.done {
+		body.WriteString("\n" + m.spinner.View())
+		return BoxStyle.Render(body.String())
+	}
+
+	if m.detail != "" {
+		m.writeDetail(&body)
+		body.WriteString("\n'esc' volver, 'q' salir.\n")
+		return BoxStyle.Render(body.String())
+	}
+
+	if len(m.result.Drones) == 0 {
+		body.WriteString(FailStyle.Render("Sin drones observados") + "\n")
+	} else {
+		tw := tabwriter.NewWriter(&body, 0, 0, 2, ' ', 0)
+		headers := make([]string, 0, 6)
+		for c := fleet.ColumnID; c <= fleet.ColumnEvent; c++ {
+			h := c.String()
+			if c == m.sortCol {
+				if m.desc {
+					h += " ▼"
+				} else {
+					h += " ▲"
+				}
+			}
+			headers = append(headers, HeaderStyle.Render(h))
+		}
+		_, _ = fmt.Fprintln(tw, "  "+strings.Join(headers, "\t"))
+		_, _ = fmt.Fprintln(tw, "  --\t-------\t-----\t---------\t--\t-------------")
+		for i, d := range m.result.Drones {
+			cursor := "  "
+			if i == m.cursor {
+				cursor = HeaderStyle.Render("› ")
+			}
+			_, _ = fmt.Fprintf(tw, "%s%s\t%s\t%s\t%s\t%s\t%s\n",
+				cursor, d.ID, fleetBattery(d), fleetRate(d), fleetAge(d), fleetML(d), fleetEvent(d))
+		}
+		_ = tw.Flush()
+	}
+	writeWarnings(&body, m.result.Warnings)
+
+	body.WriteString("\n↑/↓ seleccionar, 'enter' detalle, 's' ordenar, 'r' invertir, 'q' salir.\n")
+	return BoxStyle.Render(body.String())
+}
+
+func (m fleetModel) writeDetail(body *strings.Builder) {
+	var drone *fleet.Drone
+	for i := range m.result.Drones {
+		if m.result.Drones[i].ID == m.detail {
+			drone = &m.result.Drones[i]
+			break
+		}
+	}
+	if drone == nil {
+		body.WriteString(FailStyle.Render(fmt.Sprintf("Dron %s no observado", m.detail)) + "\n")
+		return
+	}
+
+	body.WriteString(HeaderStyle.Render("Dron "+drone.ID) + "\n\n")
+	tw := tabwriter.NewWriter(body, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintf(tw, "Bateria\t%s\n", fleetBattery(*drone))
+	_, _ = fmt.Fprintf(tw, "Mensajes por segundo\t%s\n", fleetRate(*drone))
+	_, _ = fmt.Fprintf(tw, "Ultima muestra\t%s\n", fleetAge(*drone))
+	_, _ = fmt.Fprintf(tw, "Estado ML\t%s\n", fleetML(*drone))
+	_, _ = fmt.Fprintf(tw, "Ultimo evento\t%s\n", fleetEvent(*drone))
+	if !drone.LastEventAt.IsZero() {
+		_, _ = fmt.Fprintf(tw, "Evento recibido\t%s\n", drone.LastEventAt.Format(time.RFC3339))
+	}
+	_, _ = fmt.Fprintf(tw, "Fuentes\t%s\n", strings.Join(drone.Sources, ", "))
+	_, _ = fmt.Fprintf(tw, "Topics\tdrone/%s/telemetry, drone/%s/event\n", drone.ID, drone.ID)
+	_ = tw.Flush()
+
+	if len(drone.Series) > 0 {
+		body.WriteString("\n" + HeaderStyle.Render("Series Prometheus") + "\n")
+		for _, s := range drone.Series {
+			body.WriteString("  " + s + "\n")
+		}
+	}
+}
+
+func fleetBattery(d fleet.Drone) string {
+	if !d.HasBattery {
+		return FailStyle.Render("N/A")
+	}
+	label := fmt.Sprintf("%.0f%%", d.Battery)
+	if d.Battery < 20 {
+		return FailStyle.Render(label)
+	}
+	return OKStyle.Render(label)
+}
+
+func fleetRate(d fleet.Drone) string {
+	if !d.HasRate {
+		return FailStyle.Render("N/A")
+	}
+	return fmt.Sprintf("%.2f", d.MessageRate)
+}
+
+func fleetAge(d fleet.Drone) string {
+	if d.AgeSeconds < 0 {
+		return formatFreshStatus(d.Freshness) + " sin datos"
+	}
+	return fmt.Sprintf("%s hace %ds", formatFreshStatus(d.Freshness), d.AgeSeconds)
+}
+
+func fleetML(d fleet.Drone) string {
+	if !d.HasML {
+		return WarnStyle.Render("N/A")
+	}
+	label, _ := llmStateLabels(d.MLState)
+	return label
+}
+
+func fleetEvent(d fleet.Drone) string {
+	if d.LastEvent == "" {
+		return "-"
+	}
+	return d.LastEvent
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"strings"
 	"text/tabwriter"
+	"time"
 
 	"drone-observe/internal/config"
 	"drone-observe/internal/freshness"
@@ -24,19 +25,26 @@ type freshnessModel struct {
 	spinner spinner.Model
 	signals []freshness.Signal
 	done    bool
+	watch   time.Duration
+	tracker *changeTracker
+	running bool
 }
 
-func RunFreshness(cfg config.Config) error {
-	m := newFreshnessModel(cfg)
+func RunFreshness(cfg config.Config, watch time.Duration) error {
+	m := newFreshnessModel(cfg, watch)
 	p := tea.NewProgram(m, tea.WithAltScreen())
 	_, err := p.Run()
 	return err
 }
 
-func newFreshnessModel(cfg config.Config) freshnessModel {
+func newFreshnessModel(cfg config.Config, watch time.Duration) freshnessModel {
 	s := spinner.New()
 	s.Spinner = spinner.Line
-	return freshnessModel{cfg: cfg, spinner: s}
+	m := freshnessModel{cfg: cfg, spinner: s, watch: watch}
+	if watch > 0 {
+		m.tracker = newChangeTracker()
+	}
+	return m
 }
 
 func (m freshnessModel) Init() tea.Cmd {
@@ -60,7 +68,15 @@ func (m freshnessModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case freshnessMsg:
 		m.signals = v.Signals
 		m.done = true
+		m.running = false
+		if m.tracker != nil {
+			m.tracker.observe(time.Now(), freshnessStates(m.signals))
+			return m, watchTickCmd(m.watch)
+		}
 		return m, nil
+	case watchTickMsg:
+		m.running = true
+		return m, freshnessCmd(m.cfg)
 	case tea.KeyMsg:
 		if v.String() == "q" || v.String() == "ctrl+c" {
 			return m, tea.Quit
@@ -76,7 +92,14 @@ func (m freshnessModel) View() string {
 	sub := WarnStyle.Render(fmt.Sprintf("Umbrales: warn=%ds, fail=%ds", m.cfg.FreshnessWarnSec, m.cfg.FreshnessFailSec))
 
 	var body strings.Builder
-	body.WriteString(fmt.Sprintf("%s\n%s\n%s\n", title, sub, strings.Repeat("─", 44)))
+	body.WriteString(fmt.Sprintf("%s\n%s\n", title, sub))
+	if h := historyHeader(m.cfg, "freshness"); h != "" {
+		body.WriteString(h + "\n")
+	}
+	if h := m.tracker.header(m.watch, m.running); h != "" {
+		body.WriteString(h + "\n")
+	}
+	body.WriteString(strings.Repeat("─", 44) + "\n")
 
 	if !m.done {
 		body.WriteString("\n" + m.spinner.View())
@@ -91,9 +114,24 @@ func (m freshnessModel) View() string {
 		if s.AgeSeconds >= 0 {
 			age = fmt.Sprintf("hace %ds", s.AgeSeconds)
 		}
-		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\n", s.Name, age, formatFreshStatus(s.Status))
+		_, _ = fmt.Fprintln(tw, m.tracker.decorate(s.Name, fmt.Sprintf("%s\t%s\t%s", s.Name, age, formatFreshStatus(s.Status))))
+		if len(s.Series) > 1 {
+			for _, sr := range s.Series {
+				line := fmt.Sprintf("  └ %s\thace %ds\t%s", sr.Labels, sr.AgeSeconds, formatFreshStatus(sr.Status))
+				_, _ = fmt.Fprintln(tw, m.tracker.decorate(s.Name+" "+sr.Labels, line))
+			}
+		}
 	}
 	_ = tw.Flush()
+	m.tracker.writeResolved(&body)
+
+	var warnings []string
+	for _, s := range m.signals {
+		if s.Warning != "" {
+			warnings = append(warnings, fmt.Sprintf("Aviso %s: %s", s.Name, s.Warning))
+		}
+	}
+	writeWarnings(&body, warnings)
 
 	body.WriteString("\nPresiona 'q' para salir.\n")
 	return BoxStyle.Render(body.String())
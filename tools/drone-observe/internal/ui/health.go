@@ -12,6 +12,7 @@ import (
 	"drone-observe/internal/config"
 	"drone-observe/internal/mqtt"
 	"drone-observe/internal/prometheus"
+	"drone-observe/internal/queries"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/bubbles/spinner"
@@ -22,6 +23,7 @@ type healthStatus int
 const (
 	statusPending healthStatus = iota
 	statusOK
+	statusWarn
 	statusFail
 )
 
@@ -43,6 +45,7 @@ type healthModel struct {
 	done    bool
 	ok      bool
 	err     error
+	alerts  alertsPane
 }
 
 func RunHealth(cfg config.Config) error {
@@ -68,7 +71,7 @@ func newHealthModel(cfg config.Config) healthModel {
 }
 
 func (m healthModel) Init() tea.Cmd {
-	return tea.Batch(m.spinner.Tick, healthChecksCmd(m.cfg))
+	return tea.Batch(m.spinner.Tick, healthChecksCmd(m.cfg), alertsPaneCmd(m.cfg))
 }
 
 // PARTE CRITICA **********************
@@ -78,6 +81,13 @@ func (m healthModel) Init() tea.Cmd {
 // FIN DE PARTE CRITICA ****************
 func healthChecksCmd(cfg config.Config) tea.Cmd {
 	return func() tea.Msg {
+		items, ok := healthItems(cfg)
+		return healthResultMsg{Items: items, OK: ok}
+	}
+}
+
+// healthItems evalua el Control Plane en vivo; ok es false si algun item es FAIL.
+func healthItems(cfg config.Config) ([]healthItem, bool) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
@@ -109,31 +119,37 @@ func healthChecksCmd(cfg config.Config) tea.Cmd {
 		items[2].Status = statusOK
 	}
 
-	val, ok, err := prometheus.QueryInstant(ctx, cfg.PrometheusURL, "rate(mqtt_messages_total[1m])")
-	if err != nil || !ok || val <= 0 {
+	vec, err := prometheus.QueryVector(ctx, cfg.PrometheusURL, queries.MessageRate.Expr)
+	rate, ok := vec.Aggregate(prometheus.RuleSum)
+	if err != nil || !ok || rate.Value <= 0 {
 		items[3].Status = statusFail
 		if err != nil {
 			items[3].Detail = err.Error()
 		} else {
 			items[3].Detail = "rate=0 o sin datos"
 		}
+	} else if note := vec.MultiSeriesNote(prometheus.RuleSum); note != "" {
+		items[3].Status = statusWarn
+		items[3].Detail = fmt.Sprintf("%s: %s", note, seriesDetail(vec, "%.2f"))
 	} else {
 		items[3].Status = statusOK
 	}
 
 	allOK := true
 	for _, it := range items {
-		if it.Status != statusOK {
+		if it.Status == statusFail {
 			allOK = false
 			break
 		}
 	}
 
-		return healthResultMsg{Items: items, OK: allOK}
-	}
+	return items, allOK
 }
 
 func (m healthModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if cmd, ok := m.alerts.handle(m.cfg, msg); ok {
+		return m, cmd
+	}
 	switch v := msg.(type) {
 	case healthResultMsg:
 		m.items = v.Items
@@ -154,7 +170,15 @@ func (m healthModel) View() string {
 	title := TitleStyle.Render("drone-observe health")
 	statusLine := WarnStyle.Render("Ejecutando checks...")
 	if m.done {
-		if m.ok {
+		warn := false
+		for _, it := range m.items {
+			if it.Status == statusWarn {
+				warn = true
+			}
+		}
+		if m.ok && warn {
+			statusLine = WarnStyle.Render("Resultado: OK con avisos")
+		} else if m.ok {
 			statusLine = OKStyle.Render("Resultado: OK")
 		} else {
 			statusLine = FailStyle.Render("Resultado: FAIL")
@@ -170,6 +194,7 @@ func (m healthModel) View() string {
 		}
 		body.WriteString(line + "\n")
 	}
+	body.WriteString("\n" + m.alerts.View())
 	if !m.done {
 		body.WriteString("\n" + m.spinner.View())
 	} else {
@@ -182,6 +207,8 @@ func statusIcon(s healthStatus) string {
 	switch s {
 	case statusOK:
 		return OKStyle.Render("✔")
+	case statusWarn:
+		return WarnStyle.Render("!")
 	case statusFail:
 		return FailStyle.Render("✖")
 	default:
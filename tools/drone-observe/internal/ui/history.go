@@ -0,0 +1,187 @@
+// Archivo: tools/drone-observe/internal/ui/history.go
+// Rol: estados discretos por check (compartidos por --watch y --range) y cabecera de evaluacion historica.
+// No hace: recorrer el rango; eso es internal/timeline.
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	"drone-observe/internal/config"
+	"drone-observe/internal/freshness"
+	"drone-observe/internal/limits"
+	"drone-observe/internal/topology"
+)
+
+// historicalOmitted lista lo que cada check no puede evaluar en el pasado (solo existe en vivo).
+var historicalOmitted = map[string]string{
+	"topology":  "MQTT Broker, Prometheus y Grafana (solo en vivo)",
+	"validate":  "metricas inesperadas en /metrics del backend (solo en vivo)",
+	"freshness": "",
+	"limits":    "",
+	"fleet":     "observacion MQTT (solo en vivo)",
+}
+
+// offlineOmitted lista lo que cada check no puede evaluar desde un bundle (--from).
+var offlineOmitted = map[string]string{
+	"drift": "health de datasources Grafana (solo en vivo)",
+}
+
+// RangeChecks son los checks que aceptan --range, en orden estable para ayuda y errores.
+var RangeChecks = []string{"freshness", "limits", "topology", "validate"}
+
+// CheckStates devuelve la evaluacion de estados discretos de un check para --range.
+func CheckStates(check string) (func(cfg config.Config) map[string]string, bool) {
+	switch check {
+	case "freshness":
+		return func(cfg config.Config) map[string]string { return freshnessStates(freshness.Check(cfg)) }, true
+	case "limits":
+		return func(cfg config.Config) map[string]string {
+			snap, err := limits.Observe(cfg)
+			if err != nil {
+				return map[string]string{"Limits": "error"}
+			}
+			return limitsStates(snap)
+		}, true
+	case "topology":
+		return func(cfg config.Config) map[string]string { return topologyStates(topology.Check(cfg)) }, true
+	case "validate":
+		return func(cfg config.Config) map[string]string {
+			items, _ := validateItems(cfg)
+			return validateStates(items)
+		}, true
+	}
+	return nil, false
+}
+
+// LiveChecks son los checks que acepta LiveStates (escenarios de chaos), en orden estable.
+var LiveChecks = []string{"freshness", "health", "limits", "ml", "topology", "validate"}
+
+// LiveStates devuelve la evaluacion en vivo de estados discretos de un check; agrega a CheckStates
+// los checks que solo existen en vivo (health y ml_state).
+func LiveStates(check string) (func(cfg config.Config) map[string]string, bool) {
+	switch check {
+	case "health":
+		return func(cfg config.Config) map[string]string {
+			items, _ := healthItems(cfg)
+			return healthStates(items)
+		}, true
+	case "ml":
+		return mlStates, true
+	}
+	return CheckStates(check)
+}
+
+// historyHeader describe la evaluacion historica u offline para la cabecera ("" si se evalua ahora).
+func historyHeader(cfg config.Config, check string) string {
+	if cfg.Offline() {
+		line := "Bundle " + cfg.From + " · capturado " + cfg.At.Format("2006-01-02 15:04:05 MST") + " (--from)"
+		if omitted := offlineOmitted[check]; omitted != "" {
+			line += " · omitido: " + omitted
+		}
+		return SubtitleStyle.Render(line)
+	}
+	if !cfg.Historical() {
+		return ""
+	}
+	line := "Evaluado en " + cfg.At.Format("2006-01-02 15:04:05 MST") + " (--at)"
+	if omitted := historicalOmitted[check]; omitted != "" {
+		line += " · omitido: " + omitted
+	}
+	return SubtitleStyle.Render(line)
+}
+
+func topologyStates(items []topology.Component) map[string]string {
+	states := map[string]string{}
+	for _, it := range items {
+		states[it.Name] = topoLabel(it.Status)
+	}
+	return states
+}
+
+func freshnessStates(signals []freshness.Signal) map[string]string {
+	states := map[string]string{}
+	for _, s := range signals {
+		states[s.Name] = freshLabel(s.Status, s.AgeSeconds)
+		if len(s.Series) < 2 {
+			// Con una sola serie la fila por serie repite la del signal (la TUI tampoco la muestra).
+			continue
+		}
+		for _, sr := range s.Series {
+			states[s.Name+" "+sr.Labels] = freshLabel(sr.Status, sr.AgeSeconds)
+		}
+	}
+	return states
+}
+
+func healthStates(items []healthItem) map[string]string {
+	states := map[string]string{}
+	for _, it := range items {
+		states[it.Name] = statusLabel(it.Status)
+	}
+	return states
+}
+
+func validateStates(items []validateItem) map[string]string {
+	states := map[string]string{}
+	for _, it := range items {
+		states[it.Name] = statusLabel(it.Status)
+	}
+	return states
+}
+
+func topoLabel(s topology.Status) string {
+	switch s {
+	case topology.StatusOK:
+		return "OK"
+	case topology.StatusSilent:
+		return "MUDO"
+	default:
+		return "FAIL"
+	}
+}
+
+func freshLabel(s freshness.Status, age int) string {
+	switch {
+	case age < 0:
+		return "sin datos"
+	case s == freshness.StatusOK:
+		return "OK"
+	case s == freshness.StatusWarn:
+		return "WARN"
+	default:
+		return "FAIL"
+	}
+}
+
+// MarshalText serializa el estado como texto en salidas JSON (bundle).
+func (s healthStatus) MarshalText() ([]byte, error) { return []byte(statusLabel(s)), nil }
+
+func statusLabel(s healthStatus) string {
+	switch s {
+	case statusOK:
+		return "OK"
+	case statusWarn:
+		return "WARN"
+	case statusFail:
+		return "FAIL"
+	default:
+		return "pendiente"
+	}
+}
+
+// FormatInstant formatea un instante de la linea de tiempo; con fecha si el rango cruza dias.
+func FormatInstant(t time.Time, withDate bool) string {
+	if withDate {
+		return t.Format("2006-01-02 15:04:05")
+	}
+	return t.Format("15:04:05")
+}
+
+// RangeLabel describe el check para la cabecera del reporte de --range.
+func RangeLabel(check string) string {
+	if omitted := historicalOmitted[check]; omitted != "" {
+		return fmt.Sprintf("%s (omitido: %s)", check, omitted)
+	}
+	return check
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"strings"
 	"text/tabwriter"
+	"time"
 
 	"drone-observe/internal/config"
 	"drone-observe/internal/limits"
@@ -15,6 +16,14 @@ import (
 	"github.com/charmbracelet/bubbles/spinner"
 )
 
+// Filas de la tabla; tambien son las claves del modo watch.
+const (
+	limitsRate   = "Mensajes por segundo"
+	limitsSeries = "Series observadas"
+	limitsNames  = "Nombres de metricas"
+	limitsScrape = "Ultimo scrape (age)"
+)
+
 type limitsMsg struct {
 	Snapshot limits.Snapshot
 	Err      error
@@ -25,19 +34,26 @@ type limitsModel struct {
 	spinner spinner.Model
 	data    limits.Snapshot
 	done    bool
+	watch   time.Duration
+	tracker *changeTracker
+	running bool
 }
 
-func RunLimits(cfg config.Config) error {
-	m := newLimitsModel(cfg)
+func RunLimits(cfg config.Config, watch time.Duration) error {
+	m := newLimitsModel(cfg, watch)
 	p := tea.NewProgram(m, tea.WithAltScreen())
 	_, err := p.Run()
 	return err
 }
 
-func newLimitsModel(cfg config.Config) limitsModel {
+func newLimitsModel(cfg config.Config, watch time.Duration) limitsModel {
 	s := spinner.New()
 	s.Spinner = spinner.Line
-	return limitsModel{cfg: cfg, spinner: s}
+	m := limitsModel{cfg: cfg, spinner: s, watch: watch}
+	if watch > 0 {
+		m.tracker = newChangeTracker()
+	}
+	return m
 }
 
 func (m limitsModel) Init() tea.Cmd {
@@ -61,7 +77,15 @@ func (m limitsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case limitsMsg:
 		m.data = v.Snapshot
 		m.done = true
+		m.running = false
+		if m.tracker != nil {
+			m.tracker.observe(time.Now(), limitsStates(m.data))
+			return m, watchTickCmd(m.watch)
+		}
 		return m, nil
+	case watchTickMsg:
+		m.running = true
+		return m, limitsCmd(m.cfg)
 	case tea.KeyMsg:
 		if v.String() == "q" || v.String() == "ctrl+c" {
 			return m, tea.Quit
@@ -77,7 +101,14 @@ func (m limitsModel) View() string {
 	sub := WarnStyle.Render("Limites observados (sin benchmark)")
 
 	var body strings.Builder
-	body.WriteString(fmt.Sprintf("%s\n%s\n%s\n", title, sub, strings.Repeat("─", 44)))
+	body.WriteString(fmt.Sprintf("%s\n%s\n", title, sub))
+	if h := historyHeader(m.cfg, "limits"); h != "" {
+		body.WriteString(h + "\n")
+	}
+	if h := m.tracker.header(m.watch, m.running); h != "" {
+		body.WriteString(h + "\n")
+	}
+	body.WriteString(strings.Repeat("─", 44) + "\n")
 
 	if !m.done {
 		body.WriteString("\n" + m.spinner.View())
@@ -87,16 +118,36 @@ func (m limitsModel) View() string {
 	tw := tabwriter.NewWriter(&body, 0, 0, 2, ' ', 0)
 	_, _ = fmt.Fprintln(tw, HeaderStyle.Render("Metrica")+"\t"+HeaderStyle.Render("Valor"))
 	_, _ = fmt.Fprintln(tw, "-------\t-----")
-	_, _ = fmt.Fprintf(tw, "Mensajes por segundo\t%.2f\n", m.data.MessageRate)
-	_, _ = fmt.Fprintf(tw, "Series observadas\t%.0f\n", m.data.SeriesCount)
-	_, _ = fmt.Fprintf(tw, "Nombres de metricas\t%.0f\n", m.data.MetricNameCount)
+	_, _ = fmt.Fprintln(tw, m.tracker.decorate(limitsRate, fmt.Sprintf("%s\t%.2f", limitsRate, m.data.MessageRate)))
+	_, _ = fmt.Fprintln(tw, m.tracker.decorate(limitsSeries, fmt.Sprintf("%s\t%.0f", limitsSeries, m.data.SeriesCount)))
+	_, _ = fmt.Fprintln(tw, m.tracker.decorate(limitsNames, fmt.Sprintf("%s\t%.0f", limitsNames, m.data.MetricNameCount)))
 	if m.data.ScrapeAgeSeconds >= 0 {
-		_, _ = fmt.Fprintf(tw, "Ultimo scrape (age)\t%ds\n", m.data.ScrapeAgeSeconds)
+		_, _ = fmt.Fprintln(tw, m.tracker.decorate(limitsScrape, fmt.Sprintf("%s\t%ds", limitsScrape, m.data.ScrapeAgeSeconds)))
 	} else {
-		_, _ = fmt.Fprintf(tw, "Ultimo scrape (age)\tN/A\n")
+		_, _ = fmt.Fprintln(tw, m.tracker.decorate(limitsScrape, fmt.Sprintf("%s\tN/A", limitsScrape)))
 	}
 	_ = tw.Flush()
+	writeWarnings(&body, m.data.Warnings)
 
 	body.WriteString("\nPresiona 'q' para salir.\n")
 	return BoxStyle.Render(body.String())
 }
+
+// limitsStates reduce el snapshot a estados discretos para detectar cambios en --watch:
+// flujo activo o en cero, conteos exactos y disponibilidad del scrape.
+func limitsStates(snap limits.Snapshot) map[string]string {
+	flow := "activo"
+	if snap.MessageRate <= 0 {
+		flow = "cero"
+	}
+	scrape := "observable"
+	if snap.ScrapeAgeSeconds < 0 {
+		scrape = "N/A"
+	}
+	return map[string]string{
+		limitsRate:   flow,
+		limitsSeries: fmt.Sprintf("%.0f", snap.SeriesCount),
+		limitsNames:  fmt.Sprintf("%.0f", snap.MetricNameCount),
+		limitsScrape: scrape,
+	}
+}
@@ -12,6 +12,7 @@ import (
 
 	"drone-observe/internal/config"
 	"drone-observe/internal/prometheus"
+	"drone-observe/internal/queries"
 
 	tea "github.com/charmbracelet/bubbletea"
 )
@@ -25,12 +26,14 @@ type llmMsg struct {
 	UpdatedAt  time.Time
 	HasError   bool
 	ErrorLabel string
+	Warnings   []string
 }
 
 type llmModel struct {
 	cfg        config.Config
 	last       llmMsg
 	lastUpdate time.Time
+	alerts     alertsPane
 }
 
 func RunLLM(cfg config.Config) error {
@@ -41,7 +44,7 @@ func RunLLM(cfg config.Config) error {
 }
 
 func (m llmModel) Init() tea.Cmd {
-	return tea.Batch(fetchLLMCmd(m.cfg), llmTickCmd())
+	return tea.Batch(fetchLLMCmd(m.cfg), llmTickCmd(), alertsPaneCmd(m.cfg))
 }
 
 // PARTE CRITICA **********************
@@ -53,7 +56,9 @@ func fetchLLMCmd(cfg config.Config) tea.Cmd {
 		ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second)
 		defer cancel()
 
-		scoreVal, scoreOK, err := prometheus.QueryInstant(ctx, cfg.PrometheusURL, "ml_anomaly_score")
+		var notes []string
+		scoreVec, err := prometheus.QueryVector(ctx, cfg.PrometheusURL, queries.MLAnomalyScore.Expr)
+		score, scoreOK := scoreVec.Aggregate(prometheus.RuleMax)
 		if err != nil || !scoreOK {
 			return llmMsg{
 				HasError:   true,
@@ -61,8 +66,12 @@ func fetchLLMCmd(cfg config.Config) tea.Cmd {
 				UpdatedAt:  time.Now(),
 			}
 		}
+		if note := scoreVec.MultiSeriesNote(prometheus.RuleMax); note != "" {
+			notes = append(notes, "ml_anomaly_score: "+note)
+		}
 
-		stateVal, stateOK, err := prometheus.QueryInstant(ctx, cfg.PrometheusURL, "ml_state")
+		stateVec, err := prometheus.QueryVector(ctx, cfg.PrometheusURL, queries.MLState.Expr)
+		state, stateOK := stateVec.Aggregate(prometheus.RuleMax)
 		if err != nil || !stateOK {
 			return llmMsg{
 				HasError:   true,
@@ -70,22 +79,54 @@ func fetchLLMCmd(cfg config.Config) tea.Cmd {
 				UpdatedAt:  time.Now(),
 			}
 		}
+		if note := stateVec.MultiSeriesNote(prometheus.RuleMax); note != "" {
+			notes = append(notes, "ml_state: "+note)
+		}
 
-		stateLabel, alertLabel := llmStateLabels(stateVal)
+		stateLabel, alertLabel := llmStateLabels(state.Value)
 		return llmMsg{
-			Score:      fmt.Sprintf("%.3f", scoreVal),
+			Score:      fmt.Sprintf("%.3f", score.Value),
 			State:      stateLabel,
 			AlertLabel: alertLabel,
 			UpdatedAt:  time.Now(),
+			Warnings:   notes,
 		}
 	}
 }
 
+// mlStates devuelve ml_state en vivo como OK/WARN/CRIT (la misma escala que la TUI).
+func mlStates(cfg config.Config) map[string]string {
+	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second)
+	defer cancel()
+	vec, err := prometheus.QueryVector(ctx, cfg.PrometheusURL, queries.MLState.Expr)
+	state, ok := vec.Aggregate(prometheus.RuleMax)
+	if err != nil || !ok {
+		return map[string]string{"ml_state": "sin datos"}
+	}
+	return map[string]string{"ml_state": mlStateLabel(state.Value)}
+}
+
+func mlStateLabel(state float64) string {
+	switch int(state + 0.5) {
+	case 0:
+		return "OK"
+	case 1:
+		return "WARN"
+	case 2:
+		return "CRIT"
+	default:
+		return "UNKNOWN"
+	}
+}
+
 func llmTickCmd() tea.Cmd {
 	return tea.Tick(llmRefresh, func(t time.Time) tea.Msg { return t })
 }
 
 func (m llmModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if cmd, ok := m.alerts.handle(m.cfg, msg); ok {
+		return m, cmd
+	}
 	switch v := msg.(type) {
 	case llmMsg:
 		m.last = v
@@ -121,9 +162,10 @@ func (m llmModel) View() string {
 		_, _ = fmt.Fprintf(tw, "%s\t%s\n", "ml_state", m.last.State)
 	}
 	_ = tw.Flush()
+	writeWarnings(&sb, m.last.Warnings)
 
 	alertLine := formatAlertLine(m.last)
-	body := fmt.Sprintf("%s\n%s\n%s\n\n%s\n\n%s\nPresiona 'q' para salir.\n", title, refresh, SubtitleStyle.Render(ts), sb.String(), alertLine)
+	body := fmt.Sprintf("%s\n%s\n%s\n\n%s\n\n%s\n\n%s\nPresiona 'q' para salir.\n", title, refresh, SubtitleStyle.Render(ts), sb.String(), alertLine, m.alerts.View())
 	return BoxStyle.Render(body)
 }
 
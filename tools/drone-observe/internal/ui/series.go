@@ -0,0 +1,31 @@
+// Archivo: tools/drone-observe/internal/ui/series.go
+// Rol: helpers de presentacion para resultados Prometheus con varias series.
+// No hace: consultas ni reduccion de series; eso vive en internal/prometheus.
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"drone-observe/internal/prometheus"
+)
+
+// writeWarnings agrega un bloque de avisos al cuerpo si hay alguno.
+func writeWarnings(body *strings.Builder, warnings []string) {
+	if len(warnings) == 0 {
+		return
+	}
+	body.WriteString("\n")
+	for _, w := range warnings {
+		body.WriteString(WarnStyle.Render(w) + "\n")
+	}
+}
+
+// seriesDetail lista cada serie con sus labels y valor, para detalles de una linea.
+func seriesDetail(vec prometheus.Vector, format string) string {
+	parts := make([]string, 0, len(vec))
+	for _, s := range vec {
+		parts = append(parts, fmt.Sprintf("%s="+format, s.LabelString(), s.Value))
+	}
+	return strings.Join(parts, ", ")
+}
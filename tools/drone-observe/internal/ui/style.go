@@ -30,6 +30,10 @@ var (
 			Padding(1, 2).
 			Width(94)
 
+	ChangedStyle = lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color("#E879F9"))
+
 	HeaderStyle = lipgloss.NewStyle().
 			Bold(true).
 			Foreground(lipgloss.Color("#E2E8F0"))
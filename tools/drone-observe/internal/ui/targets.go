@@ -0,0 +1,223 @@
+// Archivo: tools/drone-observe/internal/ui/targets.go
+// Rol: TUI de salud de scrape por job (targets de Prometheus vs prometheus.yml versionado).
+// No hace: modificar targets ni recargar Prometheus.
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"drone-observe/internal/config"
+	"drone-observe/internal/targets"
+	"drone-observe/internal/topology"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/bubbles/spinner"
+)
+
+type targetsMsg struct {
+	Report targets.Report
+	Err    error
+}
+
+type targetsModel struct {
+	cfg     config.Config
+	spinner spinner.Model
+	report  targets.Report
+	err     error
+	done    bool
+	watch   time.Duration
+	tracker *changeTracker
+	running bool
+}
+
+func RunTargets(cfg config.Config, watch time.Duration) error {
+	s := spinner.New()
+	s.Spinner = spinner.Line
+	m := targetsModel{cfg: cfg, spinner: s, watch: watch}
+	if watch > 0 {
+		m.tracker = newChangeTracker()
+	}
+	p := tea.NewProgram(m, tea.WithAltScreen())
+	_, err := p.Run()
+	return err
+}
+
+func (m targetsModel) Init() tea.Cmd {
+	return tea.Batch(m.spinner.Tick, targetsCmd(m.cfg))
+}
+
+func targetsCmd(cfg config.Config) tea.Cmd {
+	return func() tea.Msg {
+		report, err := targets.Check(cfg)
+		return targetsMsg{Report: report, Err: err}
+	}
+}
+
+func (m targetsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch v := msg.(type) {
+	case targetsMsg:
+		m.report, m.err = v.Report, v.Err
+		m.done = true
+		m.running = false
+		if m.tracker != nil {
+			m.tracker.observe(time.Now(), targetsStates(m.report, m.err))
+			return m, watchTickCmd(m.watch)
+		}
+		return m, nil
+	case watchTickMsg:
+		m.running = true
+		return m, targetsCmd(m.cfg)
+	case tea.KeyMsg:
+		if v.String() == "q" || v.String() == "ctrl+c" {
+			return m, tea.Quit
+		}
+	}
+	var cmd tea.Cmd
+	m.spinner, cmd = m.spinner.Update(msg)
+	return m, cmd
+}
+
+func (m targetsModel) View() string {
+	title := TitleStyle.Render("drone-observe targets")
+	sub := WarnStyle.Render(fmt.Sprintf("Scrape por job (%s)", m.cfg.PrometheusURL))
+
+	var body strings.Builder
+	body.WriteString(fmt.Sprintf("%s\n%s\n", title, sub))
+	if h := m.tracker.header(m.watch, m.running); h != "" {
+		body.WriteString(h + "\n")
+	}
+	body.WriteString(strings.Repeat("─", 44) + "\n")
+	if !m.done {
+		body.WriteString("\n" + m.spinner.View())
+		return BoxStyle.Render(body.String())
+	}
+	if m.err != nil {
+		body.WriteString(FailStyle.Render(m.err.Error()) + "\n\nPresiona 'q' para salir.\n")
+		return BoxStyle.Render(body.String())
+	}
+
+	now := time.Now()
+	tw := tabwriter.NewWriter(&body, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, strings.Join([]string{
+		HeaderStyle.Render("Job"), HeaderStyle.Render("Instancia"), HeaderStyle.Render("Estado"), HeaderStyle.Render("Ultimo scrape"),
+		HeaderStyle.Render("Duracion"), HeaderStyle.Render("Intervalo (cfg)"), HeaderStyle.Render("Samples"),
+		HeaderStyle.Render("Metadata"),
+	}, "\t"))
+	_, _ = fmt.Fprintln(tw, "---\t---------\t------\t-------------\t--------\t---------------\t-------\t--------")
+	for _, j := range m.report.Jobs {
+		for _, line := range jobRows(j, now) {
+			_, _ = fmt.Fprintln(tw, m.tracker.decorate(j.Name, line))
+		}
+	}
+	_ = tw.Flush()
+
+	var errs []string
+	for _, j := range m.report.Jobs {
+		for _, t := range j.Targets {
+			if t.LastError != "" {
+				errs = append(errs, fmt.Sprintf("%s %s: %s", j.Name, t.Instance, t.LastError))
+			}
+		}
+		if j.MetadataErr != "" {
+			errs = append(errs, fmt.Sprintf("%s metadata: %s", j.Name, j.MetadataErr))
+		}
+	}
+	if len(errs) > 0 {
+		body.WriteString("\n" + HeaderStyle.Render("Ultimo error") + "\n")
+		for _, e := range errs {
+			body.WriteString(FailStyle.Render(e) + "\n")
+		}
+	}
+	writeWarnings(&body, m.report.Warnings)
+	m.tracker.writeResolved(&body)
+
+	body.WriteString("\nPresiona 'q' para salir.\n")
+	return BoxStyle.Render(body.String())
+}
+
+// jobRows arma una fila por target; el job (con su componente y up/total) va solo en la primera.
+func jobRows(j targets.Job, now time.Time) []string {
+	name := j.Name
+	if c := topology.ComponentForJob(j.Name); c != "" {
+		name += " (" + c + ")"
+	}
+	if len(j.Targets) == 0 {
+		return []string{fmt.Sprintf("%s\t-\t%s\t-\t-\t-\t-\t-", name, WarnStyle.Render("sin targets"))}
+	}
+	if up, total := j.Up(); total > 1 {
+		name += fmt.Sprintf(" %d/%d", up, total)
+	}
+	metadata := fmt.Sprintf("%d", j.Metadata)
+	if j.MetadataErr != "" {
+		metadata = FailStyle.Render("N/A")
+	}
+	rows := make([]string, 0, len(j.Targets))
+	for i, t := range j.Targets {
+		rows = append(rows, fmt.Sprintf("%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s",
+			name, t.Instance, targetHealth(t), scrapeAge(t, now), t.Duration.Round(time.Millisecond),
+			intervalCell(t), samplesCell(t), metadata))
+		if i == 0 {
+			name, metadata = "", ""
+		}
+	}
+	return rows
+}
+
+func targetHealth(t targets.Target) string {
+	switch t.Health {
+	case "up":
+		return OKStyle.Render("UP")
+	case "down":
+		return FailStyle.Render("DOWN")
+	}
+	return WarnStyle.Render(strings.ToUpper(t.Health))
+}
+
+func scrapeAge(t targets.Target, now time.Time) string {
+	if t.LastScrape.IsZero() {
+		return "N/A"
+	}
+	age := fmt.Sprintf("hace %s", now.Sub(t.LastScrape).Round(time.Second))
+	if t.Stale(now) {
+		return WarnStyle.Render(age + " (atrasado)")
+	}
+	return age
+}
+
+// intervalCell muestra el intervalo efectivo y, si difiere, el de prometheus.yml versionado.
+func intervalCell(t targets.Target) string {
+	if t.Configured == 0 || t.Configured == t.Interval {
+		return t.Interval.String()
+	}
+	return WarnStyle.Render(fmt.Sprintf("%s (%s)", t.Interval, t.Configured))
+}
+
+func samplesCell(t targets.Target) string {
+	if !t.HasSamples {
+		return "N/A"
+	}
+	return fmt.Sprintf("%.0f", t.Samples)
+}
+
+// targetsStates reduce el reporte a estados discretos por job para --watch: up/total e intervalos distintos.
+func targetsStates(report targets.Report, err error) map[string]string {
+	if err != nil {
+		return map[string]string{"Prometheus /api/v1/targets": "error"}
+	}
+	states := map[string]string{}
+	for _, j := range report.Jobs {
+		up, total := j.Up()
+		state := fmt.Sprintf("%d/%d", up, total)
+		for _, t := range j.Targets {
+			if t.Configured != 0 && t.Configured != t.Interval {
+				state += " intervalo distinto"
+				break
+			}
+		}
+		states[j.Name] = state
+	}
+	return states
+}
@@ -12,6 +12,7 @@ import (
 
 	"drone-observe/internal/config"
 	"drone-observe/internal/prometheus"
+	"drone-observe/internal/queries"
 
 	tea "github.com/charmbracelet/bubbletea"
 )
@@ -24,12 +25,14 @@ type telemetryMsg struct {
 	UpdatedAt  time.Time
 	HasError   bool
 	ErrorLabel string
+	Warnings   []string
 }
 
 type telemetryModel struct {
 	cfg        config.Config
 	last       telemetryMsg
 	lastUpdate time.Time
+	alerts     alertsPane
 }
 
 func RunTelemetry(cfg config.Config) error {
@@ -40,7 +43,7 @@ func RunTelemetry(cfg config.Config) error {
 }
 
 func (m telemetryModel) Init() tea.Cmd {
-	return tea.Batch(fetchTelemetryCmd(m.cfg), tickCmd())
+	return tea.Batch(fetchTelemetryCmd(m.cfg), tickCmd(), alertsPaneCmd(m.cfg))
 }
 
 // PARTE CRITICA **********************
@@ -53,7 +56,9 @@ func fetchTelemetryCmd(cfg config.Config) tea.Cmd {
 		ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second)
 		defer cancel()
 
-		batteryVal, batteryOK, err := prometheus.QueryInstant(ctx, cfg.PrometheusURL, "drone_battery_last_pct")
+		var notes []string
+		batteryVec, err := prometheus.QueryVector(ctx, cfg.PrometheusURL, queries.BatteryPct.Expr)
+		battery, batteryOK := batteryVec.Aggregate(prometheus.RuleMin)
 		if err != nil || !batteryOK {
 			return telemetryMsg{
 				HasError:   true,
@@ -61,8 +66,12 @@ func fetchTelemetryCmd(cfg config.Config) tea.Cmd {
 				UpdatedAt:  time.Now(),
 			}
 		}
+		if note := batteryVec.MultiSeriesNote(prometheus.RuleMin); note != "" {
+			notes = append(notes, "Bateria: "+note)
+		}
 
-		msgRateVal, msgRateOK, err := prometheus.QueryInstant(ctx, cfg.PrometheusURL, "rate(mqtt_messages_total[1m])")
+		msgRateVec, err := prometheus.QueryVector(ctx, cfg.PrometheusURL, queries.MessageRate.Expr)
+		msgRate, msgRateOK := msgRateVec.Aggregate(prometheus.RuleSum)
 		if err != nil || !msgRateOK {
 			return telemetryMsg{
 				HasError:   true,
@@ -70,11 +79,15 @@ func fetchTelemetryCmd(cfg config.Config) tea.Cmd {
 				UpdatedAt:  time.Now(),
 			}
 		}
+		if note := msgRateVec.MultiSeriesNote(prometheus.RuleSum); note != "" {
+			notes = append(notes, "Mensajes: "+note)
+		}
 
 		return telemetryMsg{
-			Battery:   fmt.Sprintf("%.0f%%", batteryVal),
-			MsgRate:   fmt.Sprintf("%.2f msg/s", msgRateVal),
+			Battery:   fmt.Sprintf("%.0f%%", battery.Value),
+			MsgRate:   fmt.Sprintf("%.2f msg/s", msgRate.Value),
 			UpdatedAt: time.Now(),
+			Warnings:  notes,
 		}
 	}
 }
@@ -84,6 +97,9 @@ func tickCmd() tea.Cmd {
 }
 
 func (m telemetryModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if cmd, ok := m.alerts.handle(m.cfg, msg); ok {
+		return m, cmd
+	}
 	switch v := msg.(type) {
 	case telemetryMsg:
 		m.last = v
@@ -117,7 +133,8 @@ func (m telemetryModel) View() string {
 		_, _ = fmt.Fprintf(tw, "%s\t%s\n", OKStyle.Render(m.last.Battery), OKStyle.Render(m.last.MsgRate))
 	}
 	_ = tw.Flush()
+	writeWarnings(&sb, m.last.Warnings)
 
-	body := fmt.Sprintf("%s\n%s\n%s\n\n%s\nPresiona 'q' para salir.\n", title, refresh, SubtitleStyle.Render(ts), sb.String())
+	body := fmt.Sprintf("%s\n%s\n%s\n\n%s\n%s\nPresiona 'q' para salir.\n", title, refresh, SubtitleStyle.Render(ts), sb.String(), m.alerts.View())
 	return BoxStyle.Render(body)
 }
@@ -6,6 +6,7 @@ package ui
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"drone-observe/internal/config"
 	"drone-observe/internal/topology"
@@ -23,19 +24,26 @@ type topologyModel struct {
 	spinner spinner.Model
 	items   []topology.Component
 	done    bool
+	watch   time.Duration
+	tracker *changeTracker
+	running bool
 }
 
-func RunTopology(cfg config.Config) error {
-	m := newTopologyModel(cfg)
+func RunTopology(cfg config.Config, watch time.Duration) error {
+	m := newTopologyModel(cfg, watch)
 	p := tea.NewProgram(m, tea.WithAltScreen())
 	_, err := p.Run()
 	return err
 }
 
-func newTopologyModel(cfg config.Config) topologyModel {
+func newTopologyModel(cfg config.Config, watch time.Duration) topologyModel {
 	s := spinner.New()
 	s.Spinner = spinner.Line
-	return topologyModel{cfg: cfg, spinner: s}
+	m := topologyModel{cfg: cfg, spinner: s, watch: watch}
+	if watch > 0 {
+		m.tracker = newChangeTracker()
+	}
+	return m
 }
 
 func (m topologyModel) Init() tea.Cmd {
@@ -59,7 +67,15 @@ func (m topologyModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case topologyMsg:
 		m.items = v.Items
 		m.done = true
+		m.running = false
+		if m.tracker != nil {
+			m.tracker.observe(time.Now(), topologyStates(m.items))
+			return m, watchTickCmd(m.watch)
+		}
 		return m, nil
+	case watchTickMsg:
+		m.running = true
+		return m, topologyCmd(m.cfg)
 	case tea.KeyMsg:
 		if v.String() == "q" || v.String() == "ctrl+c" {
 			return m, tea.Quit
@@ -75,7 +91,14 @@ func (m topologyModel) View() string {
 	sub := WarnStyle.Render("System Topology")
 
 	var body strings.Builder
-	body.WriteString(fmt.Sprintf("%s\n%s\n%s\n", title, sub, strings.Repeat("─", 44)))
+	body.WriteString(fmt.Sprintf("%s\n%s\n", title, sub))
+	if h := historyHeader(m.cfg, "topology"); h != "" {
+		body.WriteString(h + "\n")
+	}
+	if h := m.tracker.header(m.watch, m.running); h != "" {
+		body.WriteString(h + "\n")
+	}
+	body.WriteString(strings.Repeat("─", 44) + "\n")
 	if len(m.items) == 0 && !m.done {
 		body.WriteString("\n" + m.spinner.View())
 		return BoxStyle.Render(body.String())
@@ -91,8 +114,9 @@ func (m topologyModel) View() string {
 		if it.Detail != "" {
 			line += fmt.Sprintf(" (%s)", it.Detail)
 		}
-		body.WriteString(line + "\n")
+		body.WriteString(m.tracker.decorate(it.Name, line) + "\n")
 	}
+	m.tracker.writeResolved(&body)
 
 	body.WriteString("\nPresiona 'q' para salir.\n")
 	return BoxStyle.Render(body.String())
@@ -7,14 +7,13 @@ import (
 	"bufio"
 	"context"
 	"fmt"
-	"os"
-	"path/filepath"
 	"sort"
 	"strings"
 	"time"
 
 	"drone-observe/internal/config"
-	"drone-observe/internal/prometheus"
+	"drone-observe/internal/contract"
+	"drone-observe/internal/source"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/bubbles/spinner"
@@ -37,22 +36,30 @@ type validateModel struct {
 	items   []validateItem
 	done    bool
 	ok      bool
+	watch   time.Duration
+	tracker *changeTracker
+	running bool
 }
 
-func RunValidate(cfg config.Config) error {
-	m := newValidateModel(cfg)
+func RunValidate(cfg config.Config, watch time.Duration) error {
+	m := newValidateModel(cfg, watch)
 	p := tea.NewProgram(m, tea.WithAltScreen())
 	_, err := p.Run()
 	return err
 }
 
-func newValidateModel(cfg config.Config) validateModel {
+func newValidateModel(cfg config.Config, watch time.Duration) validateModel {
 	s := spinner.New()
 	s.Spinner = spinner.Line
-	return validateModel{
+	m := validateModel{
 		cfg:     cfg,
 		spinner: s,
+		watch:   watch,
 	}
+	if watch > 0 {
+		m.tracker = newChangeTracker()
+	}
+	return m
 }
 
 func (m validateModel) Init() tea.Cmd {
@@ -66,68 +73,103 @@ func (m validateModel) Init() tea.Cmd {
 // FIN DE PARTE CRITICA ****************
 func validateCmd(cfg config.Config) tea.Cmd {
 	return func() tea.Msg {
-		ctx, cancel := context.WithTimeout(context.Background(), 6*time.Second)
-		defer cancel()
+		items, ok := validateItems(cfg)
+		return validateResultMsg{Items: items, OK: ok}
+	}
+}
 
-		contractMetrics, err := readMetricsContract(cfg.MetricsDocPath)
-		if err != nil {
-			return validateResultMsg{
-				Items: []validateItem{{Name: "Leer METRICS.md", Status: statusFail, Detail: err.Error()}},
-				OK:    false,
-			}
-		}
+// ValidateReport evalua validate para salidas no interactivas (bundle).
+func ValidateReport(cfg config.Config) any {
+	items, ok := validateItems(cfg)
+	return struct {
+		OK    bool
+		Items []validateItem
+	}{ok, items}
+}
 
-		items := make([]validateItem, 0, len(contractMetrics)+1)
-		for _, name := range contractMetrics {
-			val, ok, err := prometheus.QueryInstant(ctx, cfg.PrometheusURL, name)
-			if err != nil || !ok {
-				items = append(items, validateItem{
-					Name:   fmt.Sprintf("Metrica %s", name),
-					Status: statusFail,
-					Detail: "no visible en Prometheus",
-				})
-				continue
-			}
+// validateItems evalua el contrato en el instante de cfg; con --at se omite /metrics del backend (solo en vivo).
+func validateItems(cfg config.Config) ([]validateItem, bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), 6*time.Second)
+	defer cancel()
+
+	catalog, err := contract.Load(cfg.MetricsDocPath)
+	if err != nil {
+		return []validateItem{{Name: "Leer METRICS.md", Status: statusFail, Detail: err.Error()}}, false
+	}
+
+	at := cfg.EvalTime()
+	src := source.For(cfg)
+	contractMetrics := catalog.CurrentNames()
+	items := make([]validateItem, 0, len(contractMetrics)+1)
+	for _, name := range contractMetrics {
+		vec, err := src.Prometheus.Query(ctx, name, at)
+		if err != nil || len(vec) == 0 {
 			items = append(items, validateItem{
 				Name:   fmt.Sprintf("Metrica %s", name),
-				Status: statusOK,
-				Detail: fmt.Sprintf("valor=%.2f", val),
+				Status: statusFail,
+				Detail: "no visible en Prometheus",
+			})
+			continue
+		}
+		// El contrato actual no define labels: cada metrica debe ser una sola serie.
+		if len(vec) > 1 {
+			items = append(items, validateItem{
+				Name:   fmt.Sprintf("Metrica %s", name),
+				Status: statusWarn,
+				Detail: fmt.Sprintf("%d series (esperada 1)", len(vec)),
 			})
+			for _, s := range vec {
+				items = append(items, validateItem{
+					Name:   "  └ " + s.LabelString(),
+					Status: statusWarn,
+					Detail: fmt.Sprintf("valor=%.2f", s.Value),
+				})
+			}
+			continue
 		}
+		items = append(items, validateItem{
+			Name:   fmt.Sprintf("Metrica %s", name),
+			Status: statusOK,
+			Detail: fmt.Sprintf("valor=%.2f", vec[0].Value),
+		})
+	}
 
-		unexpected, err := readBackendMetrics(cfg.BackendMetricsURL)
-		if err != nil {
+	if cfg.Historical() {
+		return items, allOK(items)
+	}
+	unexpected, err := readBackendMetrics(src.Backend)
+	if err != nil {
+		items = append(items, validateItem{
+			Name:   "Metricas inesperadas en backend",
+			Status: statusFail,
+			Detail: err.Error(),
+		})
+	} else {
+		extra := diffUnexpected(contractMetrics, unexpected)
+		if len(extra) > 0 {
 			items = append(items, validateItem{
 				Name:   "Metricas inesperadas en backend",
 				Status: statusFail,
-				Detail: err.Error(),
+				Detail: strings.Join(extra, ", "),
 			})
 		} else {
-			extra := diffUnexpected(contractMetrics, unexpected)
-			if len(extra) > 0 {
-				items = append(items, validateItem{
-					Name:   "Metricas inesperadas en backend",
-					Status: statusFail,
-					Detail: strings.Join(extra, ", "),
-				})
-			} else {
-				items = append(items, validateItem{
-					Name:   "Metricas inesperadas en backend",
-					Status: statusOK,
-					Detail: "ninguna",
-				})
-			}
+			items = append(items, validateItem{
+				Name:   "Metricas inesperadas en backend",
+				Status: statusOK,
+				Detail: "ninguna",
+			})
 		}
+	}
+	return items, allOK(items)
+}
 
-		allOK := true
-		for _, it := range items {
-			if it.Status != statusOK {
-				allOK = false
-				break
-			}
+func allOK(items []validateItem) bool {
+	for _, it := range items {
+		if it.Status == statusFail {
+			return false
 		}
-		return validateResultMsg{Items: items, OK: allOK}
 	}
+	return true
 }
 
 func (m validateModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -136,7 +178,15 @@ func (m validateModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.items = v.Items
 		m.done = true
 		m.ok = v.OK
+		m.running = false
+		if m.tracker != nil {
+			m.tracker.observe(time.Now(), validateStates(m.items))
+			return m, watchTickCmd(m.watch)
+		}
 		return m, nil
+	case watchTickMsg:
+		m.running = true
+		return m, validateCmd(m.cfg)
 	case tea.KeyMsg:
 		if v.String() == "q" || v.String() == "ctrl+c" {
 			return m, tea.Quit
@@ -151,7 +201,15 @@ func (m validateModel) View() string {
 	title := TitleStyle.Render("drone-observe validate")
 	statusLine := WarnStyle.Render("Ejecutando validacion...")
 	if m.done {
-		if m.ok {
+		warn := false
+		for _, it := range m.items {
+			if it.Status == statusWarn {
+				warn = true
+			}
+		}
+		if m.ok && warn {
+			statusLine = WarnStyle.Render("Resultado: OK con avisos")
+		} else if m.ok {
 			statusLine = OKStyle.Render("Resultado: OK")
 		} else {
 			statusLine = FailStyle.Render("Resultado: FAIL")
@@ -159,14 +217,22 @@ func (m validateModel) View() string {
 	}
 
 	var body strings.Builder
-	body.WriteString(fmt.Sprintf("%s\n%s\n%s\n", title, statusLine, strings.Repeat("─", 44)))
+	body.WriteString(fmt.Sprintf("%s\n%s\n", title, statusLine))
+	if h := historyHeader(m.cfg, "validate"); h != "" {
+		body.WriteString(h + "\n")
+	}
+	if h := m.tracker.header(m.watch, m.running); h != "" {
+		body.WriteString(h + "\n")
+	}
+	body.WriteString(strings.Repeat("─", 44) + "\n")
 	for _, it := range m.items {
 		line := fmt.Sprintf("%s\t%s", statusIcon(it.Status), it.Name)
 		if it.Detail != "" {
 			line += fmt.Sprintf(" (%s)", it.Detail)
 		}
-		body.WriteString(line + "\n")
+		body.WriteString(m.tracker.decorate(it.Name, line) + "\n")
 	}
+	m.tracker.writeResolved(&body)
 	if !m.done {
 		body.WriteString("\n" + m.spinner.View())
 	} else {
@@ -175,66 +241,16 @@ func (m validateModel) View() string {
 	return BoxStyle.Render(body.String())
 }
 
-func readMetricsContract(path string) ([]string, error) {
-	f, usedPath, err := openMetricsFile(path)
-	if err != nil {
-		return nil, err
-	}
-	defer f.Close()
-
-	var metrics []string
-	scanner := bufio.NewScanner(f)
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if !strings.HasPrefix(line, "|") {
-			continue
-		}
-		cols := strings.Split(line, "|")
-		if len(cols) < 2 {
-			continue
-		}
-		name := strings.TrimSpace(cols[1])
-		if name == "" || name == "nombre" || strings.HasPrefix(name, "---") {
-			continue
-		}
-		metrics = append(metrics, name)
-	}
-	if err := scanner.Err(); err != nil {
-		return nil, err
-	}
-	_ = usedPath
-	return metrics, nil
-}
-
-// PARTE CRITICA **********************
-// Se buscan rutas relativas controladas para evitar fallos al ejecutar desde tools/drone-observe.
-// Si se expanden rutas arbitrarias, se pierde determinismo y trazabilidad del contrato.
-// No usar paths absolutos hardcodeados aqui.
-// FIN DE PARTE CRITICA ****************
-func openMetricsFile(path string) (*os.File, string, error) {
-	candidates := []string{
-		path,
-		filepath.Join("..", path),
-		filepath.Join("..", "..", path),
-	}
-	for _, p := range candidates {
-		if f, err := os.Open(p); err == nil {
-			return f, p, nil
-		}
-	}
-	return nil, "", fmt.Errorf("no se encontro %s en rutas conocidas", path)
-}
-
 // PARTE CRITICA **********************
 // Se usa /metrics del backend para detectar metricas no contractuales.
 // Prometheus agrega metricas propias; por eso se evita usar label __name__.
 // No filtrar ni suprimir nombres aqui: se debe exponer el drift.
 // FIN DE PARTE CRITICA ****************
-func readBackendMetrics(url string) ([]string, error) {
+func readBackendMetrics(backend source.Backend) ([]string, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	resp, err := getBody(ctx, url)
+	resp, err := backend.Metrics(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,235 @@
+package ui
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"drone-observe/internal/alerts"
+	"drone-observe/internal/freshness"
+	"drone-observe/internal/queries"
+	"drone-observe/internal/testenv"
+	"drone-observe/internal/topology"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+var evalAt = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func TestTopologyView(t *testing.T) {
+	tests := []struct {
+		golden     string
+		historical bool
+		script     func(s *testenv.Stack)
+	}{
+		{golden: "topology_ok", script: func(s *testenv.Stack) {}},
+		{golden: "topology_edge_mudo_grafana_caido", script: func(s *testenv.Stack) {
+			s.Prometheus.Set(queries.MessageRate.Expr, testenv.Sample{Value: 0})
+			s.Grafana.SetHealthy(false)
+		}},
+		{golden: "topology_ml_down", script: func(s *testenv.Stack) {
+			s.Prometheus.SetTargets(
+				testenv.Target{Job: "backend", Instance: "backend:8080", Health: "up"},
+				testenv.Target{Job: "ml-analytics", Instance: "ml-analytics:9100", Health: "down", LastError: "connection refused"},
+			)
+		}},
+		{golden: "topology_historico", historical: true, script: func(s *testenv.Stack) {
+			s.Prometheus.Set(queries.ScrapeUp.Expr,
+				testenv.Sample{Labels: map[string]string{"job": "backend", "instance": "backend:8080"}, Value: 1})
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.golden, func(t *testing.T) {
+			s := sanoStack(t)
+			tt.script(s)
+			cfg := s.Config()
+			if tt.historical {
+				cfg.At = evalAt
+			}
+			m := update(t, newTopologyModel(cfg, 0), topologyMsg{Items: topology.Check(cfg)})
+			testenv.Golden(t, tt.golden, m.View())
+		})
+	}
+}
+
+func TestFreshnessView(t *testing.T) {
+	ago := func(d time.Duration) float64 { return float64(evalAt.Add(-d).Unix()) }
+	tests := []struct {
+		golden string
+		script func(s *testenv.Stack)
+	}{
+		{golden: "freshness_ok", script: func(s *testenv.Stack) {
+			s.Prometheus.Set(queries.BatteryTimestamp.Expr, testenv.Sample{Value: ago(4 * time.Second)})
+			s.Prometheus.Set(queries.MessagesTimestamp.Expr, testenv.Sample{Value: ago(2 * time.Second)})
+		}},
+		{golden: "freshness_varias_series", script: func(s *testenv.Stack) {
+			s.Prometheus.Set(queries.BatteryTimestamp.Expr,
+				testenv.Sample{Labels: map[string]string{"drone_id": "alpha"}, Value: ago(4 * time.Second)},
+				testenv.Sample{Labels: map[string]string{"drone_id": "bravo"}, Value: ago(200 * time.Second)})
+			s.Prometheus.Set(queries.MessagesTimestamp.Expr, testenv.Sample{Value: ago(40 * time.Second)})
+		}},
+		{golden: "freshness_sin_datos", script: func(s *testenv.Stack) {}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.golden, func(t *testing.T) {
+			s := testenv.NewStack(t)
+			tt.script(s)
+			cfg := s.Config()
+			cfg.At = evalAt
+			m := update(t, newFreshnessModel(cfg, 0), freshnessMsg{Signals: freshness.Check(cfg)})
+			testenv.Golden(t, tt.golden, m.View())
+		})
+	}
+}
+
+func TestHealthView(t *testing.T) {
+	tests := []struct {
+		golden string
+		script func(s *testenv.Stack)
+		alerts *alerts.Snapshot
+	}{
+		{golden: "health_ok", script: func(s *testenv.Stack) {}, alerts: &alerts.Snapshot{}},
+		{golden: "health_flujo_cortado", script: func(s *testenv.Stack) {
+			s.Prometheus.Set(queries.MessageRate.Expr, testenv.Sample{Value: 0})
+			s.Backend.SetStatus(http.StatusInternalServerError)
+		}, alerts: &alerts.Snapshot{}},
+		{golden: "health_varias_series", script: func(s *testenv.Stack) {
+			s.Prometheus.Set(queries.MessageRate.Expr,
+				testenv.Sample{Labels: map[string]string{"instance": "backend:8080"}, Value: 1},
+				testenv.Sample{Labels: map[string]string{"instance": "backend-2:8080"}, Value: 0.5})
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.golden, func(t *testing.T) {
+			s := sanoStack(t)
+			tt.script(s)
+			cfg := s.Config()
+			items, ok := healthItems(cfg)
+			m := update(t, newHealthModel(cfg), healthResultMsg{Items: items, OK: ok})
+			if tt.alerts != nil {
+				m = update(t, m, alertsPaneMsg{Snapshot: *tt.alerts})
+			}
+			testenv.Golden(t, tt.golden, m.View())
+		})
+	}
+}
+
+func TestValidateView(t *testing.T) {
+	tests := []struct {
+		golden string
+		script func(s *testenv.Stack)
+	}{
+		{golden: "validate_ok", script: func(s *testenv.Stack) {}},
+		{golden: "validate_drift", script: func(s *testenv.Stack) {
+			s.Prometheus.Set("ml_state")
+			s.Prometheus.Set("drone_battery_last_pct",
+				testenv.Sample{Labels: map[string]string{"drone_id": "alpha"}, Value: 80},
+				testenv.Sample{Labels: map[string]string{"drone_id": "bravo"}, Value: 35})
+			s.Backend.SetMetrics(backendMetrics + "# TYPE mqtt_parse_errors_total counter\nmqtt_parse_errors_total 3\n")
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.golden, func(t *testing.T) {
+			s := sanoStack(t)
+			s.Prometheus.Set("mqtt_messages_total", testenv.Sample{Value: 1520})
+			s.Prometheus.Set("drone_battery_last_pct", testenv.Sample{Value: 64})
+			s.Prometheus.Set("ml_state", testenv.Sample{Value: 0})
+			s.Backend.SetMetrics(backendMetrics)
+			tt.script(s)
+			cfg := s.Config()
+			cfg.MetricsDocPath = "testdata/METRICS.md"
+			items, ok := validateItems(cfg)
+			m := update(t, newValidateModel(cfg, 0), validateResultMsg{Items: items, OK: ok})
+			testenv.Golden(t, tt.golden, m.View())
+		})
+	}
+}
+
+func TestLiveStates(t *testing.T) {
+	tests := []struct {
+		name   string
+		check  string
+		script func(s *testenv.Stack)
+		item   string
+		want   string
+	}{
+		{"health sano", "health", func(s *testenv.Stack) {}, "Flujo de metricas", "OK"},
+		{"health sin flujo", "health", func(s *testenv.Stack) {
+			s.Prometheus.Set(queries.MessageRate.Expr, testenv.Sample{Value: 0})
+		}, "Flujo de metricas", "FAIL"},
+		{"health backend caido", "health", func(s *testenv.Stack) {
+			s.Backend.SetStatus(http.StatusBadGateway)
+		}, "Backend /metrics", "FAIL"},
+		{"ml ok", "ml", func(s *testenv.Stack) {
+			s.Prometheus.Set(queries.MLState.Expr, testenv.Sample{Value: 0})
+		}, "ml_state", "OK"},
+		{"ml crit", "ml", func(s *testenv.Stack) {
+			s.Prometheus.Set(queries.MLState.Expr, testenv.Sample{Value: 2})
+		}, "ml_state", "CRIT"},
+		{"ml sin datos", "ml", func(s *testenv.Stack) {}, "ml_state", "sin datos"},
+		{"topology via CheckStates", "topology", func(s *testenv.Stack) {
+			s.Prometheus.Set(queries.MessageRate.Expr, testenv.Sample{Value: 0})
+		}, "Edge", "MUDO"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := sanoStack(t)
+			tt.script(s)
+			fn, ok := LiveStates(tt.check)
+			if !ok {
+				t.Fatalf("LiveStates(%q) no existe", tt.check)
+			}
+			if got := fn(s.Config())[tt.item]; got != tt.want {
+				t.Errorf("%s/%s = %q; esperado %q", tt.check, tt.item, got, tt.want)
+			}
+		})
+	}
+	for _, check := range LiveChecks {
+		if _, ok := LiveStates(check); !ok {
+			t.Errorf("LiveChecks lista %q pero LiveStates no lo evalua", check)
+		}
+	}
+}
+
+// Un refresco manual trae otro snapshot: el tick que ya estaba programado no debe abrir un segundo ciclo.
+func TestAlertsSinglePollLoop(t *testing.T) {
+	var m tea.Model = alertsModel{}
+	m, _ = m.Update(alertsMsg{})
+	m, _ = m.Update(alertsMsg{})
+	if _, cmd := m.Update(alertsTickMsg{gen: 1}); cmd != nil {
+		t.Error("tick viejo reprogramo la consulta")
+	}
+	if _, cmd := m.Update(alertsTickMsg{gen: 2}); cmd == nil {
+		t.Error("tick vigente no reprogramo la consulta")
+	}
+}
+
+const backendMetrics = `# HELP mqtt_messages_total Total de mensajes MQTT.
+# TYPE mqtt_messages_total counter
+mqtt_messages_total 1520
+# TYPE drone_battery_last_pct gauge
+drone_battery_last_pct 64
+`
+
+// sanoStack arranca el stack falso con flujo de mensajes y los targets up.
+func sanoStack(t *testing.T) *testenv.Stack {
+	t.Helper()
+	s := testenv.NewStack(t)
+	s.Prometheus.Set(queries.MessageRate.Expr, testenv.Sample{Value: 1})
+	s.Prometheus.SetTargets(
+		testenv.Target{Job: "backend", Instance: "backend:8080", Health: "up"},
+		testenv.Target{Job: "ml-analytics", Instance: "ml-analytics:9100", Health: "up"},
+	)
+	return s
+}
+
+// update aplica un mensaje al modelo y descarta el comando (los tests no corren el programa).
+func update[M tea.Model](t *testing.T, m M, msg tea.Msg) M {
+	t.Helper()
+	next, _ := m.Update(msg)
+	out, ok := next.(M)
+	if !ok {
+		t.Fatalf("Update devolvio %T", next)
+	}
+	return out
+}
@@ -0,0 +1,135 @@
+// Archivo: tools/drone-observe/internal/ui/watch.go
+// Rol: soporte de --watch para checks puntuales: re-ejecucion periodica y deteccion de cambios.
+// No hace: persistencia del historial; solo compara rondas dentro de la misma sesion TUI.
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// watchTickMsg dispara la siguiente ronda de un check en modo --watch.
+type watchTickMsg struct{}
+
+func watchTickCmd(interval time.Duration) tea.Cmd {
+	return tea.Tick(interval, func(time.Time) tea.Msg { return watchTickMsg{} })
+}
+
+type itemState struct {
+	value       string
+	changedAt   time.Time
+	changed     bool
+	everChanged bool
+}
+
+// changeTracker recuerda el ultimo estado de cada item y cuando cambio por ultima vez.
+// Es un puntero para que las copias del modelo Bubble Tea compartan el historial.
+type changeTracker struct {
+	items    map[string]*itemState
+	round    int
+	lastRun  time.Time
+	resolved []string
+}
+
+func newChangeTracker() *changeTracker {
+	return &changeTracker{items: map[string]*itemState{}}
+}
+
+// PARTE CRITICA **********************
+// La primera ronda es linea base: nada se marca como cambiado.
+// Desde la segunda, un item cambia si su estado difiere, aparece o desaparece.
+// No comparar valores continuos (tasas, edades); solo estados discretos, o todo parpadea.
+// FIN DE PARTE CRITICA ****************
+func (t *changeTracker) observe(now time.Time, states map[string]string) {
+	t.round++
+	t.lastRun = now
+	t.resolved = nil
+	for key, st := range t.items {
+		if _, ok := states[key]; !ok {
+			t.resolved = append(t.resolved, key)
+			delete(t.items, key)
+			continue
+		}
+		st.changed = false
+	}
+	sort.Strings(t.resolved)
+	for key, value := range states {
+		st, ok := t.items[key]
+		if !ok {
+			t.items[key] = &itemState{value: value, changedAt: now, changed: t.round > 1, everChanged: t.round > 1}
+			continue
+		}
+		if st.value != value {
+			st.value = value
+			st.changedAt = now
+			st.changed = true
+			st.everChanged = true
+		}
+	}
+}
+
+func (t *changeTracker) changed(key string) bool {
+	if t == nil {
+		return false
+	}
+	st, ok := t.items[key]
+	return ok && st.changed
+}
+
+// since describe cuando cambio el item por ultima vez.
+func (t *changeTracker) since(key string) string {
+	if t == nil {
+		return ""
+	}
+	st, ok := t.items[key]
+	if !ok {
+		return ""
+	}
+	if !st.everChanged {
+		return "estable desde " + st.changedAt.Format("15:04:05")
+	}
+	return "cambio " + st.changedAt.Format("15:04:05")
+}
+
+// decorate resalta una linea si el item cambio en la ultima ronda y agrega cuando cambio.
+// Sin modo watch (tracker nil) devuelve la linea intacta.
+func (t *changeTracker) decorate(key, line string) string {
+	if t == nil {
+		return line
+	}
+	since := SubtitleStyle.Render("[" + t.since(key) + "]")
+	if t.changed(key) {
+		return ChangedStyle.Render("» ") + line + " " + since
+	}
+	return "  " + line + " " + since
+}
+
+// header describe el estado del modo watch para la cabecera de la vista.
+func (t *changeTracker) header(interval time.Duration, running bool) string {
+	if t == nil || interval <= 0 {
+		return ""
+	}
+	parts := []string{fmt.Sprintf("Watch: cada %s", interval), fmt.Sprintf("ronda %d", t.round)}
+	if !t.lastRun.IsZero() {
+		parts = append(parts, "ultima "+t.lastRun.Format("15:04:05"))
+	}
+	if running {
+		parts = append(parts, "actualizando...")
+	}
+	return SubtitleStyle.Render(strings.Join(parts, " · "))
+}
+
+// writeResolved lista items que desaparecieron en la ultima ronda.
+func (t *changeTracker) writeResolved(body *strings.Builder) {
+	if t == nil || len(t.resolved) == 0 {
+		return
+	}
+	body.WriteString("\n" + ChangedStyle.Render("Desaparecidos en la ultima ronda:") + "\n")
+	for _, key := range t.resolved {
+		body.WriteString("  " + key + "\n")
+	}
+}